@@ -0,0 +1,43 @@
+package astits
+
+// AccessUnit represents an access unit assembled from a PES
+type AccessUnit struct {
+	DTS          *ClockReference // Equals PTS when the PES doesn't carry a DTS
+	Payload      []byte
+	PID          uint16
+	PTS          *ClockReference
+	RandomAccess bool // Set when the adaptation field of the first packet of the PES has its random access indicator set
+}
+
+// AccessUnitHandler represents an object capable of handling access units
+type AccessUnitHandler func(au *AccessUnit)
+
+// NewAccessUnit creates a new access unit based on a data
+// It returns nil if the data doesn't contain a PES
+func NewAccessUnit(d *Data) (au *AccessUnit) {
+	// No PES
+	if d.PES == nil || d.PES.Header == nil {
+		return
+	}
+
+	// Create access unit
+	au = &AccessUnit{
+		Payload: d.PES.Data,
+		PID:     d.PID,
+	}
+
+	// Timestamps
+	if h := d.PES.Header.OptionalHeader; h != nil {
+		au.PTS = h.PTS
+		au.DTS = h.DTS
+		if au.DTS == nil {
+			au.DTS = h.PTS
+		}
+	}
+
+	// Random access
+	if p := d.FirstPacket; p != nil && p.Header != nil && p.Header.HasAdaptationField && p.AdaptationField != nil {
+		au.RandomAccess = p.AdaptationField.RandomAccessIndicator
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAccessUnit(t *testing.T) {
+	// No PES
+	assert.Nil(t, NewAccessUnit(&Data{PAT: &PATData{}}))
+
+	// PTS only and random access
+	assert.Equal(t, &AccessUnit{
+		DTS:          ptsClockReference,
+		Payload:      []byte("data"),
+		PID:          256,
+		PTS:          ptsClockReference,
+		RandomAccess: true,
+	}, NewAccessUnit(&Data{
+		FirstPacket: &Packet{AdaptationField: &PacketAdaptationField{RandomAccessIndicator: true}, Header: &PacketHeader{HasAdaptationField: true}},
+		PES:         &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{PTS: ptsClockReference}}},
+		PID:         256,
+	}))
+
+	// PTS and DTS
+	assert.Equal(t, &AccessUnit{
+		DTS:     dtsClockReference,
+		Payload: []byte("data"),
+		PID:     256,
+		PTS:     ptsClockReference,
+	}, NewAccessUnit(&Data{
+		FirstPacket: &Packet{Header: &PacketHeader{}},
+		PES:         &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{DTS: dtsClockReference, PTS: ptsClockReference}}},
+		PID:         256,
+	}))
+}
@@ -0,0 +1,280 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// AF descriptor tags
+// Chapter: 2.4.3.6 | Link: ISO/IEC 13818-1
+// Chapter: 5.3 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102823/01.02.01_60/ts_102823v010201p.pdf
+const (
+	AFDescriptorTagTimeline = 0x04
+	AFDescriptorTagLocation = 0x05
+	AFDescriptorTagBaseURL  = 0x06
+)
+
+// TEMI URL schemes
+const (
+	TEMIURLSchemeNone  = 0x0
+	TEMIURLSchemeHTTP  = 0x1
+	TEMIURLSchemeHTTPS = 0x2
+)
+
+// AFDescriptor represents a descriptor carried in the adaptation field extension
+// Content holds the raw descriptor payload and is what is written back when serialising.
+type AFDescriptor struct {
+	BaseURL  *AFDescriptorBaseURL
+	Content  []byte
+	Length   uint8
+	Location *AFDescriptorLocation
+	Tag      uint8
+	Timeline *AFDescriptorTimeline
+}
+
+// AFDescriptorBaseURL represents a TEMI base URL descriptor
+// Chapter: 5.3.4 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102823/01.02.01_60/ts_102823v010201p.pdf
+type AFDescriptorBaseURL struct {
+	URLPath   []byte
+	URLScheme uint8
+}
+
+// AFDescriptorLocation represents a TEMI location descriptor
+// Chapter: 5.3.3 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102823/01.02.01_60/ts_102823v010201p.pdf
+type AFDescriptorLocation struct {
+	ForceReload                   bool
+	IsAnnouncement                bool
+	IsSplicing                    bool
+	TimeBeforeActivation          uint32 // Only set for announcements, in TimeBeforeActivationTimescale units
+	TimeBeforeActivationTimescale uint32
+	TimelineID                    uint8
+	URLPath                       []byte
+	URLScheme                     uint8
+	UseBaseTEMIURL                bool
+}
+
+// AFDescriptorTimeline represents a TEMI timeline descriptor
+// Chapter: 5.3.2 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102823/01.02.01_60/ts_102823v010201p.pdf
+type AFDescriptorTimeline struct {
+	Discontinuity      bool
+	Drop               bool
+	Duration           uint16
+	ForceReload        bool
+	FramesPerTCSeconds uint16
+	HasNTP             bool
+	HasPTP             bool
+	HasTimecode        uint8  // 0: no timecode, 1: short timecode, 2: long timecode
+	HasTimestamp       uint8  // 0: no timestamp, 1: 32 bits media timestamp, 2: 64 bits media timestamp
+	MediaTimestamp     uint64 // In Timescale units
+	NTPTimestamp       uint64
+	Paused             bool
+	PTPTimestamp       []byte // 80 bits
+	Timecode           uint64
+	TimelineID         uint8
+	Timescale          uint32
+}
+
+// parseAFDescriptors parses descriptors until offsetEnd
+func parseAFDescriptors(i *astikit.BytesIterator, offsetEnd int) (o []*AFDescriptor, err error) {
+	for i.Offset() < offsetEnd {
+		// Get next 2 bytes
+		var bs []byte
+		if bs, err = i.NextBytes(2); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create descriptor
+		d := &AFDescriptor{
+			Length: uint8(bs[1]),
+			Tag:    uint8(bs[0]),
+		}
+
+		// Get content
+		if d.Length > 0 {
+			if d.Content, err = i.NextBytes(int(d.Length)); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+		}
+
+		// Parse content
+		ci := astikit.NewBytesIterator(d.Content)
+		switch d.Tag {
+		case AFDescriptorTagBaseURL:
+			if d.BaseURL, err = newAFDescriptorBaseURL(ci); err != nil {
+				err = fmt.Errorf("astits: parsing base URL AF descriptor failed: %w", err)
+				return
+			}
+		case AFDescriptorTagLocation:
+			if d.Location, err = newAFDescriptorLocation(ci); err != nil {
+				err = fmt.Errorf("astits: parsing location AF descriptor failed: %w", err)
+				return
+			}
+		case AFDescriptorTagTimeline:
+			if d.Timeline, err = newAFDescriptorTimeline(ci); err != nil {
+				err = fmt.Errorf("astits: parsing timeline AF descriptor failed: %w", err)
+				return
+			}
+		}
+
+		// Append descriptor
+		o = append(o, d)
+	}
+	return
+}
+
+func parseTEMIURL(i *astikit.BytesIterator) (scheme uint8, path []byte, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+	scheme = bs[0]
+
+	// Path
+	if bs[1] > 0 {
+		if path, err = i.NextBytes(int(bs[1])); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+func newAFDescriptorBaseURL(i *astikit.BytesIterator) (d *AFDescriptorBaseURL, err error) {
+	d = &AFDescriptorBaseURL{}
+	if d.URLScheme, d.URLPath, err = parseTEMIURL(i); err != nil {
+		err = fmt.Errorf("astits: parsing TEMI URL failed: %w", err)
+		return
+	}
+	return
+}
+
+func newAFDescriptorLocation(i *astikit.BytesIterator) (d *AFDescriptorLocation, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &AFDescriptorLocation{
+		ForceReload:    bs[0]&0x80 > 0,
+		IsAnnouncement: bs[0]&0x40 > 0,
+		IsSplicing:     bs[0]&0x20 > 0,
+		TimelineID:     bs[1] & 0x7f,
+		UseBaseTEMIURL: bs[0]&0x10 > 0,
+	}
+
+	// URL
+	if !d.UseBaseTEMIURL {
+		if d.URLScheme, d.URLPath, err = parseTEMIURL(i); err != nil {
+			err = fmt.Errorf("astits: parsing TEMI URL failed: %w", err)
+			return
+		}
+	}
+
+	// Activation
+	if d.IsAnnouncement {
+		if bs, err = i.NextBytes(8); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.TimeBeforeActivationTimescale = uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])
+		d.TimeBeforeActivation = uint32(bs[4])<<24 | uint32(bs[5])<<16 | uint32(bs[6])<<8 | uint32(bs[7])
+	}
+	return
+}
+
+func newAFDescriptorTimeline(i *astikit.BytesIterator) (d *AFDescriptorTimeline, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(3); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &AFDescriptorTimeline{
+		Discontinuity: bs[1]&0x80 > 0,
+		ForceReload:   bs[0]&0x02 > 0,
+		HasNTP:        bs[0]&0x20 > 0,
+		HasPTP:        bs[0]&0x10 > 0,
+		HasTimecode:   bs[0] >> 2 & 0x3,
+		HasTimestamp:  bs[0] >> 6,
+		Paused:        bs[0]&0x01 > 0,
+		TimelineID:    bs[2],
+	}
+
+	// Timestamp
+	if d.HasTimestamp > 0 {
+		// Timescale
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.Timescale = uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])
+
+		// Media timestamp
+		n := 4
+		if d.HasTimestamp == 2 {
+			n = 8
+		}
+		if bs, err = i.NextBytes(n); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.MediaTimestamp = bytesToUint64(bs)
+	}
+
+	// NTP
+	if d.HasNTP {
+		if bs, err = i.NextBytes(8); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.NTPTimestamp = bytesToUint64(bs)
+	}
+
+	// PTP
+	if d.HasPTP {
+		if d.PTPTimestamp, err = i.NextBytes(10); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+
+	// Timecode
+	if d.HasTimecode > 0 {
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.Drop = bs[0]&0x80 > 0
+		d.FramesPerTCSeconds = uint16(bs[0]&0x7f)<<8 | uint16(bs[1])
+		d.Duration = uint16(bs[2])<<8 | uint16(bs[3])
+
+		// Time code
+		n := 3
+		if d.HasTimecode == 2 {
+			n = 8
+		}
+		if bs, err = i.NextBytes(n); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.Timecode = bytesToUint64(bs)
+	}
+	return
+}
+
+func bytesToUint64(bs []byte) (v uint64) {
+	for _, b := range bs {
+		v = v<<8 | uint64(b)
+	}
+	return
+}
@@ -0,0 +1,94 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAFDescriptors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint8(0))                       // Length, overwritten afterwards
+	w.Write("00000001")                     // Flags
+	w.Write(uint8(0))                       // Adaptation extension length, overwritten afterwards
+	w.Write("000")                          // LTW, piecewise rate and seamless splice flags
+	w.Write("0")                            // AF descriptor not present flag
+	w.Write("1111")                         // Reserved
+	w.Write(uint8(AFDescriptorTagTimeline)) // Tag
+	w.Write(uint8(26))                      // Length
+	w.Write("01")                           // Has timestamp
+	w.Write("1")                            // Has NTP
+	w.Write("0")                            // Has PTP
+	w.Write("01")                           // Has timecode
+	w.Write("1")                            // Force reload
+	w.Write("0")                            // Paused
+	w.Write("1")                            // Discontinuity
+	w.Write("1111111")                      // Reserved
+	w.Write(uint8(3))                       // Timeline ID
+	w.Write(uint32(1000))                   // Timescale
+	w.Write(uint32(5000))                   // Media timestamp
+	w.Write(uint64(6))                      // NTP timestamp
+	w.Write("1")                            // Drop
+	w.Write("000000000011001")              // Frames per TC seconds
+	w.Write(uint16(1))                      // Duration
+	w.Write([]byte{0x1, 0x2, 0x3})          // Short time code
+	w.Write(uint8(AFDescriptorTagLocation)) // Tag
+	w.Write(uint8(16))                      // Length
+	w.Write("0")                            // Force reload
+	w.Write("1")                            // Is announcement
+	w.Write("0")                            // Splicing flag
+	w.Write("0")                            // Use base TEMI URL
+	w.Write("11111")                        // Reserved
+	w.Write("0000100")                      // Timeline ID
+	w.Write(uint8(TEMIURLSchemeHTTPS))      // URL scheme
+	w.Write(uint8(4))                       // URL path length
+	w.Write([]byte("a/b/"))                 // URL path
+	w.Write(uint32(90000))                  // Time before activation timescale
+	w.Write(uint32(180000))                 // Time before activation
+	w.Write(uint8(0x7))                     // Tag
+	w.Write(uint8(1))                       // Length
+	w.Write(uint8(8))                       // Content
+	b := buf.Bytes()
+	b[0] = uint8(len(b) - 1)
+	b[2] = uint8(len(b) - 3)
+
+	// Parse
+	a, err := parsePacketAdaptationField(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.True(t, a.AdaptationExtensionField.HasAFDescriptors)
+	ds := a.AdaptationExtensionField.AFDescriptors
+	assert.Len(t, ds, 3)
+	assert.Equal(t, &AFDescriptorTimeline{
+		Discontinuity:      true,
+		Drop:               true,
+		Duration:           1,
+		ForceReload:        true,
+		FramesPerTCSeconds: 25,
+		HasNTP:             true,
+		HasTimecode:        1,
+		HasTimestamp:       1,
+		MediaTimestamp:     5000,
+		NTPTimestamp:       6,
+		Timecode:           0x010203,
+		TimelineID:         3,
+		Timescale:          1000,
+	}, ds[0].Timeline)
+	assert.Equal(t, &AFDescriptorLocation{
+		IsAnnouncement:                true,
+		TimeBeforeActivation:          180000,
+		TimeBeforeActivationTimescale: 90000,
+		TimelineID:                    4,
+		URLPath:                       []byte("a/b/"),
+		URLScheme:                     TEMIURLSchemeHTTPS,
+	}, ds[1].Location)
+	assert.Equal(t, &AFDescriptor{Content: []byte{8}, Length: 1, Tag: 0x7}, ds[2])
+
+	// Serialise
+	o := make([]byte, len(b))
+	n, err := a.Serialise(o)
+	assert.NoError(t, err)
+	assert.Equal(t, b, o[:n])
+}
@@ -389,7 +389,7 @@ func (s Stream) String() (o string) {
 		t = "MPEG-2 halved sample rate audio"
 	case astits.StreamTypeMPEG2PacketizedData:
 		t = "DVB subtitles/VBI or AC-3"
-	case astits.StreamTypeADTS:
+	case astits.StreamTypeAudioADTS:
 		t = "ADTS"
 	case astits.StreamTypeH264Video:
 		t = "H264 video"
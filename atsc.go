@@ -0,0 +1,270 @@
+package astits
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/asticode/go-astikit"
+)
+
+// ATSC string compression types
+// Chapter: 6.10 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+const (
+	ATSCCompressionTypeNone                      = 0x0
+	ATSCCompressionTypeHuffmanTitle              = 0x1 // Tables C.4 and C.5 of A/65 Annex C
+	ATSCCompressionTypeHuffmanProgramDescription = 0x2 // Tables C.6 and C.7 of A/65 Annex C
+)
+
+// ATSC string modes
+// Modes 0x00 to 0x33 select a Unicode page whose code points are the mode followed by each byte.
+// Chapter: 6.10 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+const (
+	ATSCModeLatin1         = 0x00
+	ATSCModeSCSU           = 0x3e // Standard Compression Scheme for Unicode
+	ATSCModeUTF16          = 0x3f
+	ATSCModeNotApplicable  = 0xff
+	atscHuffmanEscape      = 0x1b
+	atscHuffmanTerminator  = 0x00
+	atscHuffmanContextsNum = 128
+)
+
+// ATSC string errors
+var (
+	ErrATSCHuffmanDecodeTreesMissing = errors.New("astits: ATSC Huffman decode trees are missing")
+	ErrATSCModeNotSupported          = errors.New("astits: ATSC mode is not supported")
+)
+
+// ATSCHuffmanDecodeTrees represents Huffman decode trees, indexed by compression type, each laid out as in tables C.5
+// and C.7 of A/65 Annex C: 128 16 bits offsets to the tree of each prior character, each tree being a list of nodes
+// made of a byte per branch (0 then 1), the byte being either the index of the next node or, when its most
+// significant bit is set, the 7 bits decoded character
+// Decode trees are not bundled with the library and must be provided when decoding compressed segments.
+type ATSCHuffmanDecodeTrees map[uint8][]byte
+
+// ATSCMultipleString represents an ATSC multiple string structure
+// Chapter: 6.10 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type ATSCMultipleString struct {
+	Strings []*ATSCString
+}
+
+// ATSCString represents a string of an ATSC multiple string structure
+type ATSCString struct {
+	Language []byte // ISO 639 language code
+	Segments []*ATSCStringSegment
+}
+
+// ATSCStringSegment represents a segment of an ATSC string
+type ATSCStringSegment struct {
+	Bytes           []byte // Compressed bytes if compression type is not none
+	CompressionType uint8
+	Mode            uint8
+}
+
+// ParseATSCMultipleString parses an ATSC multiple string structure
+func ParseATSCMultipleString(b []byte) (*ATSCMultipleString, error) {
+	return parseATSCMultipleString(astikit.NewBytesIterator(b))
+}
+
+func parseATSCMultipleString(i *astikit.BytesIterator) (m *ATSCMultipleString, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Create multiple string
+	m = &ATSCMultipleString{}
+
+	// Loop through strings
+	for idx := 0; idx < int(b); idx++ {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create string
+		s := &ATSCString{Language: bs[:3]}
+
+		// Loop through segments
+		for segmentIdx := 0; segmentIdx < int(bs[3]); segmentIdx++ {
+			// Get next bytes
+			var sbs []byte
+			if sbs, err = i.NextBytes(3); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+
+			// Create segment
+			sg := &ATSCStringSegment{
+				CompressionType: sbs[0],
+				Mode:            sbs[1],
+			}
+
+			// Bytes
+			if sbs[2] > 0 {
+				if sg.Bytes, err = i.NextBytes(int(sbs[2])); err != nil {
+					err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+					return
+				}
+			}
+
+			// Append segment
+			s.Segments = append(s.Segments, sg)
+		}
+
+		// Append string
+		m.Strings = append(m.Strings, s)
+	}
+	return
+}
+
+// Decode decodes the string of the provided language, or of the first string if language is empty
+// ok is false if there is no such string.
+func (m *ATSCMultipleString) Decode(language string, ts ATSCHuffmanDecodeTrees) (s string, ok bool, err error) {
+	for _, v := range m.Strings {
+		if language == "" || string(v.Language) == language {
+			s, err = v.Decode(ts)
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+// Decode decodes the segments of the string and concatenates them
+func (s *ATSCString) Decode(ts ATSCHuffmanDecodeTrees) (string, error) {
+	var b strings.Builder
+	for idx, sg := range s.Segments {
+		v, err := sg.Decode(ts)
+		if err != nil {
+			return "", fmt.Errorf("astits: decoding segment #%d failed: %w", idx, err)
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+// Decode decompresses the segment if needed and decodes it according to its mode
+// Decode trees are only needed when the segment is compressed.
+func (s *ATSCStringSegment) Decode(ts ATSCHuffmanDecodeTrees) (o string, err error) {
+	// Decompress
+	b := s.Bytes
+	if s.CompressionType != ATSCCompressionTypeNone {
+		t, ok := ts[s.CompressionType]
+		if !ok {
+			err = ErrATSCHuffmanDecodeTreesMissing
+			return
+		}
+		if b, err = decodeATSCHuffman(b, t); err != nil {
+			err = fmt.Errorf("astits: decoding Huffman failed: %w", err)
+			return
+		}
+	}
+
+	// Switch on mode
+	switch {
+	case s.Mode == ATSCModeUTF16:
+		u := make([]uint16, len(b)/2)
+		for idx := range u {
+			u[idx] = uint16(b[2*idx])<<8 | uint16(b[2*idx+1])
+		}
+		o = string(utf16.Decode(u))
+	case s.Mode <= 0x33 || s.Mode == ATSCModeNotApplicable:
+		rs := make([]rune, len(b))
+		for idx, v := range b {
+			rs[idx] = rune(v)
+			if s.Mode != ATSCModeNotApplicable {
+				rs[idx] |= rune(s.Mode) << 8
+			}
+		}
+		o = string(rs)
+	default:
+		err = fmt.Errorf("astits: mode %#x: %w", s.Mode, ErrATSCModeNotSupported)
+	}
+	return
+}
+
+// decodeATSCHuffman decompresses bytes using decode trees laid out as in A/65 Annex C
+// The tree used to decode a character is the one of the prior character, the first character being decoded with the
+// tree of the terminator. An escape character is followed by an uncompressed 8 bits character.
+func decodeATSCHuffman(b, t []byte) (o []byte, err error) {
+	// Trees offsets
+	if len(t) < 2*atscHuffmanContextsNum {
+		err = fmt.Errorf("astits: decode trees length %d is too small", len(t))
+		return
+	}
+
+	// Loop through bits
+	r := &atscHuffmanBitsReader{b: b}
+	prior := byte(atscHuffmanTerminator)
+	for r.remaining() > 0 {
+		// Walk the tree of the prior character
+		var c byte
+		var ok bool
+		if c, ok, err = r.walk(t, prior); err != nil {
+			err = fmt.Errorf("astits: walking tree of %#x failed: %w", prior, err)
+			return
+		} else if !ok || c == atscHuffmanTerminator {
+			// Remaining bits are padding
+			break
+		}
+
+		// Escaped character
+		if c == atscHuffmanEscape {
+			if r.remaining() < 8 {
+				break
+			}
+			c = r.byte()
+		}
+
+		// Append character
+		o = append(o, c)
+		prior = c & 0x7f
+	}
+	return
+}
+
+type atscHuffmanBitsReader struct {
+	b   []byte
+	idx int // In bits
+}
+
+func (r *atscHuffmanBitsReader) remaining() int {
+	return len(r.b)*8 - r.idx
+}
+
+func (r *atscHuffmanBitsReader) bit() int {
+	v := int(r.b[r.idx/8]>>(7-uint(r.idx%8))) & 0x1
+	r.idx++
+	return v
+}
+
+func (r *atscHuffmanBitsReader) byte() (c byte) {
+	for n := 0; n < 8; n++ {
+		c = c<<1 | byte(r.bit())
+	}
+	return
+}
+
+// walk walks the tree of the prior character until a leaf is reached, ok being false if bits are exhausted before
+func (r *atscHuffmanBitsReader) walk(t []byte, prior byte) (c byte, ok bool, err error) {
+	offset := int(t[2*int(prior)])<<8 | int(t[2*int(prior)+1])
+	node := 0
+	for r.remaining() > 0 {
+		o := offset + 2*node + r.bit()
+		if o >= len(t) {
+			err = fmt.Errorf("astits: offset %d is out of range", o)
+			return
+		}
+		if t[o]&0x80 > 0 {
+			return t[o] & 0x7f, true, nil
+		}
+		node = int(t[o])
+	}
+	return
+}
@@ -0,0 +1,62 @@
+package astits
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseATSCMultipleString(t *testing.T) {
+	m, err := ParseATSCMultipleString([]byte{
+		2,
+		'e', 'n', 'g', 2,
+		ATSCCompressionTypeNone, ATSCModeLatin1, 2, 'h', 'i',
+		ATSCCompressionTypeNone, 0x04, 1, 0x14, // Cyrillic
+		'f', 'r', 'a', 1,
+		ATSCCompressionTypeNone, ATSCModeUTF16, 4, 0x00, 0xe9, 0x00, 't',
+	})
+	assert.NoError(t, err)
+	assert.Len(t, m.Strings, 2)
+	assert.Equal(t, []byte("fra"), m.Strings[1].Language)
+	s, ok, err := m.Decode("", nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "hiД", s)
+	s, ok, err = m.Decode("fra", nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "ét", s)
+	_, ok, _ = m.Decode("deu", nil)
+	assert.False(t, ok)
+
+	// Errors
+	_, err = ParseATSCMultipleString([]byte{1, 'e', 'n', 'g', 1, 0, 0, 2, 'h'})
+	assert.Error(t, err)
+	_, err = (&ATSCStringSegment{Mode: ATSCModeSCSU}).Decode(nil)
+	assert.True(t, errors.Is(err, ErrATSCModeNotSupported))
+	_, err = (&ATSCStringSegment{CompressionType: ATSCCompressionTypeHuffmanTitle}).Decode(nil)
+	assert.True(t, errors.Is(err, ErrATSCHuffmanDecodeTreesMissing))
+}
+
+func TestDecodeATSCHuffman(t *testing.T) {
+	// Every prior character uses the same tree: 'a' is 0, terminator is 10 and escape is 11
+	tr := make([]byte, 2*atscHuffmanContextsNum)
+	for idx := 0; idx < atscHuffmanContextsNum; idx++ {
+		tr[2*idx] = 0x01
+	}
+	tr = append(tr, 0x80|'a', 0x01, 0x80|atscHuffmanTerminator, 0x80|atscHuffmanEscape)
+
+	// 0 0 11 01011010 10 00
+	s, err := (&ATSCStringSegment{
+		Bytes:           []byte{0x35, 0xa8},
+		CompressionType: ATSCCompressionTypeHuffmanTitle,
+		Mode:            ATSCModeNotApplicable,
+	}).Decode(ATSCHuffmanDecodeTrees{ATSCCompressionTypeHuffmanTitle: tr})
+	assert.NoError(t, err)
+	assert.Equal(t, "aaZ", s)
+
+	// Invalid trees
+	_, err = decodeATSCHuffman([]byte{0x35}, tr[:10])
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package astits
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AVSyncAlert represents an alert raised when the drift between an audio PID and the video PID of a program crosses
+// the configured threshold
+type AVSyncAlert struct {
+	AudioPID      uint16
+	Drift         time.Duration
+	Exceeded      bool // True when the drift went above the threshold, false when it went back below it
+	ProgramNumber uint16
+	VideoPID      uint16
+}
+
+// AVSyncDrift represents the current drift between an audio PID and the video PID of a program
+type AVSyncDrift struct {
+	AudioPID      uint16
+	Drift         time.Duration
+	Exceeded      bool
+	ProgramNumber uint16
+	VideoPID      uint16
+}
+
+// AVSyncTracker tracks the relative PTS drift between the audio PIDs and the video PID of each program
+// The offset between the last video PTS and the last audio PTS is measured every time an audio PES is received, and
+// the drift is the difference between the current offset and the first offset measured. Since both streams are
+// interleaved, the drift has a jitter of up to one video frame duration.
+type AVSyncTracker struct {
+	fn        func(a AVSyncAlert)
+	m         *sync.Mutex
+	pairs     map[uint16][]*avSyncPair // Indexed by PID
+	programs  map[uint16][]*avSyncPair // Indexed by program number
+	pts       map[uint16]int64         // Indexed by PID
+	threshold time.Duration
+}
+
+type avSyncPair struct {
+	audioPID      uint16
+	baseline      int64
+	drift         int64
+	exceeded      bool
+	hasBaseline   bool
+	programNumber uint16
+	videoPID      uint16
+}
+
+// NewAVSyncTracker creates a new A/V sync tracker raising alerts when the absolute drift is bigger than threshold
+// If fn is not nil, it is called for every alert
+func NewAVSyncTracker(threshold time.Duration, fn func(a AVSyncAlert)) *AVSyncTracker {
+	return &AVSyncTracker{
+		fn:        fn,
+		m:         &sync.Mutex{},
+		pairs:     make(map[uint16][]*avSyncPair),
+		programs:  make(map[uint16][]*avSyncPair),
+		pts:       make(map[uint16]int64),
+		threshold: threshold,
+	}
+}
+
+// AddData updates the tracker with a new data and returns the alerts it triggered
+func (t *AVSyncTracker) AddData(d *Data) (as []AVSyncAlert) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// PMT
+	if d.PMT != nil {
+		t.updateProgram(d.PMT)
+		return
+	}
+
+	// PES with a PTS
+	if d.PES == nil || d.PES.Header == nil || d.PES.Header.OptionalHeader == nil || d.PES.Header.OptionalHeader.PTS == nil {
+		return
+	}
+
+	// Update PTS
+	t.pts[d.PID] = d.PES.Header.OptionalHeader.PTS.Base
+
+	// Loop through pairs
+	for _, p := range t.pairs[d.PID] {
+		// Offset is only measured when audio is received
+		if p.audioPID != d.PID {
+			continue
+		}
+
+		// Both PTS are needed
+		audioPTS, okAudio := t.pts[p.audioPID]
+		videoPTS, okVideo := t.pts[p.videoPID]
+		if !okAudio || !okVideo {
+			continue
+		}
+
+		// Offset
+		offset := clockReferenceBaseDiff(videoPTS, audioPTS)
+		if !p.hasBaseline {
+			p.baseline = offset
+			p.hasBaseline = true
+		}
+
+		// Drift
+		p.drift = offset - p.baseline
+		drift := clockReferenceBaseDuration(p.drift)
+		exceeded := drift > t.threshold || drift < -t.threshold
+
+		// Alert
+		if exceeded != p.exceeded {
+			p.exceeded = exceeded
+			a := AVSyncAlert{
+				AudioPID:      p.audioPID,
+				Drift:         drift,
+				Exceeded:      exceeded,
+				ProgramNumber: p.programNumber,
+				VideoPID:      p.videoPID,
+			}
+			as = append(as, a)
+			if t.fn != nil {
+				t.fn(a)
+			}
+		}
+	}
+	return
+}
+
+func (t *AVSyncTracker) updateProgram(pmt *PMTData) {
+	// Get PIDs
+	var audioPIDs []uint16
+	var videoPID uint16
+	var hasVideo bool
+	for _, es := range pmt.ElementaryStreams {
+		if isVideoElementaryStream(es) {
+			if !hasVideo {
+				videoPID = es.ElementaryPID
+				hasVideo = true
+			}
+		} else if isAudioElementaryStream(es) {
+			audioPIDs = append(audioPIDs, es.ElementaryPID)
+		}
+	}
+
+	// Check whether the program has changed
+	ops := t.programs[pmt.ProgramNumber]
+	if len(ops) == len(audioPIDs) {
+		changed := false
+		for idx, p := range ops {
+			if p.audioPID != audioPIDs[idx] || p.videoPID != videoPID {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return
+		}
+	}
+
+	// Remove old pairs
+	for _, p := range ops {
+		t.pairs[p.audioPID] = removeAVSyncPair(t.pairs[p.audioPID], p)
+		t.pairs[p.videoPID] = removeAVSyncPair(t.pairs[p.videoPID], p)
+	}
+	delete(t.programs, pmt.ProgramNumber)
+
+	// No video
+	if !hasVideo {
+		return
+	}
+
+	// Add new pairs
+	for _, audioPID := range audioPIDs {
+		p := &avSyncPair{
+			audioPID:      audioPID,
+			programNumber: pmt.ProgramNumber,
+			videoPID:      videoPID,
+		}
+		t.pairs[audioPID] = append(t.pairs[audioPID], p)
+		t.pairs[videoPID] = append(t.pairs[videoPID], p)
+		t.programs[pmt.ProgramNumber] = append(t.programs[pmt.ProgramNumber], p)
+	}
+}
+
+func removeAVSyncPair(ps []*avSyncPair, p *avSyncPair) (o []*avSyncPair) {
+	for _, v := range ps {
+		if v != p {
+			o = append(o, v)
+		}
+	}
+	return
+}
+
+// Drifts returns the current drifts, sorted by program number and audio PID
+func (t *AVSyncTracker) Drifts() (ds []AVSyncDrift) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Loop through programs
+	for _, ps := range t.programs {
+		for _, p := range ps {
+			if !p.hasBaseline {
+				continue
+			}
+			ds = append(ds, AVSyncDrift{
+				AudioPID:      p.audioPID,
+				Drift:         clockReferenceBaseDuration(p.drift),
+				Exceeded:      p.exceeded,
+				ProgramNumber: p.programNumber,
+				VideoPID:      p.videoPID,
+			})
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		if ds[i].ProgramNumber != ds[j].ProgramNumber {
+			return ds[i].ProgramNumber < ds[j].ProgramNumber
+		}
+		return ds[i].AudioPID < ds[j].AudioPID
+	})
+	return
+}
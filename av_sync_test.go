@@ -0,0 +1,54 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAVSyncTracker(t *testing.T) {
+	var fas []AVSyncAlert
+	tr := NewAVSyncTracker(100*time.Millisecond, func(a AVSyncAlert) { fas = append(fas, a) })
+
+	// PMT
+	assert.Empty(t, tr.AddData(&Data{PMT: &PMTData{
+		ElementaryStreams: []*PMTElementaryStream{
+			{ElementaryPID: 256, StreamType: StreamTypeH264Video},
+			{ElementaryPID: 257, StreamType: StreamTypeAudioADTS},
+			{ElementaryPID: 258, ElementaryStreamDescriptors: []*Descriptor{{AC3: &DescriptorAC3{}, Tag: DescriptorTagAC3}}, StreamType: StreamTypeMPEG2PacketizedData},
+			{ElementaryPID: 259, StreamType: StreamTypeMPEG2PacketizedData},
+		},
+		ProgramNumber: 1,
+	}}))
+
+	// Baseline
+	assert.Empty(t, tr.AddData(pcrLeadPESData(256, nil, 10000)))
+	assert.Empty(t, tr.AddData(pcrLeadPESData(257, nil, 9000)))
+	assert.Empty(t, tr.AddData(pcrLeadPESData(258, nil, 9000)))
+	assert.Equal(t, []AVSyncDrift{
+		{AudioPID: 257, ProgramNumber: 1, VideoPID: 256},
+		{AudioPID: 258, ProgramNumber: 1, VideoPID: 256},
+	}, tr.Drifts())
+
+	// Drift exceeds threshold
+	as := tr.AddData(pcrLeadPESData(256, nil, 30000))
+	assert.Empty(t, as)
+	as = tr.AddData(pcrLeadPESData(257, nil, 11000))
+	assert.Equal(t, []AVSyncAlert{{AudioPID: 257, Drift: 200 * time.Millisecond, Exceeded: true, ProgramNumber: 1, VideoPID: 256}}, as)
+
+	// Drift goes back below threshold
+	as = tr.AddData(pcrLeadPESData(257, nil, 29000))
+	assert.Equal(t, []AVSyncAlert{{AudioPID: 257, Exceeded: false, ProgramNumber: 1, VideoPID: 256}}, as)
+	assert.Len(t, fas, 2)
+
+	// Program update
+	tr.AddData(&Data{PMT: &PMTData{
+		ElementaryStreams: []*PMTElementaryStream{
+			{ElementaryPID: 256, StreamType: StreamTypeH264Video},
+			{ElementaryPID: 258, StreamType: StreamTypeMPEG1Audio},
+		},
+		ProgramNumber: 1,
+	}})
+	assert.Empty(t, tr.Drifts())
+}
@@ -0,0 +1,71 @@
+package astits
+
+import "sort"
+
+// CA PID kinds
+const (
+	CAPIDKindECM = "ECM"
+	CAPIDKindEMM = "EMM"
+)
+
+// CAPID represents a PID carrying the ECMs or EMMs of a CA system, as declared by a CA descriptor
+type CAPID struct {
+	CASystemID    uint16
+	ElementaryPID uint16 // Elementary stream the ECMs apply to, 0 if they apply to the whole program or for EMMs
+	Kind          string // See CAPIDKind*
+	PID           uint16
+	PrivateData   []byte
+	ProgramNumber uint16 // Program the ECMs apply to, 0 for EMMs
+}
+
+// CAPIDs returns the EMM PIDs declared by the CA descriptors of the CAT
+func (d *CATData) CAPIDs() (ps []CAPID) {
+	for _, v := range d.Descriptors {
+		if v.CA != nil {
+			ps = append(ps, CAPID{
+				CASystemID:  v.CA.CASystemID,
+				Kind:        CAPIDKindEMM,
+				PID:         v.CA.CAPID,
+				PrivateData: v.CA.PrivateData,
+			})
+		}
+	}
+	return
+}
+
+// CAPIDs returns the ECM PIDs declared by the CA descriptors of the PMT, both in the program info loop and in the
+// elementary streams loops
+func (d *PMTData) CAPIDs() (ps []CAPID) {
+	add := func(ds []*Descriptor, elementaryPID uint16) {
+		for _, v := range ds {
+			if v.CA != nil {
+				ps = append(ps, CAPID{
+					CASystemID:    v.CA.CASystemID,
+					ElementaryPID: elementaryPID,
+					Kind:          CAPIDKindECM,
+					PID:           v.CA.CAPID,
+					PrivateData:   v.CA.PrivateData,
+					ProgramNumber: d.ProgramNumber,
+				})
+			}
+		}
+	}
+	add(d.ProgramDescriptors, 0)
+	for _, es := range d.ElementaryStreams {
+		add(es.ElementaryStreamDescriptors, es.ElementaryPID)
+	}
+	return
+}
+
+// sortCAPIDs sorts CA PIDs by PID, program number and elementary PID
+func sortCAPIDs(ps []CAPID) {
+	sort.SliceStable(ps, func(i, j int) bool {
+		if ps[i].PID != ps[j].PID {
+			return ps[i].PID < ps[j].PID
+		}
+		if ps[i].ProgramNumber != ps[j].ProgramNumber {
+			return ps[i].ProgramNumber < ps[j].ProgramNumber
+		}
+		return ps[i].ElementaryPID < ps[j].ElementaryPID
+	})
+}
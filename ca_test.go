@@ -0,0 +1,51 @@
+package astits
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCAPIDs(t *testing.T) {
+	// Data
+	cat := &CATData{Descriptors: []*Descriptor{
+		NewCADescriptor(0x500, 0x20, nil),
+		NewStreamIdentifierDescriptor(1),
+	}}
+	pmt := &PMTData{
+		ElementaryStreams: []*PMTElementaryStream{
+			{ElementaryPID: 0x101, ElementaryStreamDescriptors: []*Descriptor{NewCADescriptor(0x500, 0x31, []byte{0x1})}},
+			{ElementaryPID: 0x102},
+		},
+		ProgramDescriptors: []*Descriptor{NewCADescriptor(0x500, 0x30, nil)},
+		ProgramNumber:      1,
+	}
+	assert.Equal(t, []CAPID{{CASystemID: 0x500, Kind: CAPIDKindEMM, PID: 0x20}}, cat.CAPIDs())
+	assert.Equal(t, []CAPID{
+		{CASystemID: 0x500, Kind: CAPIDKindECM, PID: 0x30, ProgramNumber: 1},
+		{CASystemID: 0x500, ElementaryPID: 0x101, Kind: CAPIDKindECM, PID: 0x31, PrivateData: []byte{0x1}, ProgramNumber: 1},
+	}, pmt.CAPIDs())
+
+	// Demuxer
+	dmx := New(context.Background(), nil)
+	assert.Len(t, dmx.CAPIDs(), 0)
+	dmx.updateData([]*Data{
+		{PID: 0x1000, PMT: pmt},
+		{PID: 0x1001, PMT: &PMTData{
+			ProgramDescriptors: []*Descriptor{NewCADescriptor(0x600, 0x21, nil)},
+			ProgramNumber:      2,
+		}},
+		{CAT: cat, PID: PIDCAT},
+	})
+	assert.Equal(t, []CAPID{
+		{CASystemID: 0x500, Kind: CAPIDKindEMM, PID: 0x20},
+		{CASystemID: 0x600, Kind: CAPIDKindECM, PID: 0x21, ProgramNumber: 2},
+		{CASystemID: 0x500, Kind: CAPIDKindECM, PID: 0x30, ProgramNumber: 1},
+		{CASystemID: 0x500, ElementaryPID: 0x101, Kind: CAPIDKindECM, PID: 0x31, PrivateData: []byte{0x1}, ProgramNumber: 1},
+	}, dmx.CAPIDs())
+
+	// New CAT replaces the EMM PIDs
+	dmx.updateData([]*Data{{CAT: &CATData{}, PID: PIDCAT}})
+	assert.Len(t, dmx.CAPIDs(), 3)
+}
@@ -0,0 +1,137 @@
+package astits
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Max size of the buffer SerialiseGrowable grows to
+const growableOutputMaxSize = 1 << 20
+
+// serialiseWithSentinel calls a serialise function and returns ErrNoRoomInBuffer as is when there's no room in b, so
+// that serialisers that returned it before their fields were checked one by one can still be compared with ==
+func serialiseWithSentinel(b []byte, fn func(b []byte) (int, error)) (int, error) {
+	n, err := fn(b)
+	if errors.Is(err, ErrNoRoomInBuffer) {
+		err = ErrNoRoomInBuffer
+	}
+	return n, err
+}
+
+// checkedWriter writes fields into a buffer while keeping track of the remaining space
+// Every write is checked against the remaining space and fails with an error wrapping ErrNoRoomInBuffer and naming
+// the field that overflowed.
+type checkedWriter struct {
+	b      []byte
+	offset int
+}
+
+func newCheckedWriter(b []byte) *checkedWriter {
+	return &checkedWriter{b: b}
+}
+
+// next returns the next n bytes of the buffer and moves past them
+func (w *checkedWriter) next(field string, n int) (b []byte, err error) {
+	if r := len(w.b) - w.offset; r < n {
+		err = fmt.Errorf("astits: no room to serialise %s, %d bytes needed, %d available: %w", field, n, r, ErrNoRoomInBuffer)
+		return
+	}
+	b = w.b[w.offset : w.offset+n]
+	w.offset += n
+	return
+}
+
+// writeUint8 writes a byte
+func (w *checkedWriter) writeUint8(field string, v uint8) error {
+	b, err := w.next(field, 1)
+	if err != nil {
+		return err
+	}
+	b[0] = v
+	return nil
+}
+
+// writeUint16 writes 2 bytes in big endian
+func (w *checkedWriter) writeUint16(field string, v uint16) error {
+	b, err := w.next(field, 2)
+	if err != nil {
+		return err
+	}
+	b[0], b[1] = U16toU8s(v)
+	return nil
+}
+
+// writeUint32 writes 4 bytes in big endian
+func (w *checkedWriter) writeUint32(field string, v uint32) error {
+	b, err := w.next(field, 4)
+	if err != nil {
+		return err
+	}
+	b[0], b[1], b[2], b[3] = uint8(v>>24), uint8(v>>16), uint8(v>>8), uint8(v)
+	return nil
+}
+
+// writeBytes writes bytes as is
+func (w *checkedWriter) writeBytes(field string, v []byte) error {
+	b, err := w.next(field, len(v))
+	if err != nil {
+		return err
+	}
+	copy(b, v)
+	return nil
+}
+
+// write serialises a nested field into the remaining space
+func (w *checkedWriter) write(field string, fn func(b []byte) (int, error)) error {
+	n, err := fn(w.b[w.offset:])
+	if err != nil {
+		return fmt.Errorf("astits: serialising %s failed: %w", field, err)
+	}
+	w.offset += n
+	return nil
+}
+
+// writeLength writes a 2 bytes length field, whose value is computed after the bytes it covers have been written
+// The length is the number of bytes written by fn and must fit in the provided number of bits. The remaining upper
+// bits are set to the reserved value.
+func (w *checkedWriter) writeLength(field string, bits uint, reserved uint16, fn func() error) (err error) {
+	// Reserve length
+	var b []byte
+	if b, err = w.next(field, 2); err != nil {
+		return
+	}
+
+	// Write content
+	start := w.offset
+	if err = fn(); err != nil {
+		return
+	}
+
+	// Write length
+	l := w.offset - start
+	if l >= 1<<bits {
+		return fmt.Errorf("astits: %s %d doesn't fit in %d bits", field, l, bits)
+	}
+	b[0], b[1] = U16toU8s(reserved&^(1<<bits-1) | uint16(l))
+	return
+}
+
+// SerialiseGrowable calls a serialise function, such as (*PSISection).Serialise, with a buffer that grows until the
+// serialised content fits in it, so that callers don't have to guess its size, and returns the serialised bytes
+func SerialiseGrowable(fn func(b []byte) (int, error)) (b []byte, err error) {
+	for size := MpegTsPacketSize; ; size *= 2 {
+		// Serialise
+		b = make([]byte, size)
+		var n int
+		if n, err = fn(b); err == nil {
+			b = b[:n]
+			return
+		}
+
+		// Only grow when there was no room
+		if !errors.Is(err, ErrNoRoomInBuffer) || size >= growableOutputMaxSize {
+			b = nil
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package astits
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedWriter(t *testing.T) {
+	// Fields
+	b := make([]byte, 9)
+	w := newCheckedWriter(b)
+	assert.NoError(t, w.writeUint8("a", 1))
+	assert.NoError(t, w.writeUint16("b", 0x203))
+	assert.NoError(t, w.writeLength("c", 10, 0xf000, func() error { return w.writeBytes("d", []byte{4, 5}) }))
+	assert.NoError(t, w.write("e", func(b []byte) (int, error) { return copy(b, []byte{6}), nil }))
+	assert.Equal(t, []byte{1, 2, 3, 0xf0, 2, 4, 5, 6, 0}, b)
+	assert.Equal(t, 8, w.offset)
+
+	// No room
+	err := w.writeUint16("f", 1)
+	assert.True(t, errors.Is(err, ErrNoRoomInBuffer))
+	assert.Contains(t, err.Error(), "no room to serialise f, 2 bytes needed, 1 available")
+
+	// Length overflow
+	w = newCheckedWriter(make([]byte, 10))
+	assert.Error(t, w.writeLength("g", 2, 0, func() error { return w.writeBytes("h", make([]byte, 4)) }))
+}
+
+func TestPSISectionSerialiseNoRoomForCRC32(t *testing.T) {
+	s := newMuxPMTSection(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 256, StreamType: StreamTypeH264Video}}})
+	b := make([]byte, psiSectionMaxSize)
+	n, err := s.Serialise(b)
+	assert.NoError(t, err)
+
+	// CRC32 doesn't fit
+	_, err = s.serialise(make([]byte, n-1))
+	assert.True(t, errors.Is(err, ErrNoRoomInBuffer))
+	assert.Contains(t, err.Error(), "no room to serialise CRC32")
+
+	// Elementary stream doesn't fit
+	_, err = s.serialise(make([]byte, n-5))
+	assert.True(t, errors.Is(err, ErrNoRoomInBuffer))
+	assert.Contains(t, err.Error(), "elementary stream #0")
+	assert.Contains(t, err.Error(), "no room to serialise ES info length")
+
+	// Exported serialisers return the sentinel as is
+	_, err = s.Serialise(make([]byte, n-5))
+	assert.Equal(t, ErrNoRoomInBuffer, err)
+}
+
+func TestSerialiseGrowable(t *testing.T) {
+	// PMT bigger than a packet
+	p := MuxProgram{Number: 1}
+	for idx := 0; idx < 50; idx++ {
+		p.Streams = append(p.Streams, MuxStream{PID: uint16(256 + idx), StreamType: StreamTypeH264Video})
+	}
+	s := newMuxPMTSection(p)
+	b := make([]byte, psiSectionMaxSize)
+	n, err := s.Serialise(b)
+	assert.NoError(t, err)
+	assert.True(t, n > MpegTsPacketSize)
+	g, err := SerialiseGrowable(s.Serialise)
+	assert.NoError(t, err)
+	assert.Equal(t, b[:n], g)
+
+	// Other errors are returned as is
+	e := errors.New("test")
+	_, err = SerialiseGrowable(func(b []byte) (int, error) { return 0, e })
+	assert.Equal(t, e, err)
+
+	// Growth is capped
+	_, err = SerialiseGrowable(func(b []byte) (int, error) { return 0, ErrNoRoomInBuffer })
+	assert.Equal(t, ErrNoRoomInBuffer, err)
+}
@@ -0,0 +1,69 @@
+package astits
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock represents a source of clock references
+// It allows live and file muxing to share the same PCR/PTS generation code.
+type Clock interface {
+	Now() ClockReference
+}
+
+// RealTimeClock is a clock driven by the wall clock, for live muxing
+type RealTimeClock struct {
+	now   func() time.Time
+	start ClockReference
+	t     time.Time
+}
+
+// NewRealTimeClock creates a new real time clock whose first value is start
+func NewRealTimeClock(start ClockReference) *RealTimeClock {
+	return newRealTimeClock(start, time.Now)
+}
+
+func newRealTimeClock(start ClockReference, now func() time.Time) *RealTimeClock {
+	return &RealTimeClock{
+		now:   now,
+		start: start,
+		t:     now(),
+	}
+}
+
+// Now implements the Clock interface
+func (c *RealTimeClock) Now() ClockReference {
+	// 1 ns is 0.027 tick at 27 MHz
+	return *newClockReferenceFromTicks(clockReferenceTicks(c.start) + int64(c.now().Sub(c.t))*27/1000)
+}
+
+// ByteRateClock is a clock driven by the number of bytes written at a constant rate, for file muxing
+// Clock references are therefore deterministic and don't depend on how fast bytes are written.
+type ByteRateClock struct {
+	c *muxCBRPCR
+	m *sync.Mutex
+	n int64
+}
+
+// NewByteRateClock creates a new byte rate clock whose first value is start
+// The rate is in bits per second.
+func NewByteRateClock(rate int64, start ClockReference) *ByteRateClock {
+	return &ByteRateClock{
+		c: newMuxCBRPCR(rate, start),
+		m: &sync.Mutex{},
+	}
+}
+
+// Add advances the clock by n bytes
+func (c *ByteRateClock) Add(n int) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.n += int64(n)
+}
+
+// Now implements the Clock interface
+func (c *ByteRateClock) Now() ClockReference {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return *c.c.at(c.n)
+}
@@ -27,3 +27,111 @@ func (p ClockReference) Duration() time.Duration {
 func (p ClockReference) Time() time.Time {
 	return time.Unix(0, p.Duration().Nanoseconds())
 }
+
+// Timestamps are coded on 33 bits and therefore wrap around
+const clockReferenceBaseWrap = int64(1) << 33
+
+// clockReferenceBaseDiff returns a - b in 90 kHz units, taking the 33 bits wrap around into account
+func clockReferenceBaseDiff(a, b int64) (d int64) {
+	d = (a - b) % clockReferenceBaseWrap
+	if d >= clockReferenceBaseWrap/2 {
+		d -= clockReferenceBaseWrap
+	} else if d < -clockReferenceBaseWrap/2 {
+		d += clockReferenceBaseWrap
+	}
+	return
+}
+
+// clockReferenceBaseDuration converts a 90 kHz value into a duration
+func clockReferenceBaseDuration(d int64) time.Duration {
+	return time.Duration(d * 1e9 / 90000)
+}
+
+// clockReferenceTicks returns the clock reference in 27 MHz units
+func clockReferenceTicks(p ClockReference) int64 {
+	return p.Base*300 + p.Extension
+}
+
+// newClockReferenceFromTicks builds a new clock reference from a value in 27 MHz units, taking the 33 bits base wrap
+// around into account
+func newClockReferenceFromTicks(v int64) *ClockReference {
+	v %= clockReferenceBaseWrap * 300
+	if v < 0 {
+		v += clockReferenceBaseWrap * 300
+	}
+	return newClockReference(v/300, v%300)
+}
+
+// Timestamp lengths in bytes
+const (
+	ESCRLength     = 6
+	PCRLength      = 6
+	PTSOrDTSLength = 5
+)
+
+// PTS/DTS prefixes, written in the 4 bits preceding the timestamp
+const (
+	PTSOrDTSPrefixDTS        = 0x1 // DTS following a PTS
+	PTSOrDTSPrefixPTSOnly    = 0x2
+	PTSOrDTSPrefixPTSWithDTS = 0x3 // PTS followed by a DTS
+)
+
+// WritePTSOrDTS writes a 33 bits PTS or DTS preceded by its 4 bits prefix, see PTSOrDTSPrefix*, marker bits being set
+// The base is written modulo 2^33 and the extension is ignored.
+func WritePTSOrDTS(b []byte, prefix uint8, cr *ClockReference) error {
+	if len(b) < PTSOrDTSLength {
+		return ErrNoRoomInBuffer
+	}
+	writePTSOrDTS(b, prefix, cr)
+	return nil
+}
+
+// WriteESCR writes a 42 bits ESCR made of a 33 bits base and a 9 bits extension, reserved and marker bits being set
+func WriteESCR(b []byte, cr *ClockReference) error {
+	if len(b) < ESCRLength {
+		return ErrNoRoomInBuffer
+	}
+	writeESCR(b, cr)
+	return nil
+}
+
+// WritePCR writes a 6 bytes PCR or OPCR made of a 33 bits base and a 9 bits extension, reserved bits being set, so that
+// adaptation fields can be generated outside of the muxer
+// The base is written modulo 2^33 and the extension is expected to be lower than 300.
+func WritePCR(b []byte, cr *ClockReference) error {
+	if len(b) < PCRLength {
+		return ErrNoRoomInBuffer
+	}
+	writePCR(b, cr)
+	return nil
+}
+
+// writePTSOrDTS writes a PTS or a DTS preceded by its 4 bits flag
+func writePTSOrDTS(b []byte, flag uint8, cr *ClockReference) {
+	b[0] = flag<<4 | uint8(cr.Base>>29&0xe) | 0x1
+	b[1] = uint8(cr.Base >> 22)
+	b[2] = uint8(cr.Base>>14&0xfe) | 0x1
+	b[3] = uint8(cr.Base >> 7)
+	b[4] = uint8(cr.Base<<1&0xfe) | 0x1
+}
+
+// writeESCR writes an ESCR
+func writeESCR(b []byte, cr *ClockReference) {
+	b[0] = 0xc0 | uint8(cr.Base>>27&0x38) | 0x4 | uint8(cr.Base>>28&0x3)
+	b[1] = uint8(cr.Base >> 20)
+	b[2] = uint8(cr.Base>>12&0xf8) | 0x4 | uint8(cr.Base>>13&0x3)
+	b[3] = uint8(cr.Base >> 5)
+	b[4] = uint8(cr.Base<<3&0xf8) | 0x4 | uint8(cr.Extension>>7&0x3)
+	b[5] = uint8(cr.Extension<<1&0xfe) | 0x1
+}
+
+// writePCR writes a Program Clock Reference
+func writePCR(b []byte, cr *ClockReference) {
+	v := uint64(cr.Base)<<15 | 0x3f<<9 | uint64(cr.Extension&0x1ff)
+	b[0] = uint8(v >> 40)
+	b[1] = uint8(v >> 32)
+	b[2] = uint8(v >> 24)
+	b[3] = uint8(v >> 16)
+	b[4] = uint8(v >> 8)
+	b[5] = uint8(v)
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/asticode/go-astikit"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,3 +14,42 @@ func TestClockReference(t *testing.T) {
 	assert.Equal(t, 36344825768814*time.Nanosecond, clockReference.Duration())
 	assert.Equal(t, int64(36344), clockReference.Time().Unix())
 }
+
+func TestClockReferenceBaseDiff(t *testing.T) {
+	assert.Equal(t, int64(10), clockReferenceBaseDiff(20, 10))
+	assert.Equal(t, int64(-10), clockReferenceBaseDiff(10, 20))
+	assert.Equal(t, int64(20), clockReferenceBaseDiff(10, clockReferenceBaseWrap-10))
+	assert.Equal(t, int64(-20), clockReferenceBaseDiff(clockReferenceBaseWrap-10, 10))
+	assert.Equal(t, time.Second, clockReferenceBaseDuration(90000))
+}
+
+func TestWritePTSOrDTS(t *testing.T) {
+	// No room
+	assert.Equal(t, ErrNoRoomInBuffer, WritePTSOrDTS(make([]byte, 4), PTSOrDTSPrefixPTSOnly, ptsClockReference))
+
+	// Marker bits are set
+	b := make([]byte, PTSOrDTSLength)
+	assert.NoError(t, WritePTSOrDTS(b, PTSOrDTSPrefixPTSOnly, ptsClockReference))
+	assert.Equal(t, []byte{0x2b, 0x55, 0x55, 0xaa, 0xab}, b)
+	v, err := parsePTSOrDTS(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Equal(t, ptsClockReference, v)
+
+	// Base wraps around
+	assert.NoError(t, WritePTSOrDTS(b, PTSOrDTSPrefixDTS, &ClockReference{Base: clockReferenceBaseWrap + 1}))
+	assert.Equal(t, []byte{0x11, 0x0, 0x1, 0x0, 0x3}, b)
+}
+
+func TestWriteESCR(t *testing.T) {
+	// No room
+	assert.Equal(t, ErrNoRoomInBuffer, WriteESCR(make([]byte, 5), clockReference))
+
+	// Round trip
+	b := make([]byte, ESCRLength)
+	assert.NoError(t, WriteESCR(b, clockReference))
+	v, err := parseESCR(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Equal(t, clockReference, v)
+	assert.Equal(t, byte(0x4), b[2]&0x4)
+	assert.Equal(t, byte(0x1), b[5]&0x1)
+}
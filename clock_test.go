@@ -0,0 +1,24 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealTimeClock(t *testing.T) {
+	n := time.Unix(10, 0)
+	c := newRealTimeClock(ClockReference{Base: 1000}, func() time.Time { return n })
+	assert.Equal(t, ClockReference{Base: 1000}, c.Now())
+	n = n.Add(time.Second + time.Microsecond)
+	assert.Equal(t, ClockReference{Base: 91000, Extension: 27}, c.Now())
+}
+
+func TestByteRateClock(t *testing.T) {
+	// 1 packet every ms
+	var c Clock = NewByteRateClock(MpegTsPacketSize*8*1000, ClockReference{Base: 1000})
+	assert.Equal(t, ClockReference{Base: 1000}, c.Now())
+	c.(*ByteRateClock).Add(MpegTsPacketSize)
+	assert.Equal(t, ClockReference{Base: 1090}, c.Now())
+}
@@ -2,77 +2,166 @@ package astits
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/asticode/go-astikit"
 )
 
 // PIDs
 const (
-	PIDPAT  = 0x0    // Program Association Table (PAT) contains a directory listing of all Program Map Tables.
-	PIDCAT  = 0x1    // Conditional Access Table (CAT) contains a directory listing of all ITU-T Rec. H.222 entitlement management message streams used by Program Map Tables.
-	PIDTSDT = 0x2    // Transport Stream Description Table (TSDT) contains descriptors related to the overall transport stream
-	PIDNull = 0x1fff // Null Packet (used for fixed bandwidth padding)
+	PIDPAT                    = 0x0    // Program Association Table (PAT) contains a directory listing of all Program Map Tables.
+	PIDCAT                    = 0x1    // Conditional Access Table (CAT) contains a directory listing of all ITU-T Rec. H.222 entitlement management message streams used by Program Map Tables.
+	PIDTSDT                   = 0x2    // Transport Stream Description Table (TSDT) contains descriptors related to the overall transport stream
+	PIDIPMP                   = 0x3    // IPMP Control Information Table contains a directory listing of all IPMP tool streams
+	PIDNIT                    = 0x10   // Network Information Table (NIT) contains information about the physical organisation of the network
+	PIDSDT                    = 0x11   // Service Description Table (SDT) contains the names and parameters of the services of the transport stream
+	PIDEIT                    = 0x12   // Event Information Table (EIT) contains the events of the services
+	PIDRST                    = 0x13   // Running Status Table (RST) updates the running status of events
+	PIDTDT                    = 0x14   // Time and Date Table (TDT) and Time Offset Table (TOT) contain the current UTC time and local time offsets
+	PIDNetworkSynchronisation = 0x15   // Network synchronisation of single frequency networks
+	PIDRNT                    = 0x16   // Resolution provider Notification Table (RNT) is used by TV-Anytime
+	PIDInbandSignalling       = 0x1c   // Inband signalling
+	PIDMeasurement            = 0x1d   // Measurement
+	PIDDIT                    = 0x1e   // Discontinuity Information Table (DIT) signals discontinuities in partial transport streams
+	PIDSIT                    = 0x1f   // Selection Information Table (SIT) describes the services of partial transport streams
+	PIDReservedMax            = 0x1f   // PIDs up to this one are reserved to PSI and SI tables
+	PIDNull                   = 0x1fff // Null Packet (used for fixed bandwidth padding)
+
+	PIDATSCPSIP = 0x1ffb // ATSC PSIP base PID carrying the MGT, the VCTs, the STT and the RRT
 )
 
 // Data represents a data
 type Data struct {
+	AIT         *AITData
+	ATSCEIT     *ATSCEITData // ATSC
+	CAT         *CATData
+	DIT         *DITData
+	DSMCC       *DSMCCData
 	EIT         *EITData
+	ETT         *ETTData // ATSC
 	FirstPacket *Packet
+	MGT         *MGTData // ATSC
 	NIT         *NITData
 	PAT         *PATData
+	PCR         *DataPCR // Only set when the demuxer has been created with OptPCRData
 	PES         *PESData
 	PID         uint16
 	PMT         *PMTData
+	RRT         *RRTData // ATSC
+	RST         *RSTData
 	SDT         *SDTData
+	Section     *PSISection // Section the data has been parsed from, nil for PES data
+	SIT         *SITData
+	ST          *STData
+	STT         *STTData // ATSC
+	TDT         *TDTData
 	TOT         *TOTData
+	TSDT        *TSDTData
+	VCT         *VCTData // ATSC
+}
+
+// DataPCR represents a PCR read in an adaptation field, emitted as data so that clock tracking applications don't
+// have to work at the packet level
+type DataPCR struct {
+	Discontinuity bool  // The discontinuity indicator is set
+	Offset        int64 // Byte offset of the packet in the reader
+	PCR           *ClockReference
 }
 
+// payloadBufferPool holds the buffers payloads spanning over multiple packets are concatenated into
+// Parsed data never reference these buffers since bytes are always copied out of the bytes iterator.
+var payloadBufferPool = sync.Pool{New: func() interface{} { return &[]byte{} }}
+
 // ParseData parses a payload spanning over multiple packets and returns a set of data
 func ParseData(ps []*Packet, prs PacketsParser, pm ProgramMap) (ds []*Data, err error) {
-	// Use custom parser first
+	var r *InterceptorRegistry
 	if prs != nil {
-		var skip bool
-		if ds, skip, err = prs(ps); err != nil {
-			err = fmt.Errorf("astits: custom packets parsing failed: %w", err)
-			return
-		} else if skip {
-			return
+		r = NewInterceptorRegistry()
+		r.addLegacy(prs)
+	}
+	return parseData(ps, r, pm, ProfileAuto, nil, nil, nil)
+}
+
+// parseData parses a payload spanning over multiple packets using the PIDs and table IDs of the profile
+// tablePIDs are the PIDs previously announced as carrying tables, such as the ATSC EIT PIDs listed by the MGT, and
+// psiPIDPredicate is an optional predicate provided by the user, and so is the stuffing policy sp.
+// The payload of a single packet is parsed in place, which is safe since parsed data never reference the bytes they
+// have been parsed from.
+func parseData(ps []*Packet, r *InterceptorRegistry, pm ProgramMap, p Profile, tablePIDs map[uint16]bool, psiPIDPredicate PSIPIDPredicate, sp *StuffingPolicy) (ds []*Data, err error) {
+	// Get payload type
+	var pid uint16
+	t := PayloadTypeUnknown
+	if len(ps) > 0 {
+		pid = ps[0].Header.PID
+		if isPSIPayload(pid, pm, p) || tablePIDs[pid] || (psiPIDPredicate != nil && psiPIDPredicate(pid)) {
+			t = PayloadTypePSI
+		} else if isPESPayload(ps[0].Payload) {
+			t = PayloadTypePES
 		}
 	}
 
-	// Get payload length
-	var l int
-	for _, p := range ps {
-		l += len(p.Payload)
+	// Use interceptors first
+	var skip bool
+	if ds, skip, err = r.Intercept(ps, t); err != nil {
+		err = fmt.Errorf("astits: intercepting packets failed: %w", err)
+		return
+	} else if skip {
+		return
 	}
 
-	// Append payload
-	var payload = make([]byte, l)
-	var c int
-	for _, p := range ps {
-		c += copy(payload[c:], p.Payload)
+	// Get payload
+	// A payload contained in a single packet is used as is
+	var payload []byte
+	if len(ps) == 1 {
+		payload = ps[0].Payload
+	} else if len(ps) > 1 {
+		// Get buffer large enough to hold the payload
+		buf := payloadBufferPool.Get().(*[]byte)
+		defer payloadBufferPool.Put(buf)
+		var l int
+		for _, p := range ps {
+			l += len(p.Payload)
+		}
+		if cap(*buf) < l {
+			*buf = make([]byte, 0, l)
+		}
+
+		// Append payload
+		payload = (*buf)[:0]
+		for _, p := range ps {
+			payload = append(payload, p.Payload...)
+		}
+		*buf = payload
 	}
 
 	// Create reader
 	i := astikit.NewBytesIterator(payload)
 
-	// Parse PID
-	pid := ps[0].Header.PID
-
 	// Parse payload
-	if pid == PIDCAT {
-		// Information in a CAT payload is private and dependent on the CA system. Use the PacketsParser
-		// to parse this type of payload
-	} else if IsPSIPayload(pid, pm) {
+	if t == PayloadTypePSI {
 		// Parse PSI data
 		var psiData *PSIData
-		if psiData, err = parsePSIData(i); err != nil {
+		var sw *StuffingWarning
+		if psiData, sw, err = parsePSIDataWithStuffing(i, p, sp.bytes()); err != nil {
 			err = fmt.Errorf("astits: parsing PSI data failed: %w", err)
 			return
 		}
 
+		// Handle non standard stuffing
+		if sw != nil {
+			sw.PID = pid
+			if err = sp.handle(*sw); err != nil {
+				err = fmt.Errorf("astits: handling stuffing failed: %w", err)
+				return
+			}
+		}
+
 		// Append data
-		ds = psiData.toData(ps[0], pid)
+		if len(ds) == 0 {
+			ds = psiData.toData(ps[0], pid)
+		} else {
+			ds = append(ds, psiData.toData(ps[0], pid)...)
+		}
 	} else if isPESPayload(payload) {
 		// Parse PES data
 		var pesData *PESData
@@ -91,11 +180,37 @@ func ParseData(ps []*Packet, prs PacketsParser, pm ProgramMap) (ds []*Data, err
 	return
 }
 
+// IsReservedPID checks whether the PID is reserved to PSI and SI tables or to null packets, and therefore can't carry
+// a PMT or an elementary stream
+func IsReservedPID(pid uint16) bool {
+	return pid <= PIDReservedMax || pid == PIDNull
+}
+
+// PSIPIDPredicate represents an object capable of telling whether a PID carries PSI, on top of the PIDs the demuxer
+// already considers as PSI, such as operators' private SI on non-standard PIDs
+type PSIPIDPredicate func(pid uint16) bool
+
 // IsPSIPayload checks whether the payload is a PSI one
+// It uses the DVB SI PIDs, use Profile.IsPSIPayload when the stream complies with another standard.
 func IsPSIPayload(pid uint16, pm ProgramMap) bool {
+	return isPSIPayload(pid, pm, ProfileAuto)
+}
+
+// IsPSIPayload checks whether the payload is a PSI one using the SI PIDs of the profile, which is what the demuxer
+// uses when OptProfile is set
+// With ProfileATSC, the PSIP PID is considered as PSI so that custom packets parsers can hand PSIP tables back to the
+// demuxer.
+func (p Profile) IsPSIPayload(pid uint16, pm ProgramMap) bool {
+	return isPSIPayload(pid, pm, p)
+}
+
+// isPSIPayload checks whether the payload is a PSI one using the SI PIDs of the profile
+func isPSIPayload(pid uint16, pm ProgramMap, p Profile) bool {
 	return pid == PIDPAT || // PAT
+		pid == PIDCAT || // CAT
+		pid == PIDTSDT || // TSDT
 		pm.Exists(pid) || // PMT
-		((pid >= 0x10 && pid <= 0x14) || (pid >= 0x1e && pid <= 0x1f)) //DVB
+		p.isSIPID(pid) // SI
 }
 
 // isPESPayload checks whether the payload is a PES one
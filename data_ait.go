@@ -0,0 +1,334 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// AIT table ID
+const AITTableID = 0x74
+
+// AIT application control codes
+// Chapter: 5.3.5.3 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+const (
+	AITApplicationControlCodeAutostart         = 0x1
+	AITApplicationControlCodeDestroy           = 0x3
+	AITApplicationControlCodeDisabled          = 0x7
+	AITApplicationControlCodeKill              = 0x4
+	AITApplicationControlCodePlaybackAutostart = 0x8
+	AITApplicationControlCodePrefetch          = 0x5
+	AITApplicationControlCodePresent           = 0x2
+	AITApplicationControlCodeRemote            = 0x6
+)
+
+// AIT descriptor tags, which are not in the same namespace as the descriptors of the PSI tables
+// Chapter: 5.3.6 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+const (
+	AITDescriptorTagApplication               = 0x00
+	AITDescriptorTagApplicationName           = 0x01
+	AITDescriptorTagSimpleApplicationBoundary = 0x17
+	AITDescriptorTagSimpleApplicationLocation = 0x15
+	AITDescriptorTagTransportProtocol         = 0x02
+)
+
+// AIT transport protocol IDs
+// Chapter: 5.3.6.2 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+const (
+	AITTransportProtocolIDHTTP           = 0x3
+	AITTransportProtocolIDIPMPE          = 0x2
+	AITTransportProtocolIDObjectCarousel = 0x1
+)
+
+// AITData represents an AIT data, which signals the interactive applications of a service, such as HbbTV
+// applications
+// Chapter: 5.3.4 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITData struct {
+	Applications    []*AITApplication
+	ApplicationType uint16           // 0x10 for HbbTV
+	Descriptors     []*AITDescriptor // Common to all applications
+	TestApplication bool
+}
+
+// AITApplication represents an application of an AIT
+type AITApplication struct {
+	ApplicationID  uint16
+	ControlCode    uint8 // See AITApplicationControlCode*
+	Descriptors    []*AITDescriptor
+	OrganisationID uint32
+}
+
+// AITDescriptor represents a descriptor of an AIT
+// Content holds the raw descriptor payload, whatever its tag.
+type AITDescriptor struct {
+	Content                   []byte
+	Length                    uint8
+	SimpleApplicationLocation *AITDescriptorSimpleApplicationLocation
+	Tag                       uint8
+	TransportProtocol         *AITDescriptorTransportProtocol
+}
+
+// AITDescriptorSimpleApplicationLocation represents a simple application location descriptor
+// Chapter: 5.3.7 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorSimpleApplicationLocation struct {
+	InitialPath []byte // Relative to the URL base of the transport protocol
+}
+
+// AITDescriptorTransportProtocol represents a transport protocol descriptor
+// Chapter: 5.3.6.2 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorTransportProtocol struct {
+	ComponentTag      uint8 // Only set for object carousels
+	Label             uint8
+	OriginalNetworkID uint16 // Only set for object carousels with a remote connection
+	ProtocolID        uint16 // See AITTransportProtocolID*
+	RemoteConnection  bool   // Only set for object carousels
+	Selector          []byte
+	ServiceID         uint16   // Only set for object carousels with a remote connection
+	TransportStreamID uint16   // Only set for object carousels with a remote connection
+	URLBase           []byte   // Only set for HTTP
+	URLExtensions     [][]byte // Only set for HTTP
+}
+
+// isAITElementaryStream checks whether the elementary stream carries an AIT, which is announced by an application
+// signalling descriptor
+func isAITElementaryStream(es *PMTElementaryStream) bool {
+	if es.StreamType != StreamTypeMPEG2MPEG2TabledData {
+		return false
+	}
+	for _, d := range es.ElementaryStreamDescriptors {
+		if d.Tag == DescriptorTagApplicationSignalling {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAITSection parses an AIT section
+func parseAITSection(i *astikit.BytesIterator, tableIDExtension uint16) (d *AITData, err error) {
+	// Create data
+	d = &AITData{
+		ApplicationType: tableIDExtension & 0x7fff,
+		TestApplication: tableIDExtension&0x8000 > 0,
+	}
+
+	// Common descriptors
+	var l int
+	if l, err = parseAITLoopLength(i); err != nil {
+		err = fmt.Errorf("astits: parsing common descriptors length failed: %w", err)
+		return
+	}
+	if d.Descriptors, err = parseAITDescriptors(i, i.Offset()+l); err != nil {
+		err = fmt.Errorf("astits: parsing common descriptors failed: %w", err)
+		return
+	}
+
+	// Application loop length
+	if l, err = parseAITLoopLength(i); err != nil {
+		err = fmt.Errorf("astits: parsing application loop length failed: %w", err)
+		return
+	}
+
+	// Loop through applications
+	offsetEnd := i.Offset() + l
+	for i.Offset() < offsetEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(7); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create application
+		a := &AITApplication{
+			ApplicationID:  uint16(bs[4])<<8 | uint16(bs[5]),
+			ControlCode:    bs[6],
+			OrganisationID: uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3]),
+		}
+
+		// Descriptors
+		if l, err = parseAITLoopLength(i); err != nil {
+			err = fmt.Errorf("astits: parsing application descriptors length failed: %w", err)
+			return
+		}
+		if a.Descriptors, err = parseAITDescriptors(i, i.Offset()+l); err != nil {
+			err = fmt.Errorf("astits: parsing application descriptors failed: %w", err)
+			return
+		}
+
+		// Append application
+		d.Applications = append(d.Applications, a)
+	}
+	return
+}
+
+// parseAITLoopLength parses a 12 bits loop length preceded by reserved bits
+func parseAITLoopLength(i *astikit.BytesIterator) (l int, err error) {
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+	l = int(bs[0]&0xf)<<8 | int(bs[1])
+	return
+}
+
+// parseAITDescriptors parses descriptors until offsetEnd
+func parseAITDescriptors(i *astikit.BytesIterator, offsetEnd int) (o []*AITDescriptor, err error) {
+	for i.Offset() < offsetEnd {
+		// Get next 2 bytes
+		var bs []byte
+		if bs, err = i.NextBytes(2); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create descriptor
+		d := &AITDescriptor{
+			Length: uint8(bs[1]),
+			Tag:    uint8(bs[0]),
+		}
+
+		// Get content
+		if d.Length > 0 {
+			if d.Content, err = i.NextBytes(int(d.Length)); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+		}
+
+		// Parse content
+		switch d.Tag {
+		case AITDescriptorTagSimpleApplicationLocation:
+			d.SimpleApplicationLocation = &AITDescriptorSimpleApplicationLocation{InitialPath: d.Content}
+		case AITDescriptorTagTransportProtocol:
+			if d.TransportProtocol, err = newAITDescriptorTransportProtocol(astikit.NewBytesIterator(d.Content)); err != nil {
+				err = fmt.Errorf("astits: parsing transport protocol AIT descriptor failed: %w", err)
+				return
+			}
+		}
+
+		// Append descriptor
+		o = append(o, d)
+	}
+	return
+}
+
+func newAITDescriptorTransportProtocol(i *astikit.BytesIterator) (d *AITDescriptorTransportProtocol, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(3); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &AITDescriptorTransportProtocol{
+		Label:      bs[2],
+		ProtocolID: uint16(bs[0])<<8 | uint16(bs[1]),
+	}
+
+	// Selector
+	if d.Selector = i.Dump(); len(d.Selector) == 0 {
+		return
+	}
+	i = astikit.NewBytesIterator(d.Selector)
+
+	// Switch on protocol
+	switch d.ProtocolID {
+	case AITTransportProtocolIDHTTP:
+		// URL base
+		if d.URLBase, err = parseAITURL(i); err != nil {
+			err = fmt.Errorf("astits: parsing URL base failed: %w", err)
+			return
+		}
+
+		// Get next byte
+		var b byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// URL extensions
+		for idx := 0; idx < int(b); idx++ {
+			var e []byte
+			if e, err = parseAITURL(i); err != nil {
+				err = fmt.Errorf("astits: parsing URL extension failed: %w", err)
+				return
+			}
+			d.URLExtensions = append(d.URLExtensions, e)
+		}
+	case AITTransportProtocolIDObjectCarousel:
+		// Get next byte
+		var b byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+		d.RemoteConnection = b&0x80 > 0
+
+		// Remote connection
+		if d.RemoteConnection {
+			if bs, err = i.NextBytes(6); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+			d.OriginalNetworkID = uint16(bs[0])<<8 | uint16(bs[1])
+			d.TransportStreamID = uint16(bs[2])<<8 | uint16(bs[3])
+			d.ServiceID = uint16(bs[4])<<8 | uint16(bs[5])
+		}
+
+		// Component tag
+		if d.ComponentTag, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// parseAITURL parses an URL preceded by its 8 bits length
+func parseAITURL(i *astikit.BytesIterator) (u []byte, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Get URL
+	if b > 0 {
+		if u, err = i.NextBytes(int(b)); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// URL returns the URL of an application delivered over HTTP, made of the URL base of its transport protocol and of
+// the initial path of its simple application location
+// Transport protocols are looked for in the descriptors of the application first, then in the common descriptors.
+func (d *AITData) URL(a *AITApplication) (url string, ok bool) {
+	// Get initial path
+	var l *AITDescriptorSimpleApplicationLocation
+	for _, v := range a.Descriptors {
+		if v.SimpleApplicationLocation != nil {
+			l = v.SimpleApplicationLocation
+			break
+		}
+	}
+	if l == nil {
+		return
+	}
+
+	// Get URL base
+	for _, ds := range [][]*AITDescriptor{a.Descriptors, d.Descriptors} {
+		for _, v := range ds {
+			if v.TransportProtocol != nil && v.TransportProtocol.ProtocolID == AITTransportProtocolIDHTTP {
+				return string(v.TransportProtocol.URLBase) + string(l.InitialPath), true
+			}
+		}
+	}
+	return
+}
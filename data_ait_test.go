@@ -0,0 +1,142 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func aitBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf00d))                           // Reserved and common descriptors length
+	w.Write(uint8(AITDescriptorTagTransportProtocol)) // Tag
+	w.Write(uint8(11))                                // Length
+	w.Write(uint16(AITTransportProtocolIDHTTP))       // Protocol ID
+	w.Write(uint8(1))                                 // Label
+	w.Write(uint8(5))                                 // URL base length
+	w.Write([]byte("http:"))                          // URL base
+	w.Write(uint8(1))                                 // URL extensions count
+	w.Write(uint8(0))                                 // URL extension length
+	w.Write(uint16(0xf031))                           // Reserved and application loop length
+
+	// HbbTV application
+	w.Write(uint32(0x1))                                      // Organisation ID
+	w.Write(uint16(0x2))                                      // Application ID
+	w.Write(uint8(AITApplicationControlCodeAutostart))        // Control code
+	w.Write(uint16(0xf00b))                                   // Reserved and descriptors length
+	w.Write(uint8(AITDescriptorTagSimpleApplicationLocation)) // Tag
+	w.Write(uint8(9))                                         // Length
+	w.Write([]byte("//a/index"))                              // Initial path
+
+	// Object carousel application
+	w.Write(uint32(0x3))                                  // Organisation ID
+	w.Write(uint16(0x4))                                  // Application ID
+	w.Write(uint8(AITApplicationControlCodePresent))      // Control code
+	w.Write(uint16(0xf014))                               // Reserved and descriptors length
+	w.Write(uint8(AITDescriptorTagTransportProtocol))     // Tag
+	w.Write(uint8(11))                                    // Length
+	w.Write(uint16(AITTransportProtocolIDObjectCarousel)) // Protocol ID
+	w.Write(uint8(2))                                     // Label
+	w.Write("1")                                          // Remote connection
+	w.Write("1111111")                                    // Reserved
+	w.Write(uint16(0x5))                                  // Original network ID
+	w.Write(uint16(0x6))                                  // Transport stream ID
+	w.Write(uint16(0x7))                                  // Service ID
+	w.Write(uint8(0x8))                                   // Component tag
+	w.Write(uint8(AITDescriptorTagApplicationName))       // Tag
+	w.Write(uint8(5))                                     // Length
+	w.Write([]byte("eng"))                                // ISO 639 language code
+	w.Write(uint8(1))                                     // Application name length
+	w.Write([]byte("a"))                                  // Application name
+	return buf.Bytes()
+}
+
+func TestParseAITSection(t *testing.T) {
+	d, err := parseAITSection(astikit.NewBytesIterator(aitBytes()), 0x8010)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x10), d.ApplicationType)
+	assert.True(t, d.TestApplication)
+	assert.Equal(t, []*AITDescriptor{{
+		Content: []byte{0x0, 0x3, 0x1, 0x5, 'h', 't', 't', 'p', ':', 0x1, 0x0},
+		Length:  11,
+		Tag:     AITDescriptorTagTransportProtocol,
+		TransportProtocol: &AITDescriptorTransportProtocol{
+			Label:         1,
+			ProtocolID:    AITTransportProtocolIDHTTP,
+			Selector:      []byte{0x5, 'h', 't', 't', 'p', ':', 0x1, 0x0},
+			URLBase:       []byte("http:"),
+			URLExtensions: [][]byte{nil},
+		},
+	}}, d.Descriptors)
+	assert.Len(t, d.Applications, 2)
+	assert.Equal(t, &AITApplication{
+		ApplicationID: 2,
+		ControlCode:   AITApplicationControlCodeAutostart,
+		Descriptors: []*AITDescriptor{{
+			Content:                   []byte("//a/index"),
+			Length:                    9,
+			SimpleApplicationLocation: &AITDescriptorSimpleApplicationLocation{InitialPath: []byte("//a/index")},
+			Tag:                       AITDescriptorTagSimpleApplicationLocation,
+		}},
+		OrganisationID: 1,
+	}, d.Applications[0])
+	assert.Equal(t, uint32(3), d.Applications[1].OrganisationID)
+	assert.Equal(t, uint16(4), d.Applications[1].ApplicationID)
+	assert.Equal(t, uint8(AITApplicationControlCodePresent), d.Applications[1].ControlCode)
+	assert.Len(t, d.Applications[1].Descriptors, 2)
+	assert.Equal(t, &AITDescriptorTransportProtocol{
+		ComponentTag:      8,
+		Label:             2,
+		OriginalNetworkID: 5,
+		ProtocolID:        AITTransportProtocolIDObjectCarousel,
+		RemoteConnection:  true,
+		Selector:          []byte{0xff, 0x0, 0x5, 0x0, 0x6, 0x0, 0x7, 0x8},
+		ServiceID:         7,
+		TransportStreamID: 6,
+	}, d.Applications[1].Descriptors[0].TransportProtocol)
+	assert.Equal(t, []byte{'e', 'n', 'g', 0x1, 'a'}, d.Applications[1].Descriptors[1].Content)
+
+	// URL
+	u, ok := d.URL(d.Applications[0])
+	assert.True(t, ok)
+	assert.Equal(t, "http://a/index", u)
+	_, ok = d.URL(d.Applications[1])
+	assert.False(t, ok)
+}
+
+func TestParseDataAIT(t *testing.T) {
+	// Create section
+	b := aitBytes()
+	s := []byte{AITTableID, 0xb0 | uint8((len(b)+9)>>8), uint8(len(b) + 9), 0x0, 0x10, 0xc3, 0x0, 0x0}
+	s = append(s, b...)
+	c, err := computeCRC32(s)
+	assert.NoError(t, err)
+	s = append(s, uint8(c>>24), uint8(c>>16), uint8(c>>8), uint8(c))
+	ps := []*Packet{{Header: &PacketHeader{PID: 0x100, PayloadUnitStartIndicator: true}, Payload: append([]byte{0x0}, s...)}}
+
+	// Parse
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileDVB, map[uint16]bool{0x100: true}, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, TableTypeAIT, ds[0].Section.Header.Type)
+	assert.Equal(t, uint16(0x10), ds[0].AIT.ApplicationType)
+	assert.False(t, ds[0].AIT.TestApplication)
+	u, ok := ds[0].AIT.URL(ds[0].AIT.Applications[0])
+	assert.True(t, ok)
+	assert.Equal(t, "http://a/index", u)
+
+	// Demuxer
+	dmx := New(context.Background(), nil)
+	dmx.updateData([]*Data{{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{
+		{ElementaryPID: 0x100, ElementaryStreamDescriptors: []*Descriptor{{
+			ApplicationSignalling: &DescriptorApplicationSignalling{},
+			Tag:                   DescriptorTagApplicationSignalling,
+		}}, StreamType: StreamTypeMPEG2MPEG2TabledData},
+		{ElementaryPID: 0x101, StreamType: StreamTypeMPEG2MPEG2TabledData},
+	}}, PID: 0x1000}})
+	assert.Equal(t, map[uint16]bool{0x100: true}, dmx.tablePIDs)
+}
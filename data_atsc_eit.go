@@ -0,0 +1,94 @@
+package astits
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// ATSCEITData represents an ATSC EIT data
+// Unlike DVB EITs, ATSC EITs are carried on PIDs announced by the MGT, each EIT-k covering a 3 hours time slot.
+// Chapter: 6.5 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type ATSCEITData struct {
+	Events          []*ATSCEITEvent
+	ProtocolVersion uint8
+	SourceID        uint16 // Source ID of the virtual channel the events belong to
+}
+
+// ATSCEITEvent represents an ATSC EIT event
+type ATSCEITEvent struct {
+	Descriptors []*Descriptor
+	Duration    time.Duration
+	ETMLocation uint8  // Where the ETT holding the description of the event is, see VCTETMLocation*
+	EventID     uint16 // 14 bits
+	StartTime   uint32 // Seconds since 1980-01-06T00:00:00Z GPS time, the GPS to UTC offset being carried by the STT
+	Title       *ATSCMultipleString
+}
+
+// parseATSCEITSection parses an ATSC EIT section
+func parseATSCEITSection(i *astikit.BytesIterator, tableIDExtension uint16) (d *ATSCEITData, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create data
+	d = &ATSCEITData{
+		ProtocolVersion: bs[0],
+		SourceID:        tableIDExtension,
+	}
+
+	// Loop through events
+	eventsNum := int(bs[1])
+	for idx := 0; idx < eventsNum; idx++ {
+		// Get next bytes
+		if bs, err = i.NextBytes(10); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create event
+		e := &ATSCEITEvent{
+			Duration:    time.Duration(uint32(bs[6]&0xf)<<16|uint32(bs[7])<<8|uint32(bs[8])) * time.Second,
+			ETMLocation: bs[6] >> 4 & 0x3,
+			EventID:     uint16(bs[0]&0x3f)<<8 | uint16(bs[1]),
+			StartTime:   uint32(bs[2])<<24 | uint32(bs[3])<<16 | uint32(bs[4])<<8 | uint32(bs[5]),
+		}
+
+		// Title
+		if bs[9] > 0 {
+			var tbs []byte
+			if tbs, err = i.NextBytes(int(bs[9])); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+			if e.Title, err = ParseATSCMultipleString(tbs); err != nil {
+				err = fmt.Errorf("astits: parsing title failed: %w", err)
+				return
+			}
+		}
+
+		// Descriptors
+		if e.Descriptors, err = parseDescriptors(i); err != nil {
+			err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+			return
+		}
+
+		// Append event
+		d.Events = append(d.Events, e)
+	}
+	return
+}
+
+// UTCStartTime returns the start time of the event as a UTC time, given the GPS to UTC offset of the last STT
+func (e *ATSCEITEvent) UTCStartTime(gpsUTCOffset uint8) time.Time {
+	return ATSCGPSTime(e.StartTime, gpsUTCOffset)
+}
+
+// ETMID returns the ID of the ETM holding the description of the event
+func (e *ATSCEITEvent) ETMID(sourceID uint16) uint32 {
+	return newATSCETMID(sourceID, e.EventID)
+}
@@ -0,0 +1,68 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func atscEITBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint8(0))               // Protocol version
+	w.Write(uint8(2))               // Events in section
+	w.Write("11")                   // Event #1 reserved
+	w.Write("00000000000101")       // Event #1 ID
+	w.Write(uint32(1000000000))     // Event #1 start time
+	w.Write("11")                   // Event #1 reserved
+	w.Write("01")                   // Event #1 ETM location
+	w.Write("00000000111000010000") // Event #1 length in seconds
+	w.Write(uint8(10))              // Event #1 title length
+	w.Write([]byte{1, 'e', 'n', 'g', 1, ATSCCompressionTypeNone, ATSCModeLatin1, 2, 'h', 'i'})
+	w.Write("1111")                 // Event #1 reserved
+	descriptorsBytes(w)             // Event #1 descriptors
+	w.Write("11")                   // Event #2 reserved
+	w.Write("00000000000110")       // Event #2 ID
+	w.Write(uint32(1000003600))     // Event #2 start time
+	w.Write("11")                   // Event #2 reserved
+	w.Write("00")                   // Event #2 ETM location
+	w.Write("00000000011100001000") // Event #2 length in seconds
+	w.Write(uint8(0))               // Event #2 title length
+	w.Write(uint16(0xf000))         // Event #2 descriptors length
+	return buf.Bytes()
+}
+
+func TestParseATSCEITSection(t *testing.T) {
+	d, err := parseATSCEITSection(astikit.NewBytesIterator(atscEITBytes()), 0x1001)
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{ATSCEIT: d})
+	assert.Equal(t, &ATSCEITData{
+		Events: []*ATSCEITEvent{
+			{
+				Descriptors: descriptors,
+				Duration:    time.Hour,
+				ETMLocation: VCTETMLocationPhysicalChannel,
+				EventID:     5,
+				StartTime:   1000000000,
+				Title: &ATSCMultipleString{Strings: []*ATSCString{{
+					Language: []byte("eng"),
+					Segments: []*ATSCStringSegment{{Bytes: []byte("hi"), Mode: ATSCModeLatin1}},
+				}}},
+			},
+			{
+				Duration:  30 * time.Minute,
+				EventID:   6,
+				StartTime: 1000003600,
+			},
+		},
+		SourceID: 0x1001,
+	}, d)
+	assert.Equal(t, uint32(0x10010016), d.Events[0].ETMID(d.SourceID))
+
+	// Truncated
+	_, err = parseATSCEITSection(astikit.NewBytesIterator(atscEITBytes()[:15]), 0x1001)
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// CATData represents a CAT data
+// The CA descriptors it holds declare the EMM PIDs of the CA systems used in the transport stream.
+// Chapter: 2.4.4.6 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type CATData struct {
+	Descriptors []*Descriptor
+}
+
+// parseCATSection parses a CAT section
+func parseCATSection(i *astikit.BytesIterator, offsetSectionsEnd int) (d *CATData, err error) {
+	// Create data
+	d = &CATData{}
+
+	// Descriptors fill the section, there is no loop length
+	if d.Descriptors, err = parseDescriptorsUntil(i, offsetSectionsEnd); err != nil {
+		err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+		return
+	}
+	return
+}
+
+// Serialise serialises the CAT data
+func (d *CATData) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	for i, v := range d.Descriptors {
+		if err := w.write(fmt.Sprintf("descriptor #%d", i), v.serialise); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
@@ -0,0 +1,59 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+var cat = &CATData{Descriptors: []*Descriptor{{
+	CA: &DescriptorCA{
+		CAPID:       0x123,
+		CASystemID:  0x100,
+		PrivateData: []byte{0xa, 0xb},
+	},
+	Length: 6,
+	Tag:    DescriptorTagCA,
+}}}
+
+func catBytes() []byte {
+	return []byte{0x9, 0x6, 0x1, 0x0, 0xe1, 0x23, 0xa, 0xb}
+}
+
+// catPSIBytes returns a CAT section preceded by its pointer field
+func catPSIBytes(t *testing.T) []byte {
+	b := make([]byte, 184)
+	n, err := (&PSISection{
+		Header: &PSISectionHeader{SectionSyntaxIndicator: true, TableID: 1},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{CAT: cat},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 0xffff},
+		},
+	}).Serialise(b[1:])
+	assert.NoError(t, err)
+	return b[:n+1]
+}
+
+func TestParseCATSection(t *testing.T) {
+	b := catBytes()
+	d, err := parseCATSection(astikit.NewBytesIterator(b), len(b))
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{CAT: d})
+	assert.Equal(t, cat, d)
+}
+
+func TestCATDataSerialise(t *testing.T) {
+	b := make([]byte, 8)
+	n, err := cat.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, n)
+	assert.Equal(t, catBytes(), b)
+
+	// Section round trip
+	d, err := parsePSIData(astikit.NewBytesIterator(catPSIBytes(t)), ProfileAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, TableTypeCAT, d.Sections[0].Header.Type)
+	removeOriginalBytesFromData(&Data{CAT: d.Sections[0].Syntax.Data.CAT})
+	assert.Equal(t, cat, d.Sections[0].Syntax.Data.CAT)
+}
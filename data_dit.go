@@ -0,0 +1,28 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// DITData represents a DIT data
+// It is inserted in partial transport streams, such as recordings of a PVR, where the SI may be discontinuous.
+// Page: 92 | Chapter: 7.1.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DITData struct {
+	TransitionFlag bool // Set when the transition is due to a change of the originating source, unset when it is due to a selection change
+}
+
+// parseDITSection parses a DIT section
+func parseDITSection(i *astikit.BytesIterator) (d *DITData, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Create data
+	d = &DITData{TransitionFlag: b&0x80 > 0}
+	return
+}
@@ -0,0 +1,17 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDITSection(t *testing.T) {
+	d, err := parseDITSection(astikit.NewBytesIterator([]byte{0xff}))
+	assert.NoError(t, err)
+	assert.Equal(t, &DITData{TransitionFlag: true}, d)
+	d, err = parseDITSection(astikit.NewBytesIterator([]byte{0x7f}))
+	assert.NoError(t, err)
+	assert.Equal(t, &DITData{}, d)
+}
@@ -0,0 +1,310 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// DSM-CC table IDs
+// Chapter: 9.2.2 | Link: https://www.iso.org/standard/25039.html
+const (
+	DSMCCTableIDMultiprotocolEncapsulation = 0x3a
+	DSMCCTableIDUNMessages                 = 0x3b // DSI and DII
+	DSMCCTableIDDownloadData               = 0x3c // DDB
+	DSMCCTableIDStreamDescriptors          = 0x3d
+	DSMCCTableIDPrivateData                = 0x3e
+)
+
+// DSM-CC message IDs
+// Chapter: 7.3 | Link: https://www.iso.org/standard/25039.html
+const (
+	DSMCCMessageIDDII = 0x1002 // Download Info Indication
+	DSMCCMessageIDDDB = 0x1003 // Download Data Block
+	DSMCCMessageIDDSI = 0x1006 // Download Server Initiate
+)
+
+// DSMCCData represents a DSM-CC data
+// Sections carrying U-N download messages are parsed, the payload of the other ones, such as multiprotocol
+// encapsulation datagrams, is left as is.
+// Chapter: 9.2 | Link: https://www.iso.org/standard/25039.html
+// Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101202/01.02.01_60/tr_101202v010201p.pdf
+type DSMCCData struct {
+	DDB     *DSMCCDDB
+	DII     *DSMCCDII
+	DSI     *DSMCCDSI
+	Header  *DSMCCMessageHeader // Only set for U-N download messages
+	Payload []byte              // Only set for sections that don't carry U-N download messages
+}
+
+// DSMCCMessageHeader represents a DSM-CC message header, or a download data header for DDBs
+type DSMCCMessageHeader struct {
+	AdaptationHeader      []byte
+	DSMCCType             uint8 // 0x3 for U-N download messages
+	MessageID             uint16
+	ProtocolDiscriminator uint8  // 0x11 for MPEG-2 DSM-CC
+	TransactionID         uint32 // Download ID for DDBs
+}
+
+// DSMCCDSI represents a DSM-CC Download Server Initiate message
+// For object carousels, it points at the service gateway, which is the root directory of the carousel.
+type DSMCCDSI struct {
+	CompatibilityDescriptor []byte
+	PrivateData             []byte
+	ServerID                []byte
+	ServiceGateway          *DSMCCIOR // Only set for object carousels
+}
+
+// DSMCCDII represents a DSM-CC Download Info Indication message, which describes the modules of a download
+type DSMCCDII struct {
+	AckPeriod               uint8
+	BlockSize               uint16
+	CompatibilityDescriptor []byte
+	DownloadID              uint32
+	Modules                 []*DSMCCDIIModule
+	PrivateData             []byte
+	TCDownloadScenario      uint32
+	TCDownloadWindow        uint32
+	WindowSize              uint8
+}
+
+// DSMCCDIIModule represents a module described by a DII
+type DSMCCDIIModule struct {
+	ID      uint16
+	Info    []byte // BIOP module info for object carousels, descriptors for data carousels
+	Size    uint32 // Size of the module as transmitted, which is the compressed size if it is compressed
+	Version uint8
+}
+
+// DSMCCDDB represents a DSM-CC Download Data Block message, which carries a block of a module
+type DSMCCDDB struct {
+	BlockData     []byte
+	BlockNumber   uint16
+	DownloadID    uint32
+	ModuleID      uint16
+	ModuleVersion uint8
+}
+
+// isDSMCCSectionsStreamType checks whether the stream type designates DSM-CC sections, which must then be parsed as
+// tables
+func isDSMCCSectionsStreamType(t uint8) bool {
+	return t >= StreamTypeDSMCCMultiProtocolEncapsulation && t <= StreamTypeDSMCCTabledData
+}
+
+// parseDSMCCSection parses a DSM-CC section
+func parseDSMCCSection(i *astikit.BytesIterator, offsetSectionsEnd, tableID int) (d *DSMCCData, err error) {
+	// Create data
+	d = &DSMCCData{}
+
+	// Not a U-N download message
+	if tableID != DSMCCTableIDUNMessages && tableID != DSMCCTableIDDownloadData {
+		if offsetSectionsEnd > i.Offset() {
+			if d.Payload, err = i.NextBytes(offsetSectionsEnd - i.Offset()); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+		}
+		return
+	}
+
+	// Parse header
+	if d.Header, err = parseDSMCCMessageHeader(i); err != nil {
+		err = fmt.Errorf("astits: parsing DSM-CC message header failed: %w", err)
+		return
+	}
+
+	// Switch on message ID
+	switch d.Header.MessageID {
+	case DSMCCMessageIDDDB:
+		if d.DDB, err = parseDSMCCDDB(i, offsetSectionsEnd, d.Header.TransactionID); err != nil {
+			err = fmt.Errorf("astits: parsing DSM-CC DDB failed: %w", err)
+			return
+		}
+	case DSMCCMessageIDDII:
+		if d.DII, err = parseDSMCCDII(i); err != nil {
+			err = fmt.Errorf("astits: parsing DSM-CC DII failed: %w", err)
+			return
+		}
+	case DSMCCMessageIDDSI:
+		if d.DSI, err = parseDSMCCDSI(i); err != nil {
+			err = fmt.Errorf("astits: parsing DSM-CC DSI failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// parseDSMCCMessageHeader parses a DSM-CC message header
+func parseDSMCCMessageHeader(i *astikit.BytesIterator) (h *DSMCCMessageHeader, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(12); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create header
+	h = &DSMCCMessageHeader{
+		DSMCCType:             bs[1],
+		MessageID:             uint16(bs[2])<<8 | uint16(bs[3]),
+		ProtocolDiscriminator: bs[0],
+		TransactionID:         uint32(bs[4])<<24 | uint32(bs[5])<<16 | uint32(bs[6])<<8 | uint32(bs[7]),
+	}
+
+	// Adaptation header
+	if bs[9] > 0 {
+		if h.AdaptationHeader, err = i.NextBytes(int(bs[9])); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// parseDSMCCDDB parses a DSM-CC DDB
+func parseDSMCCDDB(i *astikit.BytesIterator, offsetSectionsEnd int, downloadID uint32) (d *DSMCCDDB, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(6); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create DDB
+	d = &DSMCCDDB{
+		BlockNumber:   uint16(bs[4])<<8 | uint16(bs[5]),
+		DownloadID:    downloadID,
+		ModuleID:      uint16(bs[0])<<8 | uint16(bs[1]),
+		ModuleVersion: bs[2],
+	}
+
+	// Block data fills the section
+	if offsetSectionsEnd > i.Offset() {
+		if d.BlockData, err = i.NextBytes(offsetSectionsEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// parseDSMCCDII parses a DSM-CC DII
+func parseDSMCCDII(i *astikit.BytesIterator) (d *DSMCCDII, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(16); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create DII
+	d = &DSMCCDII{
+		AckPeriod:          bs[7],
+		BlockSize:          uint16(bs[4])<<8 | uint16(bs[5]),
+		DownloadID:         uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3]),
+		TCDownloadScenario: uint32(bs[12])<<24 | uint32(bs[13])<<16 | uint32(bs[14])<<8 | uint32(bs[15]),
+		TCDownloadWindow:   uint32(bs[8])<<24 | uint32(bs[9])<<16 | uint32(bs[10])<<8 | uint32(bs[11]),
+		WindowSize:         bs[6],
+	}
+
+	// Compatibility descriptor
+	if d.CompatibilityDescriptor, err = parseDSMCCLengthBytes(i, 2); err != nil {
+		err = fmt.Errorf("astits: parsing compatibility descriptor failed: %w", err)
+		return
+	}
+
+	// Get next bytes
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Loop through modules
+	modulesNum := int(uint16(bs[0])<<8 | uint16(bs[1]))
+	for idx := 0; idx < modulesNum; idx++ {
+		// Get next bytes
+		if bs, err = i.NextBytes(7); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create module
+		m := &DSMCCDIIModule{
+			ID:      uint16(bs[0])<<8 | uint16(bs[1]),
+			Size:    uint32(bs[2])<<24 | uint32(bs[3])<<16 | uint32(bs[4])<<8 | uint32(bs[5]),
+			Version: bs[6],
+		}
+
+		// Module info
+		if m.Info, err = parseDSMCCLengthBytes(i, 1); err != nil {
+			err = fmt.Errorf("astits: parsing module info failed: %w", err)
+			return
+		}
+
+		// Append module
+		d.Modules = append(d.Modules, m)
+	}
+
+	// Private data
+	if d.PrivateData, err = parseDSMCCLengthBytes(i, 2); err != nil {
+		err = fmt.Errorf("astits: parsing private data failed: %w", err)
+		return
+	}
+	return
+}
+
+// parseDSMCCDSI parses a DSM-CC DSI
+func parseDSMCCDSI(i *astikit.BytesIterator) (d *DSMCCDSI, err error) {
+	// Create DSI
+	d = &DSMCCDSI{}
+
+	// Server ID
+	if d.ServerID, err = i.NextBytes(20); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Compatibility descriptor
+	if d.CompatibilityDescriptor, err = parseDSMCCLengthBytes(i, 2); err != nil {
+		err = fmt.Errorf("astits: parsing compatibility descriptor failed: %w", err)
+		return
+	}
+
+	// Private data
+	if d.PrivateData, err = parseDSMCCLengthBytes(i, 2); err != nil {
+		err = fmt.Errorf("astits: parsing private data failed: %w", err)
+		return
+	}
+
+	// Object carousels store the service gateway info in the private data, whereas data carousels store a group info
+	// indication, which is not an IOR
+	if ior, err := parseDSMCCIOR(astikit.NewBytesIterator(d.PrivateData)); err == nil && ior.TypeID == DSMCCObjectKindServiceGateway {
+		d.ServiceGateway = ior
+	}
+	return
+}
+
+// parseDSMCCLengthBytes parses bytes preceded by their length, written on n bytes
+func parseDSMCCLengthBytes(i *astikit.BytesIterator, n int) (o []byte, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(n); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Get length
+	var l int
+	for _, b := range bs {
+		l = l<<8 | int(b)
+	}
+
+	// Get next bytes
+	if l > 0 {
+		if o, err = i.NextBytes(l); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
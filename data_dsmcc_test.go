@@ -0,0 +1,191 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func dsmccMessageHeaderBytes(w *astikit.BitsWriter, messageID uint16, transactionID uint32) {
+	w.Write(uint8(0x11))      // Protocol discriminator
+	w.Write(uint8(0x3))       // DSM-CC type
+	w.Write(messageID)        // Message ID
+	w.Write(transactionID)    // Transaction ID
+	w.Write(uint8(0xff))      // Reserved
+	w.Write(uint8(2))         // Adaptation length
+	w.Write(uint16(0))        // Message length
+	w.Write([]byte{0x1, 0x2}) // Adaptation header
+}
+
+func dsmccIORBytes(typeID string, carouselID uint32, moduleID uint16, objectKey []byte) []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint32(4))                                                    // Type ID length
+	w.Write([]byte(typeID + "\x00"))                                      // Type ID
+	w.Write(uint32(1))                                                    // Tagged profiles count
+	w.Write(uint32(dsmccTagBIOP))                                         // Profile ID tag
+	w.Write(uint32(2 + 14 + len(objectKey) + 23))                         // Profile data length
+	w.Write(uint8(0))                                                     // Byte order
+	w.Write(uint8(2))                                                     // Lite components count
+	w.Write(uint32(dsmccTagObjectLocation))                               // Component ID tag
+	w.Write(uint8(9 + len(objectKey)))                                    // Component data length
+	w.Write(carouselID)                                                   // Carousel ID
+	w.Write(moduleID)                                                     // Module ID
+	w.Write([]byte{0x1, 0x0})                                             // Version
+	w.Write(uint8(len(objectKey)))                                        // Object key length
+	w.Write(objectKey)                                                    // Object key
+	w.Write(uint32(dsmccTagConnBinder))                                   // Component ID tag
+	w.Write(uint8(18))                                                    // Component data length
+	w.Write(uint8(1))                                                     // Taps count
+	w.Write(uint16(0))                                                    // ID
+	w.Write(uint16(0x16))                                                 // Use
+	w.Write(uint16(0xb))                                                  // Association tag
+	w.Write(uint8(10))                                                    // Selector length
+	w.Write([]byte{0x0, 0x1, 0x0, 0x0, 0x0, 0x1, 0xff, 0xff, 0xff, 0xff}) // Selector
+	return buf.Bytes()
+}
+
+func dsmccDSIBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	dsmccMessageHeaderBytes(w, DSMCCMessageIDDSI, 0x80000000)
+	w.Write(bytes.Repeat([]byte{0xff}, 20)) // Server ID
+	w.Write(uint16(0))                      // Compatibility descriptor length
+	ior := dsmccIORBytes(DSMCCObjectKindServiceGateway, 1, 2, []byte{0x3})
+	w.Write(uint16(len(ior))) // Private data length
+	w.Write(ior)              // Private data
+	return buf.Bytes()
+}
+
+func dsmccDIIBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	dsmccMessageHeaderBytes(w, DSMCCMessageIDDII, 0x80000002)
+	w.Write(uint32(1))        // Download ID
+	w.Write(uint16(4066))     // Block size
+	w.Write(uint8(0))         // Window size
+	w.Write(uint8(0))         // Ack period
+	w.Write(uint32(0))        // Download window
+	w.Write(uint32(0))        // Download scenario
+	w.Write(uint16(2))        // Compatibility descriptor length
+	w.Write([]byte{0x0, 0x0}) // Compatibility descriptor
+	w.Write(uint16(1))        // Number of modules
+	w.Write(uint16(2))        // Module ID
+	w.Write(uint32(3))        // Module size
+	w.Write(uint8(4))         // Module version
+	w.Write(uint8(1))         // Module info length
+	w.Write(uint8(5))         // Module info
+	w.Write(uint16(0))        // Private data length
+	return buf.Bytes()
+}
+
+func dsmccDDBBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	dsmccMessageHeaderBytes(w, DSMCCMessageIDDDB, 1)
+	w.Write(uint16(2))             // Module ID
+	w.Write(uint8(4))              // Module version
+	w.Write(uint8(0xff))           // Reserved
+	w.Write(uint16(0))             // Block number
+	w.Write([]byte{0x1, 0x2, 0x3}) // Block data
+	return buf.Bytes()
+}
+
+func TestParseDSMCCSection(t *testing.T) {
+	// DSI
+	b := dsmccDSIBytes()
+	d, err := parseDSMCCSection(astikit.NewBytesIterator(b), len(b), DSMCCTableIDUNMessages)
+	assert.NoError(t, err)
+	assert.Equal(t, &DSMCCMessageHeader{
+		AdaptationHeader:      []byte{0x1, 0x2},
+		DSMCCType:             0x3,
+		MessageID:             DSMCCMessageIDDSI,
+		ProtocolDiscriminator: 0x11,
+		TransactionID:         0x80000000,
+	}, d.Header)
+	assert.NotNil(t, d.DSI)
+	assert.Equal(t, bytes.Repeat([]byte{0xff}, 20), d.DSI.ServerID)
+	assert.Equal(t, &DSMCCIOR{
+		AssociationTag: 0xb,
+		ObjectLocation: &DSMCCObjectLocation{
+			CarouselID: 1,
+			ModuleID:   2,
+			ObjectKey:  []byte{0x3},
+		},
+		TypeID: DSMCCObjectKindServiceGateway,
+	}, d.DSI.ServiceGateway)
+
+	// DII
+	b = dsmccDIIBytes()
+	d, err = parseDSMCCSection(astikit.NewBytesIterator(b), len(b), DSMCCTableIDUNMessages)
+	assert.NoError(t, err)
+	assert.Equal(t, &DSMCCDII{
+		BlockSize:               4066,
+		CompatibilityDescriptor: []byte{0x0, 0x0},
+		DownloadID:              1,
+		Modules: []*DSMCCDIIModule{{
+			ID:      2,
+			Info:    []byte{0x5},
+			Size:    3,
+			Version: 4,
+		}},
+	}, d.DII)
+
+	// DDB
+	b = dsmccDDBBytes()
+	d, err = parseDSMCCSection(astikit.NewBytesIterator(b), len(b), DSMCCTableIDDownloadData)
+	assert.NoError(t, err)
+	assert.Equal(t, &DSMCCDDB{
+		BlockData:     []byte{0x1, 0x2, 0x3},
+		DownloadID:    1,
+		ModuleID:      2,
+		ModuleVersion: 4,
+	}, d.DDB)
+
+	// Private data
+	d, err = parseDSMCCSection(astikit.NewBytesIterator([]byte{0x1, 0x2}), 2, DSMCCTableIDPrivateData)
+	assert.NoError(t, err)
+	assert.Equal(t, &DSMCCData{Payload: []byte{0x1, 0x2}}, d)
+}
+
+func TestParseDataDSMCC(t *testing.T) {
+	// Create section
+	b := dsmccDDBBytes()
+	s := []byte{DSMCCTableIDDownloadData, 0xb0 | uint8((len(b)+9)>>8), uint8(len(b) + 9), 0x0, 0x2, 0xc9, 0x0, 0x0}
+	s = append(s, b...)
+	c, err := computeCRC32(s)
+	assert.NoError(t, err)
+	s = append(s, uint8(c>>24), uint8(c>>16), uint8(c>>8), uint8(c))
+	ps := []*Packet{{Header: &PacketHeader{PID: 0x100, PayloadUnitStartIndicator: true}, Payload: append([]byte{0x0}, s...)}}
+	p := ps[0].Payload
+
+	// PID has not been announced
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileDVB, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 0)
+
+	// PID has been announced
+	ds, err = parseData(ps, nil, NewProgramMap(), ProfileDVB, map[uint16]bool{0x100: true}, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, TableTypeDSMCC, ds[0].Section.Header.Type)
+	assert.Equal(t, []byte{0x1, 0x2, 0x3}, ds[0].DSMCC.DDB.BlockData)
+
+	// Checksum is not checked when section syntax indicator is not set
+	p[2] &= 0x7f
+	p[len(p)-1] ^= 0xff
+	ds, err = parseData(ps, nil, NewProgramMap(), ProfileDVB, map[uint16]bool{0x100: true}, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+
+	// Demuxer
+	dmx := New(context.Background(), nil)
+	dmx.updateData([]*Data{{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{
+		{ElementaryPID: 0x100, StreamType: StreamTypeDSMCCUNMessages},
+		{ElementaryPID: 0x101, StreamType: StreamTypeH264Video},
+	}}, PID: 0x1000}})
+	assert.Equal(t, map[uint16]bool{0x100: true}, dmx.tablePIDs)
+}
@@ -11,13 +11,46 @@ import (
 // Page: 36 | Chapter: 5.2.4 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type EITData struct {
 	Events                   []*EITDataEvent
-	LastTableID              uint8
+	LastTableID              uint8 // Last table ID used by the sub-table, which may span several table IDs in the case of a schedule
 	OriginalNetworkID        uint16
-	SegmentLastSectionNumber uint8
+	SegmentLastSectionNumber uint8 // Number of the last section of the current segment, segments being made of 8 sections
 	ServiceID                uint16
 	TransportStreamID        uint16
 }
 
+// Number of sections in an EIT segment
+const eitSegmentSize = 8
+
+// SegmentSectionNumbers returns the numbers of the first and last sections of the segment a section belongs to, which
+// are needed to know when a segment, and therefore a schedule sub-table, is complete
+func (d *EITData) SegmentSectionNumbers(sectionNumber uint8) (first, last uint8) {
+	first = sectionNumber - sectionNumber%eitSegmentSize
+	last = d.SegmentLastSectionNumber
+	if last < first || last >= first+eitSegmentSize {
+		last = sectionNumber
+	}
+	return
+}
+
+// TableIDs returns the first and last table IDs of the sub-table a table ID belongs to
+// Present/following sub-tables use a single table ID whereas schedule sub-tables span from 0x50 (or 0x60) to the last
+// table ID.
+func (d *EITData) TableIDs(tableID int) (first, last int) {
+	switch {
+	case tableID >= 0x50 && tableID <= 0x5f:
+		first = 0x50
+	case tableID >= 0x60 && tableID <= 0x6f:
+		first = 0x60
+	default:
+		return tableID, tableID
+	}
+	last = int(d.LastTableID)
+	if last < first || last > first+0xf {
+		last = tableID
+	}
+	return
+}
+
 // EITDataEvent represents an EIT data event
 type EITDataEvent struct {
 	Descriptors    []*Descriptor
@@ -121,3 +154,50 @@ func parseEITSection(i *astikit.BytesIterator, offsetSectionsEnd int, tableIDExt
 	}
 	return
 }
+
+func (d *EITData) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint16("transport stream ID", d.TransportStreamID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint16("original network ID", d.OriginalNetworkID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("segment last section number", d.SegmentLastSectionNumber); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("last table ID", d.LastTableID); err != nil {
+		return w.offset, err
+	}
+	for i, e := range d.Events {
+		if err := w.write(fmt.Sprintf("event #%d", i), e.Serialise); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
+
+func (e *EITDataEvent) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint16("event ID", e.EventID); err != nil {
+		return w.offset, err
+	}
+	bs, err := w.next("start time and duration", 8)
+	if err != nil {
+		return w.offset, err
+	}
+	writeDVBTime(bs, e.StartTime)
+	writeDVBDurationSeconds(bs[5:], e.Duration)
+	// Running status and free CA mode share their bytes with the descriptors loop length
+	if err = w.writeLength("descriptors loop length", 12, uint16(e.RunningStatus)<<13|uint16(Btou8(e.HasFreeCSAMode))<<12, func() error {
+		for i, d := range e.Descriptors {
+			if err := w.write(fmt.Sprintf("descriptor #%d", i), d.serialise); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
@@ -2,6 +2,7 @@ package astits
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/asticode/go-astikit"
@@ -47,3 +48,50 @@ func TestParseEITSection(t *testing.T) {
 	assert.Equal(t, d, eit)
 	assert.NoError(t, err)
 }
+
+func TestEITDataSerialise(t *testing.T) {
+	var b = eitBytes()
+	o := make([]byte, 1024)
+	n, err := eit.Serialise(o)
+	assert.NoError(t, err)
+	assert.Equal(t, b, o[:n])
+
+	// Round trip through a section
+	s := &PSISection{
+		Header: &PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x4e, TableType: PSITableTypeEIT, Type: TableTypeEIT},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{EIT: eit},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: eit.ServiceID},
+		},
+	}
+	_, err = (&PSIData{Sections: []*PSISection{s}}).Serialise(o)
+	assert.NoError(t, err)
+	d, err := parsePSIData(astikit.NewBytesIterator(o), ProfileAuto)
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{EIT: d.Sections[0].Syntax.Data.EIT})
+	assert.Equal(t, eit, d.Sections[0].Syntax.Data.EIT)
+
+	// No room
+	_, err = eit.Serialise(o[:10])
+	assert.True(t, errors.Is(err, ErrNoRoomInBuffer))
+}
+
+func TestEITDataSubTable(t *testing.T) {
+	d := &EITData{LastTableID: 0x52, SegmentLastSectionNumber: 10}
+	first, last := d.SegmentSectionNumbers(9)
+	assert.Equal(t, uint8(8), first)
+	assert.Equal(t, uint8(10), last)
+	first, last = d.SegmentSectionNumbers(17)
+	assert.Equal(t, uint8(16), first)
+	assert.Equal(t, uint8(17), last)
+
+	ft, lt := d.TableIDs(0x51)
+	assert.Equal(t, 0x50, ft)
+	assert.Equal(t, 0x52, lt)
+	ft, lt = d.TableIDs(0x61)
+	assert.Equal(t, 0x60, ft)
+	assert.Equal(t, 0x61, lt)
+	ft, lt = d.TableIDs(0x4e)
+	assert.Equal(t, 0x4e, ft)
+	assert.Equal(t, 0x4e, lt)
+}
@@ -0,0 +1,63 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// ETTData represents an ATSC ETT data
+// It holds the extended text message, such as a description, of either a virtual channel or an event.
+// Chapter: 6.6 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type ETTData struct {
+	ETMID               uint32 // Source ID, event ID and whether it describes an event or a channel
+	ExtendedTextMessage *ATSCMultipleString
+	ProtocolVersion     uint8
+}
+
+// parseETTSection parses an ATSC ETT section
+func parseETTSection(i *astikit.BytesIterator, offsetSectionsEnd int) (d *ETTData, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(5); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create data
+	d = &ETTData{
+		ETMID:           uint32(bs[1])<<24 | uint32(bs[2])<<16 | uint32(bs[3])<<8 | uint32(bs[4]),
+		ProtocolVersion: bs[0],
+	}
+
+	// Extended text message fills the section
+	if offsetSectionsEnd > i.Offset() {
+		if bs, err = i.NextBytes(offsetSectionsEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		if d.ExtendedTextMessage, err = ParseATSCMultipleString(bs); err != nil {
+			err = fmt.Errorf("astits: parsing extended text message failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// SourceID returns the source ID of the virtual channel the message belongs to
+func (d *ETTData) SourceID() uint16 {
+	return uint16(d.ETMID >> 16)
+}
+
+// EventID returns the ID of the event the message describes, ok being false if it describes a channel
+func (d *ETTData) EventID() (id uint16, ok bool) {
+	if d.ETMID&0x3 != 0x2 {
+		return
+	}
+	return uint16(d.ETMID>>2) & 0x3fff, true
+}
+
+// newATSCETMID creates the ETM ID of an event
+func newATSCETMID(sourceID, eventID uint16) uint32 {
+	return uint32(sourceID)<<16 | uint32(eventID&0x3fff)<<2 | 0x2
+}
@@ -0,0 +1,68 @@
+package astits
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func ettBytes() []byte {
+	return []byte{
+		0x0,                   // Protocol version
+		0x10, 0x01, 0x0, 0x16, // ETM ID
+		1, 'e', 'n', 'g', 1, ATSCCompressionTypeNone, ATSCModeLatin1, 3, 'a', 'b', 'c', // Extended text message
+	}
+}
+
+// ettPSIBytes returns an ETT section, preceded by its pointer field
+func ettPSIBytes() []byte {
+	b := ettBytes()
+	s := append([]byte{0xcc, 0xf0, uint8(len(b) + 9), 0x0, 0x0, 0xc1, 0x0, 0x0}, b...)
+	c, _ := computeCRC32(s)
+	return append(append([]byte{0x0}, s...), byte(c>>24), byte(c>>16), byte(c>>8), byte(c))
+}
+
+func TestParseETTSection(t *testing.T) {
+	b := ettBytes()
+	d, err := parseETTSection(astikit.NewBytesIterator(b), len(b))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x10010016), d.ETMID)
+	assert.Equal(t, uint16(0x1001), d.SourceID())
+	id, ok := d.EventID()
+	assert.True(t, ok)
+	assert.Equal(t, uint16(5), id)
+	s, _, err := d.ExtendedTextMessage.Decode("eng", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", s)
+
+	// Channel ETM
+	_, ok = (&ETTData{ETMID: 0x10010000}).EventID()
+	assert.False(t, ok)
+}
+
+func TestParseDataATSCTablePIDs(t *testing.T) {
+	ps := []*Packet{{Header: &PacketHeader{PID: 0x1d00, PayloadUnitStartIndicator: true}, Payload: ettPSIBytes()}}
+
+	// PID has not been announced
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileATSC, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 0)
+
+	// PID has been announced
+	ds, err = parseData(ps, nil, NewProgramMap(), ProfileATSC, map[uint16]bool{0x1d00: true}, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, uint32(0x10010016), ds[0].ETT.ETMID)
+	assert.Equal(t, TableTypeETT, ds[0].Section.Header.Type)
+
+	// Demuxer
+	dmx := New(context.Background(), nil, OptProfile(ProfileATSC))
+	dmx.updateData([]*Data{{MGT: &MGTData{Tables: []*MGTTable{
+		{PID: PIDATSCPSIP, TableType: MGTTableTypeTVCTCurrent},
+		{PID: 0x1d00, TableType: MGTTableTypeEITFirst},
+		{PID: 0x1e00, TableType: MGTTableTypeEventETTFirst},
+	}}, PID: PIDATSCPSIP}})
+	assert.Equal(t, map[uint16]bool{0x1d00: true, 0x1e00: true}, dmx.tablePIDs)
+}
@@ -0,0 +1,108 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// MGT table types
+// Chapter: 6.2 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+const (
+	MGTTableTypeTVCTCurrent   = 0x0
+	MGTTableTypeTVCTNext      = 0x1
+	MGTTableTypeCVCTCurrent   = 0x2
+	MGTTableTypeCVCTNext      = 0x3
+	MGTTableTypeChannelETT    = 0x4
+	MGTTableTypeDCCSCT        = 0x5
+	MGTTableTypeEITFirst      = 0x100 // EIT-0
+	MGTTableTypeEITLast       = 0x17f // EIT-127
+	MGTTableTypeEventETTFirst = 0x200 // Event ETT-0
+	MGTTableTypeEventETTLast  = 0x27f // Event ETT-127
+	MGTTableTypeRRTFirst      = 0x301 // RRT with rating region 1
+	MGTTableTypeRRTLast       = 0x3ff // RRT with rating region 255
+	MGTTableTypeDCCTFirst     = 0x1400
+	MGTTableTypeDCCTLast      = 0x14ff
+)
+
+// MGTData represents an ATSC MGT data
+// It lists the PSIP tables of the transport stream, along with their PIDs and versions.
+// Chapter: 6.2 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type MGTData struct {
+	Descriptors     []*Descriptor
+	ProtocolVersion uint8
+	Tables          []*MGTTable
+}
+
+// MGTTable represents an ATSC MGT table
+type MGTTable struct {
+	Descriptors   []*Descriptor
+	NumberBytes   uint32 // Size of the table, in bytes
+	PID           uint16
+	TableType     uint16
+	VersionNumber uint8
+}
+
+// parseMGTSection parses an ATSC MGT section
+func parseMGTSection(i *astikit.BytesIterator) (d *MGTData, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(3); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create data
+	d = &MGTData{ProtocolVersion: bs[0]}
+
+	// Loop through tables
+	tablesDefined := int(bs[1])<<8 | int(bs[2])
+	for idx := 0; idx < tablesDefined; idx++ {
+		// Get next bytes
+		if bs, err = i.NextBytes(9); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create table
+		t := &MGTTable{
+			NumberBytes:   uint32(bs[5])<<24 | uint32(bs[6])<<16 | uint32(bs[7])<<8 | uint32(bs[8]),
+			PID:           uint16(bs[2]&0x1f)<<8 | uint16(bs[3]),
+			TableType:     uint16(bs[0])<<8 | uint16(bs[1]),
+			VersionNumber: bs[4] & 0x1f,
+		}
+
+		// Descriptors
+		if t.Descriptors, err = parseDescriptors(i); err != nil {
+			err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+			return
+		}
+
+		// Append table
+		d.Tables = append(d.Tables, t)
+	}
+
+	// Descriptors
+	if d.Descriptors, err = parseDescriptors(i); err != nil {
+		err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+		return
+	}
+	return
+}
+
+// hasOwnPID checks whether the table is carried on a PID of its own rather than on the PSIP base PID
+func (t *MGTTable) hasOwnPID() bool {
+	return t.TableType == MGTTableTypeChannelETT ||
+		(t.TableType >= MGTTableTypeEITFirst && t.TableType <= MGTTableTypeEITLast) ||
+		(t.TableType >= MGTTableTypeEventETTFirst && t.TableType <= MGTTableTypeEventETTLast)
+}
+
+// Table returns the first table of the provided type, or nil if there is none
+func (d *MGTData) Table(tableType uint16) *MGTTable {
+	for _, t := range d.Tables {
+		if t.TableType == tableType {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+var mgt = &MGTData{
+	Descriptors: descriptors,
+	Tables: []*MGTTable{
+		{
+			Descriptors:   descriptors,
+			NumberBytes:   0x12345,
+			PID:           PIDATSCPSIP,
+			TableType:     MGTTableTypeTVCTCurrent,
+			VersionNumber: 3,
+		},
+		{
+			NumberBytes:   1024,
+			PID:           0x1d00,
+			TableType:     MGTTableTypeEITFirst,
+			VersionNumber: 31,
+		},
+	},
+}
+
+func mgtBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint8(0))           // Protocol version
+	w.Write(uint16(2))          // Tables defined
+	w.Write(uint16(0))          // Table #1 type
+	w.Write("111")              // Table #1 reserved
+	w.Write("1111111111011")    // Table #1 PID
+	w.Write("111")              // Table #1 reserved
+	w.Write("00011")            // Table #1 version number
+	w.Write(uint32(0x12345))    // Table #1 number bytes
+	w.Write("1111")             // Table #1 reserved
+	descriptorsBytes(w)         // Table #1 descriptors
+	w.Write(uint16(0x100))      // Table #2 type
+	w.Write("111")              // Table #2 reserved
+	w.Write("1110100000000")    // Table #2 PID
+	w.Write("111")              // Table #2 reserved
+	w.Write("11111")            // Table #2 version number
+	w.Write(uint32(1024))       // Table #2 number bytes
+	w.Write("1111000000000000") // Table #2 descriptors length
+	w.Write("1111")             // Reserved
+	descriptorsBytes(w)         // Descriptors
+	return buf.Bytes()
+}
+
+func TestParseMGTSection(t *testing.T) {
+	d, err := parseMGTSection(astikit.NewBytesIterator(mgtBytes()))
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{MGT: d})
+	assert.Equal(t, mgt, d)
+	assert.Equal(t, d.Tables[1], d.Table(MGTTableTypeEITFirst))
+	assert.Nil(t, d.Table(MGTTableTypeCVCTCurrent))
+
+	// Truncated
+	_, err = parseMGTSection(astikit.NewBytesIterator(mgtBytes()[:10]))
+	assert.Error(t, err)
+}
+
+func TestParseDataMGT(t *testing.T) {
+	// Section
+	b := mgtBytes()
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint8(0))                      // Pointer field
+	w.Write(uint8(0xc7))                   // Table ID
+	w.Write(uint16(0xf000 | (len(b) + 9))) // Syntax indicator, private indicator, reserved and section length
+	w.Write(uint16(0))                     // Table ID extension
+	w.Write("11000011")                    // Reserved, version number and current/next indicator
+	w.Write(uint8(0))                      // Section number
+	w.Write(uint8(0))                      // Last section number
+	w.Write(b)
+	crc32, _ := computeCRC32(buf.Bytes()[1:])
+	w.Write(crc32)
+	ps := []*Packet{{Header: &PacketHeader{PID: PIDATSCPSIP, PayloadUnitStartIndicator: true}, Payload: buf.Bytes()}}
+
+	// ATSC
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileATSC, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	removeOriginalBytesFromData(ds[0])
+	assert.Equal(t, mgt, ds[0].MGT)
+	assert.Equal(t, uint16(PIDATSCPSIP), ds[0].PID)
+
+	// DVB
+	ds, err = parseData(ps, nil, NewProgramMap(), ProfileDVB, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 0)
+}
@@ -77,3 +77,43 @@ func parseNITSection(i *astikit.BytesIterator, tableIDExtension uint16) (d *NITD
 	}
 	return
 }
+
+func (d *NITData) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+
+	// Network descriptors
+	if err := w.write("network descriptors", func(b []byte) (int, error) {
+		return serialiseDescriptors(b, d.NetworkDescriptors)
+	}); err != nil {
+		return w.offset, err
+	}
+
+	// Transport stream loop
+	if err := w.writeLength("transport stream loop length", 12, 0xf000, func() error {
+		for i, ts := range d.TransportStreams {
+			if err := w.write(fmt.Sprintf("transport stream #%d", i), ts.Serialise); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
+func (ts *NITDataTransportStream) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint16("transport stream ID", ts.TransportStreamID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint16("original network ID", ts.OriginalNetworkID); err != nil {
+		return w.offset, err
+	}
+	if err := w.write("transport descriptors", func(b []byte) (int, error) {
+		return serialiseDescriptors(b, ts.TransportDescriptors)
+	}); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
@@ -39,3 +39,17 @@ func TestParseNITSection(t *testing.T) {
 	assert.Equal(t, d, nit)
 	assert.NoError(t, err)
 }
+
+func TestNITDataSerialise(t *testing.T) {
+	var b = nitBytes()
+	d, err := parseNITSection(astikit.NewBytesIterator(b), uint16(1))
+	assert.NoError(t, err)
+	o := make([]byte, 1024)
+	n, err := d.Serialise(o)
+	assert.NoError(t, err)
+	assert.Equal(t, len(b), n)
+	d, err = parseNITSection(astikit.NewBytesIterator(o[:n]), uint16(1))
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{NIT: d})
+	assert.Equal(t, nit, d)
+}
@@ -19,6 +19,57 @@ type PATProgram struct {
 	ProgramNumber uint16 // Relates to the Table ID extension in the associated PMT. A value of 0 is reserved for a NIT packet identifier.
 }
 
+// Max number of programs a PAT section can hold while fitting in a single packet: 4 bytes of packet header, 1 byte of
+// pointer field, 8 bytes of section headers and 4 bytes of CRC32 are left out
+const patSectionMaxPrograms = (MpegTsPacketSize - 4 - 1 - 8 - 4) / 4
+
+// NewPATSections creates the sections of a PAT, splitting its program loop over as many sections as needed so that
+// each section fits in a single packet
+// Section numbers are set accordingly, version numbers are left untouched.
+func NewPATSections(d *PATData) (ss []*PSISection, err error) {
+	// Too many programs
+	n := (len(d.Programs) + patSectionMaxPrograms - 1) / patSectionMaxPrograms
+	if n > 256 {
+		err = fmt.Errorf("astits: %d programs don't fit in 256 sections", len(d.Programs))
+		return
+	} else if n == 0 {
+		n = 1
+	}
+
+	// Loop through sections
+	for idx := 0; idx < n; idx++ {
+		// Get programs
+		start := idx * patSectionMaxPrograms
+		end := start + patSectionMaxPrograms
+		if end > len(d.Programs) {
+			end = len(d.Programs)
+		}
+
+		// Append section
+		ss = append(ss, &PSISection{
+			Header: &PSISectionHeader{
+				SectionSyntaxIndicator: true,
+				TableID:                0,
+				TableType:              PSITableTypePAT,
+				Type:                   TableTypePAT,
+			},
+			Syntax: &PSISectionSyntax{
+				Data: &PSISectionSyntaxData{PAT: &PATData{
+					Programs:          d.Programs[start:end],
+					TransportStreamID: d.TransportStreamID,
+				}},
+				Header: &PSISectionSyntaxHeader{
+					CurrentNextIndicator: true,
+					LastSectionNumber:    uint8(n - 1),
+					SectionNumber:        uint8(idx),
+					TableIDExtension:     d.TransportStreamID,
+				},
+			},
+		})
+	}
+	return
+}
+
 // parsePATSection parses a PAT section
 func parsePATSection(i *astikit.BytesIterator, offsetSectionsEnd int, tableIDExtension uint16) (d *PATData, err error) {
 	// Create data
@@ -43,28 +94,35 @@ func parsePATSection(i *astikit.BytesIterator, offsetSectionsEnd int, tableIDExt
 }
 
 func (p *PATData) Serialise(b []byte) (int, error) {
-	currentIdx := 0
+	return serialiseWithSentinel(b, p.serialise)
+}
+
+func (p *PATData) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
 	for i := range p.Programs {
-		n, err := p.Programs[i].Serialise(b[currentIdx:])
-		if err != nil {
-			return currentIdx, err
+		if err := w.write(fmt.Sprintf("program #%d", i), p.Programs[i].serialise); err != nil {
+			return w.offset, err
 		}
-		currentIdx += n
 	}
 
-	return currentIdx, nil
+	return w.offset, nil
 }
 
 func (p *PATProgram) Serialise(b []byte) (int, error) {
-	if len(b) < 4 {
-		return 0, ErrNoRoomInBuffer
+	return serialiseWithSentinel(b, p.serialise)
+}
+
+func (p *PATProgram) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint16("program number", p.ProgramNumber); err != nil {
+		return w.offset, err
 	}
-	b[0], b[1] = U16toU8s(p.ProgramNumber)
 	// if p.ProgramNumber == 0 {
 	// 	//TODO figure out Network PID
 	// 	return 2, errors.New("Network PID not implemented")
 	// }
-	b[2] = uint8(0x1f&(p.ProgramMapID>>8)) | 7<<5
-	b[3] = uint8(0xff & p.ProgramMapID)
-	return 4, nil
+	if err := w.writeUint16("program map PID", 7<<13|0x1fff&p.ProgramMapID); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
 }
@@ -34,3 +34,37 @@ func TestParsePATSection(t *testing.T) {
 	assert.Equal(t, d, pat)
 	assert.NoError(t, err)
 }
+
+func TestNewPATSections(t *testing.T) {
+	// No programs
+	ss, err := NewPATSections(&PATData{TransportStreamID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+
+	// Programs span several sections
+	d := &PATData{TransportStreamID: 1}
+	for i := 0; i < 100; i++ {
+		d.Programs = append(d.Programs, &PATProgram{ProgramMapID: uint16(256 + i), ProgramNumber: uint16(i + 1)})
+	}
+	ss, err = NewPATSections(d)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 3)
+	var ps []*PATProgram
+	for idx, s := range ss {
+		// Each section fits in a single packet
+		b := make([]byte, MpegTsPacketSize-4)
+		_, err = (&PSIData{Sections: []*PSISection{s}}).Serialise(b)
+		assert.NoError(t, err)
+		v, err := parsePSIData(astikit.NewBytesIterator(b), ProfileAuto)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(idx), v.Sections[0].Syntax.Header.SectionNumber)
+		assert.Equal(t, uint8(2), v.Sections[0].Syntax.Header.LastSectionNumber)
+		ps = append(ps, v.Sections[0].Syntax.Data.PAT.Programs...)
+	}
+	assert.Equal(t, d.Programs, ps)
+
+	// Too many programs
+	d.Programs = make([]*PATProgram, 256*patSectionMaxPrograms+1)
+	_, err = NewPATSections(d)
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package astits
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/asticode/go-astikit"
@@ -41,8 +42,12 @@ const (
 // http://dvd.sourceforge.net/dvdinfo/pes-hdr.html
 // http://happy.emu.id.au/lab/tut/dttb/dtbtut4b.htm
 type PESData struct {
-	Data   []byte
-	Header *PESHeader
+	Data                 []byte
+	HasOptionalHeader    bool
+	Header               *PESHeader
+	LengthMismatch       int    // Number of bytes received minus the number of bytes announced by a non zero packet length: negative when the PES is truncated, positive when it is overlong
+	OptionalHeaderLength int    // Length of the optional header, flags and stuffing bytes included
+	RawOptionalHeader    []byte // Raw bytes of the optional header, so that fields not modeled yet can be passed through
 }
 
 // PESHeader represents a packet PES header
@@ -117,17 +122,50 @@ func parsePESData(i *astikit.BytesIterator) (d *PESData, err error) {
 		return
 	}
 
+	// Optional header
+	if d.HasOptionalHeader = d.Header.OptionalHeader != nil; d.HasOptionalHeader {
+		// Seek to optional header
+		i.Seek(6)
+
+		// Raw bytes
+		d.OptionalHeaderLength = dataStart - 6
+		if d.RawOptionalHeader, err = i.NextBytes(d.OptionalHeaderLength); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+
+	// Check length
+	// Data that arrived is kept when the PES is truncated and bytes beyond the announced length are ignored
+	if d.Header.PacketLength > 0 {
+		if d.LengthMismatch = i.Len() - dataEnd; d.LengthMismatch < 0 {
+			dataEnd = i.Len()
+		}
+	}
+
 	// Seek to data
 	i.Seek(dataStart)
 
 	// Extract data
-	if d.Data, err = i.NextBytes(dataEnd - dataStart); err != nil {
-		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
-		return
+	if dataEnd > dataStart {
+		if d.Data, err = i.NextBytes(dataEnd - dataStart); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
 	}
 	return
 }
 
+// IsTruncated checks whether fewer bytes than announced by the packet length have been received
+func (d *PESData) IsTruncated() bool {
+	return d.LengthMismatch < 0
+}
+
+// IsOverlong checks whether more bytes than announced by the packet length have been received
+func (d *PESData) IsOverlong() bool {
+	return d.LengthMismatch > 0
+}
+
 // hasPESOptionalHeader checks whether the data has a PES optional header
 func hasPESOptionalHeader(streamID uint8) bool {
 	return streamID != StreamIDPaddingStream && streamID != StreamIDPrivateStream2
@@ -296,7 +334,7 @@ func parsePESOptionalHeader(i *astikit.BytesIterator) (h *PESOptionalHeader, dat
 			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
 			return
 		}
-		h.CRC = uint16(bs[0])>>8 | uint16(bs[1])
+		h.CRC = uint16(bs[0])<<8 | uint16(bs[1])
 	}
 
 	// Extension
@@ -412,3 +450,220 @@ func parseESCR(i *astikit.BytesIterator) (cr *ClockReference, err error) {
 	cr = newClockReference(int64(escr>>9), int64(escr&0x1ff))
 	return
 }
+
+// serialiseDSMTrickMode serialises a DSM trick mode
+// Reserved bits are set to 1
+func serialiseDSMTrickMode(m *DSMTrickMode) (b byte) {
+	b = m.TrickModeControl << 5
+	if m.TrickModeControl == TrickModeControlFastForward || m.TrickModeControl == TrickModeControlFastReverse {
+		b |= (m.FieldID&0x3)<<3 | (m.IntraSliceRefresh&0x1)<<2 | m.FrequencyTruncation&0x3
+	} else if m.TrickModeControl == TrickModeControlFreezeFrame {
+		b |= (m.FieldID&0x3)<<3 | 0x7
+	} else if m.TrickModeControl == TrickModeControlSlowMotion || m.TrickModeControl == TrickModeControlSlowReverse {
+		b |= m.RepeatControl & 0x1f
+	} else {
+		b |= 0x1f
+	}
+	return
+}
+
+// Serialise serialises the PES data, packet start code prefix included
+// When the optional header is not set, its raw bytes are written instead, if any
+// A packet length of 0 is kept as is since it signals an unbounded video PES, otherwise it is computed
+func (d *PESData) Serialise(b []byte) (int, error) {
+	if len(b) < 6 {
+		return 0, ErrNoRoomInBuffer
+	}
+	b[0], b[1], b[2] = 0x0, 0x0, 0x1
+	b[3] = d.Header.StreamID
+	idx := 6 // Skip packet length we put in afterward
+
+	if hasPESOptionalHeader(d.Header.StreamID) {
+		if d.Header.OptionalHeader != nil {
+			n, err := d.Header.OptionalHeader.Serialise(b[idx:])
+			if err != nil {
+				return idx, err
+			}
+			idx += n
+		} else if d.HasOptionalHeader && len(d.RawOptionalHeader) > 0 {
+			if len(b)-idx < len(d.RawOptionalHeader) {
+				return idx, ErrNoRoomInBuffer
+			}
+			idx += copy(b[idx:], d.RawOptionalHeader)
+		}
+	}
+
+	if len(b)-idx < len(d.Data) {
+		return idx, ErrNoRoomInBuffer
+	}
+	idx += copy(b[idx:], d.Data)
+
+	var packetLength uint16
+	if d.Header.PacketLength > 0 {
+		if idx-6 > 0xffff {
+			return idx, errors.New("astits: PES packet length doesn't fit on 16 bits")
+		}
+		packetLength = uint16(idx - 6)
+	}
+	b[4], b[5] = U16toU8s(packetLength)
+	return idx, nil
+}
+
+// Serialise serialises the PES optional header
+// If the header length is bigger than what the fields need, the remaining bytes are written as stuffing bytes
+func (h *PESOptionalHeader) Serialise(b []byte) (int, error) {
+	// Compute header length
+	var ptsDTSIndicator uint8 = PTSDTSIndicatorNoPTSOrDTS
+	headerLength := 0
+	if h.PTS != nil && h.DTS != nil {
+		ptsDTSIndicator = PTSDTSIndicatorBothPresent
+		headerLength += 10
+	} else if h.PTS != nil {
+		ptsDTSIndicator = PTSDTSIndicatorOnlyPTS
+		headerLength += 5
+	}
+	hasESCR := h.HasESCR && h.ESCR != nil
+	if hasESCR {
+		headerLength += 6
+	}
+	if h.HasESRate {
+		headerLength += 3
+	}
+	hasDSMTrickMode := h.HasDSMTrickMode && h.DSMTrickMode != nil
+	if hasDSMTrickMode {
+		headerLength++
+	}
+	if h.HasAdditionalCopyInfo {
+		headerLength++
+	}
+	if h.HasCRC {
+		headerLength += 2
+	}
+	if h.HasExtension {
+		headerLength++
+		if h.HasPrivateData {
+			headerLength += 16
+		}
+		if h.HasPackHeaderField {
+			headerLength++
+		}
+		if h.HasProgramPacketSequenceCounter {
+			headerLength += 2
+		}
+		if h.HasPSTDBuffer {
+			headerLength += 2
+		}
+		if h.HasExtension2 {
+			headerLength += 2 + len(h.Extension2Data)
+		}
+	}
+	stuffingLength := 0
+	if int(h.HeaderLength) > headerLength {
+		stuffingLength = int(h.HeaderLength) - headerLength
+	}
+	if headerLength+stuffingLength > 0xff {
+		return 0, errors.New("astits: PES header length doesn't fit on 8 bits")
+	}
+	if len(b) < 3+headerLength+stuffingLength {
+		return 0, ErrNoRoomInBuffer
+	}
+
+	// Flags
+	markerBits := h.MarkerBits
+	if markerBits == 0 {
+		markerBits = 0x2
+	}
+	b[0] = markerBits<<6 | (h.ScramblingControl&0x3)<<4 | Btou8(h.Priority)<<3 | Btou8(h.DataAlignmentIndicator)<<2 | Btou8(h.IsCopyrighted)<<1 | Btou8(h.IsOriginal)
+	b[1] = ptsDTSIndicator<<6 | Btou8(hasESCR)<<5 | Btou8(h.HasESRate)<<4 | Btou8(hasDSMTrickMode)<<3 | Btou8(h.HasAdditionalCopyInfo)<<2 | Btou8(h.HasCRC)<<1 | Btou8(h.HasExtension)
+	b[2] = uint8(headerLength + stuffingLength)
+	idx := 3
+
+	// PTS/DTS
+	if ptsDTSIndicator == PTSDTSIndicatorBothPresent {
+		writePTSOrDTS(b[idx:], PTSOrDTSPrefixPTSWithDTS, h.PTS)
+		writePTSOrDTS(b[idx+PTSOrDTSLength:], PTSOrDTSPrefixDTS, h.DTS)
+		idx += 2 * PTSOrDTSLength
+	} else if ptsDTSIndicator == PTSDTSIndicatorOnlyPTS {
+		writePTSOrDTS(b[idx:], PTSOrDTSPrefixPTSOnly, h.PTS)
+		idx += PTSOrDTSLength
+	}
+
+	// ESCR
+	if hasESCR {
+		writeESCR(b[idx:], h.ESCR)
+		idx += ESCRLength
+	}
+
+	// ES rate
+	if h.HasESRate {
+		b[idx] = 0x80 | uint8(h.ESRate>>15&0x7f)
+		b[idx+1] = uint8(h.ESRate >> 7)
+		b[idx+2] = uint8(h.ESRate<<1&0xfe) | 0x1
+		idx += 3
+	}
+
+	// Trick mode
+	if hasDSMTrickMode {
+		b[idx] = serialiseDSMTrickMode(h.DSMTrickMode)
+		idx++
+	}
+
+	// Additional copy info
+	if h.HasAdditionalCopyInfo {
+		b[idx] = 0x80 | h.AdditionalCopyInfo&0x7f
+		idx++
+	}
+
+	// CRC
+	if h.HasCRC {
+		b[idx], b[idx+1] = U16toU8s(h.CRC)
+		idx += 2
+	}
+
+	// Extension
+	if h.HasExtension {
+		b[idx] = Btou8(h.HasPrivateData)<<7 | Btou8(h.HasPackHeaderField)<<6 | Btou8(h.HasProgramPacketSequenceCounter)<<5 | Btou8(h.HasPSTDBuffer)<<4 | 0xe | Btou8(h.HasExtension2)
+		idx++
+
+		// Private data
+		if h.HasPrivateData {
+			copy(b[idx:idx+16], h.PrivateData)
+			idx += 16
+		}
+
+		// Pack field length
+		if h.HasPackHeaderField {
+			b[idx] = h.PackField
+			idx++
+		}
+
+		// Program packet sequence counter
+		if h.HasProgramPacketSequenceCounter {
+			b[idx] = 0x80 | h.PacketSequenceCounter&0x7f
+			b[idx+1] = 0x80 | (h.MPEG1OrMPEG2ID&0x1)<<6 | h.OriginalStuffingLength&0x3f
+			idx += 2
+		}
+
+		// P-STD buffer
+		if h.HasPSTDBuffer {
+			b[idx] = 0x40 | (h.PSTDBufferScale&0x1)<<5 | uint8(h.PSTDBufferSize>>8&0x1f)
+			b[idx+1] = uint8(h.PSTDBufferSize)
+			idx += 2
+		}
+
+		// Extension 2
+		if h.HasExtension2 {
+			b[idx] = 0x80 | uint8(len(h.Extension2Data)&0x7f)
+			b[idx+1] = 0x0
+			idx += 2
+			idx += copy(b[idx:], h.Extension2Data)
+		}
+	}
+
+	// Stuffing bytes
+	for i := 0; i < stuffingLength; i++ {
+		b[idx] = 0xff
+		idx++
+	}
+	return idx, nil
+}
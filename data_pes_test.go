@@ -123,6 +123,7 @@ var pesWithoutHeader = &PESData{
 		PacketLength: 4,
 		StreamID:     StreamIDPaddingStream,
 	},
+	LengthMismatch: 5, // "stuff"
 }
 
 func pesWithoutHeaderBytes() []byte {
@@ -136,7 +137,11 @@ func pesWithoutHeaderBytes() []byte {
 }
 
 var pesWithHeader = &PESData{
-	Data: []byte("data"),
+	Data:                 []byte("data"),
+	HasOptionalHeader:    true,
+	LengthMismatch:       5, // "stuff"
+	OptionalHeaderLength: 65,
+	RawOptionalHeader:    pesWithHeaderBytes()[6:71],
 	Header: &PESHeader{
 		OptionalHeader: &PESOptionalHeader{
 			AdditionalCopyInfo:              127,
@@ -234,4 +239,94 @@ func TestParsePESData(t *testing.T) {
 	d, err = parsePESData(astikit.NewBytesIterator(pesWithHeaderBytes()))
 	assert.NoError(t, err)
 	assert.Equal(t, pesWithHeader, d)
+	assert.True(t, d.IsOverlong())
+
+	// Truncated
+	d, err = parsePESData(astikit.NewBytesIterator(pesWithoutHeaderBytes()[:8]))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("da"), d.Data)
+	assert.Equal(t, -2, d.LengthMismatch)
+	assert.True(t, d.IsTruncated())
+	assert.False(t, d.IsOverlong())
+
+	// Exact
+	d, err = parsePESData(astikit.NewBytesIterator(pesWithoutHeaderBytes()[:10]))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, d.LengthMismatch)
+}
+
+func TestSerialiseDSMTrickMode(t *testing.T) {
+	for _, m := range []*DSMTrickMode{
+		{FieldID: 2, FrequencyTruncation: 3, IntraSliceRefresh: 1, TrickModeControl: TrickModeControlFastForward},
+		{FieldID: 1, FrequencyTruncation: 2, TrickModeControl: TrickModeControlFastReverse},
+		{FieldID: 3, TrickModeControl: TrickModeControlFreezeFrame},
+		{RepeatControl: 21, TrickModeControl: TrickModeControlSlowMotion},
+		{RepeatControl: 7, TrickModeControl: TrickModeControlSlowReverse},
+	} {
+		assert.Equal(t, m, parseDSMTrickMode(serialiseDSMTrickMode(m)))
+	}
+	assert.Equal(t, dsmTrickModeSlowBytes()[0], serialiseDSMTrickMode(dsmTrickModeSlow))
+}
+
+func TestPESDataSerialise(t *testing.T) {
+	// No optional header
+	b := make([]byte, 1024)
+	n, err := pesWithoutHeader.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, pesWithoutHeaderBytes()[:n], b[:n])
+
+	// Optional header
+	n, err = pesWithHeader.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 75, n)
+	d, err := parsePESData(astikit.NewBytesIterator(b[:n]))
+	assert.NoError(t, err)
+	assert.Equal(t, pesWithHeader.Data, d.Data)
+	assert.Equal(t, pesWithHeader.Header, d.Header)
+	assert.Equal(t, b[6:71], d.RawOptionalHeader)
+
+	// Raw optional header
+	n, err = (&PESData{
+		Data:              pesWithHeader.Data,
+		HasOptionalHeader: true,
+		Header:            &PESHeader{PacketLength: 1, StreamID: 1},
+		RawOptionalHeader: pesWithHeader.RawOptionalHeader,
+	}).Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, pesWithHeaderBytes()[:75], b[:n])
+
+	// Trick mode and additional copy info
+	for _, m := range []*DSMTrickMode{
+		{FieldID: 2, FrequencyTruncation: 1, IntraSliceRefresh: 1, TrickModeControl: TrickModeControlFastForward},
+		{FieldID: 1, TrickModeControl: TrickModeControlFreezeFrame},
+		{RepeatControl: 3, TrickModeControl: TrickModeControlSlowReverse},
+	} {
+		pes := &PESData{
+			Data: []byte("data"),
+			Header: &PESHeader{
+				OptionalHeader: &PESOptionalHeader{
+					AdditionalCopyInfo:    42,
+					DSMTrickMode:          m,
+					HasAdditionalCopyInfo: true,
+					HasDSMTrickMode:       true,
+					HeaderLength:          2,
+					MarkerBits:            2,
+				},
+				PacketLength: 9,
+				StreamID:     StreamIDPrivateStream1,
+			},
+		}
+		n, err = pes.Serialise(b)
+		assert.NoError(t, err)
+		d, err = parsePESData(astikit.NewBytesIterator(b[:n]))
+		assert.NoError(t, err)
+		assert.Equal(t, pes.Data, d.Data)
+		assert.Equal(t, pes.Header, d.Header)
+		assert.True(t, d.HasOptionalHeader)
+		assert.Equal(t, 5, d.OptionalHeaderLength)
+	}
+
+	// No room in buffer
+	_, err = pesWithHeader.Serialise(make([]byte, 10))
+	assert.Equal(t, ErrNoRoomInBuffer, err)
 }
@@ -58,7 +58,7 @@ const (
 	// 0x88 - 0x8F privately defined
 	StreamTypeBluRayPresentationGraphicStream = 0x90 // Blu-ray Presentation Graphic Stream (subtitling)
 	StreamTypeATSCDSMCCNetworkResourcesTable  = 0x91 // ATSC DSM CC Network Resources table
-	// 0x92 - 0xBF privately defined
+	// 0x92 - 0xBF privately defined, see Blu-ray stream types below
 	StreamTypeDigiCipher2text                                                          = 0xC0 // DigiCipher II text
 	StreamTypeDolbyDigitalAC3Max6ChannelAudioWithAES128CBC                             = 0xC1 // Dolby Digital (AC-3) up to six channel audio with AES-128-CBC data encryption
 	StreamTypeATSEDSMCCSynchronousDataOrDolbyDigitalPlusMax16ChannelAudioWithAES128CBC = 0xC2 // ATSC DSM CC synchronous data or Dolby Digital Plus up to 16 channel audio with AES-128-CBC data encryption
@@ -74,6 +74,28 @@ const (
 
 )
 
+// Blu-ray stream types, which are only meaningful when the HDMV registration is present
+// 0x81, 0x83, 0x84, 0x85 and 0x90 are shared with the stream types above
+const (
+	StreamTypeBluRayLPCMAudio                      = 0x80 // Blu-ray LPCM audio
+	StreamTypeBluRayDTSAudio                       = 0x82 // Blu-ray DTS audio
+	StreamTypeBluRayDTSHDMasterAudio               = 0x86 // Blu-ray DTS-HD Master Audio
+	StreamTypeBluRayInteractiveGraphicStream       = 0x91 // Blu-ray Interactive Graphic Stream (menus)
+	StreamTypeBluRayTextSubtitle                   = 0x92 // Blu-ray text subtitles
+	StreamTypeBluRayDolbyDigitalPlusSecondaryAudio = 0xA1 // Blu-ray Dolby Digital Plus secondary audio
+	StreamTypeBluRayDTSHDSecondaryAudio            = 0xA2 // Blu-ray DTS-HD secondary audio
+	StreamTypeBluRayVC1Video                       = 0xEA // Blu-ray VC-1 video
+)
+
+// Elementary stream kinds
+const (
+	StreamKindAudio    = "audio"
+	StreamKindGraphics = "graphics"
+	StreamKindSubtitle = "subtitle"
+	StreamKindUnknown  = "unknown"
+	StreamKindVideo    = "video"
+)
+
 // PMTData represents a PMT data
 // https://en.wikipedia.org/wiki/Program-specific_information
 type PMTData struct {
@@ -90,6 +112,149 @@ type PMTElementaryStream struct {
 	StreamType                  uint8         // This defines the structure of the data contained within the elementary packet identifier.
 }
 
+// FindDescriptor returns the first elementary stream descriptor with the provided tag, or nil
+func (es *PMTElementaryStream) FindDescriptor(tag uint8) *Descriptor {
+	for _, d := range es.ElementaryStreamDescriptors {
+		if d.Tag == tag {
+			return d
+		}
+	}
+	return nil
+}
+
+// Language returns the language of the elementary stream, or an empty string
+// The ISO 639 language descriptor prevails, then come the first items of the subtitling and teletext descriptors
+func (es *PMTElementaryStream) Language() string {
+	if d := es.FindDescriptor(DescriptorTagISO639LanguageAndAudioType); d != nil && d.ISO639LanguageAndAudioType != nil {
+		return string(d.ISO639LanguageAndAudioType.Language)
+	}
+	if d := es.FindDescriptor(DescriptorTagSubtitling); d != nil && d.Subtitling != nil && len(d.Subtitling.Items) > 0 {
+		return string(d.Subtitling.Items[0].Language)
+	}
+	if d := es.FindDescriptor(DescriptorTagTeletext); d != nil && d.Teletext != nil && len(d.Teletext.Items) > 0 {
+		return string(d.Teletext.Items[0].Language)
+	}
+	return ""
+}
+
+// Registration returns the registration descriptor of the elementary stream, or nil
+func (es *PMTElementaryStream) Registration() *DescriptorRegistration {
+	if d := es.FindDescriptor(DescriptorTagRegistration); d != nil {
+		return d.Registration
+	}
+	return nil
+}
+
+// Hierarchy returns the hierarchy descriptor of the elementary stream, or nil
+func (es *PMTElementaryStream) Hierarchy() *DescriptorHierarchy {
+	if d := es.FindDescriptor(DescriptorTagHierarchy); d != nil {
+		return d.Hierarchy
+	}
+	return nil
+}
+
+// EmbeddedElementaryStream returns the elementary stream that needs to be decoded before the provided one, as
+// described by their hierarchy descriptors, or nil
+func (p *PMTData) EmbeddedElementaryStream(es *PMTElementaryStream) *PMTElementaryStream {
+	// Get hierarchy
+	h := es.Hierarchy()
+	if h == nil || h.Type == HierarchyTypeBaseLayer || h.EmbeddedLayerIndex == h.LayerIndex {
+		return nil
+	}
+
+	// Look for the elementary stream with the embedded layer index
+	for _, e := range p.ElementaryStreams {
+		if eh := e.Hierarchy(); eh != nil && eh.LayerIndex == h.EmbeddedLayerIndex {
+			return e
+		}
+	}
+	return nil
+}
+
+// Kind classifies the elementary stream
+// When bluRay is true, which is the case when the HDMV registration is present (see DescribeTransportStream), Blu-ray
+// private stream types prevail.
+func (es *PMTElementaryStream) Kind(bluRay bool) string {
+	// Blu-ray
+	if bluRay {
+		switch es.StreamType {
+		case StreamTypeBluRayLPCMAudio,
+			StreamTypeBluRayAndATSCDolbyDigitalAC3Max6ChannelAudio,
+			StreamTypeBluRayDTSAudio,
+			StreamTypeBlueRayDolbyTrueHDAudio,
+			StreamTypeBluRayDoblyDigitalPlusAC3Max16ChannelAudio,
+			StreamTypeBluRayDTS8ChannelAudio,
+			StreamTypeBluRayDTSHDMasterAudio,
+			StreamTypeBluRayDolbyDigitalPlusSecondaryAudio,
+			StreamTypeBluRayDTSHDSecondaryAudio:
+			return StreamKindAudio
+		case StreamTypeBluRayPresentationGraphicStream,
+			StreamTypeBluRayTextSubtitle:
+			return StreamKindSubtitle
+		case StreamTypeBluRayInteractiveGraphicStream:
+			return StreamKindGraphics
+		case StreamTypeBluRayVC1Video:
+			return StreamKindVideo
+		}
+	}
+
+	// Generic
+	if isVideoElementaryStream(es) {
+		return StreamKindVideo
+	} else if isAudioElementaryStream(es) {
+		return StreamKindAudio
+	} else if es.FindDescriptor(DescriptorTagSubtitling) != nil || es.FindDescriptor(DescriptorTagTeletext) != nil {
+		return StreamKindSubtitle
+	}
+	return StreamKindUnknown
+}
+
+// isVideoElementaryStream checks whether the elementary stream carries video
+func isVideoElementaryStream(es *PMTElementaryStream) bool {
+	switch es.StreamType {
+	case StreamTypeMPEG1Video,
+		StreamTypeMPEG2HighRateInterlacedVideo,
+		StreamTypeMPEG4H263Video,
+		StreamTypeH264Video,
+		StreamTypeSVCMPEG4AVCSubBitstream,
+		StreamTypeMVCMPEG4AVCSubBitstream,
+		StreamTypeJPEG2000Video,
+		StreamTypeH265Video,
+		StreamTypeChineseVideoStandard,
+		StreamTypeBBCDiracVideo,
+		StreamTypeMicrosoftWindowsMediaVideo9:
+		return true
+	}
+	return false
+}
+
+// isAudioElementaryStream checks whether the elementary stream carries audio
+// Private streams are considered as audio when they are described by an audio descriptor
+func isAudioElementaryStream(es *PMTElementaryStream) bool {
+	switch es.StreamType {
+	case StreamTypeMPEG1Audio,
+		StreamTypeMPEG2HalvedSampleRateAudio,
+		StreamTypeAudioADTS,
+		StreamTypeMPEG4LOASMultiFormatFramedAudio,
+		StreamTypeMPEG4RawAudio,
+		StreamTypeBluRayAndATSCDolbyDigitalAC3Max6ChannelAudio,
+		StreamTypeBlueRayDolbyTrueHDAudio,
+		StreamTypeBluRayDoblyDigitalPlusAC3Max16ChannelAudio,
+		StreamTypeBluRayDTS8ChannelAudio,
+		StreamTypeATSCDoblyDigitalPlusAC3Max16ChannelAudio,
+		StreamTypeDolbyDigitalAC3Max6ChannelAudioWithAES128CBC,
+		StreamTypeADTSAACWithAES128CBC:
+		return true
+	case StreamTypeMPEG2PacketizedData:
+		for _, d := range es.ElementaryStreamDescriptors {
+			if d.AC3 != nil || d.EnhancedAC3 != nil || (d.Extension != nil && d.Extension.SupplementaryAudio != nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // parsePMTSection parses a PMT section
 func parsePMTSection(i *astikit.BytesIterator, offsetSectionsEnd int, tableIDExtension uint16) (d *PMTData, err error) {
 	// Create data
@@ -148,51 +313,53 @@ func parsePMTSection(i *astikit.BytesIterator, offsetSectionsEnd int, tableIDExt
 }
 
 func (p *PMTData) Serialise(b []byte) (int, error) {
-	if len(b) < 4 {
-		return 0, ErrNoRoomInBuffer
-	}
-	b[0] = 0x7<<5 | uint8(0x1f&(p.PCRPID>>8))
-	b[1] = uint8(0xff & p.PCRPID)
-	program_info_length := 0
-	idx := 4
-	for i := range p.ProgramDescriptors {
-		n, err := p.ProgramDescriptors[i].Serialise(b[idx:])
-		if err != nil {
-			return idx, err
+	return serialiseWithSentinel(b, p.serialise)
+}
+
+func (p *PMTData) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint16("PCR PID", 7<<13|0x1fff&p.PCRPID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeLength("program info length", 10, 0xf000, func() error {
+		for i := range p.ProgramDescriptors {
+			if err := w.write(fmt.Sprintf("program descriptor #%d", i), p.ProgramDescriptors[i].serialise); err != nil {
+				return err
+			}
 		}
-		idx += n
-		program_info_length += n
+		return nil
+	}); err != nil {
+		return w.offset, err
 	}
 	for i := range p.ElementaryStreams {
-		n, err := p.ElementaryStreams[i].Serialise(b[idx:])
-		if err != nil {
-			return idx, err
+		if err := w.write(fmt.Sprintf("elementary stream #%d", i), p.ElementaryStreams[i].serialise); err != nil {
+			return w.offset, err
 		}
-		idx += n
 	}
-	b[2] = 0xf0 | uint8(0x3&(uint8(program_info_length)>>8))
-	b[3] = uint8(program_info_length)
-	return idx, nil
+	return w.offset, nil
 }
 
 func (pes *PMTElementaryStream) Serialise(b []byte) (int, error) {
-	if len(b) < 5 {
-		return 0, ErrNoRoomInBuffer
-	}
-	b[0] = pes.StreamType
-	b[1] = 0x7<<5 | uint8(0x1f&(pes.ElementaryPID>>8))
-	b[2] = uint8(0xff & pes.ElementaryPID)
-	es_info_length := 0
-	idx := 5
-	for i := range pes.ElementaryStreamDescriptors {
-		n, err := pes.ElementaryStreamDescriptors[i].Serialise(b[idx:])
-		if err != nil {
-			return idx, err
+	return serialiseWithSentinel(b, pes.serialise)
+}
+
+func (pes *PMTElementaryStream) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint8("stream type", pes.StreamType); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint16("elementary PID", 7<<13|0x1fff&pes.ElementaryPID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeLength("ES info length", 10, 0xf000, func() error {
+		for i := range pes.ElementaryStreamDescriptors {
+			if err := w.write(fmt.Sprintf("elementary stream descriptor #%d", i), pes.ElementaryStreamDescriptors[i].serialise); err != nil {
+				return err
+			}
 		}
-		idx += n
-		es_info_length += n
+		return nil
+	}); err != nil {
+		return w.offset, err
 	}
-	b[3] = 0xf0 | (uint8(0x3 & (es_info_length >> 8)))
-	b[4] = uint8(es_info_length)
-	return idx, nil
+	return w.offset, nil
 }
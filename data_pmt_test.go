@@ -41,3 +41,71 @@ func TestParsePMTSection(t *testing.T) {
 	assert.Equal(t, d, pmt)
 	assert.NoError(t, err)
 }
+
+func TestPMTElementaryStreamAccessors(t *testing.T) {
+	es := &PMTElementaryStream{}
+	assert.Nil(t, es.FindDescriptor(DescriptorTagRegistration))
+	assert.Equal(t, "", es.Language())
+	assert.Nil(t, es.Registration())
+
+	// Subtitling
+	es.ElementaryStreamDescriptors = []*Descriptor{{
+		Subtitling: &DescriptorSubtitling{Items: []*DescriptorSubtitlingItem{{Language: []byte("fra")}}},
+		Tag:        DescriptorTagSubtitling,
+	}}
+	assert.Equal(t, "fra", es.Language())
+
+	// ISO 639 and registration
+	r := &DescriptorRegistration{FormatIdentifier: 0x41432d33}
+	es.ElementaryStreamDescriptors = append(es.ElementaryStreamDescriptors,
+		&Descriptor{Registration: r, Tag: DescriptorTagRegistration},
+		&Descriptor{ISO639LanguageAndAudioType: &DescriptorISO639LanguageAndAudioType{Language: []byte("eng")}, Tag: DescriptorTagISO639LanguageAndAudioType},
+	)
+	assert.Equal(t, es.ElementaryStreamDescriptors[1], es.FindDescriptor(DescriptorTagRegistration))
+	assert.Equal(t, "eng", es.Language())
+	assert.Equal(t, r, es.Registration())
+}
+
+func TestPMTDataEmbeddedElementaryStream(t *testing.T) {
+	es := func(pid uint16, t, layer, embedded uint8) *PMTElementaryStream {
+		return &PMTElementaryStream{
+			ElementaryPID: pid,
+			ElementaryStreamDescriptors: []*Descriptor{{
+				Hierarchy: &DescriptorHierarchy{EmbeddedLayerIndex: embedded, LayerIndex: layer, Type: t},
+				Tag:       DescriptorTagHierarchy,
+			}},
+		}
+	}
+	d := &PMTData{ElementaryStreams: []*PMTElementaryStream{
+		es(0x100, HierarchyTypeBaseLayer, 0, 0x3f),
+		es(0x101, HierarchyTypeSpatialScalability, 1, 0),
+		es(0x102, HierarchyTypeTemporalScalability, 2, 1),
+		{ElementaryPID: 0x103},
+	}}
+	assert.Nil(t, d.EmbeddedElementaryStream(d.ElementaryStreams[0]))
+	assert.Equal(t, d.ElementaryStreams[0], d.EmbeddedElementaryStream(d.ElementaryStreams[1]))
+	assert.Equal(t, d.ElementaryStreams[1], d.EmbeddedElementaryStream(d.ElementaryStreams[2]))
+	assert.Nil(t, d.EmbeddedElementaryStream(d.ElementaryStreams[3]))
+	assert.Nil(t, d.ElementaryStreams[3].Hierarchy())
+}
+
+func TestPMTElementaryStreamKind(t *testing.T) {
+	for _, v := range []struct {
+		bluRay   bool
+		es       *PMTElementaryStream
+		expected string
+	}{
+		{es: &PMTElementaryStream{StreamType: StreamTypeH264Video}, expected: StreamKindVideo},
+		{es: &PMTElementaryStream{StreamType: StreamTypeAudioADTS}, expected: StreamKindAudio},
+		{es: &PMTElementaryStream{ElementaryStreamDescriptors: []*Descriptor{{Tag: DescriptorTagSubtitling}}, StreamType: StreamTypeMPEG2PacketizedData}, expected: StreamKindSubtitle},
+		{es: &PMTElementaryStream{StreamType: StreamTypeBluRayDTSAudio}, expected: StreamKindUnknown},
+		{bluRay: true, es: &PMTElementaryStream{StreamType: StreamTypeBluRayDTSAudio}, expected: StreamKindAudio},
+		{bluRay: true, es: &PMTElementaryStream{StreamType: StreamTypeBlueRayDolbyTrueHDAudio}, expected: StreamKindAudio},
+		{bluRay: true, es: &PMTElementaryStream{StreamType: StreamTypeBluRayPresentationGraphicStream}, expected: StreamKindSubtitle},
+		{bluRay: true, es: &PMTElementaryStream{StreamType: StreamTypeBluRayInteractiveGraphicStream}, expected: StreamKindGraphics},
+		{bluRay: true, es: &PMTElementaryStream{StreamType: StreamTypeBluRayVC1Video}, expected: StreamKindVideo},
+		{bluRay: true, es: &PMTElementaryStream{StreamType: StreamTypeH264Video}, expected: StreamKindVideo},
+	} {
+		assert.Equal(t, v.expected, v.es.Kind(v.bluRay))
+	}
+}
@@ -7,24 +7,126 @@ import (
 	"github.com/asticode/go-astikit"
 )
 
+// Size of a PSI section syntax header, from the table ID extension to the last section number
+const psiSectionSyntaxHeaderSize = 5
+
 // PSI table IDs
 const (
+	PSITableTypeAIT     = "AIT"
+	PSITableTypeATSCEIT = "ATSC EIT"
 	PSITableTypeBAT     = "BAT"
+	PSITableTypeCAT     = "CAT"
+	PSITableTypeCVCT    = "CVCT"
 	PSITableTypeDIT     = "DIT"
+	PSITableTypeDSMCC   = "DSM-CC"
 	PSITableTypeEIT     = "EIT"
+	PSITableTypeETT     = "ETT"
+	PSITableTypeMGT     = "MGT"
 	PSITableTypeNIT     = "NIT"
 	PSITableTypeNull    = "Null"
 	PSITableTypePAT     = "PAT"
 	PSITableTypePMT     = "PMT"
+	PSITableTypeRRT     = "RRT"
 	PSITableTypeRST     = "RST"
 	PSITableTypeSDT     = "SDT"
 	PSITableTypeSIT     = "SIT"
 	PSITableTypeST      = "ST"
+	PSITableTypeSTT     = "STT"
 	PSITableTypeTDT     = "TDT"
 	PSITableTypeTOT     = "TOT"
+	PSITableTypeTSDT    = "TSDT"
+	PSITableTypeTVCT    = "TVCT"
 	PSITableTypeUnknown = "Unknown"
 )
 
+// TableType represents a PSI table type
+// Unlike the PSITableType* strings, which are kept for display, it is cheap to compare and is classified out of the
+// table ID with a lookup.
+type TableType uint8
+
+// PSI table types
+const (
+	TableTypeUnknown TableType = iota
+	TableTypeBAT
+	TableTypeCAT
+	TableTypeDIT
+	TableTypeEIT
+	TableTypeNIT
+	TableTypeNull
+	TableTypePAT
+	TableTypePMT
+	TableTypeRST
+	TableTypeSDT
+	TableTypeSIT
+	TableTypeST
+	TableTypeTDT
+	TableTypeTOT
+	TableTypeMGT     // ATSC
+	TableTypeTVCT    // ATSC
+	TableTypeCVCT    // ATSC
+	TableTypeATSCEIT // ATSC
+	TableTypeETT     // ATSC
+	TableTypeSTT     // ATSC
+	TableTypeRRT     // ATSC
+	TableTypeTSDT
+	TableTypeDSMCC
+	TableTypeAIT
+)
+
+var tableTypeNames = map[TableType]string{
+	TableTypeAIT:     PSITableTypeAIT,
+	TableTypeATSCEIT: PSITableTypeATSCEIT,
+	TableTypeBAT:     PSITableTypeBAT,
+	TableTypeCAT:     PSITableTypeCAT,
+	TableTypeCVCT:    PSITableTypeCVCT,
+	TableTypeDIT:     PSITableTypeDIT,
+	TableTypeDSMCC:   PSITableTypeDSMCC,
+	TableTypeEIT:     PSITableTypeEIT,
+	TableTypeETT:     PSITableTypeETT,
+	TableTypeMGT:     PSITableTypeMGT,
+	TableTypeNIT:     PSITableTypeNIT,
+	TableTypeNull:    PSITableTypeNull,
+	TableTypePAT:     PSITableTypePAT,
+	TableTypePMT:     PSITableTypePMT,
+	TableTypeRRT:     PSITableTypeRRT,
+	TableTypeRST:     PSITableTypeRST,
+	TableTypeSDT:     PSITableTypeSDT,
+	TableTypeSIT:     PSITableTypeSIT,
+	TableTypeST:      PSITableTypeST,
+	TableTypeSTT:     PSITableTypeSTT,
+	TableTypeTDT:     PSITableTypeTDT,
+	TableTypeTOT:     PSITableTypeTOT,
+	TableTypeTSDT:    PSITableTypeTSDT,
+	TableTypeTVCT:    PSITableTypeTVCT,
+	TableTypeUnknown: PSITableTypeUnknown,
+}
+
+// String implements the fmt.Stringer interface and returns the matching PSITableType* string
+func (t TableType) String() string {
+	if n, ok := tableTypeNames[t]; ok {
+		return n
+	}
+	return PSITableTypeUnknown
+}
+
+// Table types indexed by table ID
+var tableTypes = newTableTypes()
+
+func newTableTypes() (ts [256]TableType) {
+	for id := range ts {
+		ts[id] = classifyTableType(id)
+	}
+	return
+}
+
+// TableTypeFromID returns the table type of a table ID
+func TableTypeFromID(tableID int) TableType {
+	if tableID < 0 || tableID >= len(tableTypes) {
+		return TableTypeUnknown
+	}
+	return tableTypes[tableID]
+}
+
 // PSIData represents a PSI data
 // https://en.wikipedia.org/wiki/Program-specific_information
 type PSIData struct {
@@ -41,11 +143,12 @@ type PSISection struct {
 
 // PSISectionHeader represents a PSI section header
 type PSISectionHeader struct {
-	PrivateBit             bool   // The PAT, PMT, and CAT all set this to 0. Other tables set this to 1.
-	SectionLength          uint16 // The number of bytes that follow for the syntax section (with CRC value) and/or table data. These bytes must not exceed a value of 1021.
-	SectionSyntaxIndicator bool   // A flag that indicates if the syntax section follows the section length. The PAT, PMT, and CAT all set this to 1.
-	TableID                int    // Table Identifier, that defines the structure of the syntax section and other contained data. As an exception, if this is the byte that immediately follow previous table section and is set to 0xFF, then it indicates that the repeat of table section end here and the rest of TS data payload shall be stuffed with 0xFF. Consequently the value 0xFF shall not be used for the Table Identifier.
-	TableType              string
+	PrivateBit             bool      // The PAT, PMT, and CAT all set this to 0. Other tables set this to 1.
+	SectionLength          uint16    // The number of bytes that follow for the syntax section (with CRC value) and/or table data. These bytes must not exceed a value of 1021.
+	SectionSyntaxIndicator bool      // A flag that indicates if the syntax section follows the section length. The PAT, PMT, and CAT all set this to 1.
+	TableID                int       // Table Identifier, that defines the structure of the syntax section and other contained data. As an exception, if this is the byte that immediately follow previous table section and is set to 0xFF, then it indicates that the repeat of table section end here and the rest of TS data payload shall be stuffed with 0xFF. Consequently the value 0xFF shall not be used for the Table Identifier.
+	TableType              string    // Kept for display and compatibility, Type should be preferred
+	Type                   TableType // Out of the table ID
 }
 
 // PSISectionSyntax represents a PSI section syntax
@@ -65,16 +168,38 @@ type PSISectionSyntaxHeader struct {
 
 // PSISectionSyntaxData represents a PSI section syntax data
 type PSISectionSyntaxData struct {
-	EIT *EITData
-	NIT *NITData
-	PAT *PATData
-	PMT *PMTData
-	SDT *SDTData
-	TOT *TOTData
+	AIT     *AITData
+	ATSCEIT *ATSCEITData
+	CAT     *CATData
+	DIT     *DITData
+	DSMCC   *DSMCCData
+	EIT     *EITData
+	ETT     *ETTData
+	MGT     *MGTData
+	NIT     *NITData
+	PAT     *PATData
+	PMT     *PMTData
+	RRT     *RRTData
+	RST     *RSTData
+	SDT     *SDTData
+	SIT     *SITData
+	ST      *STData
+	STT     *STTData
+	TDT     *TDTData
+	TOT     *TOTData
+	TSDT    *TSDTData
+	VCT     *VCTData
+}
+
+// parsePSIData parses a PSI data, table IDs being interpreted in the namespace of the profile
+func parsePSIData(i *astikit.BytesIterator, p Profile) (d *PSIData, err error) {
+	d, _, err = parsePSIDataWithStuffing(i, p, nil)
+	return
 }
 
-// parsePSIData parses a PSI data
-func parsePSIData(i *astikit.BytesIterator) (d *PSIData, err error) {
+// parsePSIDataWithStuffing parses a PSI data whose last section may be followed by one of the provided non standard
+// stuffing bytes, in which case a warning without PID is returned
+func parsePSIDataWithStuffing(i *astikit.BytesIterator, p Profile, stuffingBytes []uint8) (d *PSIData, w *StuffingWarning, err error) {
 	// Init data
 	d = &PSIData{}
 
@@ -92,38 +217,77 @@ func parsePSIData(i *astikit.BytesIterator) (d *PSIData, err error) {
 	i.Skip(d.PointerField)
 
 	// Parse sections
-	var s *PSISection
-	var stop bool
-	for i.HasBytesLeft() && !stop {
-		if s, stop, err = parsePSISection(i); err != nil {
+	it := newPSISectionIterator(i)
+	it.stuffingBytes = stuffingBytes
+	for {
+		// Get next section
+		if _, _, ok := it.next(); !ok {
+			break
+		}
+
+		// Section is too short to be a section of its table, which is the case of the zero filler some legacy muxers
+		// write after the last section
+		if isPSISectionTooShort(i, p) {
+			break
+		}
+
+		// Parse section
+		var s *PSISection
+		if s, err = parsePSISection(i, p); err != nil {
 			err = fmt.Errorf("astits: parsing PSI table failed: %w", err)
 			return
 		}
 		d.Sections = append(d.Sections, s)
 	}
+
+	// Non standard stuffing
+	if it.stuffingLength > 0 {
+		w = &StuffingWarning{
+			Byte:   it.stuffingByte,
+			Length: it.stuffingLength,
+		}
+	}
 	return
 }
 
+// isPSISectionTooShort checks whether the section starting at the current offset is too short to carry the syntax
+// header and the CRC32 its table type requires, and leaves the bytes iterator at the start of the section
+func isPSISectionTooShort(i *astikit.BytesIterator, p Profile) bool {
+	// Get header bytes
+	offsetStart := i.Offset()
+	bs, err := i.NextBytes(3)
+	i.Seek(offsetStart)
+	if err != nil {
+		return true
+	}
+
+	// Get minimum length
+	t := p.TableType(int(bs[0]))
+	var l int
+	if hasPSISyntaxHeader(t) {
+		l += psiSectionSyntaxHeaderSize
+	}
+	if hasCRC32(t) {
+		l += 4
+	}
+	return int(uint16(bs[1]&0xf)<<8|uint16(bs[2])) < l
+}
+
 // parsePSISection parses a PSI section
-func parsePSISection(i *astikit.BytesIterator) (s *PSISection, stop bool, err error) {
+// Sections whose table type is unknown are skipped after their header.
+func parsePSISection(i *astikit.BytesIterator, p Profile) (s *PSISection, err error) {
 	// Init section
 	s = &PSISection{}
 
 	// Parse header
 	var offsetStart, offsetSectionsEnd, offsetEnd int
-	if s.Header, offsetStart, _, offsetSectionsEnd, offsetEnd, err = parsePSISectionHeader(i); err != nil {
+	if s.Header, offsetStart, _, offsetSectionsEnd, offsetEnd, err = parsePSISectionHeader(i, p); err != nil {
 		err = fmt.Errorf("astits: parsing PSI section header failed: %w", err)
 		return
 	}
 
-	// Check whether we need to stop the parsing
-	if shouldStopPSIParsing(s.Header.TableType) {
-		stop = true
-		return
-	}
-
 	// Check whether there's a syntax section
-	if s.Header.SectionLength > 0 {
+	if s.Header.Type != TableTypeUnknown && s.Header.SectionLength > 0 {
 		// Parse syntax
 		if s.Syntax, err = parsePSISectionSyntax(i, s.Header, offsetSectionsEnd); err != nil {
 			err = fmt.Errorf("astits: parsing PSI section syntax failed: %w", err)
@@ -131,7 +295,8 @@ func parsePSISection(i *astikit.BytesIterator) (s *PSISection, stop bool, err er
 		}
 
 		// Process CRC32
-		if hasCRC32(s.Header.TableType) {
+		// DSM-CC sections whose section syntax indicator is not set carry a checksum instead, which is not checked
+		if hasCRC32(s.Header.Type) && (s.Header.Type != TableTypeDSMCC || s.Header.SectionSyntaxIndicator) {
 			// Seek to the end of the sections
 			i.Seek(offsetSectionsEnd)
 
@@ -197,13 +362,8 @@ func computeCRC32(bs []byte) (o uint32, err error) {
 	return
 }
 
-// shouldStopPSIParsing checks whether the PSI parsing should be stopped
-func shouldStopPSIParsing(tableType string) bool {
-	return tableType == PSITableTypeNull || tableType == PSITableTypeUnknown
-}
-
 // parsePSISectionHeader parses a PSI section header
-func parsePSISectionHeader(i *astikit.BytesIterator) (h *PSISectionHeader, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd int, err error) {
+func parsePSISectionHeader(i *astikit.BytesIterator, p Profile) (h *PSISectionHeader, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd int, err error) {
 	// Init
 	h = &PSISectionHeader{}
 	offsetStart = i.Offset()
@@ -219,12 +379,8 @@ func parsePSISectionHeader(i *astikit.BytesIterator) (h *PSISectionHeader, offse
 	h.TableID = int(b)
 
 	// Table type
-	h.TableType = psiTableType(h.TableID)
-
-	// Check whether we need to stop the parsing
-	if shouldStopPSIParsing(h.TableType) {
-		return
-	}
+	h.Type = p.TableType(h.TableID)
+	h.TableType = h.Type.String()
 
 	// Get next bytes
 	var bs []byte
@@ -246,64 +402,89 @@ func parsePSISectionHeader(i *astikit.BytesIterator) (h *PSISectionHeader, offse
 	offsetSectionsStart = i.Offset()
 	offsetEnd = offsetSectionsStart + int(h.SectionLength)
 	offsetSectionsEnd = offsetEnd
-	if hasCRC32(h.TableType) {
+	if hasCRC32(h.Type) {
 		offsetSectionsEnd -= 4
 	}
 	return
 }
 
 // hasCRC32 checks whether the table has a CRC32
-func hasCRC32(tableType string) bool {
-	return tableType == PSITableTypePAT ||
-		tableType == PSITableTypePMT ||
-		tableType == PSITableTypeEIT ||
-		tableType == PSITableTypeNIT ||
-		tableType == PSITableTypeTOT ||
-		tableType == PSITableTypeSDT
+func hasCRC32(t TableType) bool {
+	return t == TableTypePAT ||
+		t == TableTypeCAT ||
+		t == TableTypePMT ||
+		t == TableTypeEIT ||
+		t == TableTypeNIT ||
+		t == TableTypeTOT ||
+		t == TableTypeSDT ||
+		t == TableTypeSIT ||
+		t == TableTypeTSDT ||
+		t == TableTypeDSMCC ||
+		t == TableTypeAIT ||
+		t == TableTypeMGT ||
+		t == TableTypeTVCT ||
+		t == TableTypeCVCT ||
+		t == TableTypeATSCEIT ||
+		t == TableTypeETT ||
+		t == TableTypeSTT ||
+		t == TableTypeRRT
 }
 
-// psiTableType returns the psi table type based on the table id
+// classifyTableType returns the table type of a table ID
 // Page: 28 | https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
-func psiTableType(tableID int) string {
+func classifyTableType(tableID int) TableType {
 	switch {
+	case tableID == AITTableID:
+		return TableTypeAIT
 	case tableID == 0x4a:
-		return PSITableTypeBAT
+		return TableTypeBAT
+	case tableID == 1:
+		return TableTypeCAT
 	case tableID >= 0x4e && tableID <= 0x6f:
-		return PSITableTypeEIT
+		return TableTypeEIT
 	case tableID == 0x7e:
-		return PSITableTypeDIT
+		return TableTypeDIT
 	case tableID == 0x40, tableID == 0x41:
-		return PSITableTypeNIT
+		return TableTypeNIT
 	case tableID == 0xff:
-		return PSITableTypeNull
+		return TableTypeNull
 	case tableID == 0:
-		return PSITableTypePAT
+		return TableTypePAT
 	case tableID == 2:
-		return PSITableTypePMT
+		return TableTypePMT
 	case tableID == 0x71:
-		return PSITableTypeRST
+		return TableTypeRST
 	case tableID == 0x42, tableID == 0x46:
-		return PSITableTypeSDT
+		return TableTypeSDT
 	case tableID == 0x7f:
-		return PSITableTypeSIT
+		return TableTypeSIT
 	case tableID == 0x72:
-		return PSITableTypeST
+		return TableTypeST
 	case tableID == 0x70:
-		return PSITableTypeTDT
+		return TableTypeTDT
 	case tableID == 0x73:
-		return PSITableTypeTOT
+		return TableTypeTOT
+	case tableID == 3:
+		return TableTypeTSDT
+	case tableID >= DSMCCTableIDMultiprotocolEncapsulation && tableID <= DSMCCTableIDPrivateData:
+		return TableTypeDSMCC
 	default:
-		return PSITableTypeUnknown
+		return TableTypeUnknown
 	}
 }
 
+// psiTableType returns the psi table type based on the table id
+func psiTableType(tableID int) string {
+	return TableTypeFromID(tableID).String()
+}
+
 // parsePSISectionSyntax parses a PSI section syntax
 func parsePSISectionSyntax(i *astikit.BytesIterator, h *PSISectionHeader, offsetSectionsEnd int) (s *PSISectionSyntax, err error) {
 	// Init
 	s = &PSISectionSyntax{}
 
 	// Header
-	if hasPSISyntaxHeader(h.TableType) {
+	if hasPSISyntaxHeader(h.Type) {
 		if s.Header, err = parsePSISectionSyntaxHeader(i); err != nil {
 			err = fmt.Errorf("astits: parsing PSI section syntax header failed: %w", err)
 			return
@@ -319,12 +500,24 @@ func parsePSISectionSyntax(i *astikit.BytesIterator, h *PSISectionHeader, offset
 }
 
 // hasPSISyntaxHeader checks whether the section has a syntax header
-func hasPSISyntaxHeader(tableType string) bool {
-	return tableType == PSITableTypeEIT ||
-		tableType == PSITableTypeNIT ||
-		tableType == PSITableTypePAT ||
-		tableType == PSITableTypePMT ||
-		tableType == PSITableTypeSDT
+func hasPSISyntaxHeader(t TableType) bool {
+	return t == TableTypeCAT ||
+		t == TableTypeEIT ||
+		t == TableTypeNIT ||
+		t == TableTypePAT ||
+		t == TableTypePMT ||
+		t == TableTypeSDT ||
+		t == TableTypeSIT ||
+		t == TableTypeTSDT ||
+		t == TableTypeDSMCC ||
+		t == TableTypeAIT ||
+		t == TableTypeMGT ||
+		t == TableTypeTVCT ||
+		t == TableTypeCVCT ||
+		t == TableTypeATSCEIT ||
+		t == TableTypeETT ||
+		t == TableTypeSTT ||
+		t == TableTypeRRT
 }
 
 // parsePSISectionSyntaxHeader parses a PSI section syntax header
@@ -381,49 +574,111 @@ func parsePSISectionSyntaxData(i *astikit.BytesIterator, h *PSISectionHeader, sh
 	d = &PSISectionSyntaxData{}
 
 	// Switch on table type
-	switch h.TableType {
-	case PSITableTypeBAT:
+	switch h.Type {
+	case TableTypeAIT:
+		if d.AIT, err = parseAITSection(i, sh.TableIDExtension); err != nil {
+			err = fmt.Errorf("astits: parsing AIT section failed: %w", err)
+			return
+		}
+	case TableTypeBAT:
 		// TODO Parse BAT
-	case PSITableTypeDIT:
-		// TODO Parse DIT
-	case PSITableTypeEIT:
+	case TableTypeDIT:
+		if d.DIT, err = parseDITSection(i); err != nil {
+			err = fmt.Errorf("astits: parsing DIT section failed: %w", err)
+			return
+		}
+	case TableTypeDSMCC:
+		if d.DSMCC, err = parseDSMCCSection(i, offsetSectionsEnd, h.TableID); err != nil {
+			err = fmt.Errorf("astits: parsing DSM-CC section failed: %w", err)
+			return
+		}
+	case TableTypeEIT:
 		if d.EIT, err = parseEITSection(i, offsetSectionsEnd, sh.TableIDExtension); err != nil {
 			err = fmt.Errorf("astits: parsing EIT section failed: %w", err)
 			return
 		}
-	case PSITableTypeNIT:
+	case TableTypeATSCEIT:
+		if d.ATSCEIT, err = parseATSCEITSection(i, sh.TableIDExtension); err != nil {
+			err = fmt.Errorf("astits: parsing ATSC EIT section failed: %w", err)
+			return
+		}
+	case TableTypeETT:
+		if d.ETT, err = parseETTSection(i, offsetSectionsEnd); err != nil {
+			err = fmt.Errorf("astits: parsing ETT section failed: %w", err)
+			return
+		}
+	case TableTypeMGT:
+		if d.MGT, err = parseMGTSection(i); err != nil {
+			err = fmt.Errorf("astits: parsing MGT section failed: %w", err)
+			return
+		}
+	case TableTypeTVCT, TableTypeCVCT:
+		if d.VCT, err = parseVCTSection(i, sh.TableIDExtension, h.Type == TableTypeCVCT); err != nil {
+			err = fmt.Errorf("astits: parsing VCT section failed: %w", err)
+			return
+		}
+	case TableTypeCAT:
+		if d.CAT, err = parseCATSection(i, offsetSectionsEnd); err != nil {
+			err = fmt.Errorf("astits: parsing CAT section failed: %w", err)
+			return
+		}
+	case TableTypeNIT:
 		if d.NIT, err = parseNITSection(i, sh.TableIDExtension); err != nil {
 			err = fmt.Errorf("astits: parsing NIT section failed: %w", err)
 			return
 		}
-	case PSITableTypePAT:
+	case TableTypePAT:
 		if d.PAT, err = parsePATSection(i, offsetSectionsEnd, sh.TableIDExtension); err != nil {
 			err = fmt.Errorf("astits: parsing PAT section failed: %w", err)
 			return
 		}
-	case PSITableTypePMT:
+	case TableTypePMT:
 		if d.PMT, err = parsePMTSection(i, offsetSectionsEnd, sh.TableIDExtension); err != nil {
 			err = fmt.Errorf("astits: parsing PMT section failed: %w", err)
 			return
 		}
-	case PSITableTypeRST:
-		// TODO Parse RST
-	case PSITableTypeSDT:
+	case TableTypeRRT:
+		if d.RRT, err = parseRRTSection(i, sh.TableIDExtension); err != nil {
+			err = fmt.Errorf("astits: parsing RRT section failed: %w", err)
+			return
+		}
+	case TableTypeRST:
+		if d.RST, err = parseRSTSection(i, offsetSectionsEnd); err != nil {
+			err = fmt.Errorf("astits: parsing RST section failed: %w", err)
+			return
+		}
+	case TableTypeSDT:
 		if d.SDT, err = parseSDTSection(i, offsetSectionsEnd, sh.TableIDExtension); err != nil {
 			err = fmt.Errorf("astits: parsing PMT section failed: %w", err)
 			return
 		}
-	case PSITableTypeSIT:
-		// TODO Parse SIT
-	case PSITableTypeST:
-		// TODO Parse ST
-	case PSITableTypeTOT:
+	case TableTypeSIT:
+		if d.SIT, err = parseSITSection(i, offsetSectionsEnd); err != nil {
+			err = fmt.Errorf("astits: parsing SIT section failed: %w", err)
+			return
+		}
+	case TableTypeST:
+		d.ST = parseSTSection(i, offsetSectionsEnd)
+	case TableTypeSTT:
+		if d.STT, err = parseSTTSection(i, offsetSectionsEnd); err != nil {
+			err = fmt.Errorf("astits: parsing STT section failed: %w", err)
+			return
+		}
+	case TableTypeTOT:
 		if d.TOT, err = parseTOTSection(i); err != nil {
 			err = fmt.Errorf("astits: parsing TOT section failed: %w", err)
 			return
 		}
-	case PSITableTypeTDT:
-		// TODO Parse TDT
+	case TableTypeTDT:
+		if d.TDT, err = parseTDTSection(i); err != nil {
+			err = fmt.Errorf("astits: parsing TDT section failed: %w", err)
+			return
+		}
+	case TableTypeTSDT:
+		if d.TSDT, err = parseTSDTSection(i, offsetSectionsEnd); err != nil {
+			err = fmt.Errorf("astits: parsing TSDT section failed: %w", err)
+			return
+		}
 	}
 	return
 }
@@ -431,91 +686,134 @@ func parsePSISectionSyntaxData(i *astikit.BytesIterator, h *PSISectionHeader, sh
 // toData parses the PSI tables and returns a set of Data
 func (d *PSIData) toData(firstPacket *Packet, pid uint16) (ds []*Data) {
 	// Loop through sections
+	ds = make([]*Data, 0, len(d.Sections))
 	for _, s := range d.Sections {
+		// Section has no syntax, which only empty stuffing sections may legitimately lack
+		if (s.Syntax == nil || s.Syntax.Data == nil) && s.Header.Type != TableTypeST {
+			continue
+		}
+
 		// Switch on table type
-		switch s.Header.TableType {
-		case PSITableTypeEIT:
-			ds = append(ds, &Data{EIT: s.Syntax.Data.EIT, FirstPacket: firstPacket, PID: pid})
-		case PSITableTypeNIT:
-			ds = append(ds, &Data{FirstPacket: firstPacket, NIT: s.Syntax.Data.NIT, PID: pid})
-		case PSITableTypePAT:
-			ds = append(ds, &Data{FirstPacket: firstPacket, PAT: s.Syntax.Data.PAT, PID: pid})
-		case PSITableTypePMT:
-			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, PMT: s.Syntax.Data.PMT})
-		case PSITableTypeSDT:
-			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, SDT: s.Syntax.Data.SDT})
-		case PSITableTypeTOT:
-			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, TOT: s.Syntax.Data.TOT})
+		switch s.Header.Type {
+		case TableTypeAIT:
+			ds = append(ds, &Data{AIT: s.Syntax.Data.AIT, FirstPacket: firstPacket, PID: pid, Section: s})
+		case TableTypeATSCEIT:
+			ds = append(ds, &Data{ATSCEIT: s.Syntax.Data.ATSCEIT, FirstPacket: firstPacket, PID: pid, Section: s})
+		case TableTypeCAT:
+			ds = append(ds, &Data{CAT: s.Syntax.Data.CAT, FirstPacket: firstPacket, PID: pid, Section: s})
+		case TableTypeDIT:
+			ds = append(ds, &Data{DIT: s.Syntax.Data.DIT, FirstPacket: firstPacket, PID: pid, Section: s})
+		case TableTypeDSMCC:
+			ds = append(ds, &Data{DSMCC: s.Syntax.Data.DSMCC, FirstPacket: firstPacket, PID: pid, Section: s})
+		case TableTypeEIT:
+			ds = append(ds, &Data{EIT: s.Syntax.Data.EIT, FirstPacket: firstPacket, PID: pid, Section: s})
+		case TableTypeETT:
+			ds = append(ds, &Data{ETT: s.Syntax.Data.ETT, FirstPacket: firstPacket, PID: pid, Section: s})
+		case TableTypeMGT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, MGT: s.Syntax.Data.MGT, PID: pid, Section: s})
+		case TableTypeNIT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, NIT: s.Syntax.Data.NIT, PID: pid, Section: s})
+		case TableTypePAT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PAT: s.Syntax.Data.PAT, PID: pid, Section: s})
+		case TableTypePMT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, PMT: s.Syntax.Data.PMT, Section: s})
+		case TableTypeRRT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, RRT: s.Syntax.Data.RRT, Section: s})
+		case TableTypeRST:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, RST: s.Syntax.Data.RST, Section: s})
+		case TableTypeSDT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, SDT: s.Syntax.Data.SDT, Section: s})
+		case TableTypeSIT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, Section: s, SIT: s.Syntax.Data.SIT})
+		case TableTypeST:
+			// Empty stuffing sections have no syntax
+			st := &STData{}
+			if s.Syntax != nil {
+				st = s.Syntax.Data.ST
+			}
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, Section: s, ST: st})
+		case TableTypeSTT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, Section: s, STT: s.Syntax.Data.STT})
+		case TableTypeTDT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, Section: s, TDT: s.Syntax.Data.TDT})
+		case TableTypeTOT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, Section: s, TOT: s.Syntax.Data.TOT})
+		case TableTypeTSDT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, Section: s, TSDT: s.Syntax.Data.TSDT})
+		case TableTypeTVCT, TableTypeCVCT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, Section: s, VCT: s.Syntax.Data.VCT})
 		}
 	}
 	return
 }
 
 func (d *PSIData) Serialise(b []byte) (int, error) {
+	return serialiseWithSentinel(b, d.serialise)
+}
+
+func (d *PSIData) serialise(b []byte) (int, error) {
 
 	//TODO take care of pointer field
 	if d.PointerField != 0 {
 		return 0, errors.New("Error pointer field muxing unimplemented")
 	}
-	b[0] = uint8(d.PointerField)
-	idx := 1
+	w := newCheckedWriter(b)
+	if err := w.writeUint8("pointer field", uint8(d.PointerField)); err != nil {
+		return w.offset, err
+	}
 	for i := range d.Sections {
-		n, err := d.Sections[i].Serialise(b[idx:])
-		if err != nil {
-			return idx, err
+		if err := w.write(fmt.Sprintf("section #%d", i), d.Sections[i].serialise); err != nil {
+			return w.offset, err
 		}
-		idx += n
 	}
 	//TODO Handle Section.TableID=255 as stuffing bytes, but for now this works
 	//Stuff the rest with 0xff
-	for ; idx < len(b); idx++ {
+	for idx := w.offset; idx < len(b); idx++ {
 		b[idx] = 0xff
 	}
-	return idx, nil
+	return len(b), nil
 }
 
 func (s *PSISection) Serialise(b []byte) (int, error) {
+	return serialiseWithSentinel(b, s.serialise)
+}
+
+func (s *PSISection) serialise(b []byte) (int, error) {
 
 	if s.Header.TableID == 255 {
 		return 0, nil
 	}
-	if len(b) < 3 {
-		return 0, ErrNoRoomInBuffer
+	w := newCheckedWriter(b)
+	if _, err := w.next("section header", 3); err != nil { // Skip 3 byte header we put in afterward
+		return 0, err
 	}
-	idx := 3 // Skip 3 byte header we put in afterward
 
 	if s.Syntax != nil {
-		n, err := s.Syntax.Serialise(b[idx:])
-		if err != nil {
-			return idx, err
+		if err := w.write("section syntax", s.Syntax.serialise); err != nil {
+			return w.offset, err
 		}
-		idx += n
 	}
 
-	s.Header.SectionLength = uint16(idx + 4 - 3) // Add CRC32 field subtract initial 3 bytes
+	// Table type is derived from the table ID so that headers built from scratch don't need to set it
+	t := TableTypeFromID(s.Header.TableID)
+	s.Header.SectionLength = uint16(w.offset - 3) // Subtract initial 3 bytes
+	if hasCRC32(t) {
+		s.Header.SectionLength += 4 // Add CRC32 field
+	}
+	if s.Header.SectionLength > 0xfff {
+		return w.offset, fmt.Errorf("astits: section length %d doesn't fit in 12 bits", s.Header.SectionLength)
+	}
 
 	//Serialise header afterward so we ensure the section length is accurate
-	if s.Header != nil {
-		_, err := s.Header.Serialise(b[0:])
-		if err != nil {
-			return idx, err
-		}
+	if _, err := s.Header.serialise(b); err != nil {
+		return w.offset, err
 	}
 
-	if hasCRC32(s.Header.TableType) {
-
-		i := astikit.NewBytesIterator(b)
-		// Get CRC32 data
-		var crc32Data []byte
-		var err error
-		if crc32Data, err = i.NextBytes(idx); err != nil {
-			return idx, fmt.Errorf("astits: fetching next bytes failed: %w", err)
-		}
-
+	if hasCRC32(t) {
 		// Compute CRC32
-		var crc32 uint32
-		if crc32, err = computeCRC32(crc32Data); err != nil {
-			return idx, fmt.Errorf("astits: computing CRC32 failed: %w", err)
+		crc32, err := computeCRC32(b[:w.offset])
+		if err != nil {
+			return w.offset, fmt.Errorf("astits: computing CRC32 failed: %w", err)
 		}
 
 		// Check CRC32
@@ -523,76 +821,137 @@ func (s *PSISection) Serialise(b []byte) (int, error) {
 		// if crc32 != s.CRC32 {
 		// 	return idx, fmt.Errorf("astits: Table CRC32 %x != computed CRC32 %x", s.CRC32, crc32)
 		// }
-		b[idx] = uint8(crc32 >> 24)
-		b[idx+1] = uint8(crc32 >> 16)
-		b[idx+2] = uint8(crc32 >> 8)
-		b[idx+3] = uint8(crc32)
-		idx += 4
+		if err = w.writeUint32("CRC32", crc32); err != nil {
+			return w.offset, err
+		}
 	}
 
-	return idx, nil
+	return w.offset, nil
 
 }
 
 func (h *PSISectionHeader) Serialise(b []byte) (int, error) {
+	return serialiseWithSentinel(b, h.serialise)
+}
+
+func (h *PSISectionHeader) serialise(b []byte) (int, error) {
 	if h.TableID == 255 {
 		return 0, nil
 	}
-	if len(b) < 3 {
-		return 0, ErrNoRoomInBuffer
+	w := newCheckedWriter(b)
+	bs, err := w.next("section header", 3)
+	if err != nil {
+		return 0, err
 	}
-	b[0] = uint8(h.TableID)
-	b[1] = Btou8(h.SectionSyntaxIndicator)<<7 | Btou8(h.PrivateBit)<<6 | 3<<4 | uint8(0xf&(h.SectionLength>>8))
-	b[2] = uint8(0xff & h.SectionLength) // TODO how do we calculate this without having done the whole section?
-	return 3, nil
+	bs[0] = uint8(h.TableID)
+	bs[1] = Btou8(h.SectionSyntaxIndicator)<<7 | Btou8(h.PrivateBit)<<6 | 3<<4 | uint8(0xf&(h.SectionLength>>8))
+	bs[2] = uint8(0xff & h.SectionLength) // TODO how do we calculate this without having done the whole section?
+	return w.offset, nil
 	// TableType              string
 }
 
 func (s *PSISectionSyntax) Serialise(b []byte) (int, error) {
-	idx := 0
+	return serialiseWithSentinel(b, s.serialise)
+}
+
+func (s *PSISectionSyntax) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
 	if s.Header != nil {
-		n, err := s.Header.Serialise(b[idx:])
-		if err != nil {
-			return idx, err
+		if err := w.write("section syntax header", s.Header.serialise); err != nil {
+			return w.offset, err
 		}
-		idx += n
 	}
 	if s.Data != nil {
-		n, err := s.Data.Serialise(b[idx:])
-		if err != nil {
-			return idx, err
+		if err := w.write("section syntax data", s.Data.serialise); err != nil {
+			return w.offset, err
 		}
-		idx += n
 	}
 
-	return idx, nil
+	return w.offset, nil
 }
 func (sh *PSISectionSyntaxHeader) Serialise(b []byte) (int, error) {
-	if len(b) < 5 {
-		return 0, ErrNoRoomInBuffer
+	return serialiseWithSentinel(b, sh.serialise)
+}
+
+func (sh *PSISectionSyntaxHeader) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	bs, err := w.next("section syntax header", 5)
+	if err != nil {
+		return 0, err
 	}
-	b[0], b[1] = U16toU8s(sh.TableIDExtension)
+	bs[0], bs[1] = U16toU8s(sh.TableIDExtension)
 	reservedBits := uint8(3 << 6) //TODO figure out if reserved are always set
-	b[2] = uint8((0x1f&sh.VersionNumber)<<1) | Btou8(sh.CurrentNextIndicator) | reservedBits
-	b[3] = sh.SectionNumber
-	b[4] = sh.LastSectionNumber
-	return 5, nil
+	bs[2] = uint8((0x1f&sh.VersionNumber)<<1) | Btou8(sh.CurrentNextIndicator) | reservedBits
+	bs[3] = sh.SectionNumber
+	bs[4] = sh.LastSectionNumber
+	return w.offset, nil
 }
 
 func (sd *PSISectionSyntaxData) Serialise(b []byte) (int, error) {
+	return serialiseWithSentinel(b, sd.serialise)
+}
+
+func (sd *PSISectionSyntaxData) serialise(b []byte) (int, error) {
 
 	if sd.PAT != nil {
-		return sd.PAT.Serialise(b)
+		return sd.PAT.serialise(b)
 	}
 	if sd.PMT != nil {
-		return sd.PMT.Serialise(b)
-	}
-	//TODO implement serialisation of other packets
-	// 	sd.EIT.Serialise(b)
-	// 	sd.NIT.Serialise(b)
-	// 	sd.SDT.Serialise(b)
-	// 	sd.TOT.Serialise(b)
-	return 0, nil
+		return sd.PMT.serialise(b)
+	}
+	if sd.NIT != nil {
+		return sd.NIT.Serialise(b)
+	}
+	if sd.SDT != nil {
+		return sd.SDT.Serialise(b)
+	}
+	if sd.TDT != nil {
+		return sd.TDT.Serialise(b)
+	}
+	if sd.TOT != nil {
+		return sd.TOT.Serialise(b)
+	}
+	if sd.EIT != nil {
+		return sd.EIT.Serialise(b)
+	}
+	if sd.CAT != nil {
+		return sd.CAT.Serialise(b)
+	}
+	if sd.TSDT != nil {
+		return sd.TSDT.Serialise(b)
+	}
+
+	// Other tables can't be serialised yet
+	var t string
+	switch {
+	case sd.AIT != nil:
+		t = PSITableTypeAIT
+	case sd.ATSCEIT != nil:
+		t = PSITableTypeATSCEIT
+	case sd.DIT != nil:
+		t = PSITableTypeDIT
+	case sd.DSMCC != nil:
+		t = PSITableTypeDSMCC
+	case sd.ETT != nil:
+		t = PSITableTypeETT
+	case sd.MGT != nil:
+		t = PSITableTypeMGT
+	case sd.RRT != nil:
+		t = PSITableTypeRRT
+	case sd.RST != nil:
+		t = PSITableTypeRST
+	case sd.SIT != nil:
+		t = PSITableTypeSIT
+	case sd.ST != nil:
+		t = PSITableTypeST
+	case sd.STT != nil:
+		t = PSITableTypeSTT
+	case sd.VCT != nil:
+		t = "VCT"
+	default:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("astits: serialisation of table type %s not supported", t)
 }
 
 func Btou8(b bool) uint8 {
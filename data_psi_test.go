@@ -19,6 +19,7 @@ var psi = &PSIData{
 				SectionSyntaxIndicator: true,
 				TableID:                78,
 				TableType:              PSITableTypeEIT,
+				Type:                   TableTypeEIT,
 			},
 			Syntax: &PSISectionSyntax{
 				Data:   &PSISectionSyntaxData{EIT: eit},
@@ -33,6 +34,7 @@ var psi = &PSIData{
 				SectionSyntaxIndicator: true,
 				TableID:                64,
 				TableType:              PSITableTypeNIT,
+				Type:                   TableTypeNIT,
 			},
 			Syntax: &PSISectionSyntax{
 				Data:   &PSISectionSyntaxData{NIT: nit},
@@ -47,6 +49,7 @@ var psi = &PSIData{
 				SectionSyntaxIndicator: true,
 				TableID:                0,
 				TableType:              PSITableTypePAT,
+				Type:                   TableTypePAT,
 			},
 			Syntax: &PSISectionSyntax{
 				Data:   &PSISectionSyntaxData{PAT: pat},
@@ -61,6 +64,7 @@ var psi = &PSIData{
 				SectionSyntaxIndicator: true,
 				TableID:                2,
 				TableType:              PSITableTypePMT,
+				Type:                   TableTypePMT,
 			},
 			Syntax: &PSISectionSyntax{
 				Data:   &PSISectionSyntaxData{PMT: pmt},
@@ -75,6 +79,7 @@ var psi = &PSIData{
 				SectionSyntaxIndicator: true,
 				TableID:                66,
 				TableType:              PSITableTypeSDT,
+				Type:                   TableTypeSDT,
 			},
 			Syntax: &PSISectionSyntax{
 				Data:   &PSISectionSyntaxData{SDT: sdt},
@@ -89,12 +94,13 @@ var psi = &PSIData{
 				SectionSyntaxIndicator: true,
 				TableID:                115,
 				TableType:              PSITableTypeTOT,
+				Type:                   TableTypeTOT,
 			},
 			Syntax: &PSISectionSyntax{
 				Data: &PSISectionSyntaxData{TOT: tot},
 			},
 		},
-		{Header: &PSISectionHeader{TableID: 254, TableType: PSITableTypeUnknown}},
+		{Header: &PSISectionHeader{SectionLength: 1, TableID: 254, TableType: PSITableTypeUnknown, Type: TableTypeUnknown}},
 	},
 }
 
@@ -151,6 +157,9 @@ func psiBytes() []byte {
 	w.Write(totBytes())                    // TOT data
 	w.Write(uint32(0x6969b13))             // TOT CRC32
 	w.Write(uint8(254))                    // Unknown table ID
+	w.Write(uint16(1))                     // Unknown section length
+	w.Write(uint8(0))                      // Unknown data
+	w.Write(uint8(0xff))                   // Stuffing
 	w.Write(uint8(0))                      // PAT table ID
 	return buf.Bytes()
 }
@@ -167,11 +176,11 @@ func TestParsePSIData(t *testing.T) {
 	w.Write("000000001110") // TOT section length
 	w.Write(totBytes())     // TOT data
 	w.Write(uint32(32))     // TOT CRC32
-	_, err := parsePSIData(astikit.NewBytesIterator(buf.Bytes()))
+	_, err := parsePSIData(astikit.NewBytesIterator(buf.Bytes()), ProfileAuto)
 	assert.EqualError(t, err, "astits: parsing PSI table failed: astits: Table CRC32 20 != computed CRC32 6969b13")
 
 	// Valid
-	d, err := parsePSIData(astikit.NewBytesIterator(psiBytes()))
+	d, err := parsePSIData(astikit.NewBytesIterator(psiBytes()), ProfileAuto)
 	assert.NoError(t, err)
 	for i := range d.Sections {
 		if d.Sections[i].Syntax != nil && d.Sections[i].Syntax.Data != nil {
@@ -187,6 +196,7 @@ var psiSectionHeader = &PSISectionHeader{
 	SectionSyntaxIndicator: true,
 	TableID:                0,
 	TableType:              PSITableTypePAT,
+	Type:                   TableTypePAT,
 }
 
 func psiSectionHeaderBytes() []byte {
@@ -204,18 +214,22 @@ func TestParsePSISectionHeader(t *testing.T) {
 	// Unknown table type
 	buf := &bytes.Buffer{}
 	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
-	w.Write(uint8(254)) // Table ID
-	w.Write("1")        // Syntax section indicator
-	w.Write("0000000")  // Finish the byte
-	d, _, _, _, _, err := parsePSISectionHeader(astikit.NewBytesIterator(buf.Bytes()))
+	w.Write(uint8(254))     // Table ID
+	w.Write("1")            // Syntax section indicator
+	w.Write("000")          // Private bit and reserved
+	w.Write("000000000010") // Section length
+	d, _, _, _, _, err := parsePSISectionHeader(astikit.NewBytesIterator(buf.Bytes()), ProfileAuto)
 	assert.Equal(t, d, &PSISectionHeader{
-		TableID:   254,
-		TableType: PSITableTypeUnknown,
+		SectionLength:          2,
+		SectionSyntaxIndicator: true,
+		TableID:                254,
+		TableType:              PSITableTypeUnknown,
+		Type:                   TableTypeUnknown,
 	})
 	assert.NoError(t, err)
 
 	// Valid table type
-	d, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd, err := parsePSISectionHeader(astikit.NewBytesIterator(psiSectionHeaderBytes()))
+	d, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd, err := parsePSISectionHeader(astikit.NewBytesIterator(psiSectionHeaderBytes()), ProfileAuto)
 	assert.Equal(t, d, psiSectionHeader)
 	assert.Equal(t, 0, offsetStart)
 	assert.Equal(t, 3, offsetSectionsStart)
@@ -243,7 +257,18 @@ func TestPSITableType(t *testing.T) {
 	assert.Equal(t, PSITableTypeST, psiTableType(114))
 	assert.Equal(t, PSITableTypeTDT, psiTableType(112))
 	assert.Equal(t, PSITableTypeTOT, psiTableType(115))
-	assert.Equal(t, PSITableTypeUnknown, psiTableType(1))
+	assert.Equal(t, PSITableTypeCAT, psiTableType(1))
+	assert.Equal(t, PSITableTypeTSDT, psiTableType(3))
+	assert.Equal(t, PSITableTypeUnknown, psiTableType(4))
+}
+
+func TestTableType(t *testing.T) {
+	assert.Equal(t, TableTypePMT, TableTypeFromID(2))
+	assert.Equal(t, TableTypeEIT, TableTypeFromID(0x60))
+	assert.Equal(t, TableTypeUnknown, TableTypeFromID(-1))
+	assert.Equal(t, TableTypeUnknown, TableTypeFromID(256))
+	assert.Equal(t, PSITableTypeTOT, TableTypeTOT.String())
+	assert.Equal(t, PSITableTypeUnknown, TableType(0xff).String())
 }
 
 var psiSectionSyntaxHeader = &PSISectionSyntaxHeader{
@@ -275,15 +300,24 @@ func TestParsePSISectionSyntaxHeader(t *testing.T) {
 func TestPSIToData(t *testing.T) {
 	p := &Packet{}
 	assert.Equal(t, []*Data{
-		{EIT: eit, FirstPacket: p, PID: 2},
-		{FirstPacket: p, NIT: nit, PID: 2},
-		{FirstPacket: p, PAT: pat, PID: 2},
-		{FirstPacket: p, PMT: pmt, PID: 2},
-		{FirstPacket: p, SDT: sdt, PID: 2},
-		{FirstPacket: p, TOT: tot, PID: 2},
+		{EIT: eit, FirstPacket: p, PID: 2, Section: psi.Sections[0]},
+		{FirstPacket: p, NIT: nit, PID: 2, Section: psi.Sections[1]},
+		{FirstPacket: p, PAT: pat, PID: 2, Section: psi.Sections[2]},
+		{FirstPacket: p, PMT: pmt, PID: 2, Section: psi.Sections[3]},
+		{FirstPacket: p, PID: 2, SDT: sdt, Section: psi.Sections[4]},
+		{FirstPacket: p, PID: 2, Section: psi.Sections[5], TOT: tot},
 	}, psi.toData(p, uint16(2)))
 }
 
+func TestPSISectionSyntaxDataSerialiseUnsupported(t *testing.T) {
+	b := make([]byte, MpegTsPacketSize)
+	n, err := (&PSISectionSyntaxData{}).Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	_, err = (&PSISectionSyntaxData{SIT: &SITData{}}).Serialise(b)
+	assert.EqualError(t, err, "astits: serialisation of table type SIT not supported")
+}
+
 func removeOriginalBytesFromPSIData(d *PSISectionSyntaxData) {
 	if d.PMT != nil {
 		for j := range d.PMT.ProgramDescriptors {
@@ -0,0 +1,152 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// RRTData represents an ATSC RRT data
+// It defines, for a rating region, the rating dimensions and values content advisory descriptors refer to by index.
+// Chapter: 6.4 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type RRTData struct {
+	Descriptors      []*Descriptor
+	Dimensions       []*RRTDimension
+	ProtocolVersion  uint8
+	RatingRegion     uint8 // 0x1 is the US and its possessions, 0x2 is Canada
+	RatingRegionName *ATSCMultipleString
+}
+
+// RRTDimension represents a rating dimension of an ATSC RRT, such as the MPAA rating or the age based TV rating
+type RRTDimension struct {
+	GraduatedScale bool // Higher values represent increasing levels of rated content
+	Name           *ATSCMultipleString
+	Values         []*RRTValue
+}
+
+// RRTValue represents a rating value of an ATSC RRT dimension
+type RRTValue struct {
+	Abbreviation *ATSCMultipleString
+	Name         *ATSCMultipleString
+}
+
+// parseRRTSection parses an ATSC RRT section
+func parseRRTSection(i *astikit.BytesIterator, tableIDExtension uint16) (d *RRTData, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Create data
+	d = &RRTData{
+		ProtocolVersion: b,
+		RatingRegion:    uint8(tableIDExtension),
+	}
+
+	// Rating region name
+	if d.RatingRegionName, err = parseRRTString(i); err != nil {
+		err = fmt.Errorf("astits: parsing rating region name failed: %w", err)
+		return
+	}
+
+	// Get next byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Loop through dimensions
+	dimensionsNum := int(b)
+	for idx := 0; idx < dimensionsNum; idx++ {
+		// Create dimension
+		dm := &RRTDimension{}
+
+		// Name
+		if dm.Name, err = parseRRTString(i); err != nil {
+			err = fmt.Errorf("astits: parsing dimension name failed: %w", err)
+			return
+		}
+
+		// Get next byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// Graduated scale
+		dm.GraduatedScale = b&0x10 > 0
+
+		// Loop through values
+		valuesNum := int(b & 0xf)
+		for valueIdx := 0; valueIdx < valuesNum; valueIdx++ {
+			// Create value
+			v := &RRTValue{}
+
+			// Abbreviation
+			if v.Abbreviation, err = parseRRTString(i); err != nil {
+				err = fmt.Errorf("astits: parsing value abbreviation failed: %w", err)
+				return
+			}
+
+			// Name
+			if v.Name, err = parseRRTString(i); err != nil {
+				err = fmt.Errorf("astits: parsing value name failed: %w", err)
+				return
+			}
+
+			// Append value
+			dm.Values = append(dm.Values, v)
+		}
+
+		// Append dimension
+		d.Dimensions = append(d.Dimensions, dm)
+	}
+
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Descriptors
+	if d.Descriptors, err = parseDescriptorsUntil(i, i.Offset()+(int(bs[0]&0x3)<<8|int(bs[1]))); err != nil {
+		err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+		return
+	}
+	return
+}
+
+// parseRRTString parses a multiple string structure preceded by its length, nil being returned if the length is 0
+func parseRRTString(i *astikit.BytesIterator) (m *ATSCMultipleString, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// No string
+	if b == 0 {
+		return
+	}
+
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(int(b)); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+	return ParseATSCMultipleString(bs)
+}
+
+// Value returns the rating value a content advisory descriptor refers to by dimension and value indexes, or nil if
+// there is none
+func (d *RRTData) Value(dimension, value int) *RRTValue {
+	if dimension < 0 || dimension >= len(d.Dimensions) || value < 0 || value >= len(d.Dimensions[dimension].Values) {
+		return nil
+	}
+	return d.Dimensions[dimension].Values[value]
+}
@@ -0,0 +1,53 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+// rrtString returns a multiple string structure holding a single english Latin-1 string, preceded by its length
+func rrtString(s string) []byte {
+	b := append([]byte{1, 'e', 'n', 'g', 1, ATSCCompressionTypeNone, ATSCModeLatin1, uint8(len(s))}, s...)
+	return append([]byte{uint8(len(b))}, b...)
+}
+
+func rrtBytes() (b []byte) {
+	b = append(b, 0x0)                     // Protocol version
+	b = append(b, rrtString("US")...)      // Rating region name
+	b = append(b, 1)                       // Dimensions defined
+	b = append(b, rrtString("MPAA")...)    // Dimension #1 name
+	b = append(b, 0xf0|2)                  // Dimension #1 reserved, graduated scale and values defined
+	b = append(b, 0)                       // Value #1 abbreviation
+	b = append(b, 0)                       // Value #1 name
+	b = append(b, rrtString("G")...)       // Value #2 abbreviation
+	b = append(b, rrtString("General")...) // Value #2 name
+	b = append(b, 0xfc, 0x3)               // Reserved and descriptors length
+	b = append(b, DescriptorTagStreamIdentifier, 0x1, 0x7)
+	return
+}
+
+func TestParseRRTSection(t *testing.T) {
+	d, err := parseRRTSection(astikit.NewBytesIterator(rrtBytes()), 0xff01)
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{RRT: d})
+	assert.Equal(t, descriptors, d.Descriptors)
+	assert.Equal(t, uint8(1), d.RatingRegion)
+	s, _, err := d.RatingRegionName.Decode("", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "US", s)
+	assert.Len(t, d.Dimensions, 1)
+	assert.True(t, d.Dimensions[0].GraduatedScale)
+	assert.Len(t, d.Dimensions[0].Values, 2)
+	assert.Equal(t, &RRTValue{}, d.Value(0, 0))
+	s, _, err = d.Value(0, 1).Name.Decode("eng", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "General", s)
+	assert.Nil(t, d.Value(0, 2))
+	assert.Nil(t, d.Value(1, 0))
+
+	// Truncated
+	_, err = parseRRTSection(astikit.NewBytesIterator(rrtBytes()[:20]), 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// RSTData represents a RST data
+// It updates the running status of events quickly, without waiting for the next EIT, and its sections are only sent
+// when a running status changes.
+// Page: 41 | Chapter: 5.2.7 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type RSTData struct {
+	Events []*RSTDataEvent
+}
+
+// RSTDataEvent represents a RST data event
+type RSTDataEvent struct {
+	EventID           uint16
+	OriginalNetworkID uint16
+	RunningStatus     uint8 // See RunningStatus*
+	ServiceID         uint16
+	TransportStreamID uint16
+}
+
+// parseRSTSection parses a RST section
+func parseRSTSection(i *astikit.BytesIterator, offsetSectionsEnd int) (d *RSTData, err error) {
+	// Create data
+	d = &RSTData{}
+
+	// Loop until end of section data is reached
+	for i.Offset()+9 <= offsetSectionsEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(9); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Append event
+		d.Events = append(d.Events, &RSTDataEvent{
+			EventID:           uint16(bs[6])<<8 | uint16(bs[7]),
+			OriginalNetworkID: uint16(bs[2])<<8 | uint16(bs[3]),
+			RunningStatus:     bs[8] & 0x7,
+			ServiceID:         uint16(bs[4])<<8 | uint16(bs[5]),
+			TransportStreamID: uint16(bs[0])<<8 | uint16(bs[1]),
+		})
+	}
+	return
+}
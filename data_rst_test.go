@@ -0,0 +1,27 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+var rst = &RSTData{Events: []*RSTDataEvent{
+	{EventID: 4, OriginalNetworkID: 2, RunningStatus: RunningStatusRunning, ServiceID: 3, TransportStreamID: 1},
+	{EventID: 8, OriginalNetworkID: 6, RunningStatus: RunningStatusPausing, ServiceID: 7, TransportStreamID: 5},
+}}
+
+func rstBytes() []byte {
+	return []byte{
+		0x0, 0x1, 0x0, 0x2, 0x0, 0x3, 0x0, 0x4, 0xfc,
+		0x0, 0x5, 0x0, 0x6, 0x0, 0x7, 0x0, 0x8, 0xfb,
+	}
+}
+
+func TestParseRSTSection(t *testing.T) {
+	b := rstBytes()
+	d, err := parseRSTSection(astikit.NewBytesIterator(b), len(b))
+	assert.Equal(t, rst, d)
+	assert.NoError(t, err)
+}
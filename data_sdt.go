@@ -105,3 +105,44 @@ func parseSDTSection(i *astikit.BytesIterator, offsetSectionsEnd int, tableIDExt
 	}
 	return
 }
+
+// Serialise serialises the SDT data
+// Services can be added, removed or modified beforehand, the CRC being computed when serialising the section.
+func (d *SDTData) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint16("original network ID", d.OriginalNetworkID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("reserved", 0xff); err != nil {
+		return w.offset, err
+	}
+	for i, s := range d.Services {
+		if err := w.write(fmt.Sprintf("service #%d", i), s.Serialise); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
+
+// Serialise serialises the SDT data service
+func (s *SDTDataService) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint16("service ID", s.ServiceID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("EIT flags", 0xfc|Btou8(s.HasEITSchedule)<<1|Btou8(s.HasEITPresentFollowing)); err != nil {
+		return w.offset, err
+	}
+	// Running status and free CA mode share their bytes with the descriptors loop length
+	if err := w.writeLength("descriptors loop length", 12, uint16(s.RunningStatus)<<13|uint16(Btou8(s.HasFreeCSAMode))<<12, func() error {
+		for i, d := range s.Descriptors {
+			if err := w.write(fmt.Sprintf("descriptor #%d", i), d.serialise); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
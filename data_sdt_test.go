@@ -43,3 +43,51 @@ func TestParseSDTSection(t *testing.T) {
 	assert.Equal(t, d, sdt)
 	assert.NoError(t, err)
 }
+
+func TestSDTDataSerialise(t *testing.T) {
+	var b = sdtBytes()
+	d, err := parseSDTSection(astikit.NewBytesIterator(b), len(b), uint16(1))
+	assert.NoError(t, err)
+	o := make([]byte, 1024)
+	n, err := d.Serialise(o)
+	assert.NoError(t, err)
+	assert.Equal(t, len(b), n)
+	d, err = parseSDTSection(astikit.NewBytesIterator(o[:n]), n, uint16(1))
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{SDT: d})
+	assert.Equal(t, sdt, d)
+}
+
+func TestSDTSectionRemux(t *testing.T) {
+	// Parse
+	s := &PSISection{
+		Header: &PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x42},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{SDT: &SDTData{OriginalNetworkID: 2, TransportStreamID: 1, Services: []*SDTDataService{{ServiceID: 3}, {ServiceID: 4}}}},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 1},
+		},
+	}
+	s.Syntax.Data.SDT.Services[0].Descriptors = []*Descriptor{{Tag: DescriptorTagService, Service: &DescriptorService{Name: []byte("name"), Provider: []byte("provider"), Type: ServiceTypeDigitalTelevisionService}}}
+	b := make([]byte, 1024)
+	b[0] = 0 // Pointer field
+	n, err := s.Serialise(b[1:])
+	assert.NoError(t, err)
+	d, err := parsePSIData(astikit.NewBytesIterator(b[:n+1]), ProfileAuto)
+	assert.NoError(t, err)
+
+	// Modify
+	sdt := d.Sections[0].Syntax.Data.SDT
+	sdt.Services = sdt.Services[:1]
+	sdt.Services[0].RunningStatus = RunningStatusRunning
+	sdt.Services[0].Descriptors[0].Service.Name = []byte("new name")
+
+	// Serialise
+	n, err = d.Sections[0].Serialise(b[1:])
+	assert.NoError(t, err)
+	d, err = parsePSIData(astikit.NewBytesIterator(b[:n+1]), ProfileAuto)
+	assert.NoError(t, err)
+	sdt = d.Sections[0].Syntax.Data.SDT
+	assert.Len(t, sdt.Services, 1)
+	assert.Equal(t, uint8(RunningStatusRunning), sdt.Services[0].RunningStatus)
+	assert.Equal(t, []byte("new name"), sdt.Services[0].Descriptors[0].Service.Name)
+}
@@ -0,0 +1,64 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// SITData represents a SIT data
+// It replaces the other SI tables in partial transport streams, such as recordings of a PVR, and describes the
+// services and events they contain.
+// Page: 93 | Chapter: 7.1.2 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type SITData struct {
+	Services                []*SITDataService
+	TransmissionDescriptors []*Descriptor // Transmission parameters of the partial transport stream, such as its bitrate
+}
+
+// SITDataService represents a SIT data service
+type SITDataService struct {
+	Descriptors   []*Descriptor // Descriptors of the SDT and the EIT of the service when it was recorded
+	RunningStatus uint8
+	ServiceID     uint16
+}
+
+// parseSITSection parses a SIT section
+func parseSITSection(i *astikit.BytesIterator, offsetSectionsEnd int) (d *SITData, err error) {
+	// Create data
+	d = &SITData{}
+
+	// Transmission descriptors
+	if d.TransmissionDescriptors, err = parseDescriptors(i); err != nil {
+		err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+		return
+	}
+
+	// Loop until end of section data is reached
+	for i.Offset() < offsetSectionsEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(3); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create service
+		s := &SITDataService{
+			RunningStatus: bs[2] >> 4 & 0x7,
+			ServiceID:     uint16(bs[0])<<8 | uint16(bs[1]),
+		}
+
+		// We need to rewind since the current byte is used by the descriptor as well
+		i.Skip(-1)
+
+		// Descriptors
+		if s.Descriptors, err = parseDescriptors(i); err != nil {
+			err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+			return
+		}
+
+		// Append service
+		d.Services = append(d.Services, s)
+	}
+	return
+}
@@ -0,0 +1,38 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+var sit = &SITData{
+	Services: []*SITDataService{{
+		Descriptors:   descriptors,
+		RunningStatus: RunningStatusRunning,
+		ServiceID:     2,
+	}},
+	TransmissionDescriptors: descriptors,
+}
+
+func sitBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write("1111")     // Reserved for future use
+	descriptorsBytes(w) // Transmission descriptors
+	w.Write(uint16(2))  // Service ID
+	w.Write("1")        // Reserved for future use
+	w.Write("100")      // Running status
+	descriptorsBytes(w) // Service descriptors
+	return buf.Bytes()
+}
+
+func TestParseSITSection(t *testing.T) {
+	b := sitBytes()
+	d, err := parseSITSection(astikit.NewBytesIterator(b), len(b))
+	removeOriginalBytesFromData(&Data{SIT: d})
+	assert.Equal(t, sit, d)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,17 @@
+package astits
+
+import (
+	"github.com/asticode/go-astikit"
+)
+
+// STData represents a ST data
+// Stuffing tables invalidate existing sections at a delivery system boundary, their content is meaningless.
+// Page: 41 | Chapter: 5.2.8 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type STData struct {
+	Length int // Number of stuffing bytes following the section length
+}
+
+// parseSTSection parses a ST section
+func parseSTSection(i *astikit.BytesIterator, offsetSectionsEnd int) *STData {
+	return &STData{Length: offsetSectionsEnd - i.Offset()}
+}
@@ -0,0 +1,25 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSTSection(t *testing.T) {
+	// Partial transport stream boundary: a DIT, a ST and an empty ST
+	d, err := parsePSIData(astikit.NewBytesIterator([]byte{
+		0x0,                   // Pointer field
+		0x7e, 0x70, 0x1, 0x80, // DIT
+		0x72, 0x70, 0x3, 0xff, 0xff, 0xff, // ST
+		0x72, 0x70, 0x0, // Empty ST
+		0xff, 0xff, // Stuffing
+	}), ProfileAuto)
+	assert.NoError(t, err)
+	ds := d.toData(nil, PIDDIT)
+	assert.Len(t, ds, 3)
+	assert.Equal(t, &DITData{TransitionFlag: true}, ds[0].DIT)
+	assert.Equal(t, &STData{Length: 3}, ds[1].ST)
+	assert.Equal(t, &STData{}, ds[2].ST)
+}
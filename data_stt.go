@@ -0,0 +1,69 @@
+package astits
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// STTData represents an ATSC STT data
+// Chapter: 6.1 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type STTData struct {
+	DaylightSaving  STTDaylightSaving
+	Descriptors     []*Descriptor
+	GPSUTCOffset    uint8 // Number of leap seconds GPS time is ahead of UTC
+	ProtocolVersion uint8
+	SystemTime      uint32 // Seconds since 1980-01-06T00:00:00Z GPS time
+}
+
+// STTDaylightSaving represents the daylight saving information of an ATSC STT
+// When the status changes, day of month and hour indicate when the transition happens, in local time.
+type STTDaylightSaving struct {
+	DayOfMonth uint8 // 0 if no transition is announced
+	Hour       uint8
+	Status     bool // Daylight saving time is in effect
+}
+
+// Start of GPS time
+var atscGPSEpoch = time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+// ATSCGPSTime converts GPS seconds, as used by ATSC tables, to a UTC time
+// GPS time doesn't have leap seconds, the GPS to UTC offset carried by the STT must therefore be provided.
+func ATSCGPSTime(seconds uint32, gpsUTCOffset uint8) time.Time {
+	return atscGPSEpoch.Add(time.Duration(int64(seconds)-int64(gpsUTCOffset)) * time.Second)
+}
+
+// parseSTTSection parses an ATSC STT section
+func parseSTTSection(i *astikit.BytesIterator, offsetSectionsEnd int) (d *STTData, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(8); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create data
+	d = &STTData{
+		DaylightSaving: STTDaylightSaving{
+			DayOfMonth: bs[6] & 0x1f,
+			Hour:       bs[7],
+			Status:     bs[6]&0x80 > 0,
+		},
+		GPSUTCOffset:    bs[5],
+		ProtocolVersion: bs[0],
+		SystemTime:      uint32(bs[1])<<24 | uint32(bs[2])<<16 | uint32(bs[3])<<8 | uint32(bs[4]),
+	}
+
+	// Descriptors fill the section, there is no loop length
+	if d.Descriptors, err = parseDescriptorsUntil(i, offsetSectionsEnd); err != nil {
+		err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+		return
+	}
+	return
+}
+
+// UTCTime returns the system time as a UTC time
+func (d *STTData) UTCTime() time.Time {
+	return ATSCGPSTime(d.SystemTime, d.GPSUTCOffset)
+}
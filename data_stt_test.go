@@ -0,0 +1,44 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func sttBytes() []byte {
+	return []byte{
+		0x0,                    // Protocol version
+		0x45, 0x93, 0x09, 0x12, // System time
+		18,             // GPS UTC offset
+		0xe0 | 12,      // Daylight saving status and day of month
+		2,              // Daylight saving hour
+		0x52, 0x1, 0x7, // Stream identifier descriptor
+	}
+}
+
+func TestParseSTTSection(t *testing.T) {
+	b := sttBytes()
+	d, err := parseSTTSection(astikit.NewBytesIterator(b), len(b))
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{STT: d})
+	assert.Equal(t, &STTData{
+		DaylightSaving: STTDaylightSaving{DayOfMonth: 12, Hour: 2, Status: true},
+		Descriptors:    descriptors,
+		GPSUTCOffset:   18,
+		SystemTime:     1167264018,
+	}, d)
+	assert.Equal(t, time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC), d.UTCTime())
+
+	// Truncated
+	_, err = parseSTTSection(astikit.NewBytesIterator(b[:5]), 5)
+	assert.Error(t, err)
+}
+
+func TestATSCGPSTime(t *testing.T) {
+	assert.Equal(t, time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC), ATSCGPSTime(0, 0))
+	assert.Equal(t, time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC), ATSCGPSTime(1167264018, 18))
+	assert.Equal(t, time.Date(2017, time.January, 1, 1, 0, 0, 0, time.UTC), (&ATSCEITEvent{StartTime: 1167264018 + 3600}).UTCStartTime(18))
+}
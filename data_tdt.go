@@ -0,0 +1,38 @@
+package astits
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// TDTData represents a TDT data
+// Page: 39 | Chapter: 5.2.5 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type TDTData struct {
+	UTCTime time.Time
+}
+
+// parseTDTSection parses a TDT section
+func parseTDTSection(i *astikit.BytesIterator) (d *TDTData, err error) {
+	// Create data
+	d = &TDTData{}
+
+	// UTC time
+	if d.UTCTime, err = parseDVBTime(i); err != nil {
+		err = fmt.Errorf("astits: parsing DVB time failed: %w", err)
+		return
+	}
+	return
+}
+
+// Serialise serialises the TDT data
+func (d *TDTData) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	bs, err := w.next("UTC time", 5)
+	if err != nil {
+		return w.offset, err
+	}
+	writeDVBTime(bs, d.UTCTime)
+	return w.offset, nil
+}
@@ -0,0 +1,24 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+var tdt = &TDTData{UTCTime: dvbTime}
+
+func TestParseTDTSection(t *testing.T) {
+	d, err := parseTDTSection(astikit.NewBytesIterator(dvbTimeBytes))
+	assert.Equal(t, tdt, d)
+	assert.NoError(t, err)
+}
+
+func TestTDTDataSerialise(t *testing.T) {
+	b := make([]byte, 5)
+	n, err := tdt.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, dvbTimeBytes, b)
+}
@@ -24,11 +24,12 @@ func TestParseData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, cds, ds)
 
-	// Do nothing for CAT
-	ps = []*Packet{{Header: &PacketHeader{PID: PIDCAT}}}
+	// CAT
+	ps = []*Packet{{Header: &PacketHeader{PID: PIDCAT}, Payload: catPSIBytes(t)}}
 	ds, err = ParseData(ps, nil, pm)
 	assert.NoError(t, err)
-	assert.Empty(t, ds)
+	assert.Len(t, ds, 1)
+	assert.NotNil(t, ds[0].CAT)
 
 	// PES
 	p := pesWithHeaderBytes()
@@ -65,6 +66,16 @@ func TestParseData(t *testing.T) {
 		removeOriginalBytesFromData(ds[i])
 	}
 	assert.Equal(t, psi.toData(ps[0], uint16(256)), ds)
+
+	// PSI PID predicate
+	ps = []*Packet{{Header: &PacketHeader{PID: 0x1234}, Payload: catPSIBytes(t)}}
+	ds, err = parseData(ps, nil, pm, ProfileAuto, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 0)
+	ds, err = parseData(ps, nil, pm, ProfileAuto, nil, func(pid uint16) bool { return pid == 0x1234 }, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.NotNil(t, ds[0].CAT)
 }
 
 func TestIsPSIPayload(t *testing.T) {
@@ -75,9 +86,16 @@ func TestIsPSIPayload(t *testing.T) {
 			pids = append(pids, i)
 		}
 	}
-	assert.Equal(t, []int{0, 16, 17, 18, 19, 20, 30, 31}, pids)
-	pm.Set(uint16(1), uint16(0))
-	assert.True(t, IsPSIPayload(uint16(1), pm))
+	assert.Equal(t, []int{0, 1, 2, 16, 17, 18, 19, 20, 30, 31}, pids)
+	pm.Set(uint16(3), uint16(0))
+	assert.True(t, IsPSIPayload(uint16(3), pm))
+
+	// Profile
+	assert.False(t, IsPSIPayload(PIDATSCPSIP, pm))
+	assert.True(t, ProfileATSC.IsPSIPayload(PIDATSCPSIP, pm))
+	assert.False(t, ProfileATSC.IsPSIPayload(PIDSDT, pm))
+	assert.True(t, ProfileATSC.IsPSIPayload(PIDPAT, pm))
+	assert.True(t, ProfileATSC.IsPSIPayload(uint16(3), pm))
 }
 
 func TestIsPESPayload(t *testing.T) {
@@ -89,3 +107,111 @@ func TestIsPESPayload(t *testing.T) {
 	w.Write("000000000000000000000001")
 	assert.True(t, isPESPayload(buf.Bytes()))
 }
+
+func TestParseDataBluRayPresentationGraphics(t *testing.T) {
+	// PGS segments are carried in private stream 1 PES with a PTS
+	b := make([]byte, 184)
+	n, err := (&PESData{
+		Data: []byte{0x80, 0x0, 0x0}, // End of display set segment
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{PTS: ptsClockReference},
+			PacketLength:   1,
+			StreamID:       StreamIDPrivateStream1,
+		},
+	}).Serialise(b)
+	assert.NoError(t, err)
+	ds, err := ParseData([]*Packet{{Header: &PacketHeader{PID: 0x1200, PayloadUnitStartIndicator: true}, Payload: b[:n]}}, nil, NewProgramMap())
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, []byte{0x80, 0x0, 0x0}, ds[0].PES.Data)
+	assert.Equal(t, ptsClockReference, ds[0].PES.Header.OptionalHeader.PTS)
+}
+
+func TestIsReservedPID(t *testing.T) {
+	assert.True(t, IsReservedPID(PIDPAT))
+	assert.True(t, IsReservedPID(PIDReservedMax))
+	assert.True(t, IsReservedPID(PIDNull))
+	assert.False(t, IsReservedPID(PIDReservedMax+1))
+	assert.False(t, IsReservedPID(PIDNull-1))
+}
+
+func TestParseDataSinglePacket(t *testing.T) {
+	// Parse
+	ps := []*Packet{{Header: &PacketHeader{PID: 0x100}, Payload: pesWithHeaderBytes()}}
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileAuto, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Data{{FirstPacket: ps[0], PES: pesWithHeader, PID: 0x100}}, ds)
+
+	// Data don't reference the packet payload
+	for idx := range ps[0].Payload {
+		ps[0].Payload[idx] = 0
+	}
+	assert.Equal(t, pesWithHeader, ds[0].PES)
+}
+
+func TestParseDataZeroFilledPSI(t *testing.T) {
+	// Create payload made of a PAT followed by the zero filler some legacy muxers write
+	ss, err := NewPATSections(&PATData{
+		Programs:          []*PATProgram{{ProgramMapID: 0x1000, ProgramNumber: 1}},
+		TransportStreamID: 1,
+	})
+	assert.NoError(t, err)
+	b := make([]byte, MpegTsPacketSize-4)
+	n, err := (&PSIData{Sections: ss}).Serialise(b)
+	assert.NoError(t, err)
+	for idx := n; idx < len(b); idx++ {
+		b[idx] = 0
+	}
+	ps := []*Packet{{Header: &PacketHeader{PID: PIDPAT, PayloadUnitStartIndicator: true}, Payload: b}}
+
+	// Parse
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileAuto, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, uint16(1), ds[0].PAT.TransportStreamID)
+
+	// Sections without syntax are skipped
+	ds = (&PSIData{Sections: []*PSISection{{Header: &PSISectionHeader{TableType: PSITableTypePAT, Type: TableTypePAT}}}}).toData(nil, PIDPAT)
+	assert.Len(t, ds, 0)
+}
+
+func BenchmarkParseData(b *testing.B) {
+	// PES spanning several packets
+	d := muxerTestData(0x100, 1)
+	d.PES.Data = bytes.Repeat([]byte{1}, 10*MpegTsPacketSize)
+	pes, err := newMuxPESPackets(d, 0)
+	assert.NoError(b, err)
+
+	// PES fitting in one packet
+	d = muxerTestData(0x100, 1)
+	d.PES.Data = bytes.Repeat([]byte{1}, 100)
+	pesSingle, err := newMuxPESPackets(d, 0)
+	assert.NoError(b, err)
+
+	// PSI fitting in one packet
+	pm := NewProgramMap()
+	pm.Set(0x1000, 1)
+	psi, err := newMuxPSIPackets(0x1000, []*PSISection{newMuxPMTSection(MuxProgram{
+		Number:  1,
+		Streams: []MuxStream{{PID: 0x100, StreamType: StreamTypeH264Video}},
+	})}, 0, StuffingByte)
+	assert.NoError(b, err)
+
+	for _, v := range []struct {
+		name string
+		ps   []*Packet
+	}{
+		{name: "PES", ps: pes},
+		{name: "PES single packet", ps: pesSingle},
+		{name: "PSI", ps: psi},
+	} {
+		b.Run(v.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := parseData(v.ps, nil, pm, ProfileAuto, nil, nil, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -32,3 +32,19 @@ func parseTOTSection(i *astikit.BytesIterator) (d *TOTData, err error) {
 	}
 	return
 }
+
+// Serialise serialises the TOT data
+func (d *TOTData) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	bs, err := w.next("UTC time", 5)
+	if err != nil {
+		return w.offset, err
+	}
+	writeDVBTime(bs, d.UTCTime)
+	if err = w.write("descriptors", func(b []byte) (int, error) {
+		return serialiseDescriptors(b, d.Descriptors)
+	}); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
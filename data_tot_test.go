@@ -28,3 +28,15 @@ func TestParseTOTSection(t *testing.T) {
 	assert.Equal(t, d, tot)
 	assert.NoError(t, err)
 }
+
+func TestTOTDataSerialise(t *testing.T) {
+	d, err := parseTOTSection(astikit.NewBytesIterator(totBytes()))
+	assert.NoError(t, err)
+	b := make([]byte, 1024)
+	n, err := d.Serialise(b)
+	assert.NoError(t, err)
+	d, err = parseTOTSection(astikit.NewBytesIterator(b[:n]))
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{TOT: d})
+	assert.Equal(t, tot, d)
+}
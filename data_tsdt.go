@@ -0,0 +1,38 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// TSDTData represents a TSDT data
+// The descriptors it holds apply to the whole transport stream.
+// Chapter: 2.4.4.12 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type TSDTData struct {
+	Descriptors []*Descriptor
+}
+
+// parseTSDTSection parses a TSDT section
+func parseTSDTSection(i *astikit.BytesIterator, offsetSectionsEnd int) (d *TSDTData, err error) {
+	// Create data
+	d = &TSDTData{}
+
+	// Descriptors fill the section, there is no loop length
+	if d.Descriptors, err = parseDescriptorsUntil(i, offsetSectionsEnd); err != nil {
+		err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+		return
+	}
+	return
+}
+
+// Serialise serialises the TSDT data
+func (d *TSDTData) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	for i, v := range d.Descriptors {
+		if err := w.write(fmt.Sprintf("descriptor #%d", i), v.serialise); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
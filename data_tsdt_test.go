@@ -0,0 +1,30 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var tsdt = &TSDTData{Descriptors: []*Descriptor{NewRegistrationDescriptor("GA94")}}
+
+func TestParseTSDTSection(t *testing.T) {
+	// Serialise
+	b := make([]byte, 184)
+	n, err := (&PSISection{
+		Header: &PSISectionHeader{SectionSyntaxIndicator: true, TableID: 3},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{TSDT: tsdt},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 0xffff},
+		},
+	}).Serialise(b[1:])
+	assert.NoError(t, err)
+
+	// Parse
+	ds, err := ParseData([]*Packet{{Header: &PacketHeader{PID: PIDTSDT, PayloadUnitStartIndicator: true}, Payload: b[:n+1]}}, nil, NewProgramMap())
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, TableTypeTSDT, ds[0].Section.Header.Type)
+	removeOriginalBytesFromData(ds[0])
+	assert.Equal(t, tsdt, ds[0].TSDT)
+}
@@ -0,0 +1,154 @@
+package astits
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/asticode/go-astikit"
+)
+
+// VCT ETM locations
+const (
+	VCTETMLocationNone            = 0x0
+	VCTETMLocationPhysicalChannel = 0x1 // ETM is in the PTC carrying this PSIP
+	VCTETMLocationTransmitted     = 0x2 // ETM is in the PTC specified by the channel TSID
+)
+
+// VCT modulation modes
+const (
+	VCTModulationModeAnalog    = 0x1
+	VCTModulationModeSCTEMode1 = 0x2 // 64-QAM
+	VCTModulationModeSCTEMode2 = 0x3 // 256-QAM
+	VCTModulationMode8VSB      = 0x4
+	VCTModulationMode16VSB     = 0x5
+)
+
+// VCT service types
+const (
+	VCTServiceTypeAnalogTelevision = 0x1
+	VCTServiceTypeATSCDigitalTV    = 0x2
+	VCTServiceTypeATSCAudio        = 0x3
+	VCTServiceTypeATSCDataOnly     = 0x4
+	VCTServiceTypeSoftwareDownload = 0x5
+)
+
+// VCTData represents an ATSC TVCT or CVCT data
+// Both tables share the same layout, cable ones giving a meaning to bits terrestrial ones reserve.
+// Chapter: 6.3 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type VCTData struct {
+	AdditionalDescriptors []*Descriptor
+	Channels              []*VCTChannel
+	IsCable               bool // CVCT if true, TVCT otherwise
+	ProtocolVersion       uint8
+	TransportStreamID     uint16
+}
+
+// VCTChannel represents an ATSC virtual channel
+type VCTChannel struct {
+	AccessControlled   bool
+	CarrierFrequency   uint32 // Deprecated by A/65, should be 0
+	ChannelTSID        uint16
+	Descriptors        []*Descriptor
+	ETMLocation        uint8
+	Hidden             bool
+	HideGuide          bool
+	MajorChannelNumber uint16
+	MinorChannelNumber uint16
+	ModulationMode     uint8
+	OutOfBand          bool // CVCT only
+	PathSelect         bool // CVCT only
+	ProgramNumber      uint16
+	ServiceType        uint8
+	ShortName          string // Up to 7 UTF-16 code units
+	SourceID           uint16
+}
+
+// parseVCTSection parses an ATSC TVCT or CVCT section
+func parseVCTSection(i *astikit.BytesIterator, tableIDExtension uint16, isCable bool) (d *VCTData, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create data
+	d = &VCTData{
+		IsCable:           isCable,
+		ProtocolVersion:   bs[0],
+		TransportStreamID: tableIDExtension,
+	}
+
+	// Loop through channels
+	channelsNum := int(bs[1])
+	for idx := 0; idx < channelsNum; idx++ {
+		// Get next bytes
+		if bs, err = i.NextBytes(32); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create channel
+		c := &VCTChannel{
+			AccessControlled:   bs[26]&0x20 > 0,
+			CarrierFrequency:   uint32(bs[18])<<24 | uint32(bs[19])<<16 | uint32(bs[20])<<8 | uint32(bs[21]),
+			ChannelTSID:        uint16(bs[22])<<8 | uint16(bs[23]),
+			ETMLocation:        bs[26] >> 6,
+			Hidden:             bs[26]&0x10 > 0,
+			HideGuide:          bs[26]&0x2 > 0,
+			MajorChannelNumber: uint16(bs[14]&0xf)<<6 | uint16(bs[15]>>2),
+			MinorChannelNumber: uint16(bs[15]&0x3)<<8 | uint16(bs[16]),
+			ModulationMode:     bs[17],
+			ProgramNumber:      uint16(bs[24])<<8 | uint16(bs[25]),
+			ServiceType:        bs[27] & 0x3f,
+			ShortName:          parseVCTShortName(bs[:14]),
+			SourceID:           uint16(bs[28])<<8 | uint16(bs[29]),
+		}
+		if isCable {
+			c.OutOfBand = bs[26]&0x4 > 0
+			c.PathSelect = bs[26]&0x8 > 0
+		}
+
+		// Descriptors
+		if c.Descriptors, err = parseDescriptorsUntil(i, i.Offset()+(int(bs[30]&0x3)<<8|int(bs[31]))); err != nil {
+			err = fmt.Errorf("astits: parsing descriptors failed: %w", err)
+			return
+		}
+
+		// Append channel
+		d.Channels = append(d.Channels, c)
+	}
+
+	// Get next bytes
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Additional descriptors
+	if d.AdditionalDescriptors, err = parseDescriptorsUntil(i, i.Offset()+(int(bs[0]&0x3)<<8|int(bs[1]))); err != nil {
+		err = fmt.Errorf("astits: parsing additional descriptors failed: %w", err)
+		return
+	}
+	return
+}
+
+// parseVCTShortName parses a short name, which is padded with null code units
+func parseVCTShortName(bs []byte) string {
+	u := make([]uint16, len(bs)/2)
+	for idx := range u {
+		u[idx] = uint16(bs[2*idx])<<8 | uint16(bs[2*idx+1])
+	}
+	return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+}
+
+// Channel returns the channel with the provided major and minor numbers, or nil if there is none
+func (d *VCTData) Channel(major, minor uint16) *VCTChannel {
+	for _, c := range d.Channels {
+		if c.MajorChannelNumber == major && c.MinorChannelNumber == minor {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func vctBytes() []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint8(0)) // Protocol version
+	w.Write(uint8(1)) // Channels in section
+	for _, c := range []uint16{'K', 'Q', 'E', 'D', '-', 'H', 'D'} {
+		w.Write(c) // Channel #1 short name
+	}
+	w.Write("1111")                       // Channel #1 reserved
+	w.Write("0000001001")                 // Channel #1 major channel number
+	w.Write("0000000001")                 // Channel #1 minor channel number
+	w.Write(uint8(VCTModulationMode8VSB)) // Channel #1 modulation mode
+	w.Write(uint32(0))                    // Channel #1 carrier frequency
+	w.Write(uint16(0x1234))               // Channel #1 channel TSID
+	w.Write(uint16(3))                    // Channel #1 program number
+	w.Write("01")                         // Channel #1 ETM location
+	w.Write("1")                          // Channel #1 access controlled
+	w.Write("0")                          // Channel #1 hidden
+	w.Write("1")                          // Channel #1 path select
+	w.Write("1")                          // Channel #1 out of band
+	w.Write("1")                          // Channel #1 hide guide
+	w.Write("111")                        // Channel #1 reserved
+	w.Write("000010")                     // Channel #1 service type
+	w.Write(uint16(0x1001))               // Channel #1 source ID
+	w.Write("111111")                     // Channel #1 reserved
+	w.Write("0000000011")                 // Channel #1 descriptors length
+	w.Write(uint8(DescriptorTagStreamIdentifier))
+	w.Write(uint8(1))
+	w.Write(uint8(7))
+	w.Write("111111")     // Reserved
+	w.Write("0000000000") // Additional descriptors length
+	return buf.Bytes()
+}
+
+func TestParseVCTSection(t *testing.T) {
+	// Terrestrial
+	d, err := parseVCTSection(astikit.NewBytesIterator(vctBytes()), 0x1234, false)
+	assert.NoError(t, err)
+	removeOriginalBytesFromData(&Data{VCT: d})
+	c := &VCTChannel{
+		AccessControlled:   true,
+		ChannelTSID:        0x1234,
+		Descriptors:        descriptors,
+		ETMLocation:        VCTETMLocationPhysicalChannel,
+		HideGuide:          true,
+		MajorChannelNumber: 9,
+		MinorChannelNumber: 1,
+		ModulationMode:     VCTModulationMode8VSB,
+		ProgramNumber:      3,
+		ServiceType:        VCTServiceTypeATSCDigitalTV,
+		ShortName:          "KQED-HD",
+		SourceID:           0x1001,
+	}
+	assert.Equal(t, &VCTData{Channels: []*VCTChannel{c}, TransportStreamID: 0x1234}, d)
+	assert.Equal(t, c, d.Channel(9, 1))
+	assert.Nil(t, d.Channel(9, 2))
+
+	// Cable
+	d, err = parseVCTSection(astikit.NewBytesIterator(vctBytes()), 0x1234, true)
+	assert.NoError(t, err)
+	assert.True(t, d.IsCable)
+	assert.True(t, d.Channels[0].OutOfBand)
+	assert.True(t, d.Channels[0].PathSelect)
+
+	// Truncated
+	_, err = parseVCTSection(astikit.NewBytesIterator(vctBytes()[:20]), 0x1234, false)
+	assert.Error(t, err)
+}
+
+func TestParseVCTShortName(t *testing.T) {
+	assert.Equal(t, "ABC", parseVCTShortName([]byte{0x0, 'A', 0x0, 'B', 0x0, 'C', 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0}))
+	assert.Equal(t, "é", parseVCTShortName([]byte{0x0, 0xe9, 0x0, 0x0}))
+}
@@ -0,0 +1,103 @@
+package astits
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+)
+
+// Default interval at which read deadlines are applied
+const defaultReadPollInterval = 100 * time.Millisecond
+
+// readDeadliner represents an object capable of setting a read deadline such as a net.Conn
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// deadlineReader applies short read deadlines to the underlying reader so that ctx cancellation and idle timeouts
+// are taken into account while waiting for data, without any wrapper goroutine
+type deadlineReader struct {
+	ctx          context.Context
+	d            readDeadliner
+	disabled     bool
+	idleTimeout  time.Duration
+	lastRead     time.Time
+	pollInterval time.Duration
+	r            io.Reader
+}
+
+// newDeadlineReader returns a deadline reader if the reader supports read deadlines and can't be seeked, otherwise
+// it returns the reader as is
+func newDeadlineReader(ctx context.Context, r io.Reader, pollInterval, idleTimeout time.Duration) io.Reader {
+	// Seekable readers are files, not live sockets
+	if _, ok := r.(io.Seeker); ok {
+		return r
+	}
+
+	// Reader doesn't support deadlines
+	d, ok := r.(readDeadliner)
+	if !ok || pollInterval <= 0 {
+		return r
+	}
+	return &deadlineReader{
+		ctx:          ctx,
+		d:            d,
+		idleTimeout:  idleTimeout,
+		lastRead:     time.Now(),
+		pollInterval: pollInterval,
+		r:            r,
+	}
+}
+
+// Read implements the io.Reader interface
+func (r *deadlineReader) Read(p []byte) (n int, err error) {
+	// Deadlines are not supported
+	if r.disabled {
+		return r.r.Read(p)
+	}
+
+	for {
+		// Set deadline
+		if err = r.d.SetReadDeadline(time.Now().Add(r.pollInterval)); err != nil {
+			// Some readers, such as regular files, don't support deadlines
+			r.disabled = true
+			return r.r.Read(p)
+		}
+
+		// Read
+		n, err = r.r.Read(p)
+		if n > 0 {
+			r.lastRead = time.Now()
+			if isTimeoutError(err) {
+				err = nil
+			}
+			return
+		}
+
+		// Not a timeout
+		if !isTimeoutError(err) {
+			return
+		}
+
+		// Check ctx error
+		if err = r.ctx.Err(); err != nil {
+			return
+		}
+
+		// Check idle timeout
+		if r.idleTimeout > 0 && time.Since(r.lastRead) >= r.idleTimeout {
+			err = ErrReadIdleTimeout
+			return
+		}
+	}
+}
+
+// isTimeoutError checks whether the error has been caused by a deadline
+func isTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
@@ -0,0 +1,66 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeadlineReader(t *testing.T) {
+	// Reader without deadlines
+	r := bytes.NewReader([]byte("test"))
+	assert.Equal(t, r, newDeadlineReader(context.Background(), r, time.Millisecond, 0))
+
+	// Reader with deadlines
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	assert.IsType(t, &deadlineReader{}, newDeadlineReader(context.Background(), c1, time.Millisecond, 0))
+
+	// Disabled
+	assert.Equal(t, c1, newDeadlineReader(context.Background(), c1, 0, 0))
+}
+
+func TestDeadlineReader(t *testing.T) {
+	// Data
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := newDeadlineReader(ctx, c1, time.Millisecond, 0)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		c2.Write([]byte("test"))
+	}()
+	b := make([]byte, 4)
+	n, err := r.Read(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", string(b[:n]))
+
+	// Ctx cancellation
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+	_, err = r.Read(b)
+	assert.Equal(t, context.Canceled, err)
+
+	// Idle timeout
+	r = newDeadlineReader(context.Background(), c1, time.Millisecond, 5*time.Millisecond)
+	_, err = r.Read(b)
+	assert.Equal(t, ErrReadIdleTimeout, err)
+}
+
+func TestDemuxerReadIdleTimeout(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	dmx := New(context.Background(), c1, OptPacketSize(188), OptReadPollInterval(time.Millisecond), OptReadIdleTimeout(5*time.Millisecond))
+	_, err := dmx.NextPacket()
+	assert.True(t, errors.Is(err, ErrReadIdleTimeout))
+}
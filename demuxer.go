@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 )
 
 // Sync byte
@@ -14,6 +15,8 @@ const syncByte = '\x47'
 var (
 	ErrNoMorePackets                = errors.New("astits: no more packets")
 	ErrPacketMustStartWithASyncByte = errors.New("astits: packet must start with a sync byte")
+	ErrReadIdleTimeout              = errors.New("astits: no data read before idle timeout")
+	ErrReaderNotSeekable            = errors.New("astits: reader is not seekable")
 )
 
 // Demuxer represents a demuxer
@@ -21,37 +24,179 @@ var (
 // http://seidl.cs.vsb.cz/download/dvb/DVB_Poster.pdf
 // http://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.13.01_40/en_300468v011301o.pdf
 type Demuxer struct {
-	ctx              context.Context
-	dataBuffer       []*Data
-	optPacketSize    int
-	optPacketsParser PacketsParser
-	packetBuffer     *packetBuffer
-	packetPool       *PacketPool
-	programMap       ProgramMap
-	r                io.Reader
+	ctx                  context.Context
+	dataBuffer           []*Data
+	lastPAT              *Data
+	lastCAT              *CATData
+	lastPMTs             map[uint16]*Data // Indexed by program number, since several PMTs may share a PID
+	optAccessUnitHandler AccessUnitHandler
+	optAVSyncTracker     *AVSyncTracker
+	optDropHandler       DropHandler
+	optDSMCCCarousel     *DSMCCCarousel
+	optInterceptors      *InterceptorRegistry
+	optMaxDataBuffer     int
+	optMaxPacketPoolSize int
+	optPCRData           bool
+	optPCRHandler        PCRHandler
+	optPCRLeadTracker    *PCRLeadTracker
+	optPCRTimeline       *PCRTimeline
+	optPESCRCValidator   *PESCRCValidator
+	optPacketSize        int
+	optPacketMiddlewares []PacketMiddleware
+	optPacketTee         *PacketTee
+	optProfile           Profile
+	optPSIPIDPredicate   PSIPIDPredicate
+	optReadIdleTimeout   time.Duration
+	optRecorder          io.Writer
+	optRecorderPIDs      map[uint16]bool
+	optReadPollInterval  time.Duration
+	optScramblingTracker *ScramblingTracker
+	optServiceDB         *ServiceDB
+	optStreamConsistency *StreamConsistencyChecker
+	optSubtableTracker   *SubtableTracker
+	optTEMITimeline      *TEMITimeline
+	optSeekReplayPSI     bool
+	optStreamBufferSize  int
+	optStuffingPolicy    *StuffingPolicy
+	packetBuffer         *packetBuffer
+	packetPool           *PacketPool
+	programMap           ProgramMap
+	pmtVersions          map[uint16]uint8 // Version numbers of the last PMTs, indexed by program number
+	profile              Profile          // Profile in use, which is detected when optProfile is ProfileAuto
+	psiVersions          map[uint16]uint8 // Version numbers of the last PAT and PMTs, indexed by PID
+	r                    io.Reader
+	tablePIDs            map[uint16]bool // PIDs announced as carrying tables, such as the ATSC EIT and ETT PIDs listed by the MGT
 }
 
+// PacketMiddleware represents an object called with every packet read before it is processed any further
+// It can modify the packet, or drop it by returning drop = true
+type PacketMiddleware func(p *Packet) (drop bool, err error)
+
+// Drop reasons
+const (
+	DropReasonDataBufferFull = "data buffer full"
+	DropReasonPacketPoolFull = "packet pool full"
+)
+
+// DropEvent represents data or packets dropped by the demuxer because a memory cap has been reached
+type DropEvent struct {
+	Data    *Data     // Dropped data, if any
+	Packets []*Packet // Dropped packets of an incomplete payload, if any
+	PID     uint16
+	Reason  string
+}
+
+// DropHandler represents an object called whenever the demuxer drops data or packets
+type DropHandler func(e DropEvent)
+
+// PCREvent represents a PCR read by the demuxer
+type PCREvent struct {
+	Discontinuity bool // The discontinuity indicator is set
+	PCR           *ClockReference
+	PID           uint16
+}
+
+// PCRHandler represents an object called with every PCR read, which spares applications only interested in clocks
+// from working at the packet level
+type PCRHandler func(e PCREvent)
+
 // PacketsParser represents an object capable of parsing a set of packets containing a unique payload spanning over those packets
 // Use the skip returned argument to indicate whether the default process should still be executed on the set of packets
+// It is used by interceptors, see InterceptorRegistry.
 type PacketsParser func(ps []*Packet) (ds []*Data, skip bool, err error)
 
+// Default number of data buffered by Stream
+const defaultStreamBufferSize = 16
+
 // New creates a new transport stream based on a reader
 func New(ctx context.Context, r io.Reader, opts ...func(*Demuxer)) (d *Demuxer) {
 	// Init
 	d = &Demuxer{
-		ctx:        ctx,
-		packetPool: NewPacketPool(),
-		programMap: NewProgramMap(),
-		r:          r,
+		ctx:                 ctx,
+		lastPMTs:            make(map[uint16]*Data),
+		optInterceptors:     NewInterceptorRegistry(),
+		optReadPollInterval: defaultReadPollInterval,
+		optStreamBufferSize: defaultStreamBufferSize,
+		programMap:          NewProgramMap(),
+		pmtVersions:         make(map[uint16]uint8),
+		psiVersions:         make(map[uint16]uint8),
+		r:                   r,
+		tablePIDs:           make(map[uint16]bool),
 	}
 
 	// Apply options
 	for _, opt := range opts {
 		opt(d)
 	}
+	d.profile = d.optProfile
+
+	// Create packet pool
+	d.packetPool = d.newPacketPool()
+	return
+}
+
+// OptDropHandler returns the option to set the handler called whenever data or packets are dropped because a memory
+// cap has been reached
+func OptDropHandler(h DropHandler) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optDropHandler = h
+	}
+}
+
+// OptMaxDataBuffer returns the option to cap the number of data buffered by the demuxer, the oldest data being
+// dropped when the cap is exceeded
+// 0 means no cap.
+func OptMaxDataBuffer(n int) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optMaxDataBuffer = n
+	}
+}
+
+// OptMaxPacketPoolSize returns the option to cap the number of packets buffered per PID while waiting for a payload to
+// complete, protecting from streams that never complete a payload
+// When the cap is reached, the packets buffered for the PID are dropped. 0 means no cap.
+func OptMaxPacketPoolSize(n int) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optMaxPacketPoolSize = n
+	}
+}
+
+// newPacketPool creates a new packet pool applying the memory caps
+func (dmx *Demuxer) newPacketPool() (p *PacketPool) {
+	p = NewPacketPool()
+	p.maxSize = dmx.optMaxPacketPoolSize
+	p.onDrop = func(pid uint16, ps []*Packet) {
+		if dmx.optDropHandler != nil {
+			dmx.optDropHandler(DropEvent{Packets: ps, PID: pid, Reason: DropReasonPacketPoolFull})
+		}
+	}
 	return
 }
 
+// newPacketBuffer creates a new packet buffer reading from the demuxer reader, which detects the packet size if it
+// is not set
+func (dmx *Demuxer) newPacketBuffer() (*packetBuffer, error) {
+	return newPacketBuffer(newDeadlineReader(dmx.ctx, dmx.r, dmx.optReadPollInterval, dmx.optReadIdleTimeout), dmx.optPacketSize)
+}
+
+// OptProfile returns the option to set the broadcast standard the stream complies with, which determines the PIDs
+// parsed as SI and the namespace of their table IDs
+// The default, ProfileAuto, detects ATSC streams.
+func OptProfile(p Profile) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optProfile = p
+	}
+}
+
+// OptPSIPIDPredicate returns the option to parse the payloads of the PIDs matching the predicate as PSI, which allows
+// routing private SI on non-standard PIDs through PSI parsing without a custom packets parser
+// PIDs the demuxer already considers as PSI are parsed as PSI regardless of the predicate.
+func OptPSIPIDPredicate(f PSIPIDPredicate) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPSIPIDPredicate = f
+	}
+}
+
 // OptPacketSize returns the option to set the packet size
 func OptPacketSize(packetSize int) func(*Demuxer) {
 	return func(d *Demuxer) {
@@ -59,10 +204,214 @@ func OptPacketSize(packetSize int) func(*Demuxer) {
 	}
 }
 
+// OptPacketMiddlewares returns the option to add packet middlewares, which are called in order
+func OptPacketMiddlewares(ms ...PacketMiddleware) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPacketMiddlewares = append(d.optPacketMiddlewares, ms...)
+	}
+}
+
+// OptPacketTee returns the option to send every packet read to a packet tee
+// The tee is closed once there are no more packets.
+func OptPacketTee(t *PacketTee) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPacketTee = t
+	}
+}
+
+// OptProgramMap returns the option to pre-seed the program map, indexed by program map PID, for instance with the map
+// exported by (*Demuxer).ProgramMap() during a previous session
+// It allows classifying PMT PIDs immediately when joining a stream mid-flight, before the next PAT is received.
+func OptProgramMap(m map[uint16]uint16) func(*Demuxer) {
+	return func(d *Demuxer) {
+		for pid, number := range m {
+			d.programMap.Set(pid, number)
+		}
+	}
+}
+
 // OptPacketsParser returns the option to set the packets parser
+// Unlike with OptInterceptor, the data it returns without skipping the default process are replaced by the PSI data.
+// Deprecated: use OptInterceptor.
 func OptPacketsParser(p PacketsParser) func(*Demuxer) {
 	return func(d *Demuxer) {
-		d.optPacketsParser = p
+		d.optInterceptors.addLegacy(p)
+	}
+}
+
+// OptInterceptor returns the option to add an interceptor executed on every payload
+// Interceptors are executed in the order of the options.
+func OptInterceptor(p PacketsParser) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optInterceptors.Add(p)
+	}
+}
+
+// OptPIDInterceptor returns the option to add an interceptor executed on the payloads of a PID
+// Interceptors are executed in the order of the options.
+func OptPIDInterceptor(pid uint16, p PacketsParser) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optInterceptors.AddPID(pid, p)
+	}
+}
+
+// OptPayloadTypeInterceptor returns the option to add an interceptor executed on the payloads of a type
+// Interceptors are executed in the order of the options.
+func OptPayloadTypeInterceptor(t PayloadType, p PacketsParser) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optInterceptors.AddPayloadType(t, p)
+	}
+}
+
+// OptReadIdleTimeout returns the option to make reads fail with ErrReadIdleTimeout when no data has been received
+// for the provided duration
+// It only applies to readers supporting read deadlines, such as net.Conn
+func OptReadIdleTimeout(timeout time.Duration) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optReadIdleTimeout = timeout
+	}
+}
+
+// OptReadPollInterval returns the option to set the interval at which read deadlines are applied to readers
+// supporting them, such as net.Conn, so that ctx cancellation is taken into account while waiting for data
+// Default is 100ms, 0 disables read deadlines.
+func OptReadPollInterval(interval time.Duration) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optReadPollInterval = interval
+	}
+}
+
+// OptRecorder returns the option to copy the raw bytes of every packet read to a writer, before it is processed any
+// further
+// If pids is not empty, only packets with those PIDs are copied.
+func OptRecorder(w io.Writer, pids ...uint16) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optRecorder = w
+		d.optRecorderPIDs = nil
+		if len(pids) > 0 {
+			d.optRecorderPIDs = make(map[uint16]bool)
+			for _, pid := range pids {
+				d.optRecorderPIDs[pid] = true
+			}
+		}
+	}
+}
+
+// OptSeekReplayPSI returns the option to make the demuxer return the last PAT and PMTs it has seen before any other
+// data after a seek, so that consumers can process data located in the middle of a file right away
+func OptSeekReplayPSI(replay bool) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optSeekReplayPSI = replay
+	}
+}
+
+// OptStreamBufferSize returns the option to set the number of data Stream buffers before blocking
+func OptStreamBufferSize(size int) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optStreamBufferSize = size
+	}
+}
+
+// OptStuffingPolicy returns the option to recognise non standard stuffing bytes following the last section of PSI
+// payloads
+func OptStuffingPolicy(p StuffingPolicy) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optStuffingPolicy = &p
+	}
+}
+
+// OptAccessUnitHandler returns the option to set the handler called with every access unit assembled from a PES
+func OptAccessUnitHandler(h AccessUnitHandler) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optAccessUnitHandler = h
+	}
+}
+
+// OptAVSyncTracker returns the option to feed an A/V sync tracker with every data
+func OptAVSyncTracker(t *AVSyncTracker) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optAVSyncTracker = t
+	}
+}
+
+// OptDSMCCCarousel returns the option to feed a DSM-CC carousel with every data, so that its module and file
+// handlers are called as the carousel is received
+// Modules that can't be reassembled, for instance because their decompression fails, are skipped.
+func OptDSMCCCarousel(c *DSMCCCarousel) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optDSMCCCarousel = c
+	}
+}
+
+// OptPCRData returns the option to emit a data holding the PCR, its PID and the byte offset of its packet, for every
+// PCR read, including the ones of packets without payload such as those of PCR only PIDs
+func OptPCRData(enabled bool) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPCRData = enabled
+	}
+}
+
+// OptPCRHandler returns the option to set the handler called with every PCR read, including the ones of packets
+// without payload such as those of PCR only PIDs
+func OptPCRHandler(h PCRHandler) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPCRHandler = h
+	}
+}
+
+// OptPCRLeadTracker returns the option to feed a PCR lead tracker with every packet and data
+func OptPCRLeadTracker(t *PCRLeadTracker) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPCRLeadTracker = t
+	}
+}
+
+// OptPESCRCValidator returns the option to feed a PES CRC validator with every data
+func OptPESCRCValidator(v *PESCRCValidator) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPESCRCValidator = v
+	}
+}
+
+// OptPCRTimeline returns the option to feed a PCR timeline with every packet read
+func OptPCRTimeline(t *PCRTimeline) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPCRTimeline = t
+	}
+}
+
+// OptScramblingTracker returns the option to feed a scrambling tracker with every packet read
+func OptScramblingTracker(t *ScramblingTracker) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optScramblingTracker = t
+	}
+}
+
+// OptServiceDB returns the option to feed a service database with every data
+func OptServiceDB(db *ServiceDB) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optServiceDB = db
+	}
+}
+
+// OptStreamConsistencyChecker returns the option to feed a stream consistency checker with every data
+func OptStreamConsistencyChecker(c *StreamConsistencyChecker) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optStreamConsistency = c
+	}
+}
+
+// OptSubtableTracker returns the option to feed a sub-table tracker with every data
+func OptSubtableTracker(t *SubtableTracker) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optSubtableTracker = t
+	}
+}
+
+// OptTEMITimeline returns the option to feed a TEMI timeline with every packet read
+func OptTEMITimeline(t *TEMITimeline) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optTEMITimeline = t
 	}
 }
 
@@ -71,28 +420,109 @@ func (dmx *Demuxer) NextPacket() (p *Packet, err error) {
 	// Check ctx error
 	// TODO Handle ctx error another way since if the read blocks, everything blocks
 	// Maybe execute everything in a goroutine and listen the ctx channel in the same for loop
+	// Readers supporting read deadlines (e.g. net.Conn) don't have this problem, see deadlineReader
 	if err = dmx.ctx.Err(); err != nil {
 		return
 	}
 
 	// Create packet buffer if not exists
 	if dmx.packetBuffer == nil {
-		if dmx.packetBuffer, err = newPacketBuffer(dmx.r, dmx.optPacketSize); err != nil {
+		if dmx.packetBuffer, err = dmx.newPacketBuffer(); err != nil {
 			err = fmt.Errorf("astits: creating packet buffer failed: %w", err)
 			return
 		}
 	}
 
-	// Fetch next packet from buffer
-	if p, err = dmx.packetBuffer.next(); err != nil {
-		if err != ErrNoMorePackets {
-			err = fmt.Errorf("astits: fetching next packet from buffer failed: %w", err)
+	// Loop until a packet is not dropped
+	for {
+		// Fetch next packet from buffer
+		if p, err = dmx.packetBuffer.next(); err != nil {
+			if err == ErrNoMorePackets {
+				// Close packet tee
+				if dmx.optPacketTee != nil {
+					dmx.optPacketTee.Close()
+				}
+			} else {
+				err = fmt.Errorf("astits: fetching next packet from buffer failed: %w", err)
+			}
+			return
+		}
+
+		// Record packet
+		if dmx.optRecorder != nil && (dmx.optRecorderPIDs == nil || dmx.optRecorderPIDs[p.Header.PID]) {
+			if _, err = dmx.optRecorder.Write(dmx.packetBuffer.lastBytes); err != nil {
+				err = fmt.Errorf("astits: recording packet failed: %w", err)
+				return
+			}
+		}
+
+		// Apply packet middlewares
+		var drop bool
+		if drop, err = dmx.applyPacketMiddlewares(p); err != nil {
+			err = fmt.Errorf("astits: applying packet middlewares failed: %w", err)
+			return
+		} else if !drop {
+			break
+		}
+	}
+
+	// Handle PCR
+	if dmx.optPCRHandler != nil && p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.HasPCR {
+		dmx.optPCRHandler(PCREvent{
+			Discontinuity: p.AdaptationField.DiscontinuityIndicator,
+			PCR:           p.AdaptationField.PCR,
+			PID:           p.Header.PID,
+		})
+	}
+
+	// Update PCR lead tracker
+	if dmx.optPCRLeadTracker != nil {
+		dmx.optPCRLeadTracker.AddPacket(p)
+	}
+
+	// Update PCR timeline
+	if dmx.optPCRTimeline != nil {
+		dmx.optPCRTimeline.AddPacket(p)
+	}
+
+	// Update scrambling tracker
+	if dmx.optScramblingTracker != nil {
+		dmx.optScramblingTracker.AddPacket(p)
+	}
+
+	// Update TEMI timeline
+	if dmx.optTEMITimeline != nil {
+		dmx.optTEMITimeline.AddPacket(p)
+	}
+
+	// Send packet to tee
+	if dmx.optPacketTee != nil {
+		dmx.optPacketTee.Send(p)
+	}
+	return
+}
+
+func (dmx *Demuxer) applyPacketMiddlewares(p *Packet) (drop bool, err error) {
+	for _, m := range dmx.optPacketMiddlewares {
+		if drop, err = m(p); err != nil || drop {
+			return
 		}
-		return
 	}
 	return
 }
 
+// PIDFilterPacketMiddleware returns a packet middleware dropping packets whose PID is not in pids
+func PIDFilterPacketMiddleware(pids ...uint16) PacketMiddleware {
+	m := make(map[uint16]bool)
+	for _, pid := range pids {
+		m[pid] = true
+	}
+	return func(p *Packet) (drop bool, err error) {
+		drop = !m[p.Header.PID]
+		return
+	}
+}
+
 // NextData retrieves the next data
 func (dmx *Demuxer) NextData() (d *Data, err error) {
 	// Check data buffer
@@ -118,7 +548,7 @@ func (dmx *Demuxer) NextData() (d *Data, err error) {
 					}
 
 					// Parse data
-					if ds, err = ParseData(ps, dmx.optPacketsParser, dmx.programMap); err != nil {
+					if ds, err = parseData(ps, dmx.optInterceptors, dmx.programMap, dmx.profile, dmx.tablePIDs, dmx.optPSIPIDPredicate, dmx.optStuffingPolicy); err != nil {
 						// We need to silence this error as there may be some incomplete data here
 						// We still want to try to parse all packets, in case final data is complete
 						continue
@@ -129,21 +559,43 @@ func (dmx *Demuxer) NextData() (d *Data, err error) {
 						return
 					}
 				}
+				err = ErrNoMorePackets
 				return
 			}
 			err = fmt.Errorf("astits: fetching next packet failed: %w", err)
 			return
 		}
 
-		// Add packet to the pool
-		if ps = dmx.packetPool.Add(p); len(ps) == 0 {
-			continue
+		// Detect profile before the packet is parsed, since it determines whether the packet is parsed as PSI
+		if dmx.profile == ProfileAuto {
+			dmx.profile = detectPacketProfile(p)
 		}
 
-		// Parse data
-		if ds, err = ParseData(ps, dmx.optPacketsParser, dmx.programMap); err != nil {
-			err = fmt.Errorf("astits: building new data failed: %w", err)
-			return
+		// Packets without payload, such as the ones of PCR only PIDs, can't complete a payload and don't need to go
+		// through the packet pool
+		ds = nil
+		if p.Header.HasPayload {
+			// Add packet to the pool
+			if ps = dmx.packetPool.Add(p); len(ps) > 0 {
+				// Parse data
+				if ds, err = parseData(ps, dmx.optInterceptors, dmx.programMap, dmx.profile, dmx.tablePIDs, dmx.optPSIPIDPredicate, dmx.optStuffingPolicy); err != nil {
+					err = fmt.Errorf("astits: building new data failed: %w", err)
+					return
+				}
+			}
+		}
+
+		// Append PCR data after the data completed by the packet, since it most likely belongs to a previous payload
+		if dmx.optPCRData && p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.HasPCR {
+			ds = append(ds, &Data{
+				FirstPacket: p,
+				PCR: &DataPCR{
+					Discontinuity: p.AdaptationField.DiscontinuityIndicator,
+					Offset:        dmx.packetBuffer.lastOffset,
+					PCR:           p.AdaptationField.PCR,
+				},
+				PID: p.Header.PID,
+			})
 		}
 
 		// Update data
@@ -153,6 +605,71 @@ func (dmx *Demuxer) NextData() (d *Data, err error) {
 	}
 }
 
+// ProgramMap returns a copy of the program map, indexed by program map PID
+func (dmx *Demuxer) ProgramMap() map[uint16]uint16 {
+	return dmx.programMap.Map()
+}
+
+// CAPIDs returns the ECM PIDs declared by the last PMTs and the EMM PIDs declared by the last CAT, sorted by PID, so
+// that scrambled streams tooling can route them to a CA system
+func (dmx *Demuxer) CAPIDs() (ps []CAPID) {
+	// EMMs
+	if dmx.lastCAT != nil {
+		ps = append(ps, dmx.lastCAT.CAPIDs()...)
+	}
+
+	// ECMs
+	for _, d := range dmx.lastPMTs {
+		ps = append(ps, d.PMT.CAPIDs()...)
+	}
+
+	// Sort
+	sortCAPIDs(ps)
+	return
+}
+
+// Stream retrieves data in a goroutine and sends them to the returned data channel
+// Once the buffer is full, reading stops until the consumer catches up.
+// Both channels are closed when there are no more packets, when ctx is cancelled or after an error has been sent to
+// the error channel. Since reads are blocking, cancellation is only taken into account once the current read returns.
+// The demuxer must not be used by anything else while the channels are open.
+func (dmx *Demuxer) Stream(ctx context.Context) (<-chan *Data, <-chan error) {
+	// Create channels
+	dc := make(chan *Data, dmx.optStreamBufferSize)
+	ec := make(chan error, 1)
+
+	// Read in a goroutine
+	go func() {
+		// Close channels
+		defer close(ec)
+		defer close(dc)
+
+		for {
+			// Check ctx error
+			if ctx.Err() != nil {
+				return
+			}
+
+			// Get next data
+			d, err := dmx.NextData()
+			if err != nil {
+				if err != ErrNoMorePackets {
+					ec <- err
+				}
+				return
+			}
+
+			// Send data
+			select {
+			case dc <- d:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return dc, ec
+}
+
 func (dmx *Demuxer) updateData(ds []*Data) (d *Data) {
 	// Check whether there is data to be processed
 	if len(ds) > 0 {
@@ -160,8 +677,68 @@ func (dmx *Demuxer) updateData(ds []*Data) (d *Data) {
 		d = ds[0]
 		dmx.dataBuffer = append(dmx.dataBuffer, ds[1:]...)
 
-		// Update program map
+		// Drop oldest data
+		for dmx.optMaxDataBuffer > 0 && len(dmx.dataBuffer) > dmx.optMaxDataBuffer {
+			if dmx.optDropHandler != nil {
+				dmx.optDropHandler(DropEvent{Data: dmx.dataBuffer[0], PID: dmx.dataBuffer[0].PID, Reason: DropReasonDataBufferFull})
+			}
+			dmx.dataBuffer = dmx.dataBuffer[1:]
+		}
+
+		// Loop through data
 		for _, v := range ds {
+			// Update trackers
+			if dmx.optAVSyncTracker != nil {
+				dmx.optAVSyncTracker.AddData(v)
+			}
+			if dmx.optDSMCCCarousel != nil {
+				dmx.optDSMCCCarousel.AddData(v)
+			}
+			if dmx.optPCRLeadTracker != nil {
+				dmx.optPCRLeadTracker.AddData(v)
+			}
+			if dmx.optPESCRCValidator != nil {
+				dmx.optPESCRCValidator.AddData(v)
+			}
+			if dmx.optServiceDB != nil {
+				dmx.optServiceDB.AddData(v)
+			}
+			if dmx.optStreamConsistency != nil {
+				dmx.optStreamConsistency.AddData(v)
+			}
+			if dmx.optSubtableTracker != nil {
+				dmx.optSubtableTracker.AddData(v)
+			}
+
+			// Handle access unit
+			if dmx.optAccessUnitHandler != nil {
+				if au := NewAccessUnit(v); au != nil {
+					dmx.optAccessUnitHandler(au)
+				}
+			}
+
+			// Update PSI cache
+			if v.CAT != nil {
+				dmx.lastCAT = v.CAT
+			}
+			if v.PAT != nil {
+				dmx.lastPAT = v
+			} else if v.PMT != nil {
+				dmx.lastPMTs[v.PMT.ProgramNumber] = v
+				if dmx.profile == ProfileAuto {
+					dmx.profile = detectPMTProfile(v.PMT)
+				}
+			}
+			if v.PAT != nil || v.PMT != nil {
+				if n, ok := psiDataVersionNumber(v); ok {
+					dmx.psiVersions[v.PID] = n
+					if v.PMT != nil {
+						dmx.pmtVersions[v.PMT.ProgramNumber] = n
+					}
+				}
+			}
+
+			// Update program map
 			if v.PAT != nil {
 				for _, pgm := range v.PAT.Programs {
 					// Program number 0 is reserved to NIT
@@ -170,6 +747,22 @@ func (dmx *Demuxer) updateData(ds []*Data) (d *Data) {
 					}
 				}
 			}
+
+			// Update table PIDs
+			if v.MGT != nil {
+				for _, t := range v.MGT.Tables {
+					if t.hasOwnPID() {
+						dmx.tablePIDs[t.PID] = true
+					}
+				}
+			}
+			if v.PMT != nil {
+				for _, es := range v.PMT.ElementaryStreams {
+					if isDSMCCSectionsStreamType(es.StreamType) || isAITElementaryStream(es) {
+						dmx.tablePIDs[es.ElementaryPID] = true
+					}
+				}
+			}
 		}
 	}
 	return
@@ -179,10 +772,61 @@ func (dmx *Demuxer) updateData(ds []*Data) (d *Data) {
 func (dmx *Demuxer) Rewind() (n int64, err error) {
 	dmx.dataBuffer = []*Data{}
 	dmx.packetBuffer = nil
-	dmx.packetPool = NewPacketPool()
+	dmx.packetPool = dmx.newPacketPool()
 	if n, err = rewind(dmx.r); err != nil {
 		err = fmt.Errorf("astits: rewinding reader failed: %w", err)
 		return
 	}
 	return
 }
+
+// Seek seeks the demuxer reader, whence being interpreted as in io.Seeker, and moves back to the start of the packet
+// containing the resulting offset
+// If the packet size has not been detected yet, it is detected before seeking.
+// Buffered packets and data are dropped. If OptSeekReplayPSI has been set, the last PAT and PMTs that have been seen
+// are returned by NextData before any other data.
+func (dmx *Demuxer) Seek(offset int64, whence int) (n int64, err error) {
+	// Reader must be seekable
+	s, ok := dmx.r.(io.Seeker)
+	if !ok {
+		err = ErrReaderNotSeekable
+		return
+	}
+
+	// Create packet buffer before seeking, since auto detecting the packet size rewinds the reader
+	pb := dmx.packetBuffer
+	if pb == nil {
+		if pb, err = dmx.newPacketBuffer(); err != nil {
+			err = fmt.Errorf("astits: creating packet buffer failed: %w", err)
+			return
+		}
+	}
+
+	// Seek
+	if n, err = s.Seek(offset, whence); err != nil {
+		err = fmt.Errorf("astits: seeking to %d failed: %w", offset, err)
+		return
+	}
+
+	// Align offset on packets
+	if n%int64(pb.packetSize) > 0 {
+		if n, err = s.Seek(n-n%int64(pb.packetSize), io.SeekStart); err != nil {
+			err = fmt.Errorf("astits: seeking to packet start failed: %w", err)
+			return
+		}
+	}
+
+	// Reset buffers
+	dmx.dataBuffer = []*Data{}
+	dmx.packetBuffer = &packetBuffer{offset: n, packetSize: pb.packetSize, r: pb.r}
+	dmx.packetPool = dmx.newPacketPool()
+
+	// Replay PSI
+	if dmx.optSeekReplayPSI {
+		if dmx.lastPAT != nil {
+			dmx.dataBuffer = append(dmx.dataBuffer, dmx.lastPAT)
+		}
+		dmx.dataBuffer = append(dmx.dataBuffer, dmx.sortedLastPMTs()...)
+	}
+	return
+}
@@ -0,0 +1,138 @@
+package astits
+
+import (
+	"sort"
+)
+
+// DemuxerState represents the state of a demuxer that allows resuming the analysis of a stream mid-flight, for
+// instance after a restart, without waiting for the next PAT and PMTs
+// It only holds exported values and can be encoded with encoding/json or encoding/gob.
+type DemuxerState struct {
+	PAT        *DemuxerStatePSI  // Last PAT
+	PMTs       []DemuxerStatePSI // Last PMTs, sorted by PID and program number
+	ProgramMap map[uint16]uint16 // Indexed by program map PID
+}
+
+// DemuxerStatePSI represents a PSI table of a demuxer state
+type DemuxerStatePSI struct {
+	PAT           *PATData
+	PID           uint16
+	PMT           *PMTData
+	VersionNumber uint8
+}
+
+// OptState returns the option to restore a state exported by (*Demuxer).State() during a previous session
+// The restored PAT and PMTs are returned by NextData before any other data. Since the packets they have been parsed
+// from are gone, their first packet is a placeholder holding only a header with their PID.
+func OptState(s DemuxerState) func(*Demuxer) {
+	return func(d *Demuxer) {
+		// Program map
+		for pid, number := range s.ProgramMap {
+			d.programMap.Set(pid, number)
+		}
+
+		// PAT
+		if s.PAT != nil && s.PAT.PAT != nil {
+			d.lastPAT = &Data{FirstPacket: newDemuxerStatePacket(s.PAT.PID), PAT: s.PAT.PAT, PID: s.PAT.PID}
+			d.psiVersions[s.PAT.PID] = s.PAT.VersionNumber
+			d.dataBuffer = append(d.dataBuffer, d.lastPAT)
+		}
+
+		// PMTs
+		for _, v := range s.PMTs {
+			if v.PMT == nil {
+				continue
+			}
+			d.lastPMTs[v.PMT.ProgramNumber] = &Data{FirstPacket: newDemuxerStatePacket(v.PID), PID: v.PID, PMT: v.PMT}
+			d.pmtVersions[v.PMT.ProgramNumber] = v.VersionNumber
+			d.psiVersions[v.PID] = v.VersionNumber
+			d.dataBuffer = append(d.dataBuffer, d.lastPMTs[v.PMT.ProgramNumber])
+		}
+	}
+}
+
+// newDemuxerStatePacket creates the placeholder first packet of a restored PSI table
+func newDemuxerStatePacket(pid uint16) *Packet {
+	return &Packet{Header: &PacketHeader{
+		HasPayload:                true,
+		PayloadUnitStartIndicator: true,
+		PID:                       pid,
+	}}
+}
+
+// State returns a snapshot of the demuxer state
+// It must not be called while the demuxer is reading.
+func (dmx *Demuxer) State() (s DemuxerState) {
+	// Init
+	s = DemuxerState{ProgramMap: dmx.programMap.Map()}
+
+	// PAT
+	if dmx.lastPAT != nil {
+		s.PAT = &DemuxerStatePSI{
+			PAT:           dmx.lastPAT.PAT,
+			PID:           dmx.lastPAT.PID,
+			VersionNumber: dmx.psiVersions[dmx.lastPAT.PID],
+		}
+	}
+
+	// PMTs
+	for _, d := range dmx.sortedLastPMTs() {
+		s.PMTs = append(s.PMTs, DemuxerStatePSI{
+			PID:           d.PID,
+			PMT:           d.PMT,
+			VersionNumber: dmx.pmtVersions[d.PMT.ProgramNumber],
+		})
+	}
+	return
+}
+
+// sortedLastPMTs returns the last PMTs sorted by PID and program number
+func (dmx *Demuxer) sortedLastPMTs() (ds []*Data) {
+	for _, d := range dmx.lastPMTs {
+		ds = append(ds, d)
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		if ds[i].PID != ds[j].PID {
+			return ds[i].PID < ds[j].PID
+		}
+		return ds[i].PMT.ProgramNumber < ds[j].PMT.ProgramNumber
+	})
+	return
+}
+
+// PSIVersionNumber returns the version number of the last PAT or PMT seen on a PID
+func (dmx *Demuxer) PSIVersionNumber(pid uint16) (v uint8, ok bool) {
+	v, ok = dmx.psiVersions[pid]
+	return
+}
+
+// PMTVersionNumber returns the version number of the last PMT seen for a program, which, unlike PSIVersionNumber,
+// is reliable when several PMTs share a PID
+func (dmx *Demuxer) PMTVersionNumber(programNumber uint16) (v uint8, ok bool) {
+	v, ok = dmx.pmtVersions[programNumber]
+	return
+}
+
+// psiDataVersionNumber returns the version number of the section a data has been parsed from, falling back on the
+// first section starting in its first packet for data built by custom parsers
+func psiDataVersionNumber(d *Data) (v uint8, ok bool) {
+	if d.Section != nil && d.Section.Syntax != nil && d.Section.Syntax.Header != nil {
+		return d.Section.Syntax.Header.VersionNumber, true
+	}
+	return psiPacketVersionNumber(d.FirstPacket)
+}
+
+// psiPacketVersionNumber returns the version number of the first section starting in a packet
+func psiPacketVersionNumber(p *Packet) (v uint8, ok bool) {
+	// No section start
+	if p == nil || !p.Header.PayloadUnitStartIndicator || len(p.Payload) == 0 {
+		return
+	}
+
+	// Section has no syntax
+	o := 1 + int(p.Payload[0])
+	if len(p.Payload) < o+6 || p.Payload[o+1]&0x80 == 0 {
+		return
+	}
+	return p.Payload[o+5] >> 1 & 0x1f, true
+}
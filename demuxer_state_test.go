@@ -0,0 +1,111 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemuxerState(t *testing.T) {
+	// Mux
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 256, StreamType: StreamTypeH264Video}}}))
+	_, err := m.WriteData(muxerTestData(256, 1))
+	assert.NoError(t, err)
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 2, Streams: []MuxStream{{PID: 257, StreamType: StreamTypeH264Video}}}))
+	_, err = m.WriteData(muxerTestData(256, 2))
+	assert.NoError(t, err)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err = dmx.NextData(); err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+	}
+	v, ok := dmx.PSIVersionNumber(PIDPAT)
+	assert.True(t, ok)
+	assert.Equal(t, uint8(1), v)
+	s := dmx.State()
+	assert.Equal(t, map[uint16]uint16{0x1000: 1, 0x1001: 2}, s.ProgramMap)
+	assert.Equal(t, uint8(1), s.PAT.VersionNumber)
+	assert.Len(t, s.PAT.PAT.Programs, 2)
+	assert.Len(t, s.PMTs, 2)
+	assert.Equal(t, uint16(0x1000), s.PMTs[0].PID)
+	assert.Equal(t, uint8(0), s.PMTs[0].VersionNumber)
+	assert.Equal(t, uint16(2), s.PMTs[1].PMT.ProgramNumber)
+
+	// Encode
+	b, err := json.Marshal(s)
+	assert.NoError(t, err)
+	var r DemuxerState
+	assert.NoError(t, json.Unmarshal(b, &r))
+
+	// Restore
+	dmx = New(context.Background(), bytes.NewReader(nil), OptState(r))
+	assert.Equal(t, s, dmx.State())
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, s.PAT.PAT, d.PAT)
+	assert.Equal(t, uint16(PIDPAT), d.FirstPacket.Header.PID)
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, s.PMTs[0].PMT, d.PMT)
+	assert.Equal(t, uint16(0x1000), d.FirstPacket.Header.PID)
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x1001), d.PID)
+}
+
+func TestDemuxerSharedPMTPID(t *testing.T) {
+	// Two PMTs sharing a PID in the same payload
+	b := make([]byte, MpegTsPacketSize-4)
+	n, err := (&PSIData{Sections: []*PSISection{
+		{
+			Header: &PSISectionHeader{SectionSyntaxIndicator: true, TableID: 2},
+			Syntax: &PSISectionSyntax{
+				Data:   &PSISectionSyntaxData{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 256, StreamType: StreamTypeH264Video}}, PCRPID: 256, ProgramNumber: 1}},
+				Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 1, VersionNumber: 2},
+			},
+		},
+		{
+			Header: &PSISectionHeader{SectionSyntaxIndicator: true, TableID: 2},
+			Syntax: &PSISectionSyntax{
+				Data:   &PSISectionSyntaxData{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 257, StreamType: StreamTypeH264Video}}, PCRPID: 257, ProgramNumber: 2}},
+				Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 2, VersionNumber: 5},
+			},
+		},
+	}}).Serialise(b)
+	assert.NoError(t, err)
+	pm := NewProgramMap()
+	pm.Set(0x1000, 1)
+	ds, err := ParseData([]*Packet{{Header: &PacketHeader{PID: 0x1000, PayloadUnitStartIndicator: true}, Payload: b[:n]}}, nil, pm)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 2)
+	assert.Equal(t, uint16(1), ds[0].PMT.ProgramNumber)
+	assert.Equal(t, uint16(2), ds[1].PMT.ProgramNumber)
+
+	// Demuxer keeps both
+	dmx := New(context.Background(), nil)
+	dmx.updateData(ds)
+	v, ok := dmx.PMTVersionNumber(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint8(2), v)
+	v, ok = dmx.PMTVersionNumber(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint8(5), v)
+	_, ok = dmx.PMTVersionNumber(3)
+	assert.False(t, ok)
+	s := dmx.State()
+	assert.Len(t, s.PMTs, 2)
+	assert.Equal(t, uint16(1), s.PMTs[0].PMT.ProgramNumber)
+	assert.Equal(t, uint8(2), s.PMTs[0].VersionNumber)
+	assert.Equal(t, uint16(2), s.PMTs[1].PMT.ProgramNumber)
+	assert.Equal(t, uint8(5), s.PMTs[1].VersionNumber)
+	assert.Equal(t, []*Data{ds[0], ds[1]}, dmx.sortedLastPMTs())
+}
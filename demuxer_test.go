@@ -3,7 +3,9 @@ package astits
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/asticode/go-astikit"
@@ -13,9 +15,41 @@ import (
 func TestDemuxerNew(t *testing.T) {
 	ps := 1
 	pp := func(ps []*Packet) (ds []*Data, skip bool, err error) { return }
-	dmx := New(context.Background(), nil, OptPacketSize(ps), OptPacketsParser(pp))
+	lt := NewPCRLeadTracker(0)
+	st := NewAVSyncTracker(0, nil)
+	ah := func(au *AccessUnit) {}
+	tl := NewPCRTimeline(0)
+	tt := NewTEMITimeline()
+	cv := NewPESCRCValidator(nil)
+	sct := NewScramblingTracker()
+	sdb := NewServiceDB()
+	dh := func(e DropEvent) {}
+	dc := NewDSMCCCarousel()
+	ph := func(e PCREvent) {}
+	scc := NewStreamConsistencyChecker(nil)
+	stt := NewSubtableTracker()
+	dmx := New(context.Background(), nil, OptPacketSize(ps), OptPacketsParser(pp), OptPCRLeadTracker(lt), OptAVSyncTracker(st), OptAccessUnitHandler(ah), OptPCRTimeline(tl), OptTEMITimeline(tt), OptPESCRCValidator(cv), OptScramblingTracker(sct), OptServiceDB(sdb), OptDropHandler(dh), OptDSMCCCarousel(dc), OptMaxDataBuffer(2), OptMaxPacketPoolSize(3), OptProfile(ProfileATSC), OptStreamConsistencyChecker(scc), OptSubtableTracker(stt), OptPCRHandler(ph), OptPCRData(true))
 	assert.Equal(t, ps, dmx.optPacketSize)
-	assert.Equal(t, fmt.Sprintf("%p", pp), fmt.Sprintf("%p", dmx.optPacketsParser))
+	assert.Equal(t, fmt.Sprintf("%p", ah), fmt.Sprintf("%p", dmx.optAccessUnitHandler))
+	assert.Equal(t, st, dmx.optAVSyncTracker)
+	assert.Equal(t, lt, dmx.optPCRLeadTracker)
+	assert.Equal(t, tl, dmx.optPCRTimeline)
+	assert.Equal(t, tt, dmx.optTEMITimeline)
+	assert.Equal(t, cv, dmx.optPESCRCValidator)
+	assert.Equal(t, sct, dmx.optScramblingTracker)
+	assert.Equal(t, sdb, dmx.optServiceDB)
+	assert.Equal(t, fmt.Sprintf("%p", dh), fmt.Sprintf("%p", dmx.optDropHandler))
+	assert.Equal(t, dc, dmx.optDSMCCCarousel)
+	assert.Equal(t, 2, dmx.optMaxDataBuffer)
+	assert.Equal(t, 3, dmx.optMaxPacketPoolSize)
+	assert.Equal(t, 3, dmx.packetPool.maxSize)
+	assert.Equal(t, ProfileATSC, dmx.optProfile)
+	assert.Equal(t, scc, dmx.optStreamConsistency)
+	assert.Equal(t, stt, dmx.optSubtableTracker)
+	assert.Equal(t, fmt.Sprintf("%p", ph), fmt.Sprintf("%p", dmx.optPCRHandler))
+	assert.True(t, dmx.optPCRData)
+	assert.Equal(t, 1, dmx.optInterceptors.Len())
+	assert.Equal(t, fmt.Sprintf("%p", pp), fmt.Sprintf("%p", dmx.optInterceptors.is[0].p))
 }
 
 func TestDemuxerNextPacket(t *testing.T) {
@@ -51,6 +85,86 @@ func TestDemuxerNextPacket(t *testing.T) {
 	assert.EqualError(t, err, ErrNoMorePackets.Error())
 }
 
+func TestDemuxerPacketMiddlewares(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	for idx, pid := range []uint16{1, 2, 1, 3} {
+		h := *packetHeader
+		h.PID = pid
+		b, _ := packet(h, *packetAdaptationField, []byte{byte(idx)})
+		w.Write(b)
+	}
+	var count int
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptPacketMiddlewares(
+		func(p *Packet) (drop bool, err error) {
+			count++
+			return
+		},
+		PIDFilterPacketMiddleware(1, 3),
+		func(p *Packet) (drop bool, err error) {
+			p.Header.TransportPriority = false
+			return
+		},
+	))
+
+	// Packets
+	var pids []uint16
+	for {
+		p, err := dmx.NextPacket()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		assert.False(t, p.Header.TransportPriority)
+		pids = append(pids, p.Header.PID)
+	}
+	assert.Equal(t, []uint16{1, 1, 3}, pids)
+	assert.Equal(t, 4, count)
+
+	// Error
+	dmx = New(context.Background(), bytes.NewReader(buf.Bytes()), OptPacketMiddlewares(func(p *Packet) (drop bool, err error) {
+		err = errors.New("test")
+		return
+	}))
+	_, err := dmx.NextPacket()
+	assert.Error(t, err)
+}
+
+func TestDemuxerRecorder(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	var bs [][]byte
+	for idx, pid := range []uint16{1, 2, 1} {
+		h := *packetHeader
+		h.PID = pid
+		b, _ := packet(h, *packetAdaptationField, []byte{byte(idx)})
+		w.Write(b)
+		bs = append(bs, b)
+	}
+
+	// All PIDs
+	rec := &bytes.Buffer{}
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptRecorder(rec), OptPacketMiddlewares(PIDFilterPacketMiddleware(2)))
+	for {
+		if _, err := dmx.NextPacket(); err != nil {
+			break
+		}
+	}
+	assert.Equal(t, buf.Bytes(), rec.Bytes())
+
+	// PID filter
+	rec.Reset()
+	dmx = New(context.Background(), bytes.NewReader(buf.Bytes()), OptRecorder(rec, 1))
+	for {
+		if _, err := dmx.NextPacket(); err != nil {
+			break
+		}
+	}
+	assert.Equal(t, append(append([]byte{}, bs[0]...), bs[2]...), rec.Bytes())
+}
+
 func TestDemuxerNextData(t *testing.T) {
 	// Init
 	buf := &bytes.Buffer{}
@@ -87,6 +201,140 @@ func TestDemuxerNextData(t *testing.T) {
 	assert.EqualError(t, err, ErrNoMorePackets.Error())
 }
 
+func TestDemuxerStream(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	b := psiBytes()
+	b1, _ := packet(PacketHeader{ContinuityCounter: uint8(0), PayloadUnitStartIndicator: true, PID: PIDPAT}, PacketAdaptationField{}, b[:147])
+	w.Write(b1)
+	b2, _ := packet(PacketHeader{ContinuityCounter: uint8(1), PID: PIDPAT}, PacketAdaptationField{}, b[147:])
+	w.Write(b2)
+
+	// All data
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptStreamBufferSize(1))
+	dc, ec := dmx.Stream(context.Background())
+	var ds []*Data
+	for d := range dc {
+		removeOriginalBytesFromData(d)
+		d.FirstPacket = nil
+		ds = append(ds, d)
+	}
+	assert.NoError(t, <-ec)
+	assert.Equal(t, psi.toData(nil, PIDPAT), ds)
+
+	// Cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	dmx = New(context.Background(), bytes.NewReader(buf.Bytes()), OptStreamBufferSize(0))
+	dc, ec = dmx.Stream(ctx)
+	<-dc
+	cancel()
+	for range dc {
+	}
+	assert.NoError(t, <-ec)
+
+	// Error
+	dmx = New(context.Background(), bytes.NewReader([]byte("invalid")))
+	dc, ec = dmx.Stream(context.Background())
+	for range dc {
+	}
+	assert.Error(t, <-ec)
+}
+
+func TestDemuxerSeek(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	b := psiBytes()
+	b1, _ := packet(PacketHeader{ContinuityCounter: uint8(0), PayloadUnitStartIndicator: true, PID: PIDPAT}, PacketAdaptationField{}, b[:147])
+	w.Write(b1)
+	b2, _ := packet(PacketHeader{ContinuityCounter: uint8(1), PID: PIDPAT}, PacketAdaptationField{}, b[147:])
+	w.Write(b2)
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptSeekReplayPSI(true))
+
+	// Read everything
+	var ds []*Data
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		ds = append(ds, d)
+	}
+
+	// Seek
+	n, err := dmx.Seek(200, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(192), n)
+	assert.Equal(t, 0, len(dmx.packetPool.b))
+	assert.Equal(t, 192, dmx.packetBuffer.packetSize)
+
+	// PSI is replayed
+	var pat, pmt *Data
+	for _, d := range ds {
+		if d.PAT != nil {
+			pat = d
+		} else if d.PMT != nil {
+			pmt = d
+		}
+	}
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, pat, d)
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, pmt, d)
+
+	// Reader is not seekable
+	_, err = New(context.Background(), &bytes.Buffer{}).Seek(0, io.SeekStart)
+	assert.Equal(t, ErrReaderNotSeekable, err)
+}
+
+func TestDemuxerSeekBeforeFirstRead(t *testing.T) {
+	// Init
+	var b []byte
+	for idx := 0; idx < 10; idx++ {
+		pb := make([]byte, MpegTsPacketSize)
+		_, err := (&Packet{Header: &PacketHeader{HasPayload: true, PID: uint16(16 + idx)}, Payload: make([]byte, 184)}).Serialise(pb)
+		assert.NoError(t, err)
+		b = append(b, pb...)
+	}
+
+	// Packet size is auto detected
+	dmx := New(context.Background(), bytes.NewReader(b))
+	n, err := dmx.Seek(MpegTsPacketSize*5+3, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(MpegTsPacketSize*5), n)
+	p, err := dmx.NextPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(21), p.Header.PID)
+	assert.Equal(t, int64(MpegTsPacketSize*5), dmx.packetBuffer.lastOffset)
+}
+
+func TestDemuxerProgramMap(t *testing.T) {
+	// Init
+	b := make([]byte, MpegTsPacketSize)
+	p := &Packet{Header: &PacketHeader{HasPayload: true, PayloadUnitStartIndicator: true, PID: 0x1000}, Payload: make([]byte, 184)}
+	_, err := (&PSIData{Sections: []*PSISection{newMuxPMTSection(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 256, StreamType: StreamTypeH264Video}}})}}).Serialise(p.Payload)
+	assert.NoError(t, err)
+	_, err = p.Serialise(b)
+	assert.NoError(t, err)
+	b = append(b, b...)
+
+	// PMT PID is unknown until the next PAT
+	dmx := New(context.Background(), bytes.NewReader(b))
+	_, err = dmx.NextData()
+	assert.Equal(t, ErrNoMorePackets, err)
+
+	// Pre-seeded
+	dmx = New(context.Background(), bytes.NewReader(b), OptProgramMap(map[uint16]uint16{0x1000: 1}))
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+	assert.Equal(t, map[uint16]uint16{0x1000: 1}, dmx.ProgramMap())
+}
+
 func TestDemuxerRewind(t *testing.T) {
 	r := bytes.NewReader([]byte("content"))
 	dmx := New(context.Background(), r)
@@ -115,6 +363,11 @@ func removeOriginalBytesFromData(d *Data) {
 			}
 		}
 	}
+	if d.CAT != nil {
+		for j := range d.CAT.Descriptors {
+			d.CAT.Descriptors[j].originalBytes = nil
+		}
+	}
 	if d.EIT != nil {
 		for j := range d.EIT.Events {
 			for k := range d.EIT.Events[j].Descriptors {
@@ -144,4 +397,114 @@ func removeOriginalBytesFromData(d *Data) {
 			d.TOT.Descriptors[k].originalBytes = nil
 		}
 	}
+	if d.MGT != nil {
+		for j := range d.MGT.Tables {
+			for k := range d.MGT.Tables[j].Descriptors {
+				d.MGT.Tables[j].Descriptors[k].originalBytes = nil
+			}
+		}
+		for l := range d.MGT.Descriptors {
+			d.MGT.Descriptors[l].originalBytes = nil
+		}
+	}
+	if d.ATSCEIT != nil {
+		for j := range d.ATSCEIT.Events {
+			for k := range d.ATSCEIT.Events[j].Descriptors {
+				d.ATSCEIT.Events[j].Descriptors[k].originalBytes = nil
+			}
+		}
+	}
+	if d.RRT != nil {
+		for k := range d.RRT.Descriptors {
+			d.RRT.Descriptors[k].originalBytes = nil
+		}
+	}
+	if d.TSDT != nil {
+		for k := range d.TSDT.Descriptors {
+			d.TSDT.Descriptors[k].originalBytes = nil
+		}
+	}
+	if d.SIT != nil {
+		for j := range d.SIT.Services {
+			for k := range d.SIT.Services[j].Descriptors {
+				d.SIT.Services[j].Descriptors[k].originalBytes = nil
+			}
+		}
+		for l := range d.SIT.TransmissionDescriptors {
+			d.SIT.TransmissionDescriptors[l].originalBytes = nil
+		}
+	}
+	if d.STT != nil {
+		for k := range d.STT.Descriptors {
+			d.STT.Descriptors[k].originalBytes = nil
+		}
+	}
+	if d.VCT != nil {
+		for j := range d.VCT.Channels {
+			for k := range d.VCT.Channels[j].Descriptors {
+				d.VCT.Channels[j].Descriptors[k].originalBytes = nil
+			}
+		}
+		for l := range d.VCT.AdditionalDescriptors {
+			d.VCT.AdditionalDescriptors[l].originalBytes = nil
+		}
+	}
+}
+
+func TestDemuxerMaxDataBuffer(t *testing.T) {
+	var es []DropEvent
+	dmx := New(context.Background(), nil, OptMaxDataBuffer(1), OptDropHandler(func(e DropEvent) { es = append(es, e) }))
+	d1, d2, d3 := &Data{PID: 1}, &Data{PID: 2}, &Data{PID: 3}
+	d := dmx.updateData([]*Data{d1, d2, d3})
+	assert.Equal(t, d1, d)
+	assert.Equal(t, []*Data{d3}, dmx.dataBuffer)
+	assert.Equal(t, []DropEvent{{Data: d2, PID: 2, Reason: DropReasonDataBufferFull}}, es)
+}
+
+func TestDemuxerPCROnlyPID(t *testing.T) {
+	// Init
+	var b []byte
+	for idx, p := range []*Packet{
+		{Header: &PacketHeader{HasPayload: true, PayloadUnitStartIndicator: true, PID: 256}, Payload: []byte{0x0, 0x0, 0x1, 0xe0, 0x0, 0x0, 0x80, 0x0, 0x0}},
+		{AdaptationField: &PacketAdaptationField{HasPCR: true, Length: 183, PCR: &ClockReference{Base: 1}}, Header: &PacketHeader{HasAdaptationField: true, PID: 257}},
+		{AdaptationField: &PacketAdaptationField{DiscontinuityIndicator: true, HasPCR: true, Length: 183, PCR: &ClockReference{Base: 2}}, Header: &PacketHeader{HasAdaptationField: true, PID: 257}},
+		{Header: &PacketHeader{ContinuityCounter: 1, HasPayload: true, PayloadUnitStartIndicator: true, PID: 256}, Payload: []byte{0x0, 0x0, 0x1, 0xe0, 0x0, 0x0, 0x80, 0x0, 0x0}},
+	} {
+		pb := make([]byte, MpegTsPacketSize)
+		_, err := p.Serialise(pb)
+		assert.NoError(t, err, "packet #%d", idx)
+		b = append(b, pb...)
+	}
+	var es []PCREvent
+	dmx := New(context.Background(), bytes.NewReader(b), OptPCRHandler(func(e PCREvent) { es = append(es, e) }))
+
+	// Next data
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(256), d.PID)
+	assert.NotNil(t, d.PES)
+	assert.Equal(t, []PCREvent{
+		{PCR: &ClockReference{Base: 1}, PID: 257},
+		{Discontinuity: true, PCR: &ClockReference{Base: 2}, PID: 257},
+	}, es)
+	assert.Len(t, dmx.packetPool.b[257], 0)
+
+	// PCR data
+	dmx = New(context.Background(), bytes.NewReader(b), OptPCRData(true))
+	var pcrs []*DataPCR
+	for {
+		d, err = dmx.NextData()
+		if err == ErrNoMorePackets {
+			break
+		}
+		assert.NoError(t, err)
+		if d.PCR != nil {
+			assert.Equal(t, uint16(257), d.PID)
+			pcrs = append(pcrs, d.PCR)
+		}
+	}
+	assert.Equal(t, []*DataPCR{
+		{Offset: 188, PCR: &ClockReference{Base: 1}},
+		{Discontinuity: true, Offset: 376, PCR: &ClockReference{Base: 2}},
+	}, pcrs)
 }
@@ -1,12 +1,17 @@
 package astits
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/asticode/go-astikit"
 )
 
+// errDescriptorSerialisationNotSupported is returned when a descriptor can't be serialised out of its struct
+var errDescriptorSerialisationNotSupported = errors.New("astits: descriptor serialisation not supported")
+
 // Audio types
 // Page: 683 | https://books.google.fr/books?id=6dgWB3-rChYC&printsec=frontcover&hl=fr
 const (
@@ -28,35 +33,190 @@ const (
 // Descriptor tags
 // Chapter: 6.1 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 const (
+	DescriptorTagAAC                        = 0x7c
 	DescriptorTagAC3                        = 0x6a
+	DescriptorTagAdaptationFieldData        = 0x70
+	DescriptorTagAncillaryData              = 0x6b
+	DescriptorTagAnnouncementSupport        = 0x6e
+	DescriptorTagApplicationSignalling      = 0x6f
 	DescriptorTagAVCVideo                   = 0x28
+	DescriptorTagBouquetName                = 0x47
+	DescriptorTagCA                         = 0x9
+	DescriptorTagCableDeliverySystem        = 0x44
+	DescriptorTagCAIdentifier               = 0x53
+	DescriptorTagCellFrequencyLink          = 0x6d
+	DescriptorTagCellList                   = 0x6c
 	DescriptorTagComponent                  = 0x50
 	DescriptorTagContent                    = 0x54
+	DescriptorTagContentIdentifier          = 0x76
+	DescriptorTagCopyright                  = 0xd
+	DescriptorTagCountryAvailability        = 0x49
+	DescriptorTagDataBroadcast              = 0x64
+	DescriptorTagDataBroadcastID            = 0x66
 	DescriptorTagDataStreamAlignment        = 0x6
+	DescriptorTagDefaultAuthority           = 0x73
+	DescriptorTagDSNG                       = 0x68
+	DescriptorTagDTS                        = 0x7b
+	DescriptorTagECMRepetitionRate          = 0x78
 	DescriptorTagEnhancedAC3                = 0x7a
 	DescriptorTagExtendedEvent              = 0x4e
 	DescriptorTagExtension                  = 0x7f
+	DescriptorTagFrequencyList              = 0x62
+	DescriptorTagFTAContentManagement       = 0x7e
+	DescriptorTagHierarchy                  = 0x4
+	DescriptorTagIBP                        = 0x12
 	DescriptorTagISO639LanguageAndAudioType = 0xa
+	DescriptorTagLinkage                    = 0x4a
 	DescriptorTagLocalTimeOffset            = 0x58
 	DescriptorTagMaximumBitrate             = 0xe
+	DescriptorTagMetadata                   = 0x26
+	DescriptorTagMetadataPointer            = 0x25
+	DescriptorTagMetadataSTD                = 0x27
+	DescriptorTagMosaic                     = 0x51
+	DescriptorTagMPEG4Video                 = 0x1b
+	DescriptorTagMultilingualBouquetName    = 0x5c
+	DescriptorTagMultilingualComponent      = 0x5e
+	DescriptorTagMultilingualNetworkName    = 0x5b
+	DescriptorTagMultilingualServiceName    = 0x5d
 	DescriptorTagNetworkName                = 0x40
+	DescriptorTagNVODReference              = 0x4b
 	DescriptorTagParentalRating             = 0x55
+	DescriptorTagPartialTransportStream     = 0x63
+	DescriptorTagPDC                        = 0x69
 	DescriptorTagPrivateDataIndicator       = 0xf
 	DescriptorTagPrivateDataSpecifier       = 0x5f
 	DescriptorTagRegistration               = 0x5
+	DescriptorTagRelatedContent             = 0x74
+	DescriptorTagS2SatelliteDeliverySystem  = 0x79
+	DescriptorTagSatelliteDeliverySystem    = 0x43
+	DescriptorTagScrambling                 = 0x65
 	DescriptorTagService                    = 0x48
+	DescriptorTagServiceAvailability        = 0x72
+	DescriptorTagServiceIdentifier          = 0x71
+	DescriptorTagServiceList                = 0x41
+	DescriptorTagServiceMove                = 0x60
 	DescriptorTagShortEvent                 = 0x4d
+	DescriptorTagShortSmoothingBuffer       = 0x61
+	DescriptorTagSmoothingBuffer            = 0x10
+	DescriptorTagSTD                        = 0x11
 	DescriptorTagStreamIdentifier           = 0x52
+	DescriptorTagStuffing                   = 0x42
 	DescriptorTagSubtitling                 = 0x59
+	DescriptorTagSystemClock                = 0xb
+	DescriptorTagTelephone                  = 0x57
 	DescriptorTagTeletext                   = 0x56
+	DescriptorTagTerrestrialDeliverySystem  = 0x5a
+	DescriptorTagTimeShiftedEvent           = 0x4f
+	DescriptorTagTimeShiftedService         = 0x4c
+	DescriptorTagTimeSliceFECIdentifier     = 0x77
+	DescriptorTagTransportStream            = 0x67
+	DescriptorTagTVAID                      = 0x75
 	DescriptorTagVBIData                    = 0x45
 	DescriptorTagVBITeletext                = 0x46
+	DescriptorTagXAITLocation               = 0x7d
 )
 
 // Descriptor extension tags
 // Chapter: 6.3 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 const (
+	DescriptorTagExtensionAC4                = 0x15
+	DescriptorTagExtensionCP                 = 0x2
+	DescriptorTagExtensionImageIcon          = 0x0
+	DescriptorTagExtensionServiceRelocated   = 0x5
 	DescriptorTagExtensionSupplementaryAudio = 0x6
+	DescriptorTagExtensionURILinkage         = 0x13
+)
+
+// AC-4 channel modes
+// Chapter: D.7 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	AC4ChannelModeMono         = 0x0
+	AC4ChannelModeStereo       = 0x1
+	AC4ChannelModeMultichannel = 0x2
+)
+
+// Hierarchy types
+// Chapter: 2.6.7 | Link: https://www.itu.int/rec/T-REC-H.222.0
+const (
+	HierarchyTypeSpatialScalability   = 0x1
+	HierarchyTypeSNRScalability       = 0x2
+	HierarchyTypeTemporalScalability  = 0x3
+	HierarchyTypeDataPartitioning     = 0x4
+	HierarchyTypeExtensionBitstream   = 0x5
+	HierarchyTypePrivateStream        = 0x6
+	HierarchyTypeMultiViewProfile     = 0x7
+	HierarchyTypeCombinedScalability  = 0x8
+	HierarchyTypeMVCVideoSubBitstream = 0x9
+	HierarchyTypeBaseLayer            = 0xf
+)
+
+// Image icon transport modes
+// Chapter: 6.4.7 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	ImageIconTransportModeData = 0x0
+	ImageIconTransportModeURL  = 0x1
+)
+
+// URI linkage types
+// Chapter: 6.4.15 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	URILinkageTypeOnlineSDT = 0x0
+	URILinkageTypeIPTVSDnS  = 0x1
+	URILinkageTypeMRS       = 0x2
+	URILinkageTypeDVBI      = 0x3
+)
+
+// Announcement reference types
+// Chapter: 6.2.3 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	AnnouncementReferenceTypeAudioStreamInUsualService    = 0x0
+	AnnouncementReferenceTypeSeparateAudioStreamInService = 0x1
+	AnnouncementReferenceTypeSeparateService              = 0x2
+	AnnouncementReferenceTypeAudioStreamInSeparateService = 0x3
+)
+
+// Linkage types
+// Chapter: 6.2.19 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	LinkageTypeInformationService          = 0x1
+	LinkageTypeEPGService                  = 0x2
+	LinkageTypeCAReplacementService        = 0x3
+	LinkageTypeTSContainingCompleteSI      = 0x4
+	LinkageTypeServiceReplacementService   = 0x5
+	LinkageTypeDataBroadcastService        = 0x6
+	LinkageTypeRCSMap                      = 0x7
+	LinkageTypeMobileHandOver              = 0x8
+	LinkageTypeSystemSoftwareUpdateService = 0x9
+	LinkageTypeTSContainingSSUBATOrNIT     = 0xa
+	LinkageTypeIPMACNotificationService    = 0xb
+	LinkageTypeTSContainingINTBATOrNIT     = 0xc
+	LinkageTypeEventLinkage                = 0xd
+	LinkageTypeExtendedEventLinkageFirst   = 0xe
+	LinkageTypeExtendedEventLinkageLast    = 0x1f
+	LinkageTypeDownstreamApplicationSignal = 0x20
+)
+
+// Mobile hand-over types
+// Chapter: 6.2.19 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	MobileHandOverTypeIdenticalService  = 0x1
+	MobileHandOverTypeLocalVariation    = 0x2
+	MobileHandOverTypeAssociatedService = 0x3
+)
+
+// Private data specifiers
+// Link: https://www.dvbservices.com/identifiers/private_data_spec_id
+const (
+	PrivateDataSpecifierBSkyB  = 0x2
+	PrivateDataSpecifierEACEM  = 0x28 // Also known as EICTA
+	PrivateDataSpecifierNorDig = 0x29
+)
+
+// Private descriptor tags, which are only meaningful within the scope of their private data specifier
+// Link: IEC 62216
+const (
+	DescriptorTagEACEMLogicalChannelNumber   = 0x83
+	DescriptorTagEACEMLogicalChannelNumberV2 = 0x87
 )
 
 // Service types
@@ -90,28 +250,52 @@ const (
 // TODO Handle UTF8
 type Descriptor struct {
 	AC3                        *DescriptorAC3
+	AnnouncementSupport        *DescriptorAnnouncementSupport
+	ApplicationSignalling      *DescriptorApplicationSignalling
 	AVCVideo                   *DescriptorAVCVideo
+	CA                         *DescriptorCA
+	CableDeliverySystem        *DescriptorCableDeliverySystem
 	Component                  *DescriptorComponent
 	Content                    *DescriptorContent
+	Copyright                  *DescriptorCopyright
+	CountryAvailability        *DescriptorCountryAvailability
 	DataStreamAlignment        *DescriptorDataStreamAlignment
 	EnhancedAC3                *DescriptorEnhancedAC3
 	ExtendedEvent              *DescriptorExtendedEvent
 	Extension                  *DescriptorExtension
+	FrequencyList              *DescriptorFrequencyList
+	Hierarchy                  *DescriptorHierarchy
+	IBP                        *DescriptorIBP
 	ISO639LanguageAndAudioType *DescriptorISO639LanguageAndAudioType
 	Length                     uint8
+	Linkage                    *DescriptorLinkage
 	LocalTimeOffset            *DescriptorLocalTimeOffset
+	LogicalChannelNumber       *DescriptorLogicalChannelNumber   // EACEM private descriptor
+	LogicalChannelNumberV2     *DescriptorLogicalChannelNumberV2 // EACEM private descriptor
 	MaximumBitrate             *DescriptorMaximumBitrate
+	Metadata                   *DescriptorMetadata
+	MetadataPointer            *DescriptorMetadataPointer
+	MetadataSTD                *DescriptorMetadataSTD
+	MPEG4Video                 *DescriptorMPEG4Video
 	NetworkName                *DescriptorNetworkName
+	NVODReference              *DescriptorNVODReference
 	ParentalRating             *DescriptorParentalRating
 	PrivateDataIndicator       *DescriptorPrivateDataIndicator
 	PrivateDataSpecifier       *DescriptorPrivateDataSpecifier
+	PrivateDataSpecifierScope  uint32 // Private data specifier in effect in the descriptor loop for this descriptor, 0 if none
 	Registration               *DescriptorRegistration
+	S2SatelliteDeliverySystem  *DescriptorS2SatelliteDeliverySystem
+	SatelliteDeliverySystem    *DescriptorSatelliteDeliverySystem
 	Service                    *DescriptorService
 	ShortEvent                 *DescriptorShortEvent
+	SmoothingBuffer            *DescriptorSmoothingBuffer
+	STD                        *DescriptorSTD
 	StreamIdentifier           *DescriptorStreamIdentifier
 	Subtitling                 *DescriptorSubtitling
+	SystemClock                *DescriptorSystemClock
 	Tag                        uint8 // the tag defines the structure of the contained data following the descriptor length.
 	Teletext                   *DescriptorTeletext
+	TerrestrialDeliverySystem  *DescriptorTerrestrialDeliverySystem
 	Unknown                    *DescriptorUnknown
 	UserDefined                []byte
 	VBIData                    *DescriptorVBIData
@@ -195,6 +379,116 @@ func newDescriptorAC3(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorAC3
 	return
 }
 
+// DescriptorAnnouncementSupport represents an announcement support descriptor
+// Chapter: 6.2.3 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorAnnouncementSupport struct {
+	AnnouncementSupportIndicator uint16 // Bit i is set when announcement type i is supported
+	Items                        []*DescriptorAnnouncementSupportItem
+}
+
+// DescriptorAnnouncementSupportItem represents an announcement support descriptor item
+// Chapter: 6.2.3 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorAnnouncementSupportItem struct {
+	AnnouncementType  uint8
+	ComponentTag      uint8
+	OriginalNetworkID uint16
+	ReferenceType     uint8
+	ServiceID         uint16
+	TransportStreamID uint16
+}
+
+func newDescriptorAnnouncementSupport(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorAnnouncementSupport, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorAnnouncementSupport{AnnouncementSupportIndicator: uint16(bs[0])<<8 | uint16(bs[1])}
+
+	// Loop
+	for i.Offset() < offsetEnd {
+		// Get next byte
+		var b byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// Create item
+		itm := &DescriptorAnnouncementSupportItem{
+			AnnouncementType: b >> 4,
+			ReferenceType:    b & 0x7,
+		}
+
+		// Reference
+		if itm.ReferenceType >= AnnouncementReferenceTypeSeparateAudioStreamInService && itm.ReferenceType <= AnnouncementReferenceTypeAudioStreamInSeparateService {
+			if bs, err = i.NextBytes(7); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+			itm.ComponentTag = bs[6]
+			itm.OriginalNetworkID = uint16(bs[0])<<8 | uint16(bs[1])
+			itm.ServiceID = uint16(bs[4])<<8 | uint16(bs[5])
+			itm.TransportStreamID = uint16(bs[2])<<8 | uint16(bs[3])
+		}
+
+		// Append item
+		d.Items = append(d.Items, itm)
+	}
+	return
+}
+
+// DescriptorApplicationSignalling represents an application signalling descriptor, which announces the elementary
+// stream carrying the AIT of a service
+// Chapter: 5.3.5.1 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type DescriptorApplicationSignalling struct {
+	Items []*DescriptorApplicationSignallingItem
+}
+
+// DescriptorApplicationSignallingItem represents an application signalling descriptor item
+type DescriptorApplicationSignallingItem struct {
+	AITVersionNumber uint8
+	ApplicationType  uint16 // 0x10 for HbbTV
+}
+
+func newDescriptorApplicationSignalling(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorApplicationSignalling, err error) {
+	// Create descriptor
+	d = &DescriptorApplicationSignalling{}
+
+	// Add items
+	for i.Offset() < offsetEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(3); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Append item
+		d.Items = append(d.Items, &DescriptorApplicationSignallingItem{
+			AITVersionNumber: bs[2] & 0x1f,
+			ApplicationType:  uint16(bs[0]&0x7f)<<8 | uint16(bs[1]),
+		})
+	}
+	return
+}
+
+func (d *DescriptorApplicationSignalling) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	for _, itm := range d.Items {
+		if err := w.writeUint16("application type", 0x8000|itm.ApplicationType&0x7fff); err != nil {
+			return w.offset, err
+		}
+		if err := w.writeUint8("AIT version number", 0xe0|itm.AITVersionNumber&0x1f); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
+
 // DescriptorAVCVideo represents an AVC video descriptor
 // No doc found unfortunately, basing the implementation on https://github.com/gfto/bitstream/blob/master/mpeg/psi/desc_28.h
 type DescriptorAVCVideo struct {
@@ -257,6 +551,40 @@ func newDescriptorAVCVideo(i *astikit.BytesIterator) (d *DescriptorAVCVideo, err
 	return
 }
 
+// DescriptorCA represents a conditional access descriptor
+// In the CAT it declares the PID carrying the EMMs of a CA system, whereas in a PMT it declares the PID carrying the
+// ECMs of a CA system for the whole program or for an elementary stream.
+// Chapter: 2.6.16 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorCA struct {
+	CAPID       uint16 // 13 bits
+	CASystemID  uint16
+	PrivateData []byte
+}
+
+func newDescriptorCA(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorCA, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(4); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorCA{
+		CAPID:      uint16(bs[2]&0x1f)<<8 | uint16(bs[3]),
+		CASystemID: uint16(bs[0])<<8 | uint16(bs[1]),
+	}
+
+	// Private data
+	if i.Offset() < offsetEnd {
+		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
 // DescriptorComponent represents a component descriptor
 // Chapter: 6.2.8 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 type DescriptorComponent struct {
@@ -356,6 +684,95 @@ func newDescriptorContent(i *astikit.BytesIterator, offsetEnd int) (d *Descripto
 	return
 }
 
+func (d *DescriptorContent) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	for _, itm := range d.Items {
+		if err := w.writeUint8("content nibbles", itm.ContentNibbleLevel1<<4|itm.ContentNibbleLevel2&0xf); err != nil {
+			return w.offset, err
+		}
+		if err := w.writeUint8("user byte", itm.UserByte); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
+
+// DescriptorCopyright represents a copyright descriptor
+// Chapter: 2.6.24 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorCopyright struct {
+	AdditionalInfo []byte
+	Identifier     uint32 // Obtained from a registration authority, such as the one designated by ISO/IEC JTC 1/SC 29
+}
+
+func newDescriptorCopyright(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorCopyright, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(4); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorCopyright{Identifier: uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])}
+
+	// Additional info
+	if i.Offset() < offsetEnd {
+		if d.AdditionalInfo, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// DescriptorCountryAvailability represents a country availability descriptor
+// Chapter: 6.2.10 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorCountryAvailability struct {
+	// When true, the service is intended for reception in the listed countries only, otherwise it is intended for
+	// reception everywhere but in the listed countries
+	CountryAvailabilityFlag bool
+	CountryCodes            [][]byte // ISO 3166 alpha-3 codes, or ETSI TS 101 162 codes for groups of countries
+}
+
+func newDescriptorCountryAvailability(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorCountryAvailability, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorCountryAvailability{CountryAvailabilityFlag: b&0x80 > 0}
+
+	// Add country codes
+	for i.Offset() < offsetEnd {
+		var bs []byte
+		if bs, err = i.NextBytes(3); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.CountryCodes = append(d.CountryCodes, bs)
+	}
+	return
+}
+
+func (d *DescriptorCountryAvailability) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint8("country availability flag", Btou8(d.CountryAvailabilityFlag)<<7|0x7f); err != nil {
+		return w.offset, err
+	}
+	for _, c := range d.CountryCodes {
+		if len(c) != 3 {
+			return w.offset, fmt.Errorf("astits: country code %q is not 3 bytes long", c)
+		}
+		if err := w.writeBytes("country code", c); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
+
 // DescriptorDataStreamAlignment represents a data stream alignment descriptor
 type DescriptorDataStreamAlignment struct {
 	Type uint8
@@ -609,12 +1026,48 @@ func newDescriptorExtendedEventItem(i *astikit.BytesIterator) (d *DescriptorExte
 	return
 }
 
+func (d *DescriptorExtendedEvent) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint8("descriptor numbers", d.Number<<4|d.LastDescriptorNumber&0xf); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeBytes("ISO 639 language code", d.ISO639LanguageCode); err != nil {
+		return w.offset, err
+	}
+	bs, err := w.next("length of items", 1)
+	if err != nil {
+		return w.offset, err
+	}
+	start := w.offset
+	for _, itm := range d.Items {
+		if err = writeDescriptorLengthBytes(w, "item description", itm.Description); err != nil {
+			return w.offset, err
+		}
+		if err = writeDescriptorLengthBytes(w, "item", itm.Content); err != nil {
+			return w.offset, err
+		}
+	}
+	if w.offset-start > 0xff {
+		return w.offset, fmt.Errorf("astits: length of items %d is bigger than 255", w.offset-start)
+	}
+	bs[0] = uint8(w.offset - start)
+	if err = writeDescriptorLengthBytes(w, "text", d.Text); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
 // DescriptorExtension represents an extension descriptor
 // Chapter: 6.2.16 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 type DescriptorExtension struct {
+	AC4                *DescriptorExtensionAC4
+	CP                 *DescriptorExtensionCP
+	ImageIcon          *DescriptorExtensionImageIcon
+	ServiceRelocated   *DescriptorExtensionServiceRelocated
 	SupplementaryAudio *DescriptorExtensionSupplementaryAudio
 	Tag                uint8
 	Unknown            *[]byte
+	URILinkage         *DescriptorExtensionURILinkage
 }
 
 func newDescriptorExtension(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorExtension, err error) {
@@ -630,11 +1083,36 @@ func newDescriptorExtension(i *astikit.BytesIterator, offsetEnd int) (d *Descrip
 
 	// Switch on tag
 	switch d.Tag {
+	case DescriptorTagExtensionAC4:
+		if d.AC4, err = newDescriptorExtensionAC4(i, offsetEnd); err != nil {
+			err = fmt.Errorf("astits: parsing extension AC-4 descriptor failed: %w", err)
+			return
+		}
+	case DescriptorTagExtensionCP:
+		if d.CP, err = newDescriptorExtensionCP(i, offsetEnd); err != nil {
+			err = fmt.Errorf("astits: parsing extension CP descriptor failed: %w", err)
+			return
+		}
+	case DescriptorTagExtensionImageIcon:
+		if d.ImageIcon, err = newDescriptorExtensionImageIcon(i); err != nil {
+			err = fmt.Errorf("astits: parsing extension image icon descriptor failed: %w", err)
+			return
+		}
+	case DescriptorTagExtensionServiceRelocated:
+		if d.ServiceRelocated, err = newDescriptorExtensionServiceRelocated(i); err != nil {
+			err = fmt.Errorf("astits: parsing extension service relocated descriptor failed: %w", err)
+			return
+		}
 	case DescriptorTagExtensionSupplementaryAudio:
 		if d.SupplementaryAudio, err = newDescriptorExtensionSupplementaryAudio(i, offsetEnd); err != nil {
 			err = fmt.Errorf("astits: parsing extension supplementary audio descriptor failed: %w", err)
 			return
 		}
+	case DescriptorTagExtensionURILinkage:
+		if d.URILinkage, err = newDescriptorExtensionURILinkage(i, offsetEnd); err != nil {
+			err = fmt.Errorf("astits: parsing extension URI linkage descriptor failed: %w", err)
+			return
+		}
 	default:
 		// Get next bytes
 		var b []byte
@@ -649,17 +1127,18 @@ func newDescriptorExtension(i *astikit.BytesIterator, offsetEnd int) (d *Descrip
 	return
 }
 
-// DescriptorExtensionSupplementaryAudio represents a supplementary audio extension descriptor
-// Chapter: 6.4.10 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
-type DescriptorExtensionSupplementaryAudio struct {
-	EditorialClassification uint8
-	HasLanguageCode         bool
-	LanguageCode            []byte
-	MixType                 bool
-	PrivateData             []byte
+// DescriptorExtensionAC4 represents an AC-4 extension descriptor
+// Chapter: D.7 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorExtensionAC4 struct {
+	AdditionalInfo           []byte
+	ChannelMode              uint8 // Only set if HasConfig is true, see AC4ChannelMode*
+	DialogEnhancementEnabled bool  // Only set if HasConfig is true
+	HasConfig                bool
+	HasTOC                   bool
+	TOC                      []byte // ac4_dsi_toc(), only set if HasTOC is true
 }
 
-func newDescriptorExtensionSupplementaryAudio(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorExtensionSupplementaryAudio, err error) {
+func newDescriptorExtensionAC4(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorExtensionAC4, err error) {
 	// Get next byte
 	var b byte
 	if b, err = i.NextByte(); err != nil {
@@ -667,24 +1146,37 @@ func newDescriptorExtensionSupplementaryAudio(i *astikit.BytesIterator, offsetEn
 		return
 	}
 
-	// Init
-	d = &DescriptorExtensionSupplementaryAudio{
-		EditorialClassification: uint8(b >> 2 & 0x1f),
-		HasLanguageCode:         b&0x1 > 0,
-		MixType:                 b&0x80 > 0,
+	// Create descriptor
+	d = &DescriptorExtensionAC4{
+		HasConfig: b&0x80 > 0,
+		HasTOC:    b&0x40 > 0,
 	}
 
-	// Language code
-	if d.HasLanguageCode {
-		if d.LanguageCode, err = i.NextBytes(3); err != nil {
+	// Config
+	if d.HasConfig {
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+		d.ChannelMode = b >> 5 & 0x3
+		d.DialogEnhancementEnabled = b&0x80 > 0
+	}
+
+	// TOC
+	if d.HasTOC {
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+		if d.TOC, err = i.NextBytes(int(b)); err != nil {
 			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
 			return
 		}
 	}
 
-	// Private data
+	// Additional info
 	if i.Offset() < offsetEnd {
-		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+		if d.AdditionalInfo, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
 			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
 			return
 		}
@@ -692,46 +1184,491 @@ func newDescriptorExtensionSupplementaryAudio(i *astikit.BytesIterator, offsetEn
 	return
 }
 
-// DescriptorISO639LanguageAndAudioType represents an ISO639 language descriptor
-// https://github.com/gfto/bitstream/blob/master/mpeg/psi/desc_0a.h
-type DescriptorISO639LanguageAndAudioType struct {
-	Language []byte
-	Type     uint8
+// DescriptorExtensionCP represents a CP extension descriptor
+// Chapter: 6.4.3 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorExtensionCP struct {
+	CPPID       uint16
+	CPSystemID  uint16
+	PrivateData []byte
 }
 
-// In some actual cases, the length is 3 and the language is described in only 2 bytes
-func newDescriptorISO639LanguageAndAudioType(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorISO639LanguageAndAudioType, err error) {
+func newDescriptorExtensionCP(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorExtensionCP, err error) {
 	// Get next bytes
 	var bs []byte
-	if bs, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+	if bs, err = i.NextBytes(4); err != nil {
 		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
 		return
 	}
 
 	// Create descriptor
-	d = &DescriptorISO639LanguageAndAudioType{
-		Language: bs[0 : len(bs)-1],
-		Type:     uint8(bs[len(bs)-1]),
+	d = &DescriptorExtensionCP{
+		CPPID:      uint16(bs[2]&0x1f)<<8 | uint16(bs[3]),
+		CPSystemID: uint16(bs[0])<<8 | uint16(bs[1]),
+	}
+
+	// Private data
+	if i.Offset() < offsetEnd {
+		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
 	}
 	return
 }
 
-// DescriptorLocalTimeOffset represents a local time offset descriptor
-// Chapter: 6.2.20 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
-type DescriptorLocalTimeOffset struct {
-	Items []*DescriptorLocalTimeOffsetItem
+// DescriptorExtensionImageIcon represents an image icon extension descriptor
+// Icon data can be split over several descriptors, in which case only the first one carries the icon properties
+// Chapter: 6.4.7 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorExtensionImageIcon struct {
+	CoordinateSystem     uint8
+	DescriptorNumber     uint8
+	HasPosition          bool
+	IconData             []byte
+	IconHorizontalOrigin uint16
+	IconID               uint8
+	IconTransportMode    uint8
+	IconType             []byte
+	IconVerticalOrigin   uint16
+	LastDescriptorNumber uint8
+	URL                  []byte
 }
 
-// DescriptorLocalTimeOffsetItem represents a local time offset item descriptor
-// Chapter: 6.2.20 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
-type DescriptorLocalTimeOffsetItem struct {
-	CountryCode             []byte
-	CountryRegionID         uint8
-	LocalTimeOffset         time.Duration
-	LocalTimeOffsetPolarity bool
-	NextTimeOffset          time.Duration
-	TimeOfChange            time.Time
-}
+func newDescriptorExtensionImageIcon(i *astikit.BytesIterator) (d *DescriptorExtensionImageIcon, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorExtensionImageIcon{
+		DescriptorNumber:     bs[0] >> 4,
+		IconID:               bs[1] & 0x7,
+		LastDescriptorNumber: bs[0] & 0xf,
+	}
+
+	// Icon properties
+	var b byte
+	if d.DescriptorNumber == 0 {
+		// Get next byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// Transport mode and position
+		d.IconTransportMode = b >> 6
+		d.HasPosition = b&0x20 > 0
+		if d.HasPosition {
+			d.CoordinateSystem = b >> 2 & 0x7
+			if bs, err = i.NextBytes(3); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+			d.IconHorizontalOrigin = uint16(bs[0])<<4 | uint16(bs[1]>>4)
+			d.IconVerticalOrigin = uint16(bs[1]&0xf)<<8 | uint16(bs[2])
+		}
+
+		// Icon type
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+		if d.IconType, err = i.NextBytes(int(b)); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// URL
+		if d.IconTransportMode == ImageIconTransportModeURL {
+			if b, err = i.NextByte(); err != nil {
+				err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+				return
+			}
+			if d.URL, err = i.NextBytes(int(b)); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+			return
+		} else if d.IconTransportMode != ImageIconTransportModeData {
+			return
+		}
+	}
+
+	// Icon data
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+	if d.IconData, err = i.NextBytes(int(b)); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+	return
+}
+
+// DescriptorExtensionServiceRelocated represents a service relocated extension descriptor
+// Chapter: 6.4.9 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorExtensionServiceRelocated struct {
+	OldOriginalNetworkID uint16
+	OldServiceID         uint16
+	OldTransportStreamID uint16
+}
+
+func newDescriptorExtensionServiceRelocated(i *astikit.BytesIterator) (d *DescriptorExtensionServiceRelocated, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(6); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorExtensionServiceRelocated{
+		OldOriginalNetworkID: uint16(bs[0])<<8 | uint16(bs[1]),
+		OldServiceID:         uint16(bs[4])<<8 | uint16(bs[5]),
+		OldTransportStreamID: uint16(bs[2])<<8 | uint16(bs[3]),
+	}
+	return
+}
+
+// DescriptorExtensionURILinkage represents a URI linkage extension descriptor
+// Chapter: 6.4.15 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorExtensionURILinkage struct {
+	HasMinPollingInterval bool
+	MinPollingInterval    uint16 // In units of 2 seconds
+	PrivateData           []byte
+	URI                   []byte
+	URILinkageType        uint8
+}
+
+func newDescriptorExtensionURILinkage(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorExtensionURILinkage, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorExtensionURILinkage{URILinkageType: bs[0]}
+
+	// URI
+	if d.URI, err = i.NextBytes(int(bs[1])); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Min polling interval
+	if d.URILinkageType == URILinkageTypeOnlineSDT || d.URILinkageType == URILinkageTypeIPTVSDnS {
+		if bs, err = i.NextBytes(2); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.HasMinPollingInterval = true
+		d.MinPollingInterval = uint16(bs[0])<<8 | uint16(bs[1])
+	}
+
+	// Private data
+	if i.Offset() < offsetEnd {
+		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// DescriptorExtensionSupplementaryAudio represents a supplementary audio extension descriptor
+// Chapter: 6.4.10 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorExtensionSupplementaryAudio struct {
+	EditorialClassification uint8
+	HasLanguageCode         bool
+	LanguageCode            []byte
+	MixType                 bool
+	PrivateData             []byte
+}
+
+func newDescriptorExtensionSupplementaryAudio(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorExtensionSupplementaryAudio, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Init
+	d = &DescriptorExtensionSupplementaryAudio{
+		EditorialClassification: uint8(b >> 2 & 0x1f),
+		HasLanguageCode:         b&0x1 > 0,
+		MixType:                 b&0x80 > 0,
+	}
+
+	// Language code
+	if d.HasLanguageCode {
+		if d.LanguageCode, err = i.NextBytes(3); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+
+	// Private data
+	if i.Offset() < offsetEnd {
+		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// DescriptorHierarchy represents a hierarchy descriptor, which identifies the layer an elementary stream carries in
+// a hierarchically coded video, audio or private stream
+// Chapter: 2.6.6 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorHierarchy struct {
+	Channel               uint8 // 6 bits, intended channel number in an ordered set of transmission channels
+	EmbeddedLayerIndex    uint8 // 6 bits, layer index of the elementary stream that needs to be decoded before this one
+	LayerIndex            uint8 // 6 bits, unique index of the elementary stream in the program
+	NoQualityScalability  bool
+	NoSpatialScalability  bool
+	NoTemporalScalability bool
+	NoViewScalability     bool
+	TrefPresent           bool  // Set when the TREF field may be present in the PES packet headers, the flag being inverted in the bitstream
+	Type                  uint8 // 4 bits
+}
+
+func newDescriptorHierarchy(i *astikit.BytesIterator) (d *DescriptorHierarchy, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(4); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorHierarchy{
+		Channel:               bs[3] & 0x3f,
+		EmbeddedLayerIndex:    bs[2] & 0x3f,
+		LayerIndex:            bs[1] & 0x3f,
+		NoQualityScalability:  bs[0]&0x10 > 0,
+		NoSpatialScalability:  bs[0]&0x20 > 0,
+		NoTemporalScalability: bs[0]&0x40 > 0,
+		NoViewScalability:     bs[0]&0x80 > 0,
+		TrefPresent:           bs[2]&0x80 == 0,
+		Type:                  bs[0] & 0xf,
+	}
+	return
+}
+
+// DescriptorIBP represents an IBP descriptor, which describes the GOP structure of a video stream
+// Chapter: 2.6.34 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorIBP struct {
+	ClosedGOP    bool   // Set when a group of pictures header is encoded before every I-frame
+	IdenticalGOP bool   // Set when the number of P-frames and B-frames between I-frames is the same throughout the sequence
+	MaxGOPLength uint16 // 14 bits, maximum number of pictures between two I-pictures
+}
+
+func newDescriptorIBP(i *astikit.BytesIterator) (d *DescriptorIBP, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorIBP{
+		ClosedGOP:    bs[0]&0x80 > 0,
+		IdenticalGOP: bs[0]&0x40 > 0,
+		MaxGOPLength: uint16(bs[0]&0x3f)<<8 | uint16(bs[1]),
+	}
+	return
+}
+
+// DescriptorISO639LanguageAndAudioType represents an ISO639 language descriptor
+// https://github.com/gfto/bitstream/blob/master/mpeg/psi/desc_0a.h
+type DescriptorISO639LanguageAndAudioType struct {
+	Language []byte
+	Type     uint8
+}
+
+// In some actual cases, the length is 3 and the language is described in only 2 bytes
+func newDescriptorISO639LanguageAndAudioType(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorISO639LanguageAndAudioType, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorISO639LanguageAndAudioType{
+		Language: bs[0 : len(bs)-1],
+		Type:     uint8(bs[len(bs)-1]),
+	}
+	return
+}
+
+// DescriptorLinkage represents a linkage descriptor
+// Chapter: 6.2.19 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorLinkage struct {
+	EventLinkage      *DescriptorLinkageEventLinkage
+	MobileHandOver    *DescriptorLinkageMobileHandOver
+	OriginalNetworkID uint16
+	PrivateData       []byte // Holds the extended event linkage info as well, for extended event linkage types
+	ServiceID         uint16
+	SSU               *DescriptorLinkageSSU
+	TransportStreamID uint16
+	Type              uint8
+}
+
+// DescriptorLinkageEventLinkage represents a linkage descriptor event linkage info
+type DescriptorLinkageEventLinkage struct {
+	EventSimulcast bool
+	TargetEventID  uint16
+	TargetListed   bool
+}
+
+// DescriptorLinkageMobileHandOver represents a linkage descriptor mobile hand-over info
+type DescriptorLinkageMobileHandOver struct {
+	HandOverType     uint8
+	InitialServiceID uint16 // Only set when origin type is 0, i.e. NIT
+	NetworkID        uint16 // Only set for identical service, local variation and associated service hand-over types
+	OriginType       uint8
+}
+
+// DescriptorLinkageSSU represents a linkage descriptor system software update info
+// Link: https://www.etsi.org/deliver/etsi_ts/102000_102099/102006/01.04.01_60/ts_102006v010401p.pdf
+type DescriptorLinkageSSU struct {
+	Items []*DescriptorLinkageSSUItem
+}
+
+// DescriptorLinkageSSUItem represents a linkage descriptor system software update info item
+type DescriptorLinkageSSUItem struct {
+	OUI          uint32
+	SelectorData []byte
+}
+
+func newDescriptorLinkage(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorLinkage, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(7); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorLinkage{
+		OriginalNetworkID: uint16(bs[2])<<8 | uint16(bs[3]),
+		ServiceID:         uint16(bs[4])<<8 | uint16(bs[5]),
+		TransportStreamID: uint16(bs[0])<<8 | uint16(bs[1]),
+		Type:              bs[6],
+	}
+
+	// Switch on type
+	var b byte
+	switch d.Type {
+	case LinkageTypeMobileHandOver:
+		// Get next byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// Create mobile hand-over
+		d.MobileHandOver = &DescriptorLinkageMobileHandOver{
+			HandOverType: b >> 4,
+			OriginType:   b & 0x1,
+		}
+
+		// Network ID
+		if d.MobileHandOver.HandOverType >= MobileHandOverTypeIdenticalService && d.MobileHandOver.HandOverType <= MobileHandOverTypeAssociatedService {
+			if bs, err = i.NextBytes(2); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+			d.MobileHandOver.NetworkID = uint16(bs[0])<<8 | uint16(bs[1])
+		}
+
+		// Initial service ID
+		if d.MobileHandOver.OriginType == 0 {
+			if bs, err = i.NextBytes(2); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+			d.MobileHandOver.InitialServiceID = uint16(bs[0])<<8 | uint16(bs[1])
+		}
+	case LinkageTypeSystemSoftwareUpdateService:
+		// Get next byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// Loop through items
+		d.SSU = &DescriptorLinkageSSU{}
+		offsetItemsEnd := i.Offset() + int(b)
+		for i.Offset() < offsetItemsEnd {
+			// Get next bytes
+			if bs, err = i.NextBytes(4); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+
+			// Create item
+			itm := &DescriptorLinkageSSUItem{OUI: uint32(bs[0])<<16 | uint32(bs[1])<<8 | uint32(bs[2])}
+
+			// Selector data
+			if itm.SelectorData, err = i.NextBytes(int(bs[3])); err != nil {
+				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+				return
+			}
+
+			// Append item
+			d.SSU.Items = append(d.SSU.Items, itm)
+		}
+	case LinkageTypeEventLinkage:
+		// Get next bytes
+		if bs, err = i.NextBytes(3); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create event linkage
+		d.EventLinkage = &DescriptorLinkageEventLinkage{
+			EventSimulcast: bs[2]&0x40 > 0,
+			TargetEventID:  uint16(bs[0])<<8 | uint16(bs[1]),
+			TargetListed:   bs[2]&0x80 > 0,
+		}
+	}
+
+	// Private data
+	if i.Offset() < offsetEnd {
+		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// DescriptorLocalTimeOffset represents a local time offset descriptor
+// Chapter: 6.2.20 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorLocalTimeOffset struct {
+	Items []*DescriptorLocalTimeOffsetItem
+}
+
+// DescriptorLocalTimeOffsetItem represents a local time offset item descriptor
+// Chapter: 6.2.20 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorLocalTimeOffsetItem struct {
+	CountryCode             []byte
+	CountryRegionID         uint8
+	LocalTimeOffset         time.Duration
+	LocalTimeOffsetPolarity bool
+	NextTimeOffset          time.Duration
+	TimeOfChange            time.Time
+}
 
 func newDescriptorLocalTimeOffset(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorLocalTimeOffset, err error) {
 	// Init
@@ -785,6 +1722,25 @@ func newDescriptorLocalTimeOffset(i *astikit.BytesIterator, offsetEnd int) (d *D
 	return
 }
 
+func (d *DescriptorLocalTimeOffset) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	for idx, itm := range d.Items {
+		if len(itm.CountryCode) != 3 {
+			return w.offset, fmt.Errorf("astits: country code %q is not 3 bytes long", itm.CountryCode)
+		}
+		bs, err := w.next(fmt.Sprintf("local time offset item #%d", idx), 13)
+		if err != nil {
+			return w.offset, err
+		}
+		copy(bs, itm.CountryCode)
+		bs[3] = itm.CountryRegionID<<2 | 0x2 | Btou8(itm.LocalTimeOffsetPolarity)
+		writeDVBDurationMinutes(bs[4:], itm.LocalTimeOffset)
+		writeDVBTime(bs[6:], itm.TimeOfChange)
+		writeDVBDurationMinutes(bs[11:], itm.NextTimeOffset)
+	}
+	return w.offset, nil
+}
+
 // DescriptorMaximumBitrate represents a maximum bitrate descriptor
 type DescriptorMaximumBitrate struct {
 	Bitrate uint32 // In bytes/second
@@ -803,6 +1759,25 @@ func newDescriptorMaximumBitrate(i *astikit.BytesIterator) (d *DescriptorMaximum
 	return
 }
 
+// DescriptorMPEG4Video represents an MPEG-4 video descriptor
+// Chapter: 2.6.36 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorMPEG4Video struct {
+	ProfileAndLevel uint8 // Profile and level of the ISO/IEC 14496-2 video stream, as defined in Table G.1 of ISO/IEC 14496-2
+}
+
+func newDescriptorMPEG4Video(i *astikit.BytesIterator) (d *DescriptorMPEG4Video, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorMPEG4Video{ProfileAndLevel: uint8(b)}
+	return
+}
+
 // DescriptorNetworkName represents a network name descriptor
 // Chapter: 6.2.27 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 type DescriptorNetworkName struct {
@@ -821,6 +1796,43 @@ func newDescriptorNetworkName(i *astikit.BytesIterator, offsetEnd int) (d *Descr
 	return
 }
 
+// DescriptorNVODReference represents an NVOD reference descriptor
+// Chapter: 6.2.26 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorNVODReference struct {
+	Items []*DescriptorNVODReferenceItem
+}
+
+// DescriptorNVODReferenceItem represents an NVOD reference descriptor item
+// Chapter: 6.2.26 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorNVODReferenceItem struct {
+	OriginalNetworkID uint16
+	ServiceID         uint16
+	TransportStreamID uint16
+}
+
+func newDescriptorNVODReference(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorNVODReference, err error) {
+	// Create descriptor
+	d = &DescriptorNVODReference{}
+
+	// Loop
+	for i.Offset() < offsetEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(6); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Append item
+		d.Items = append(d.Items, &DescriptorNVODReferenceItem{
+			OriginalNetworkID: uint16(bs[2])<<8 | uint16(bs[3]),
+			ServiceID:         uint16(bs[4])<<8 | uint16(bs[5]),
+			TransportStreamID: uint16(bs[0])<<8 | uint16(bs[1]),
+		})
+	}
+	return
+}
+
 // DescriptorParentalRating represents a parental rating descriptor
 // Chapter: 6.2.28 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 type DescriptorParentalRating struct {
@@ -865,6 +1877,19 @@ func newDescriptorParentalRating(i *astikit.BytesIterator, offsetEnd int) (d *De
 	return
 }
 
+func (d *DescriptorParentalRating) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	for _, itm := range d.Items {
+		if err := w.writeBytes("country code", itm.CountryCode); err != nil {
+			return w.offset, err
+		}
+		if err := w.writeUint8("rating", itm.Rating); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
+
 // DescriptorPrivateDataIndicator represents a private data Indicator descriptor
 type DescriptorPrivateDataIndicator struct {
 	Indicator uint32
@@ -877,27 +1902,130 @@ func newDescriptorPrivateDataIndicator(i *astikit.BytesIterator) (d *DescriptorP
 		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
 		return
 	}
-
-	// Create descriptor
-	d = &DescriptorPrivateDataIndicator{Indicator: uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])}
+
+	// Create descriptor
+	d = &DescriptorPrivateDataIndicator{Indicator: uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])}
+	return
+}
+
+// DescriptorPrivateDataSpecifier represents a private data specifier descriptor
+type DescriptorPrivateDataSpecifier struct {
+	Specifier uint32
+}
+
+func newDescriptorPrivateDataSpecifier(i *astikit.BytesIterator) (d *DescriptorPrivateDataSpecifier, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(4); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorPrivateDataSpecifier{Specifier: uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])}
+	return
+}
+
+// DescriptorLogicalChannelNumber represents an EACEM logical channel number descriptor
+// Link: IEC 62216
+type DescriptorLogicalChannelNumber struct {
+	Items []*DescriptorLogicalChannelNumberItem
+}
+
+// DescriptorLogicalChannelNumberItem represents an EACEM logical channel number descriptor item
+type DescriptorLogicalChannelNumberItem struct {
+	LogicalChannelNumber uint16
+	ServiceID            uint16
+	VisibleServiceFlag   bool
+}
+
+func newDescriptorLogicalChannelNumberItems(i *astikit.BytesIterator, offsetEnd int) (is []*DescriptorLogicalChannelNumberItem, err error) {
+	for i.Offset() < offsetEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Append item
+		is = append(is, &DescriptorLogicalChannelNumberItem{
+			LogicalChannelNumber: uint16(bs[2]&0x3)<<8 | uint16(bs[3]),
+			ServiceID:            uint16(bs[0])<<8 | uint16(bs[1]),
+			VisibleServiceFlag:   bs[2]&0x80 > 0,
+		})
+	}
+	return
+}
+
+func newDescriptorLogicalChannelNumber(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorLogicalChannelNumber, err error) {
+	d = &DescriptorLogicalChannelNumber{}
+	if d.Items, err = newDescriptorLogicalChannelNumberItems(i, offsetEnd); err != nil {
+		err = fmt.Errorf("astits: parsing items failed: %w", err)
+		return
+	}
 	return
 }
 
-// DescriptorPrivateDataSpecifier represents a private data specifier descriptor
-type DescriptorPrivateDataSpecifier struct {
-	Specifier uint32
+// DescriptorLogicalChannelNumberV2 represents an EACEM logical channel number descriptor version 2
+// Link: IEC 62216
+type DescriptorLogicalChannelNumberV2 struct {
+	ChannelLists []*DescriptorLogicalChannelNumberV2ChannelList
 }
 
-func newDescriptorPrivateDataSpecifier(i *astikit.BytesIterator) (d *DescriptorPrivateDataSpecifier, err error) {
-	// Get next bytes
-	var bs []byte
-	if bs, err = i.NextBytes(4); err != nil {
-		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
-		return
-	}
+// DescriptorLogicalChannelNumberV2ChannelList represents an EACEM logical channel number descriptor version 2
+// channel list
+type DescriptorLogicalChannelNumberV2ChannelList struct {
+	ChannelListID   uint8
+	ChannelListName []byte
+	CountryCode     []byte
+	Items           []*DescriptorLogicalChannelNumberItem
+}
 
+func newDescriptorLogicalChannelNumberV2(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorLogicalChannelNumberV2, err error) {
 	// Create descriptor
-	d = &DescriptorPrivateDataSpecifier{Specifier: uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])}
+	d = &DescriptorLogicalChannelNumberV2{}
+
+	// Loop
+	for i.Offset() < offsetEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(2); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Create channel list
+		l := &DescriptorLogicalChannelNumberV2ChannelList{ChannelListID: bs[0]}
+
+		// Channel list name
+		if l.ChannelListName, err = i.NextBytes(int(bs[1])); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Country code
+		if l.CountryCode, err = i.NextBytes(3); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Get next byte
+		var b byte
+		if b, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// Items
+		if l.Items, err = newDescriptorLogicalChannelNumberItems(i, i.Offset()+int(b)); err != nil {
+			err = fmt.Errorf("astits: parsing items failed: %w", err)
+			return
+		}
+
+		// Append channel list
+		d.ChannelLists = append(d.ChannelLists, l)
+	}
 	return
 }
 
@@ -980,6 +2108,20 @@ func newDescriptorService(i *astikit.BytesIterator) (d *DescriptorService, err e
 	return
 }
 
+func (d *DescriptorService) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint8("service type", d.Type); err != nil {
+		return w.offset, err
+	}
+	if err := writeDescriptorLengthBytes(w, "service provider", d.Provider); err != nil {
+		return w.offset, err
+	}
+	if err := writeDescriptorLengthBytes(w, "service name", d.Name); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
 // DescriptorShortEvent represents a short event descriptor
 // Chapter: 6.2.37 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 type DescriptorShortEvent struct {
@@ -1031,6 +2173,59 @@ func newDescriptorShortEvent(i *astikit.BytesIterator) (d *DescriptorShortEvent,
 	return
 }
 
+func (d *DescriptorShortEvent) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeBytes("language", d.Language); err != nil {
+		return w.offset, err
+	}
+	if err := writeDescriptorLengthBytes(w, "event name", d.EventName); err != nil {
+		return w.offset, err
+	}
+	if err := writeDescriptorLengthBytes(w, "text", d.Text); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
+// DescriptorSmoothingBuffer represents a smoothing buffer descriptor
+// Chapter: 2.6.30 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorSmoothingBuffer struct {
+	LeakRate uint32 // 22 bits, in units of 400 bits/s
+	Size     uint32 // 22 bits, in bytes
+}
+
+func newDescriptorSmoothingBuffer(i *astikit.BytesIterator) (d *DescriptorSmoothingBuffer, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(6); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorSmoothingBuffer{
+		LeakRate: uint32(bs[0]&0x3f)<<16 | uint32(bs[1])<<8 | uint32(bs[2]),
+		Size:     uint32(bs[3]&0x3f)<<16 | uint32(bs[4])<<8 | uint32(bs[5]),
+	}
+	return
+}
+
+// DescriptorSTD represents an STD descriptor
+// Chapter: 2.6.32 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorSTD struct {
+	LeakValid bool // Set when the transfer from the MB to the EB buffers of the T-STD uses the leak method
+}
+
+func newDescriptorSTD(i *astikit.BytesIterator) (d *DescriptorSTD, err error) {
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+	d = &DescriptorSTD{LeakValid: b&0x1 > 0}
+	return
+}
+
 // DescriptorStreamIdentifier represents a stream identifier descriptor
 // Chapter: 6.2.39 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 type DescriptorStreamIdentifier struct{ ComponentTag uint8 }
@@ -1110,6 +2305,40 @@ func newDescriptorSubtitling(i *astikit.BytesIterator, offsetEnd int) (d *Descri
 	return
 }
 
+// DescriptorSystemClock represents a system clock descriptor, which conveys the accuracy of the clock the system
+// clock references have been generated with
+// Chapter: 2.6.20 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorSystemClock struct {
+	ClockAccuracyExponent           uint8 // 3 bits
+	ClockAccuracyInteger            uint8 // 6 bits
+	ExternalClockReferenceIndicator bool  // Set when the system clock has been derived from an external frequency reference
+}
+
+func newDescriptorSystemClock(i *astikit.BytesIterator) (d *DescriptorSystemClock, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorSystemClock{
+		ClockAccuracyExponent:           bs[1] >> 5,
+		ClockAccuracyInteger:            bs[0] & 0x3f,
+		ExternalClockReferenceIndicator: bs[0]&0x80 > 0,
+	}
+	return
+}
+
+// ClockAccuracy returns the clock accuracy in parts per million, which is 30 ppm when the clock accuracy integer is 0
+func (d *DescriptorSystemClock) ClockAccuracy() float64 {
+	if d.ClockAccuracyInteger == 0 {
+		return 30
+	}
+	return float64(d.ClockAccuracyInteger) * math.Pow10(-int(d.ClockAccuracyExponent))
+}
+
 // DescriptorTeletext represents a teletext descriptor
 // Chapter: 6.2.43 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
 type DescriptorTeletext struct {
@@ -1276,192 +2505,516 @@ func parseDescriptors(i *astikit.BytesIterator) (o []*Descriptor, err error) {
 
 	// Loop
 	if length > 0 {
-		offsetEnd := i.Offset() + length
-		for i.Offset() < offsetEnd {
-			// Get next 2 bytes
-			if bs, err = i.NextBytes(2); err != nil {
-				err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
-				return
-			}
+		if o, err = parseDescriptorsUntil(i, i.Offset()+length); err != nil {
+			err = fmt.Errorf("astits: parsing descriptors loop failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// parseDescriptorsUntil parses descriptors until the offset is reached, for loops that are not preceded by their length
+func parseDescriptorsUntil(i *astikit.BytesIterator, offsetEnd int) (o []*Descriptor, err error) {
+	// The private data specifier in effect is scoped to the descriptor loop
+	var bs []byte
+	var privateDataSpecifier uint32
+	for i.Offset() < offsetEnd {
+		// Get next 2 bytes
+		if bs, err = i.NextBytes(2); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
 
-			// Create descriptor
-			d := &Descriptor{
-				Length: uint8(bs[1]),
-				Tag:    uint8(bs[0]),
+		// Create descriptor
+		d := &Descriptor{
+			Length:                    uint8(bs[1]),
+			PrivateDataSpecifierScope: privateDataSpecifier,
+			Tag:                       uint8(bs[0]),
+		}
+
+		// Parse data
+		if d.Length > 0 {
+			// Unfortunately there's no way to be sure the real descriptor length is the same as the one indicated
+			// previously therefore we must fetch bytes in descriptor functions and seek at the end
+			offsetDescriptorEnd := i.Offset() + int(d.Length)
+
+			// <Hack>: assign the original bytes to an internal byte slice for use when reserialising later
+			// TODO fix this to actually serialise the struct
+			origOffset := i.Offset()
+			var origBytes []byte
+			if origBytes, err = i.NextBytes(int(d.Length)); err != nil {
+				err = fmt.Errorf("astits: fetching original bytes failed: %w", err)
+				return
 			}
+			// Can't count on the original byte array persisting, so create a copy
+			d.originalBytes = make([]byte, len(origBytes))
+			copy(d.originalBytes, origBytes)
+			// Reset iterator so parsing can continue
+			i.Seek(origOffset)
+			// </Hack>
+
+			// User defined
+			if d.Tag >= 0x80 && d.Tag <= 0xfe {
+				// Get next bytes
+				if d.UserDefined, err = i.NextBytes(int(d.Length)); err != nil {
+					err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+					return
+				}
 
-			// Parse data
-			if d.Length > 0 {
-				// Unfortunately there's no way to be sure the real descriptor length is the same as the one indicated
-				// previously therefore we must fetch bytes in descriptor functions and seek at the end
-				offsetDescriptorEnd := i.Offset() + int(d.Length)
-
-				// <Hack>: assign the original bytes to an internal byte slice for use when reserialising later
-				// TODO fix this to actually serialise the struct
-				origOffset := i.Offset()
-				var origBytes []byte
-				if origBytes, err = i.NextBytes(int(d.Length)); err != nil {
-					err = fmt.Errorf("astits: fetching original bytes failed: %w", err)
+				// Private descriptors
+				if err = d.parsePrivate(); err != nil {
+					err = fmt.Errorf("astits: parsing private descriptor failed: %w", err)
 					return
 				}
-				// Can't count on the original byte array persisting, so create a copy
-				d.originalBytes = make([]byte, len(origBytes))
-				copy(d.originalBytes, origBytes)
-				// Reset iterator so parsing can continue
-				i.Seek(origOffset)
-				// </Hack>
-
-				// User defined
-				if d.Tag >= 0x80 && d.Tag <= 0xfe {
-					// Get next bytes
-					if d.UserDefined, err = i.NextBytes(int(d.Length)); err != nil {
-						err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			} else {
+				// Switch on tag
+				switch d.Tag {
+				case DescriptorTagAC3:
+					if d.AC3, err = newDescriptorAC3(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing AC3 descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagAnnouncementSupport:
+					if d.AnnouncementSupport, err = newDescriptorAnnouncementSupport(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Announcement Support descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagApplicationSignalling:
+					if d.ApplicationSignalling, err = newDescriptorApplicationSignalling(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Application Signalling descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagAVCVideo:
+					if d.AVCVideo, err = newDescriptorAVCVideo(i); err != nil {
+						err = fmt.Errorf("astits: parsing AVC Video descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagCA:
+					if d.CA, err = newDescriptorCA(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing CA descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagCableDeliverySystem:
+					if d.CableDeliverySystem, err = newDescriptorCableDeliverySystem(i); err != nil {
+						err = fmt.Errorf("astits: parsing Cable delivery system descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagComponent:
+					if d.Component, err = newDescriptorComponent(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Component descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagContent:
+					if d.Content, err = newDescriptorContent(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Content descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagCopyright:
+					if d.Copyright, err = newDescriptorCopyright(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Copyright descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagCountryAvailability:
+					if d.CountryAvailability, err = newDescriptorCountryAvailability(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Country availability descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagDataStreamAlignment:
+					if d.DataStreamAlignment, err = newDescriptorDataStreamAlignment(i); err != nil {
+						err = fmt.Errorf("astits: parsing Data Stream Alignment descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagEnhancedAC3:
+					if d.EnhancedAC3, err = newDescriptorEnhancedAC3(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Enhanced AC3 descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagExtendedEvent:
+					if d.ExtendedEvent, err = newDescriptorExtendedEvent(i); err != nil {
+						err = fmt.Errorf("astits: parsing Extended event descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagExtension:
+					if d.Extension, err = newDescriptorExtension(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Extension descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagFrequencyList:
+					if d.FrequencyList, err = newDescriptorFrequencyList(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Frequency list descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagHierarchy:
+					if d.Hierarchy, err = newDescriptorHierarchy(i); err != nil {
+						err = fmt.Errorf("astits: parsing Hierarchy descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagIBP:
+					if d.IBP, err = newDescriptorIBP(i); err != nil {
+						err = fmt.Errorf("astits: parsing IBP descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagISO639LanguageAndAudioType:
+					if d.ISO639LanguageAndAudioType, err = newDescriptorISO639LanguageAndAudioType(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing ISO639 Language and Audio Type descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagLinkage:
+					if d.Linkage, err = newDescriptorLinkage(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Linkage descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagLocalTimeOffset:
+					if d.LocalTimeOffset, err = newDescriptorLocalTimeOffset(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Local Time Offset descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagMaximumBitrate:
+					if d.MaximumBitrate, err = newDescriptorMaximumBitrate(i); err != nil {
+						err = fmt.Errorf("astits: parsing Maximum Bitrate descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagMetadata:
+					if d.Metadata, err = newDescriptorMetadata(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Metadata descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagMetadataPointer:
+					if d.MetadataPointer, err = newDescriptorMetadataPointer(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Metadata Pointer descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagMetadataSTD:
+					if d.MetadataSTD, err = newDescriptorMetadataSTD(i); err != nil {
+						err = fmt.Errorf("astits: parsing Metadata STD descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagMPEG4Video:
+					if d.MPEG4Video, err = newDescriptorMPEG4Video(i); err != nil {
+						err = fmt.Errorf("astits: parsing MPEG-4 video descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagNetworkName:
+					if d.NetworkName, err = newDescriptorNetworkName(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Network Name descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagNVODReference:
+					if d.NVODReference, err = newDescriptorNVODReference(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing NVOD Reference descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagParentalRating:
+					if d.ParentalRating, err = newDescriptorParentalRating(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Parental Rating descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagPrivateDataIndicator:
+					if d.PrivateDataIndicator, err = newDescriptorPrivateDataIndicator(i); err != nil {
+						err = fmt.Errorf("astits: parsing Private Data Indicator descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagPrivateDataSpecifier:
+					if d.PrivateDataSpecifier, err = newDescriptorPrivateDataSpecifier(i); err != nil {
+						err = fmt.Errorf("astits: parsing Private Data Specifier descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagRegistration:
+					if d.Registration, err = newDescriptorRegistration(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Registration descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagS2SatelliteDeliverySystem:
+					if d.S2SatelliteDeliverySystem, err = newDescriptorS2SatelliteDeliverySystem(i); err != nil {
+						err = fmt.Errorf("astits: parsing S2 satellite delivery system descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagSatelliteDeliverySystem:
+					if d.SatelliteDeliverySystem, err = newDescriptorSatelliteDeliverySystem(i); err != nil {
+						err = fmt.Errorf("astits: parsing Satellite delivery system descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagService:
+					if d.Service, err = newDescriptorService(i); err != nil {
+						err = fmt.Errorf("astits: parsing Service descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagShortEvent:
+					if d.ShortEvent, err = newDescriptorShortEvent(i); err != nil {
+						err = fmt.Errorf("astits: parsing Short Event descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagSmoothingBuffer:
+					if d.SmoothingBuffer, err = newDescriptorSmoothingBuffer(i); err != nil {
+						err = fmt.Errorf("astits: parsing Smoothing Buffer descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagSTD:
+					if d.STD, err = newDescriptorSTD(i); err != nil {
+						err = fmt.Errorf("astits: parsing STD descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagStreamIdentifier:
+					if d.StreamIdentifier, err = newDescriptorStreamIdentifier(i); err != nil {
+						err = fmt.Errorf("astits: parsing Stream Identifier descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagSubtitling:
+					if d.Subtitling, err = newDescriptorSubtitling(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Subtitling descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagSystemClock:
+					if d.SystemClock, err = newDescriptorSystemClock(i); err != nil {
+						err = fmt.Errorf("astits: parsing System Clock descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagTeletext:
+					if d.Teletext, err = newDescriptorTeletext(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing Teletext descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagTerrestrialDeliverySystem:
+					if d.TerrestrialDeliverySystem, err = newDescriptorTerrestrialDeliverySystem(i); err != nil {
+						err = fmt.Errorf("astits: parsing Terrestrial delivery system descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagVBIData:
+					if d.VBIData, err = newDescriptorVBIData(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing VBI Date descriptor failed: %w", err)
+						return
+					}
+				case DescriptorTagVBITeletext:
+					if d.VBITeletext, err = newDescriptorTeletext(i, offsetDescriptorEnd); err != nil {
+						err = fmt.Errorf("astits: parsing VBI Teletext descriptor failed: %w", err)
 						return
 					}
-				} else {
-					// Switch on tag
-					switch d.Tag {
-					case DescriptorTagAC3:
-						if d.AC3, err = newDescriptorAC3(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing AC3 descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagAVCVideo:
-						if d.AVCVideo, err = newDescriptorAVCVideo(i); err != nil {
-							err = fmt.Errorf("astits: parsing AVC Video descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagComponent:
-						if d.Component, err = newDescriptorComponent(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Component descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagContent:
-						if d.Content, err = newDescriptorContent(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Content descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagDataStreamAlignment:
-						if d.DataStreamAlignment, err = newDescriptorDataStreamAlignment(i); err != nil {
-							err = fmt.Errorf("astits: parsing Data Stream Alignment descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagEnhancedAC3:
-						if d.EnhancedAC3, err = newDescriptorEnhancedAC3(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Enhanced AC3 descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagExtendedEvent:
-						if d.ExtendedEvent, err = newDescriptorExtendedEvent(i); err != nil {
-							err = fmt.Errorf("astits: parsing Extended event descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagExtension:
-						if d.Extension, err = newDescriptorExtension(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Extension descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagISO639LanguageAndAudioType:
-						if d.ISO639LanguageAndAudioType, err = newDescriptorISO639LanguageAndAudioType(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing ISO639 Language and Audio Type descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagLocalTimeOffset:
-						if d.LocalTimeOffset, err = newDescriptorLocalTimeOffset(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Local Time Offset descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagMaximumBitrate:
-						if d.MaximumBitrate, err = newDescriptorMaximumBitrate(i); err != nil {
-							err = fmt.Errorf("astits: parsing Maximum Bitrate descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagNetworkName:
-						if d.NetworkName, err = newDescriptorNetworkName(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Network Name descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagParentalRating:
-						if d.ParentalRating, err = newDescriptorParentalRating(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Parental Rating descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagPrivateDataIndicator:
-						if d.PrivateDataIndicator, err = newDescriptorPrivateDataIndicator(i); err != nil {
-							err = fmt.Errorf("astits: parsing Private Data Indicator descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagPrivateDataSpecifier:
-						if d.PrivateDataSpecifier, err = newDescriptorPrivateDataSpecifier(i); err != nil {
-							err = fmt.Errorf("astits: parsing Private Data Specifier descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagRegistration:
-						if d.Registration, err = newDescriptorRegistration(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Registration descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagService:
-						if d.Service, err = newDescriptorService(i); err != nil {
-							err = fmt.Errorf("astits: parsing Service descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagShortEvent:
-						if d.ShortEvent, err = newDescriptorShortEvent(i); err != nil {
-							err = fmt.Errorf("astits: parsing Short Event descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagStreamIdentifier:
-						if d.StreamIdentifier, err = newDescriptorStreamIdentifier(i); err != nil {
-							err = fmt.Errorf("astits: parsing Stream Identifier descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagSubtitling:
-						if d.Subtitling, err = newDescriptorSubtitling(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Subtitling descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagTeletext:
-						if d.Teletext, err = newDescriptorTeletext(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing Teletext descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagVBIData:
-						if d.VBIData, err = newDescriptorVBIData(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing VBI Date descriptor failed: %w", err)
-							return
-						}
-					case DescriptorTagVBITeletext:
-						if d.VBITeletext, err = newDescriptorTeletext(i, offsetDescriptorEnd); err != nil {
-							err = fmt.Errorf("astits: parsing VBI Teletext descriptor failed: %w", err)
-							return
-						}
-					default:
-						if d.Unknown, err = newDescriptorUnknown(i, d.Tag, d.Length); err != nil {
-							err = fmt.Errorf("astits: parsing unknown descriptor failed: %w", err)
-							return
-						}
+				default:
+					if d.Unknown, err = newDescriptorUnknown(i, d.Tag, d.Length); err != nil {
+						err = fmt.Errorf("astits: parsing unknown descriptor failed: %w", err)
+						return
 					}
 				}
+			}
+
+			// Seek in iterator to make sure we move to the end of the descriptor since its content may be
+			// corrupted
+			i.Seek(offsetDescriptorEnd)
+		}
+
+		// Update private data specifier in effect
+		if d.PrivateDataSpecifier != nil {
+			privateDataSpecifier = d.PrivateDataSpecifier.Specifier
+		}
+		o = append(o, d)
+	}
+	return
+}
 
-				// Seek in iterator to make sure we move to the end of the descriptor since its content may be
-				// corrupted
-				i.Seek(offsetDescriptorEnd)
+// parsePrivate interprets the user defined bytes based on the private data specifier in effect
+func (d *Descriptor) parsePrivate() (err error) {
+	i := astikit.NewBytesIterator(d.UserDefined)
+	switch d.PrivateDataSpecifierScope {
+	case PrivateDataSpecifierEACEM:
+		switch d.Tag {
+		case DescriptorTagEACEMLogicalChannelNumber:
+			if d.LogicalChannelNumber, err = newDescriptorLogicalChannelNumber(i, len(d.UserDefined)); err != nil {
+				err = fmt.Errorf("astits: parsing Logical Channel Number descriptor failed: %w", err)
+				return
+			}
+		case DescriptorTagEACEMLogicalChannelNumberV2:
+			if d.LogicalChannelNumberV2, err = newDescriptorLogicalChannelNumberV2(i, len(d.UserDefined)); err != nil {
+				err = fmt.Errorf("astits: parsing Logical Channel Number V2 descriptor failed: %w", err)
+				return
 			}
-			o = append(o, d)
 		}
 	}
 	return
 }
 
+// Serialise serialises the descriptor
+// Descriptors are serialised out of their struct, so that modifications of parsed descriptors are written back. Parsed
+// descriptors whose tag can't be serialised that way are written back as they were read.
 func (d *Descriptor) Serialise(b []byte) (int, error) {
-	b[0] = d.Tag
-	b[1] = d.Length
-	if len(b) < int(d.Length)+2 {
-		return 0, ErrNoRoomInBuffer
-	}
-	//TODO actually create the descriptor from the struct
-	copy(b[2:], d.originalBytes)
-	// +2 to account for the Tag and Length fields
-	return int(d.Length + 2), nil
+	return serialiseWithSentinel(b, d.serialise)
+}
+
+func (d *Descriptor) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint8("descriptor tag", d.Tag); err != nil {
+		return 0, err
+	}
+
+	// Serialise data
+	bs, err := w.next("descriptor length", 1)
+	if err != nil {
+		return 0, err
+	}
+	start := w.offset
+	if err = w.write("descriptor content", d.serialiseData); err != nil {
+		// Descriptors that can't be serialised out of their struct are written back as they were parsed
+		if !errors.Is(err, errDescriptorSerialisationNotSupported) || d.originalBytes == nil {
+			return 0, err
+		}
+		bs[0] = d.Length
+		if err = w.writeBytes("descriptor content", d.originalBytes); err != nil {
+			return 0, err
+		}
+		return w.offset, nil
+	}
+	n := w.offset - start
+	if n > 0xff {
+		return 0, fmt.Errorf("astits: descriptor with tag %#x is too long", d.Tag)
+	}
+	bs[0] = uint8(n)
+	return w.offset, nil
+}
+
+// serialiseData serialises the descriptor content based on its tag
+func (d *Descriptor) serialiseData(b []byte) (int, error) {
+	// User defined
+	if d.Tag >= 0x80 && d.Tag <= 0xfe {
+		return serialiseDescriptorBytes(b, d.UserDefined)
+	}
+
+	// Switch on tag
+	switch {
+	case d.Tag == DescriptorTagApplicationSignalling && d.ApplicationSignalling != nil:
+		return d.ApplicationSignalling.serialise(b)
+	case d.Tag == DescriptorTagCA && d.CA != nil:
+		return serialiseDescriptorBytes(b, append([]byte{uint8(d.CA.CASystemID >> 8), uint8(d.CA.CASystemID), 0xe0 | uint8(d.CA.CAPID>>8)&0x1f, uint8(d.CA.CAPID)}, d.CA.PrivateData...))
+	case d.Tag == DescriptorTagCableDeliverySystem && d.CableDeliverySystem != nil:
+		return d.CableDeliverySystem.serialise(b)
+	case d.Tag == DescriptorTagContent && d.Content != nil:
+		return d.Content.serialise(b)
+	case d.Tag == DescriptorTagCopyright && d.Copyright != nil:
+		v := d.Copyright.Identifier
+		return serialiseDescriptorBytes(b, append([]byte{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, d.Copyright.AdditionalInfo...))
+	case d.Tag == DescriptorTagCountryAvailability && d.CountryAvailability != nil:
+		return d.CountryAvailability.serialise(b)
+	case d.Tag == DescriptorTagDataStreamAlignment && d.DataStreamAlignment != nil:
+		return serialiseDescriptorBytes(b, []byte{d.DataStreamAlignment.Type})
+	case d.Tag == DescriptorTagExtendedEvent && d.ExtendedEvent != nil:
+		return d.ExtendedEvent.serialise(b)
+	case d.Tag == DescriptorTagFrequencyList && d.FrequencyList != nil:
+		return d.FrequencyList.serialise(b)
+	case d.Tag == DescriptorTagHierarchy && d.Hierarchy != nil:
+		h := d.Hierarchy
+		return serialiseDescriptorBytes(b, []byte{
+			Btou8(h.NoViewScalability)<<7 | Btou8(h.NoTemporalScalability)<<6 | Btou8(h.NoSpatialScalability)<<5 |
+				Btou8(h.NoQualityScalability)<<4 | h.Type&0xf,
+			0xc0 | h.LayerIndex&0x3f,
+			Btou8(!h.TrefPresent)<<7 | 0x40 | h.EmbeddedLayerIndex&0x3f,
+			0xc0 | h.Channel&0x3f,
+		})
+	case d.Tag == DescriptorTagIBP && d.IBP != nil:
+		return serialiseDescriptorBytes(b, []byte{
+			Btou8(d.IBP.ClosedGOP)<<7 | Btou8(d.IBP.IdenticalGOP)<<6 | uint8(d.IBP.MaxGOPLength>>8)&0x3f,
+			uint8(d.IBP.MaxGOPLength),
+		})
+	case d.Tag == DescriptorTagISO639LanguageAndAudioType && d.ISO639LanguageAndAudioType != nil:
+		return serialiseDescriptorBytes(b, append(append([]byte{}, d.ISO639LanguageAndAudioType.Language...), d.ISO639LanguageAndAudioType.Type))
+	case d.Tag == DescriptorTagLocalTimeOffset && d.LocalTimeOffset != nil:
+		return d.LocalTimeOffset.serialise(b)
+	case d.Tag == DescriptorTagMaximumBitrate && d.MaximumBitrate != nil:
+		v := d.MaximumBitrate.Bitrate / 50
+		return serialiseDescriptorBytes(b, []byte{0xc0 | uint8(v>>16)&0x3f, uint8(v >> 8), uint8(v)})
+	case d.Tag == DescriptorTagMetadata && d.Metadata != nil:
+		return d.Metadata.serialise(b)
+	case d.Tag == DescriptorTagMetadataPointer && d.MetadataPointer != nil:
+		return d.MetadataPointer.serialise(b)
+	case d.Tag == DescriptorTagMetadataSTD && d.MetadataSTD != nil:
+		return d.MetadataSTD.serialise(b)
+	case d.Tag == DescriptorTagMPEG4Video && d.MPEG4Video != nil:
+		return serialiseDescriptorBytes(b, []byte{d.MPEG4Video.ProfileAndLevel})
+	case d.Tag == DescriptorTagNetworkName && d.NetworkName != nil:
+		return serialiseDescriptorBytes(b, d.NetworkName.Name)
+	case d.Tag == DescriptorTagParentalRating && d.ParentalRating != nil:
+		return d.ParentalRating.serialise(b)
+	case d.Tag == DescriptorTagPrivateDataSpecifier && d.PrivateDataSpecifier != nil:
+		v := d.PrivateDataSpecifier.Specifier
+		return serialiseDescriptorBytes(b, []byte{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)})
+	case d.Tag == DescriptorTagRegistration && d.Registration != nil:
+		v := d.Registration.FormatIdentifier
+		return serialiseDescriptorBytes(b, append([]byte{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, d.Registration.AdditionalIdentificationInfo...))
+	case d.Tag == DescriptorTagS2SatelliteDeliverySystem && d.S2SatelliteDeliverySystem != nil:
+		return d.S2SatelliteDeliverySystem.serialise(b)
+	case d.Tag == DescriptorTagSatelliteDeliverySystem && d.SatelliteDeliverySystem != nil:
+		return d.SatelliteDeliverySystem.serialise(b)
+	case d.Tag == DescriptorTagService && d.Service != nil:
+		return d.Service.serialise(b)
+	case d.Tag == DescriptorTagShortEvent && d.ShortEvent != nil:
+		return d.ShortEvent.serialise(b)
+	case d.Tag == DescriptorTagSmoothingBuffer && d.SmoothingBuffer != nil:
+		r, s := d.SmoothingBuffer.LeakRate, d.SmoothingBuffer.Size
+		return serialiseDescriptorBytes(b, []byte{
+			0xc0 | uint8(r>>16)&0x3f, uint8(r >> 8), uint8(r),
+			0xc0 | uint8(s>>16)&0x3f, uint8(s >> 8), uint8(s),
+		})
+	case d.Tag == DescriptorTagSTD && d.STD != nil:
+		return serialiseDescriptorBytes(b, []byte{0xfe | Btou8(d.STD.LeakValid)})
+	case d.Tag == DescriptorTagStreamIdentifier && d.StreamIdentifier != nil:
+		return serialiseDescriptorBytes(b, []byte{d.StreamIdentifier.ComponentTag})
+	case d.Tag == DescriptorTagSystemClock && d.SystemClock != nil:
+		return serialiseDescriptorBytes(b, []byte{
+			Btou8(d.SystemClock.ExternalClockReferenceIndicator)<<7 | 0x40 | d.SystemClock.ClockAccuracyInteger&0x3f,
+			d.SystemClock.ClockAccuracyExponent<<5 | 0x1f,
+		})
+	case d.Tag == DescriptorTagTerrestrialDeliverySystem && d.TerrestrialDeliverySystem != nil:
+		return d.TerrestrialDeliverySystem.serialise(b)
+	case d.Unknown != nil:
+		return serialiseDescriptorBytes(b, d.Unknown.Content)
+	case d.Length == 0:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("astits: serialising descriptor with tag %#x failed: %w", d.Tag, errDescriptorSerialisationNotSupported)
+}
+
+// writeDescriptorLengthBytes writes bytes preceded by their 8 bits length
+func writeDescriptorLengthBytes(w *checkedWriter, field string, v []byte) error {
+	if len(v) > 0xff {
+		return fmt.Errorf("astits: %s length %d is bigger than 255", field, len(v))
+	}
+	if err := w.writeUint8(field+" length", uint8(len(v))); err != nil {
+		return err
+	}
+	return w.writeBytes(field, v)
+}
+
+// parseDescriptorLengthBytes parses bytes preceded by their 8 bits length
+func parseDescriptorLengthBytes(i *astikit.BytesIterator) (bs []byte, err error) {
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+	if bs, err = i.NextBytes(int(b)); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+	return
+}
+
+func serialiseDescriptorBytes(b, v []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeBytes("descriptor content", v); err != nil {
+		return 0, err
+	}
+	return w.offset, nil
+}
+
+// serialiseDescriptors serialises a descriptors loop preceded by its 12 bits length
+func serialiseDescriptors(b []byte, ds []*Descriptor) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeLength("descriptors loop length", 12, 0xf000, func() error {
+		for i, d := range ds {
+			if err := w.write(fmt.Sprintf("descriptor #%d", i), d.serialise); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
 }
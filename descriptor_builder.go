@@ -0,0 +1,135 @@
+package astits
+
+// newBuiltDescriptor creates a descriptor out of its tag and content, its length being computed out of its
+// serialised content
+func newBuiltDescriptor(d *Descriptor) *Descriptor {
+	b := make([]byte, 0xff)
+	if n, err := d.serialiseData(b); err == nil {
+		d.Length = uint8(n)
+	}
+	return d
+}
+
+// NewCADescriptor creates a conditional access descriptor declaring the PID carrying the ECMs or EMMs of a CA system
+func NewCADescriptor(caSystemID, caPID uint16, privateData []byte) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		CA: &DescriptorCA{
+			CAPID:       caPID,
+			CASystemID:  caSystemID,
+			PrivateData: privateData,
+		},
+		Tag: DescriptorTagCA,
+	})
+}
+
+// NewCopyrightDescriptor creates a copyright descriptor out of a registered copyright identifier
+func NewCopyrightDescriptor(identifier uint32, additionalInfo []byte) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		Copyright: &DescriptorCopyright{
+			AdditionalInfo: additionalInfo,
+			Identifier:     identifier,
+		},
+		Tag: DescriptorTagCopyright,
+	})
+}
+
+// NewDataStreamAlignmentDescriptor creates a data stream alignment descriptor, see DataStreamAligment*
+func NewDataStreamAlignmentDescriptor(alignmentType uint8) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		DataStreamAlignment: &DescriptorDataStreamAlignment{Type: alignmentType},
+		Tag:                 DescriptorTagDataStreamAlignment,
+	})
+}
+
+// NewISO639Descriptor creates an ISO639 language descriptor out of a 3 letters language code, see AudioType*
+func NewISO639Descriptor(language string, audioType uint8) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		ISO639LanguageAndAudioType: &DescriptorISO639LanguageAndAudioType{
+			Language: []byte(language),
+			Type:     audioType,
+		},
+		Tag: DescriptorTagISO639LanguageAndAudioType,
+	})
+}
+
+// NewMaximumBitrateDescriptor creates a maximum bitrate descriptor, bitrate being in bytes/second and rounded down
+// to a multiple of 50
+func NewMaximumBitrateDescriptor(bitrate uint32) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		MaximumBitrate: &DescriptorMaximumBitrate{Bitrate: bitrate - bitrate%50},
+		Tag:            DescriptorTagMaximumBitrate,
+	})
+}
+
+// NewNetworkNameDescriptor creates a network name descriptor
+func NewNetworkNameDescriptor(name string) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		NetworkName: &DescriptorNetworkName{Name: []byte(name)},
+		Tag:         DescriptorTagNetworkName,
+	})
+}
+
+// NewPrivateDataSpecifierDescriptor creates a private data specifier descriptor, see PrivateDataSpecifier*
+func NewPrivateDataSpecifierDescriptor(specifier uint32) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		PrivateDataSpecifier: &DescriptorPrivateDataSpecifier{Specifier: specifier},
+		Tag:                  DescriptorTagPrivateDataSpecifier,
+	})
+}
+
+// NewRegistrationDescriptor creates a registration descriptor out of a 4 characters format identifier such as "AC-3"
+// Shorter format identifiers are padded with spaces, as in "ID3 ", and longer ones are truncated.
+func NewRegistrationDescriptor(formatIdentifier string) *Descriptor {
+	// Get format identifier
+	bs := []byte(formatIdentifier + "    ")[:4]
+
+	// Create descriptor
+	return newBuiltDescriptor(&Descriptor{
+		Registration: &DescriptorRegistration{FormatIdentifier: uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])},
+		Tag:          DescriptorTagRegistration,
+	})
+}
+
+// NewServiceDescriptor creates a service descriptor, see ServiceType*
+func NewServiceDescriptor(serviceType uint8, provider, name string) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		Service: &DescriptorService{
+			Name:     []byte(name),
+			Provider: []byte(provider),
+			Type:     serviceType,
+		},
+		Tag: DescriptorTagService,
+	})
+}
+
+// NewShortEventDescriptor creates a short event descriptor out of a 3 letters language code
+func NewShortEventDescriptor(language, eventName, text string) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		ShortEvent: &DescriptorShortEvent{
+			EventName: []byte(eventName),
+			Language:  []byte(language),
+			Text:      []byte(text),
+		},
+		Tag: DescriptorTagShortEvent,
+	})
+}
+
+// NewStreamIdentifierDescriptor creates a stream identifier descriptor
+func NewStreamIdentifierDescriptor(componentTag uint8) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		StreamIdentifier: &DescriptorStreamIdentifier{ComponentTag: componentTag},
+		Tag:              DescriptorTagStreamIdentifier,
+	})
+}
+
+// NewSystemClockDescriptor creates a system clock descriptor, the clock accuracy being integer * 10^-exponent ppm
+func NewSystemClockDescriptor(externalClockReference bool, clockAccuracyInteger, clockAccuracyExponent uint8) *Descriptor {
+	return newBuiltDescriptor(&Descriptor{
+		SystemClock: &DescriptorSystemClock{
+			ClockAccuracyExponent:           clockAccuracyExponent & 0x7,
+			ClockAccuracyInteger:            clockAccuracyInteger & 0x3f,
+			ExternalClockReferenceIndicator: externalClockReference,
+		},
+		Tag: DescriptorTagSystemClock,
+	})
+}
@@ -0,0 +1,51 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescriptorBuilders(t *testing.T) {
+	// Build
+	ds := []*Descriptor{
+		NewDataStreamAlignmentDescriptor(DataStreamAligmentVideoSliceOrAccessUnit),
+		NewISO639Descriptor("eng", AudioTypeCleanEffects),
+		NewMaximumBitrateDescriptor(1024),
+		NewNetworkNameDescriptor("network"),
+		NewRegistrationDescriptor("ID3"),
+		NewServiceDescriptor(0x1, "provider", "name"),
+		NewShortEventDescriptor("fra", "event", "text"),
+		NewStreamIdentifierDescriptor(7),
+		NewCADescriptor(0x500, 0x1ff, []byte{0x1, 0x2}),
+		NewSystemClockDescriptor(true, 25, 2),
+		NewCopyrightDescriptor(0x41424344, []byte{0x1, 0x2, 0x3}),
+		NewPrivateDataSpecifierDescriptor(PrivateDataSpecifierEACEM),
+	}
+	assert.Equal(t, &Descriptor{
+		Length:       4,
+		Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierID3},
+		Tag:          DescriptorTagRegistration,
+	}, ds[4])
+	assert.Equal(t, uint8(4), ds[1].Length)
+	assert.Equal(t, uint32(1000), ds[2].MaximumBitrate.Bitrate)
+	assert.Equal(t, &DescriptorCA{CAPID: 0x1ff, CASystemID: 0x500, PrivateData: []byte{0x1, 0x2}}, ds[8].CA)
+	assert.Equal(t, uint8(6), ds[8].Length)
+	assert.Equal(t, &DescriptorSystemClock{ClockAccuracyExponent: 2, ClockAccuracyInteger: 25, ExternalClockReferenceIndicator: true}, ds[9].SystemClock)
+	assert.Equal(t, uint8(2), ds[9].Length)
+	assert.Equal(t, &DescriptorCopyright{AdditionalInfo: []byte{0x1, 0x2, 0x3}, Identifier: 0x41424344}, ds[10].Copyright)
+	assert.Equal(t, uint8(7), ds[10].Length)
+	assert.Equal(t, uint32(RegistrationFormatIdentifierAC3), NewRegistrationDescriptor("AC-3").Registration.FormatIdentifier)
+
+	// Round trip
+	b := make([]byte, 1024)
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	vs, err := parseDescriptors(astikit.NewBytesIterator(b[:n]))
+	assert.NoError(t, err)
+	for _, v := range vs {
+		v.originalBytes = nil
+	}
+	assert.Equal(t, ds, vs)
+}
@@ -0,0 +1,419 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Delivery system FEC outer schemes
+// Chapter: 6.2.13.1 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	FECOuterNone      = 0x1
+	FECOuterRS204_188 = 0x2
+)
+
+// Delivery system FEC inner schemes
+// Chapter: 6.2.13.1 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	FECInner1_2                   = 0x1
+	FECInner2_3                   = 0x2
+	FECInner3_4                   = 0x3
+	FECInner5_6                   = 0x4
+	FECInner7_8                   = 0x5
+	FECInner8_9                   = 0x6
+	FECInner3_5                   = 0x7
+	FECInner4_5                   = 0x8
+	FECInner9_10                  = 0x9
+	FECInnerNoConvolutionalCoding = 0xf
+)
+
+// Cable delivery system modulations
+// Chapter: 6.2.13.1 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	CableModulation16QAM  = 0x1
+	CableModulation32QAM  = 0x2
+	CableModulation64QAM  = 0x3
+	CableModulation128QAM = 0x4
+	CableModulation256QAM = 0x5
+)
+
+// Frequency list coding types
+// Chapter: 6.2.17 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	FrequencyCodingTypeNotDefined  = 0x0
+	FrequencyCodingTypeSatellite   = 0x1
+	FrequencyCodingTypeCable       = 0x2
+	FrequencyCodingTypeTerrestrial = 0x3
+)
+
+// Satellite delivery system polarizations
+// Chapter: 6.2.13.2 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	PolarizationLinearHorizontal = 0x0
+	PolarizationLinearVertical   = 0x1
+	PolarizationCircularLeft     = 0x2
+	PolarizationCircularRight    = 0x3
+)
+
+// Satellite delivery system roll-offs, only meaningful for DVB-S2
+// Chapter: 6.2.13.2 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	RollOff035 = 0x0
+	RollOff025 = 0x1
+	RollOff020 = 0x2
+)
+
+// Satellite delivery system modulation types
+// Chapter: 6.2.13.2 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	SatelliteModulationAuto  = 0x0
+	SatelliteModulationQPSK  = 0x1
+	SatelliteModulation8PSK  = 0x2
+	SatelliteModulation16QAM = 0x3
+)
+
+// S2 satellite delivery system TS/GS modes
+// Chapter: 6.2.13.3 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	TSGSModeGenericPacketized = 0x0
+	TSGSModeGenericContinuous = 0x1
+	TSGSModeGSEHEM            = 0x2
+	TSGSModeTransportStream   = 0x3
+)
+
+// Terrestrial delivery system bandwidths
+// Chapter: 6.2.13.4 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	TerrestrialBandwidth8MHz = 0x0
+	TerrestrialBandwidth7MHz = 0x1
+	TerrestrialBandwidth6MHz = 0x2
+	TerrestrialBandwidth5MHz = 0x3
+)
+
+// Terrestrial delivery system constellations
+// Chapter: 6.2.13.4 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	TerrestrialConstellationQPSK  = 0x0
+	TerrestrialConstellation16QAM = 0x1
+	TerrestrialConstellation64QAM = 0x2
+)
+
+// Terrestrial delivery system guard intervals
+// Chapter: 6.2.13.4 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	GuardInterval1_32 = 0x0
+	GuardInterval1_16 = 0x1
+	GuardInterval1_8  = 0x2
+	GuardInterval1_4  = 0x3
+)
+
+// Terrestrial delivery system transmission modes
+// Chapter: 6.2.13.4 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+const (
+	TransmissionMode2k = 0x0
+	TransmissionMode8k = 0x1
+	TransmissionMode4k = 0x2
+)
+
+// DescriptorCableDeliverySystem represents a cable delivery system descriptor
+// Chapter: 6.2.13.1 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorCableDeliverySystem struct {
+	FECInner   uint8  // See FECInner*
+	FECOuter   uint8  // See FECOuter*
+	Frequency  uint64 // In Hz, with a precision of 100 Hz
+	Modulation uint8  // See CableModulation*
+	SymbolRate uint32 // In symbols per second, with a precision of 100 symbols per second
+}
+
+func newDescriptorCableDeliverySystem(i *astikit.BytesIterator) (d *DescriptorCableDeliverySystem, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(11); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorCableDeliverySystem{
+		FECInner:   bs[10] & 0xf,
+		FECOuter:   bs[5] & 0xf,
+		Frequency:  parseFrequency(bs[0:4], FrequencyCodingTypeCable),
+		Modulation: bs[6],
+		SymbolRate: parseSymbolRate(bs[7:11]),
+	}
+	return
+}
+
+func (d *DescriptorCableDeliverySystem) serialise(b []byte) (int, error) {
+	bs := make([]byte, 11)
+	writeFrequency(bs[0:4], d.Frequency, FrequencyCodingTypeCable)
+	bs[4] = 0xff
+	bs[5] = 0xf0 | d.FECOuter&0xf
+	bs[6] = d.Modulation
+	writeSymbolRate(bs[7:11], d.SymbolRate, d.FECInner)
+	return serialiseDescriptorBytes(b, bs)
+}
+
+// DescriptorSatelliteDeliverySystem represents a satellite delivery system descriptor
+// Chapter: 6.2.13.2 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorSatelliteDeliverySystem struct {
+	East            bool   // Whether the orbital position is in the eastern part of the orbit
+	FECInner        uint8  // See FECInner*
+	Frequency       uint64 // In Hz, with a precision of 10 kHz
+	IsDVBS2         bool   // Modulation system, DVB-S when false
+	ModulationType  uint8  // See SatelliteModulation*
+	OrbitalPosition uint16 // In tenths of degree
+	Polarization    uint8  // See Polarization*
+	RollOff         uint8  // See RollOff*, only set for DVB-S2
+	SymbolRate      uint32 // In symbols per second, with a precision of 100 symbols per second
+}
+
+func newDescriptorSatelliteDeliverySystem(i *astikit.BytesIterator) (d *DescriptorSatelliteDeliverySystem, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(11); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorSatelliteDeliverySystem{
+		East:            bs[6]&0x80 > 0,
+		FECInner:        bs[10] & 0xf,
+		Frequency:       parseFrequency(bs[0:4], FrequencyCodingTypeSatellite),
+		IsDVBS2:         bs[6]&0x4 > 0,
+		ModulationType:  bs[6] & 0x3,
+		OrbitalPosition: uint16(parseBCD(bs[4:6])),
+		Polarization:    bs[6] >> 5 & 0x3,
+		SymbolRate:      parseSymbolRate(bs[7:11]),
+	}
+
+	// Roll-off
+	if d.IsDVBS2 {
+		d.RollOff = bs[6] >> 3 & 0x3
+	}
+	return
+}
+
+func (d *DescriptorSatelliteDeliverySystem) serialise(b []byte) (int, error) {
+	bs := make([]byte, 11)
+	writeFrequency(bs[0:4], d.Frequency, FrequencyCodingTypeSatellite)
+	writeBCD(bs[4:6], uint64(d.OrbitalPosition))
+	bs[6] = Btou8(d.East)<<7 | d.Polarization&0x3<<5 | Btou8(d.IsDVBS2)<<2 | d.ModulationType&0x3
+	if d.IsDVBS2 {
+		bs[6] |= d.RollOff & 0x3 << 3
+	}
+	writeSymbolRate(bs[7:11], d.SymbolRate, d.FECInner)
+	return serialiseDescriptorBytes(b, bs)
+}
+
+// DescriptorS2SatelliteDeliverySystem represents an S2 satellite delivery system descriptor, which complements the
+// satellite delivery system descriptor of DVB-S2 transport streams
+// Chapter: 6.2.13.3 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorS2SatelliteDeliverySystem struct {
+	HasScramblingSequence   bool   // Whether a non default scrambling sequence is used
+	InputStreamIdentifier   uint8  // Only set if MultipleInputStream is true
+	MultipleInputStream     bool   // Whether the transport stream is one of several input streams
+	NotTimeslice            bool   // Whether time slicing is not used
+	ScramblingSequenceIndex uint32 // 18 bits, only set if HasScramblingSequence is true
+	TimesliceNumber         uint8  // Only set if NotTimeslice is false
+	TSGSMode                uint8  // See TSGSMode*
+}
+
+func newDescriptorS2SatelliteDeliverySystem(i *astikit.BytesIterator) (d *DescriptorS2SatelliteDeliverySystem, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorS2SatelliteDeliverySystem{
+		HasScramblingSequence: b&0x80 > 0,
+		MultipleInputStream:   b&0x40 > 0,
+		NotTimeslice:          b&0x10 > 0,
+		TSGSMode:              b & 0x3,
+	}
+
+	// Scrambling sequence
+	if d.HasScramblingSequence {
+		var bs []byte
+		if bs, err = i.NextBytes(3); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.ScramblingSequenceIndex = uint32(bs[0]&0x3)<<16 | uint32(bs[1])<<8 | uint32(bs[2])
+	}
+
+	// Input stream identifier
+	if d.MultipleInputStream {
+		if d.InputStreamIdentifier, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+	}
+
+	// Timeslice number
+	if !d.NotTimeslice {
+		if d.TimesliceNumber, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+func (d *DescriptorS2SatelliteDeliverySystem) serialise(b []byte) (int, error) {
+	bs := []byte{Btou8(d.HasScramblingSequence)<<7 | Btou8(d.MultipleInputStream)<<6 | Btou8(d.NotTimeslice)<<4 | 0xc |
+		d.TSGSMode&0x3}
+	if d.HasScramblingSequence {
+		bs = append(bs, 0xfc|uint8(d.ScramblingSequenceIndex>>16)&0x3, uint8(d.ScramblingSequenceIndex>>8), uint8(d.ScramblingSequenceIndex))
+	}
+	if d.MultipleInputStream {
+		bs = append(bs, d.InputStreamIdentifier)
+	}
+	if !d.NotTimeslice {
+		bs = append(bs, d.TimesliceNumber)
+	}
+	return serialiseDescriptorBytes(b, bs)
+}
+
+// DescriptorTerrestrialDeliverySystem represents a terrestrial delivery system descriptor
+// Chapter: 6.2.13.4 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorTerrestrialDeliverySystem struct {
+	Bandwidth            uint8  // See TerrestrialBandwidth*
+	CodeRateHPStream     uint8  // See FECInner*, minus one
+	CodeRateLPStream     uint8  // See FECInner*, minus one
+	Constellation        uint8  // See TerrestrialConstellation*
+	Frequency            uint64 // Centre frequency, in Hz, with a precision of 10 Hz
+	GuardInterval        uint8  // See GuardInterval*
+	HasMPEFEC            bool   // Whether MPE-FEC is used, only meaningful for DVB-H
+	HasOtherFrequency    bool   // Whether other frequencies are in use, see the frequency list descriptor
+	HasTimeSlicing       bool   // Whether time slicing is used, only meaningful for DVB-H
+	HierarchyInformation uint8  // 3 bits, alpha value and whether interleaving is in-depth
+	HighPriority         bool   // Whether the stream is the HP stream of a hierarchical transmission
+	TransmissionMode     uint8  // See TransmissionMode*
+}
+
+func newDescriptorTerrestrialDeliverySystem(i *astikit.BytesIterator) (d *DescriptorTerrestrialDeliverySystem, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(11); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorTerrestrialDeliverySystem{
+		Bandwidth:            bs[4] >> 5,
+		CodeRateHPStream:     bs[5] & 0x7,
+		CodeRateLPStream:     bs[6] >> 5,
+		Constellation:        bs[5] >> 6,
+		Frequency:            parseFrequency(bs[0:4], FrequencyCodingTypeTerrestrial),
+		GuardInterval:        bs[6] >> 3 & 0x3,
+		HasMPEFEC:            bs[4]&0x4 == 0,
+		HasOtherFrequency:    bs[6]&0x1 > 0,
+		HasTimeSlicing:       bs[4]&0x8 == 0,
+		HierarchyInformation: bs[5] >> 3 & 0x7,
+		HighPriority:         bs[4]&0x10 > 0,
+		TransmissionMode:     bs[6] >> 1 & 0x3,
+	}
+	return
+}
+
+func (d *DescriptorTerrestrialDeliverySystem) serialise(b []byte) (int, error) {
+	bs := []byte{
+		0, 0, 0, 0,
+		d.Bandwidth<<5 | Btou8(d.HighPriority)<<4 | Btou8(!d.HasTimeSlicing)<<3 | Btou8(!d.HasMPEFEC)<<2 | 0x3,
+		d.Constellation<<6 | d.HierarchyInformation&0x7<<3 | d.CodeRateHPStream&0x7,
+		d.CodeRateLPStream<<5 | d.GuardInterval&0x3<<3 | d.TransmissionMode&0x3<<1 | Btou8(d.HasOtherFrequency),
+		0xff, 0xff, 0xff, 0xff,
+	}
+	writeFrequency(bs[0:4], d.Frequency, FrequencyCodingTypeTerrestrial)
+	return serialiseDescriptorBytes(b, bs)
+}
+
+// parseSymbolRate parses a 7 digits BCD symbol rate expressed in Msymbol/s with 4 decimals, followed by 4 bits
+func parseSymbolRate(bs []byte) uint32 {
+	return uint32(parseBCD([]byte{bs[0], bs[1], bs[2], bs[3] & 0xf0})/10) * 100
+}
+
+// writeSymbolRate writes a 7 digits BCD symbol rate followed by the 4 bits FEC inner
+func writeSymbolRate(b []byte, symbolRate uint32, fecInner uint8) {
+	writeBCD(b, uint64(symbolRate/100)*10)
+	b[3] |= fecInner & 0xf
+}
+
+// DescriptorFrequencyList represents a frequency list descriptor, which lists the frequencies a multiplex is
+// transmitted on on top of the one of its delivery system descriptor
+// Chapter: 6.2.17 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.15.01_60/en_300468v011501p.pdf
+type DescriptorFrequencyList struct {
+	CodingType  uint8    // See FrequencyCodingType*
+	Frequencies []uint64 // In Hz, or raw values if the coding type is not defined
+}
+
+func newDescriptorFrequencyList(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorFrequencyList, err error) {
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorFrequencyList{CodingType: b & 0x3}
+
+	// Loop until end of descriptor is reached
+	for i.Offset()+4 <= offsetEnd {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Append frequency
+		d.Frequencies = append(d.Frequencies, parseFrequency(bs, d.CodingType))
+	}
+	return
+}
+
+func (d *DescriptorFrequencyList) serialise(b []byte) (int, error) {
+	bs := make([]byte, 1+4*len(d.Frequencies))
+	bs[0] = 0xfc | d.CodingType&0x3
+	for idx, f := range d.Frequencies {
+		writeFrequency(bs[1+4*idx:5+4*idx], f, d.CodingType)
+	}
+	return serialiseDescriptorBytes(b, bs)
+}
+
+// parseFrequency parses a 32 bits frequency coded as in the delivery system descriptor matching the coding type
+func parseFrequency(bs []byte, codingType uint8) uint64 {
+	switch codingType {
+	case FrequencyCodingTypeSatellite:
+		return parseBCD(bs) * 10000
+	case FrequencyCodingTypeCable:
+		return parseBCD(bs) * 100
+	case FrequencyCodingTypeTerrestrial:
+		return uint64(uint32(bs[0])<<24|uint32(bs[1])<<16|uint32(bs[2])<<8|uint32(bs[3])) * 10
+	default:
+		return uint64(uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3]))
+	}
+}
+
+// writeFrequency writes a 32 bits frequency coded as in the delivery system descriptor matching the coding type
+func writeFrequency(b []byte, f uint64, codingType uint8) {
+	switch codingType {
+	case FrequencyCodingTypeSatellite:
+		writeBCD(b, f/10000)
+		return
+	case FrequencyCodingTypeCable:
+		writeBCD(b, f/100)
+		return
+	case FrequencyCodingTypeTerrestrial:
+		f /= 10
+	}
+	b[0], b[1], b[2], b[3] = uint8(f>>24), uint8(f>>16), uint8(f>>8), uint8(f)
+}
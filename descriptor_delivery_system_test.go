@@ -0,0 +1,182 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDescriptorsDeliverySystem(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf027))                                // Reserved and length
+	w.Write(uint8(DescriptorTagCableDeliverySystem))       // Tag
+	w.Write(uint8(11))                                     // Length
+	w.Write([]byte{0x03, 0x12, 0x00, 0x00})                // Frequency
+	w.Write("111111111111")                                // Reserved
+	w.Write("0010")                                        // FEC outer
+	w.Write(uint8(CableModulation64QAM))                   // Modulation
+	w.Write([]byte{0x00, 0x69, 0x00, 0x03})                // Symbol rate and FEC inner
+	w.Write(uint8(DescriptorTagSatelliteDeliverySystem))   // Tag
+	w.Write(uint8(11))                                     // Length
+	w.Write([]byte{0x01, 0x17, 0x57, 0x25})                // Frequency
+	w.Write([]byte{0x01, 0x92})                            // Orbital position
+	w.Write("1")                                           // West east flag
+	w.Write("01")                                          // Polarization
+	w.Write("10")                                          // Roll off
+	w.Write("1")                                           // Modulation system
+	w.Write("10")                                          // Modulation type
+	w.Write([]byte{0x02, 0x75, 0x00, 0x04})                // Symbol rate and FEC inner
+	w.Write(uint8(DescriptorTagTerrestrialDeliverySystem)) // Tag
+	w.Write(uint8(11))                                     // Length
+	w.Write(uint32(47400000))                              // Centre frequency
+	w.Write("000")                                         // Bandwidth
+	w.Write("1")                                           // Priority
+	w.Write("1")                                           // Time slicing indicator
+	w.Write("0")                                           // MPE-FEC indicator
+	w.Write("11")                                          // Reserved
+	w.Write("10")                                          // Constellation
+	w.Write("000")                                         // Hierarchy information
+	w.Write("010")                                         // Code rate HP stream
+	w.Write("000")                                         // Code rate LP stream
+	w.Write("00")                                          // Guard interval
+	w.Write("01")                                          // Transmission mode
+	w.Write("1")                                           // Other frequency flag
+	w.Write(uint32(0xffffffff))                            // Reserved
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 3)
+	assert.Equal(t, &DescriptorCableDeliverySystem{
+		FECInner:   FECInner3_4,
+		FECOuter:   FECOuterRS204_188,
+		Frequency:  312000000,
+		Modulation: CableModulation64QAM,
+		SymbolRate: 6900000,
+	}, ds[0].CableDeliverySystem)
+	assert.Equal(t, &DescriptorSatelliteDeliverySystem{
+		East:            true,
+		FECInner:        FECInner5_6,
+		Frequency:       11757250000,
+		IsDVBS2:         true,
+		ModulationType:  SatelliteModulation8PSK,
+		OrbitalPosition: 192,
+		Polarization:    PolarizationLinearVertical,
+		RollOff:         RollOff020,
+		SymbolRate:      27500000,
+	}, ds[1].SatelliteDeliverySystem)
+	assert.Equal(t, &DescriptorTerrestrialDeliverySystem{
+		Bandwidth:         TerrestrialBandwidth8MHz,
+		CodeRateHPStream:  FECInner3_4 - 1,
+		Constellation:     TerrestrialConstellation64QAM,
+		Frequency:         474000000,
+		GuardInterval:     GuardInterval1_32,
+		HasMPEFEC:         true,
+		HasOtherFrequency: true,
+		HighPriority:      true,
+		TransmissionMode:  TransmissionMode8k,
+	}, ds[2].TerrestrialDeliverySystem)
+
+	// Serialise
+	b := make([]byte, buf.Len())
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b[:n])
+}
+
+func TestParseDescriptorsS2SatelliteDeliverySystem(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf00b))                                // Reserved and length
+	w.Write(uint8(DescriptorTagS2SatelliteDeliverySystem)) // Tag
+	w.Write(uint8(5))                                      // Length
+	w.Write("1")                                           // Scrambling sequence selector
+	w.Write("1")                                           // Multiple input stream flag
+	w.Write("0")                                           // Reserved zero future use
+	w.Write("1")                                           // Not timeslice flag
+	w.Write("11")                                          // Reserved
+	w.Write("11")                                          // TS/GS mode
+	w.Write("111111")                                      // Reserved
+	w.Write("100000000000000011")                          // Scrambling sequence index
+	w.Write(uint8(7))                                      // Input stream identifier
+	w.Write(uint8(DescriptorTagS2SatelliteDeliverySystem)) // Tag
+	w.Write(uint8(2))                                      // Length
+	w.Write("0")                                           // Scrambling sequence selector
+	w.Write("0")                                           // Multiple input stream flag
+	w.Write("0")                                           // Reserved zero future use
+	w.Write("0")                                           // Not timeslice flag
+	w.Write("11")                                          // Reserved
+	w.Write("00")                                          // TS/GS mode
+	w.Write(uint8(3))                                      // Timeslice number
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 2)
+	assert.Equal(t, &DescriptorS2SatelliteDeliverySystem{
+		HasScramblingSequence:   true,
+		InputStreamIdentifier:   7,
+		MultipleInputStream:     true,
+		NotTimeslice:            true,
+		ScramblingSequenceIndex: 0x20003,
+		TSGSMode:                TSGSModeTransportStream,
+	}, ds[0].S2SatelliteDeliverySystem)
+	assert.Equal(t, &DescriptorS2SatelliteDeliverySystem{
+		TimesliceNumber: 3,
+		TSGSMode:        TSGSModeGenericPacketized,
+	}, ds[1].S2SatelliteDeliverySystem)
+
+	// Serialise
+	b := make([]byte, buf.Len())
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b[:n])
+}
+
+func TestParseDescriptorsFrequencyList(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf019))                    // Reserved and length
+	w.Write(uint8(DescriptorTagFrequencyList)) // Tag
+	w.Write(uint8(9))                          // Length
+	w.Write("111111")                          // Reserved
+	w.Write("11")                              // Coding type
+	w.Write(uint32(47400000))                  // Centre frequency
+	w.Write(uint32(48200000))                  // Centre frequency
+	w.Write(uint8(DescriptorTagFrequencyList)) // Tag
+	w.Write(uint8(5))                          // Length
+	w.Write("111111")                          // Reserved
+	w.Write("01")                              // Coding type
+	w.Write([]byte{0x01, 0x17, 0x57, 0x25})    // Centre frequency
+	w.Write(uint8(DescriptorTagFrequencyList)) // Tag
+	w.Write(uint8(5))                          // Length
+	w.Write("111111")                          // Reserved
+	w.Write("10")                              // Coding type
+	w.Write([]byte{0x03, 0x12, 0x00, 0x00})    // Centre frequency
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 3)
+	assert.Equal(t, &DescriptorFrequencyList{
+		CodingType:  FrequencyCodingTypeTerrestrial,
+		Frequencies: []uint64{474000000, 482000000},
+	}, ds[0].FrequencyList)
+	assert.Equal(t, &DescriptorFrequencyList{
+		CodingType:  FrequencyCodingTypeSatellite,
+		Frequencies: []uint64{11757250000},
+	}, ds[1].FrequencyList)
+	assert.Equal(t, &DescriptorFrequencyList{
+		CodingType:  FrequencyCodingTypeCable,
+		Frequencies: []uint64{312000000},
+	}, ds[2].FrequencyList)
+
+	// Serialise
+	b := make([]byte, buf.Len())
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b[:n])
+}
@@ -0,0 +1,362 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Metadata application formats and formats signalling that a 32 bits identifier follows
+// Chapter: 2.6.56 | Link: https://www.itu.int/rec/T-REC-H.222.0
+const (
+	MetadataApplicationFormatIdentifierField = 0xffff
+	MetadataFormatIdentifierField            = 0xff
+)
+
+// Metadata format identifiers
+const (
+	MetadataFormatIdentifierID3 = 0x49443320 // "ID3 "
+	MetadataFormatIdentifierKLV = 0x4b4c5641 // "KLVA", SMPTE 336M KLV
+)
+
+// Metadata MPEG carriage flags
+// Chapter: 2.6.58 | Link: https://www.itu.int/rec/T-REC-H.222.0
+const (
+	MetadataMPEGCarriageSameTransportStream  = 0x0
+	MetadataMPEGCarriageOtherTransportStream = 0x1
+	MetadataMPEGCarriageProgramStream        = 0x2
+	MetadataMPEGCarriageOther                = 0x3
+)
+
+// Metadata decoder config flags
+// Chapter: 2.6.60 | Link: https://www.itu.int/rec/T-REC-H.222.0
+const (
+	MetadataDecoderConfigNone                       = 0x0
+	MetadataDecoderConfigInDescriptor               = 0x1
+	MetadataDecoderConfigInService                  = 0x2
+	MetadataDecoderConfigInDSMCCCarousel            = 0x3
+	MetadataDecoderConfigInOtherService             = 0x4
+	MetadataDecoderConfigReserved1                  = 0x5
+	MetadataDecoderConfigReserved2                  = 0x6
+	MetadataDecoderConfigPrivatelyDefinedOrExternal = 0x7
+)
+
+// DescriptorMetadataPointer represents a metadata pointer descriptor, which points at the metadata service
+// associated with a program
+// Chapter: 2.6.58 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorMetadataPointer struct {
+	ApplicationFormat           uint16
+	ApplicationFormatIdentifier uint32 // Only set if ApplicationFormat is MetadataApplicationFormatIdentifierField
+	Format                      uint8
+	FormatIdentifier            uint32 // Only set if Format is MetadataFormatIdentifierField
+	HasLocatorRecord            bool
+	LocatorRecord               []byte
+	MPEGCarriage                uint8 // See MetadataMPEGCarriage*
+	PrivateData                 []byte
+	ProgramNumber               uint16 // Only set if MPEGCarriage is lower than MetadataMPEGCarriageOther
+	ServiceID                   uint8
+	TransportStreamID           uint16 // Only set if MPEGCarriage is MetadataMPEGCarriageOtherTransportStream
+	TransportStreamLocation     uint16 // Only set if MPEGCarriage is MetadataMPEGCarriageOtherTransportStream
+}
+
+func newDescriptorMetadataPointer(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorMetadataPointer, err error) {
+	// Create descriptor
+	d = &DescriptorMetadataPointer{}
+
+	// Formats
+	if d.ApplicationFormat, d.ApplicationFormatIdentifier, d.Format, d.FormatIdentifier, err = parseMetadataFormats(i); err != nil {
+		err = fmt.Errorf("astits: parsing metadata formats failed: %w", err)
+		return
+	}
+
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Service ID and flags
+	d.ServiceID = bs[0]
+	d.HasLocatorRecord = bs[1]&0x80 > 0
+	d.MPEGCarriage = bs[1] >> 5 & 0x3
+
+	// Locator record
+	if d.HasLocatorRecord {
+		if d.LocatorRecord, err = parseDescriptorLengthBytes(i); err != nil {
+			err = fmt.Errorf("astits: parsing locator record failed: %w", err)
+			return
+		}
+	}
+
+	// Program number
+	if d.MPEGCarriage <= MetadataMPEGCarriageProgramStream {
+		if bs, err = i.NextBytes(2); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.ProgramNumber = uint16(bs[0])<<8 | uint16(bs[1])
+	}
+
+	// Transport stream
+	if d.MPEGCarriage == MetadataMPEGCarriageOtherTransportStream {
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		d.TransportStreamLocation = uint16(bs[0])<<8 | uint16(bs[1])
+		d.TransportStreamID = uint16(bs[2])<<8 | uint16(bs[3])
+	}
+
+	// Private data
+	if i.Offset() < offsetEnd {
+		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+func (d *DescriptorMetadataPointer) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := writeMetadataFormats(w, d.ApplicationFormat, d.ApplicationFormatIdentifier, d.Format, d.FormatIdentifier); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("metadata service id", d.ServiceID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("flags", Btou8(d.HasLocatorRecord)<<7|(d.MPEGCarriage&0x3)<<5|0x1f); err != nil {
+		return w.offset, err
+	}
+	if d.HasLocatorRecord {
+		if err := writeDescriptorLengthBytes(w, "metadata locator record", d.LocatorRecord); err != nil {
+			return w.offset, err
+		}
+	}
+	if d.MPEGCarriage <= MetadataMPEGCarriageProgramStream {
+		if err := w.writeUint16("program number", d.ProgramNumber); err != nil {
+			return w.offset, err
+		}
+	}
+	if d.MPEGCarriage == MetadataMPEGCarriageOtherTransportStream {
+		if err := w.writeUint16("transport stream location", d.TransportStreamLocation); err != nil {
+			return w.offset, err
+		}
+		if err := w.writeUint16("transport stream id", d.TransportStreamID); err != nil {
+			return w.offset, err
+		}
+	}
+	if err := w.writeBytes("private data", d.PrivateData); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
+// DescriptorMetadata represents a metadata descriptor, which describes a metadata service carried by an
+// elementary stream
+// Chapter: 2.6.60 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorMetadata struct {
+	ApplicationFormat                 uint16
+	ApplicationFormatIdentifier       uint32 // Only set if ApplicationFormat is MetadataApplicationFormatIdentifierField
+	DecoderConfig                     []byte // Only set if DecoderConfigFlags is MetadataDecoderConfigInDescriptor
+	DecoderConfigFlags                uint8  // See MetadataDecoderConfig*
+	DecoderConfigIdentificationRecord []byte // Only set if DecoderConfigFlags is MetadataDecoderConfigInDSMCCCarousel
+	DecoderConfigServiceID            uint8  // Only set if DecoderConfigFlags is MetadataDecoderConfigInOtherService
+	Format                            uint8
+	FormatIdentifier                  uint32 // Only set if Format is MetadataFormatIdentifierField
+	HasServiceIdentification          bool   // DSM-CC flag
+	PrivateData                       []byte
+	ReservedData                      []byte // Only set if DecoderConfigFlags is a reserved value with a length
+	ServiceID                         uint8
+	ServiceIdentification             []byte
+}
+
+func newDescriptorMetadata(i *astikit.BytesIterator, offsetEnd int) (d *DescriptorMetadata, err error) {
+	// Create descriptor
+	d = &DescriptorMetadata{}
+
+	// Formats
+	if d.ApplicationFormat, d.ApplicationFormatIdentifier, d.Format, d.FormatIdentifier, err = parseMetadataFormats(i); err != nil {
+		err = fmt.Errorf("astits: parsing metadata formats failed: %w", err)
+		return
+	}
+
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Service ID and flags
+	d.ServiceID = bs[0]
+	d.DecoderConfigFlags = bs[1] >> 5
+	d.HasServiceIdentification = bs[1]&0x10 > 0
+
+	// Service identification
+	if d.HasServiceIdentification {
+		if d.ServiceIdentification, err = parseDescriptorLengthBytes(i); err != nil {
+			err = fmt.Errorf("astits: parsing service identification failed: %w", err)
+			return
+		}
+	}
+
+	// Decoder config
+	switch d.DecoderConfigFlags {
+	case MetadataDecoderConfigInDescriptor:
+		if d.DecoderConfig, err = parseDescriptorLengthBytes(i); err != nil {
+			err = fmt.Errorf("astits: parsing decoder config failed: %w", err)
+			return
+		}
+	case MetadataDecoderConfigInDSMCCCarousel:
+		if d.DecoderConfigIdentificationRecord, err = parseDescriptorLengthBytes(i); err != nil {
+			err = fmt.Errorf("astits: parsing decoder config identification record failed: %w", err)
+			return
+		}
+	case MetadataDecoderConfigInOtherService:
+		if d.DecoderConfigServiceID, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+	case MetadataDecoderConfigReserved1, MetadataDecoderConfigReserved2:
+		if d.ReservedData, err = parseDescriptorLengthBytes(i); err != nil {
+			err = fmt.Errorf("astits: parsing reserved data failed: %w", err)
+			return
+		}
+	}
+
+	// Private data
+	if i.Offset() < offsetEnd {
+		if d.PrivateData, err = i.NextBytes(offsetEnd - i.Offset()); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+func (d *DescriptorMetadata) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := writeMetadataFormats(w, d.ApplicationFormat, d.ApplicationFormatIdentifier, d.Format, d.FormatIdentifier); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("metadata service id", d.ServiceID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("flags", (d.DecoderConfigFlags&0x7)<<5|Btou8(d.HasServiceIdentification)<<4|0xf); err != nil {
+		return w.offset, err
+	}
+	if d.HasServiceIdentification {
+		if err := writeDescriptorLengthBytes(w, "service identification record", d.ServiceIdentification); err != nil {
+			return w.offset, err
+		}
+	}
+	switch d.DecoderConfigFlags {
+	case MetadataDecoderConfigInDescriptor:
+		if err := writeDescriptorLengthBytes(w, "decoder config", d.DecoderConfig); err != nil {
+			return w.offset, err
+		}
+	case MetadataDecoderConfigInDSMCCCarousel:
+		if err := writeDescriptorLengthBytes(w, "decoder config identification record", d.DecoderConfigIdentificationRecord); err != nil {
+			return w.offset, err
+		}
+	case MetadataDecoderConfigInOtherService:
+		if err := w.writeUint8("decoder config metadata service id", d.DecoderConfigServiceID); err != nil {
+			return w.offset, err
+		}
+	case MetadataDecoderConfigReserved1, MetadataDecoderConfigReserved2:
+		if err := writeDescriptorLengthBytes(w, "reserved data", d.ReservedData); err != nil {
+			return w.offset, err
+		}
+	}
+	if err := w.writeBytes("private data", d.PrivateData); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
+// DescriptorMetadataSTD represents a metadata STD descriptor, which defines the buffer model of a metadata stream
+// Chapter: 2.6.62 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorMetadataSTD struct {
+	BufferSize     uint32 // 22 bits, in units of 1024 bytes
+	InputLeakRate  uint32 // 22 bits, in units of 400 bits/s
+	OutputLeakRate uint32 // 22 bits, in units of 400 bits/s
+}
+
+func newDescriptorMetadataSTD(i *astikit.BytesIterator) (d *DescriptorMetadataSTD, err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(9); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Create descriptor
+	d = &DescriptorMetadataSTD{
+		BufferSize:     uint32(bs[3]&0x3f)<<16 | uint32(bs[4])<<8 | uint32(bs[5]),
+		InputLeakRate:  uint32(bs[0]&0x3f)<<16 | uint32(bs[1])<<8 | uint32(bs[2]),
+		OutputLeakRate: uint32(bs[6]&0x3f)<<16 | uint32(bs[7])<<8 | uint32(bs[8]),
+	}
+	return
+}
+
+func (d *DescriptorMetadataSTD) serialise(b []byte) (int, error) {
+	bs := make([]byte, 0, 9)
+	for _, v := range []uint32{d.InputLeakRate, d.BufferSize, d.OutputLeakRate} {
+		bs = append(bs, 0xc0|uint8(v>>16)&0x3f, uint8(v>>8), uint8(v))
+	}
+	return serialiseDescriptorBytes(b, bs)
+}
+
+// parseMetadataFormats parses the metadata application format and format, followed by their identifier if need be
+func parseMetadataFormats(i *astikit.BytesIterator) (applicationFormat uint16, applicationFormatIdentifier uint32, format uint8, formatIdentifier uint32, err error) {
+	// Application format
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+	applicationFormat = uint16(bs[0])<<8 | uint16(bs[1])
+	if applicationFormat == MetadataApplicationFormatIdentifierField {
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		applicationFormatIdentifier = uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])
+	}
+
+	// Format
+	if format, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+	if format == MetadataFormatIdentifierField {
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		formatIdentifier = uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])
+	}
+	return
+}
+
+func writeMetadataFormats(w *checkedWriter, applicationFormat uint16, applicationFormatIdentifier uint32, format uint8, formatIdentifier uint32) error {
+	if err := w.writeUint16("metadata application format", applicationFormat); err != nil {
+		return err
+	}
+	if applicationFormat == MetadataApplicationFormatIdentifierField {
+		if err := w.writeUint32("metadata application format identifier", applicationFormatIdentifier); err != nil {
+			return err
+		}
+	}
+	if err := w.writeUint8("metadata format", format); err != nil {
+		return err
+	}
+	if format == MetadataFormatIdentifierField {
+		if err := w.writeUint32("metadata format identifier", formatIdentifier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -221,7 +221,7 @@ func TestParseDescriptor(t *testing.T) {
 	// Extension unknown
 	w.Write(uint8(DescriptorTagExtension)) // Tag
 	w.Write(uint8(5))                      // Length
-	w.Write(uint8(0))                      // Extension tag
+	w.Write(uint8(0xff))                   // Extension tag
 	w.Write([]byte("test"))                // Content
 
 	// Assert
@@ -386,3 +386,562 @@ func TestParseDescriptor(t *testing.T) {
 	})
 	assert.Equal(t, *ds[25].Extension.Unknown, []byte("test"))
 }
+
+func TestDescriptorSerialise(t *testing.T) {
+	// From struct
+	ds := []*Descriptor{
+		{NetworkName: &DescriptorNetworkName{Name: []byte("network")}, Tag: DescriptorTagNetworkName},
+		{Service: &DescriptorService{Name: []byte("name"), Provider: []byte("provider"), Type: ServiceTypeDigitalTelevisionService}, Tag: DescriptorTagService},
+		{Tag: 0x80, UserDefined: []byte("user")},
+		{ShortEvent: &DescriptorShortEvent{EventName: []byte("name"), Language: []byte("eng"), Text: []byte("text")}, Tag: DescriptorTagShortEvent},
+		{ExtendedEvent: &DescriptorExtendedEvent{
+			ISO639LanguageCode:   []byte("eng"),
+			Items:                []*DescriptorExtendedEventItem{{Content: []byte("content"), Description: []byte("description")}},
+			LastDescriptorNumber: 2,
+			Number:               1,
+			Text:                 []byte("text"),
+		}, Tag: DescriptorTagExtendedEvent},
+		{Content: &DescriptorContent{Items: []*DescriptorContentItem{{ContentNibbleLevel1: 1, ContentNibbleLevel2: 2, UserByte: 3}}}, Tag: DescriptorTagContent},
+		{ParentalRating: &DescriptorParentalRating{Items: []*DescriptorParentalRatingItem{{CountryCode: []byte("fra"), Rating: 4}}}, Tag: DescriptorTagParentalRating},
+		{StreamIdentifier: &DescriptorStreamIdentifier{ComponentTag: 5}, Tag: DescriptorTagStreamIdentifier},
+	}
+	b := make([]byte, 1024)
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	o, err := parseDescriptors(astikit.NewBytesIterator(b[:n]))
+	assert.NoError(t, err)
+	for idx := range o {
+		o[idx].originalBytes = nil
+	}
+	ds[0].Length = 7
+	ds[1].Length = 15
+	ds[2].Length = 4
+	ds[3].Length = 13
+	ds[4].Length = 30
+	ds[5].Length = 2
+	ds[6].Length = 4
+	ds[7].Length = 1
+	assert.Equal(t, ds, o)
+
+	// Unsupported
+	_, err = (&Descriptor{Component: &DescriptorComponent{}, Length: 6, Tag: DescriptorTagComponent}).Serialise(b)
+	assert.Error(t, err)
+
+	// No room in buffer
+	_, err = ds[1].Serialise(make([]byte, 5))
+	assert.Equal(t, ErrNoRoomInBuffer, err)
+
+	// Service name too long
+	_, err = (&Descriptor{Service: &DescriptorService{Name: make([]byte, 0x100)}, Tag: DescriptorTagService}).Serialise(b)
+	assert.EqualError(t, err, "astits: serialising descriptor content failed: astits: service name length 256 is bigger than 255")
+}
+
+func TestParseDescriptorsPrivateDataSpecifierScope(t *testing.T) {
+	lcn := []byte{0x0, 0x1, 0xfc, 0x5}                                              // Service 1, visible, LCN 5
+	lcnV2 := append([]byte{0x2, 0x1, 'a', 'f', 'r', 'a', 0x4}, 0x0, 0x2, 0x7c, 0x6) // Service 2, invisible, LCN 6
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0)) // Reserved and length, overwritten afterwards
+	w.Write(uint8(DescriptorTagEACEMLogicalChannelNumber))
+	w.Write(uint8(len(lcn)))
+	w.Write(lcn)
+	w.Write(uint8(DescriptorTagPrivateDataSpecifier))
+	w.Write(uint8(4))
+	w.Write(uint32(PrivateDataSpecifierEACEM))
+	w.Write(uint8(DescriptorTagEACEMLogicalChannelNumber))
+	w.Write(uint8(len(lcn)))
+	w.Write(lcn)
+	w.Write(uint8(DescriptorTagEACEMLogicalChannelNumberV2))
+	w.Write(uint8(len(lcnV2)))
+	w.Write(lcnV2)
+	b := buf.Bytes()
+	b[1] = uint8(len(b) - 2)
+	ds, err := parseDescriptors(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 4)
+
+	// No private data specifier in effect
+	assert.Equal(t, uint32(0), ds[0].PrivateDataSpecifierScope)
+	assert.Nil(t, ds[0].LogicalChannelNumber)
+	assert.Equal(t, lcn, ds[0].UserDefined)
+
+	// EACEM private data specifier in effect
+	assert.Equal(t, uint32(PrivateDataSpecifierEACEM), ds[2].PrivateDataSpecifierScope)
+	assert.Equal(t, &DescriptorLogicalChannelNumber{Items: []*DescriptorLogicalChannelNumberItem{{LogicalChannelNumber: 5, ServiceID: 1, VisibleServiceFlag: true}}}, ds[2].LogicalChannelNumber)
+	assert.Equal(t, &DescriptorLogicalChannelNumberV2{ChannelLists: []*DescriptorLogicalChannelNumberV2ChannelList{{
+		ChannelListID:   2,
+		ChannelListName: []byte("a"),
+		CountryCode:     []byte("fra"),
+		Items:           []*DescriptorLogicalChannelNumberItem{{LogicalChannelNumber: 6, ServiceID: 2}},
+	}}}, ds[3].LogicalChannelNumberV2)
+}
+
+func TestParseDescriptorsExtension(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0)) // Reserved and length, overwritten afterwards
+	// CP
+	w.Write(uint8(DescriptorTagExtension))   // Tag
+	w.Write(uint8(8))                        // Length
+	w.Write(uint8(DescriptorTagExtensionCP)) // Extension tag
+	w.Write(uint16(1))                       // CP system ID
+	w.Write("111")                           // Reserved
+	w.Write("0000100000000")                 // CP PID
+	w.Write([]byte("pri"))                   // Private data
+	// Image icon with URL
+	w.Write(uint8(DescriptorTagExtension))          // Tag
+	w.Write(uint8(15))                              // Length
+	w.Write(uint8(DescriptorTagExtensionImageIcon)) // Extension tag
+	w.Write("0000")                                 // Descriptor number
+	w.Write("0001")                                 // Last descriptor number
+	w.Write("11111")                                // Reserved
+	w.Write("010")                                  // Icon ID
+	w.Write("01")                                   // Icon transport mode
+	w.Write("1")                                    // Position flag
+	w.Write("001")                                  // Coordinate system
+	w.Write("11")                                   // Reserved
+	w.Write("000000001010")                         // Icon horizontal origin
+	w.Write("000000010100")                         // Icon vertical origin
+	w.Write(uint8(3))                               // Icon type length
+	w.Write([]byte("png"))                          // Icon type
+	w.Write(uint8(3))                               // URL length
+	w.Write([]byte("url"))                          // URL
+	// Image icon with data
+	w.Write(uint8(DescriptorTagExtension))          // Tag
+	w.Write(uint8(7))                               // Length
+	w.Write(uint8(DescriptorTagExtensionImageIcon)) // Extension tag
+	w.Write("0001")                                 // Descriptor number
+	w.Write("0001")                                 // Last descriptor number
+	w.Write("11111")                                // Reserved
+	w.Write("010")                                  // Icon ID
+	w.Write(uint8(3))                               // Icon data length
+	w.Write([]byte("dat"))                          // Icon data
+	// Service relocated
+	w.Write(uint8(DescriptorTagExtension))                 // Tag
+	w.Write(uint8(7))                                      // Length
+	w.Write(uint8(DescriptorTagExtensionServiceRelocated)) // Extension tag
+	w.Write(uint16(1))                                     // Old original network ID
+	w.Write(uint16(2))                                     // Old transport stream ID
+	w.Write(uint16(3))                                     // Old service ID
+	// URI linkage
+	w.Write(uint8(DescriptorTagExtension))           // Tag
+	w.Write(uint8(10))                               // Length
+	w.Write(uint8(DescriptorTagExtensionURILinkage)) // Extension tag
+	w.Write(uint8(URILinkageTypeOnlineSDT))          // URI linkage type
+	w.Write(uint8(3))                                // URI length
+	w.Write([]byte("uri"))                           // URI
+	w.Write(uint16(30))                              // Min polling interval
+	w.Write([]byte("pr"))                            // Private data
+	// AC-4
+	w.Write(uint8(DescriptorTagExtension))    // Tag
+	w.Write(uint8(9))                         // Length
+	w.Write(uint8(DescriptorTagExtensionAC4)) // Extension tag
+	w.Write("1")                              // AC-4 config flag
+	w.Write("1")                              // AC-4 TOC flag
+	w.Write("000000")                         // Reserved
+	w.Write("1")                              // AC-4 dialog enhancement enabled
+	w.Write("10")                             // AC-4 channel mode
+	w.Write("00000")                          // Reserved
+	w.Write(uint8(3))                         // AC-4 TOC length
+	w.Write([]byte("toc"))                    // AC-4 TOC
+	w.Write([]byte("ai"))                     // Additional info
+	// AC-4 without config nor TOC
+	w.Write(uint8(DescriptorTagExtension))    // Tag
+	w.Write(uint8(2))                         // Length
+	w.Write(uint8(DescriptorTagExtensionAC4)) // Extension tag
+	w.Write(uint8(0))                         // Flags and reserved
+	b := buf.Bytes()
+	b[1] = uint8(len(b) - 2)
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 7)
+	assert.Equal(t, &DescriptorExtensionCP{CPPID: 256, CPSystemID: 1, PrivateData: []byte("pri")}, ds[0].Extension.CP)
+	assert.Equal(t, &DescriptorExtensionImageIcon{
+		CoordinateSystem:     1,
+		HasPosition:          true,
+		IconHorizontalOrigin: 10,
+		IconID:               2,
+		IconTransportMode:    ImageIconTransportModeURL,
+		IconType:             []byte("png"),
+		IconVerticalOrigin:   20,
+		LastDescriptorNumber: 1,
+		URL:                  []byte("url"),
+	}, ds[1].Extension.ImageIcon)
+	assert.Equal(t, &DescriptorExtensionImageIcon{
+		DescriptorNumber:     1,
+		IconData:             []byte("dat"),
+		IconID:               2,
+		LastDescriptorNumber: 1,
+	}, ds[2].Extension.ImageIcon)
+	assert.Equal(t, &DescriptorExtensionServiceRelocated{OldOriginalNetworkID: 1, OldServiceID: 3, OldTransportStreamID: 2}, ds[3].Extension.ServiceRelocated)
+	assert.Equal(t, &DescriptorExtensionURILinkage{
+		HasMinPollingInterval: true,
+		MinPollingInterval:    30,
+		PrivateData:           []byte("pr"),
+		URI:                   []byte("uri"),
+		URILinkageType:        URILinkageTypeOnlineSDT,
+	}, ds[4].Extension.URILinkage)
+	assert.Equal(t, &DescriptorExtensionAC4{
+		AdditionalInfo:           []byte("ai"),
+		ChannelMode:              AC4ChannelModeMultichannel,
+		DialogEnhancementEnabled: true,
+		HasConfig:                true,
+		HasTOC:                   true,
+		TOC:                      []byte("toc"),
+	}, ds[5].Extension.AC4)
+	assert.Equal(t, &DescriptorExtensionAC4{}, ds[6].Extension.AC4)
+}
+
+func TestParseDescriptorsLinkage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0)) // Reserved and length, overwritten afterwards
+	// Mobile hand-over
+	w.Write(uint8(DescriptorTagLinkage))      // Tag
+	w.Write(uint8(13))                        // Length
+	w.Write(uint16(1))                        // Transport stream ID
+	w.Write(uint16(2))                        // Original network ID
+	w.Write(uint16(3))                        // Service ID
+	w.Write(uint8(LinkageTypeMobileHandOver)) // Linkage type
+	w.Write("0001")                           // Hand-over type
+	w.Write("111")                            // Reserved
+	w.Write("0")                              // Origin type
+	w.Write(uint16(4))                        // Network ID
+	w.Write(uint16(5))                        // Initial service ID
+	w.Write([]byte("p"))                      // Private data
+	// SSU
+	w.Write(uint8(DescriptorTagLinkage))                   // Tag
+	w.Write(uint8(14))                                     // Length
+	w.Write(uint16(1))                                     // Transport stream ID
+	w.Write(uint16(2))                                     // Original network ID
+	w.Write(uint16(3))                                     // Service ID
+	w.Write(uint8(LinkageTypeSystemSoftwareUpdateService)) // Linkage type
+	w.Write(uint8(6))                                      // OUI data length
+	w.Write([]byte{0x0, 0x1, 0x5a})                        // OUI
+	w.Write(uint8(2))                                      // Selector length
+	w.Write([]byte("se"))                                  // Selector
+	// Event linkage
+	w.Write(uint8(DescriptorTagLinkage))    // Tag
+	w.Write(uint8(10))                      // Length
+	w.Write(uint16(1))                      // Transport stream ID
+	w.Write(uint16(2))                      // Original network ID
+	w.Write(uint16(3))                      // Service ID
+	w.Write(uint8(LinkageTypeEventLinkage)) // Linkage type
+	w.Write(uint16(6))                      // Target event ID
+	w.Write("1")                            // Target listed
+	w.Write("0")                            // Event simulcast
+	w.Write("111111")                       // Reserved
+	b := buf.Bytes()
+	b[1] = uint8(len(b) - 2)
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 3)
+	assert.Equal(t, &DescriptorLinkage{
+		MobileHandOver:    &DescriptorLinkageMobileHandOver{HandOverType: MobileHandOverTypeIdenticalService, InitialServiceID: 5, NetworkID: 4},
+		OriginalNetworkID: 2,
+		PrivateData:       []byte("p"),
+		ServiceID:         3,
+		TransportStreamID: 1,
+		Type:              LinkageTypeMobileHandOver,
+	}, ds[0].Linkage)
+	assert.Equal(t, &DescriptorLinkageSSU{Items: []*DescriptorLinkageSSUItem{{OUI: 0x15a, SelectorData: []byte("se")}}}, ds[1].Linkage.SSU)
+	assert.Equal(t, &DescriptorLinkageEventLinkage{TargetEventID: 6, TargetListed: true}, ds[2].Linkage.EventLinkage)
+}
+
+func TestParseDescriptorsAnnouncementSupportAndNVODReference(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0)) // Reserved and length, overwritten afterwards
+	// Announcement support
+	w.Write(uint8(DescriptorTagAnnouncementSupport)) // Tag
+	w.Write(uint8(11))                               // Length
+	w.Write(uint16(0x5))                             // Announcement support indicator
+	w.Write("0000")                                  // Announcement type
+	w.Write("1")                                     // Reserved
+	w.Write("000")                                   // Reference type
+	w.Write("0010")                                  // Announcement type
+	w.Write("1")                                     // Reserved
+	w.Write("010")                                   // Reference type
+	w.Write(uint16(1))                               // Original network ID
+	w.Write(uint16(2))                               // Transport stream ID
+	w.Write(uint16(3))                               // Service ID
+	w.Write(uint8(4))                                // Component tag
+	// NVOD reference
+	w.Write(uint8(DescriptorTagNVODReference)) // Tag
+	w.Write(uint8(6))                          // Length
+	w.Write(uint16(1))                         // Transport stream ID
+	w.Write(uint16(2))                         // Original network ID
+	w.Write(uint16(3))                         // Service ID
+	b := buf.Bytes()
+	b[1] = uint8(len(b) - 2)
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 2)
+	assert.Equal(t, &DescriptorAnnouncementSupport{
+		AnnouncementSupportIndicator: 0x5,
+		Items: []*DescriptorAnnouncementSupportItem{
+			{},
+			{AnnouncementType: 2, ComponentTag: 4, OriginalNetworkID: 1, ReferenceType: AnnouncementReferenceTypeSeparateService, ServiceID: 3, TransportStreamID: 2},
+		},
+	}, ds[0].AnnouncementSupport)
+	assert.Equal(t, &DescriptorNVODReference{Items: []*DescriptorNVODReferenceItem{{OriginalNetworkID: 2, ServiceID: 3, TransportStreamID: 1}}}, ds[1].NVODReference)
+}
+
+func TestParseDescriptorSystemClock(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(4))                       // Reserved and length
+	w.Write(uint8(DescriptorTagSystemClock)) // Tag
+	w.Write(uint8(2))                        // Length
+	w.Write("1")                             // External clock reference indicator
+	w.Write("1")                             // Reserved
+	w.Write("000101")                        // Clock accuracy integer
+	w.Write("011")                           // Clock accuracy exponent
+	w.Write("11111")                         // Reserved
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, &DescriptorSystemClock{
+		ClockAccuracyExponent:           3,
+		ClockAccuracyInteger:            5,
+		ExternalClockReferenceIndicator: true,
+	}, ds[0].SystemClock)
+	assert.InDelta(t, 0.005, ds[0].SystemClock.ClockAccuracy(), 1e-9)
+	assert.Equal(t, float64(30), (&DescriptorSystemClock{}).ClockAccuracy())
+
+	// Serialise
+	b := make([]byte, 4)
+	n, err := ds[0].Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes()[2:], b[:n])
+}
+
+func TestParseDescriptorsSmoothingBufferSTDAndIBP(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf00f))                      // Reserved and length
+	w.Write(uint8(DescriptorTagSmoothingBuffer)) // Tag
+	w.Write(uint8(6))                            // Length
+	w.Write("11")                                // Reserved
+	w.Write("0000000000000100000000")            // Leak rate
+	w.Write("11")                                // Reserved
+	w.Write("0000000000001000000000")            // Size
+	w.Write(uint8(DescriptorTagSTD))             // Tag
+	w.Write(uint8(1))                            // Length
+	w.Write("1111111")                           // Reserved
+	w.Write("1")                                 // Leak valid flag
+	w.Write(uint8(DescriptorTagIBP))             // Tag
+	w.Write(uint8(2))                            // Length
+	w.Write("1")                                 // Closed GOP flag
+	w.Write("0")                                 // Identical GOP flag
+	w.Write("00000000001111")                    // Max GOP length
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 3)
+	assert.Equal(t, &DescriptorSmoothingBuffer{LeakRate: 256, Size: 512}, ds[0].SmoothingBuffer)
+	assert.Equal(t, &DescriptorSTD{LeakValid: true}, ds[1].STD)
+	assert.Equal(t, &DescriptorIBP{ClosedGOP: true, MaxGOPLength: 15}, ds[2].IBP)
+
+	// Serialise
+	b := make([]byte, buf.Len())
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b[:n])
+}
+
+func TestParseDescriptorsMPEG4VideoAndHierarchy(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf009))                 // Reserved and length
+	w.Write(uint8(DescriptorTagMPEG4Video)) // Tag
+	w.Write(uint8(1))                       // Length
+	w.Write(uint8(0xf5))                    // Profile and level
+	w.Write(uint8(DescriptorTagHierarchy))  // Tag
+	w.Write(uint8(4))                       // Length
+	w.Write("1")                            // No view scalability flag
+	w.Write("0")                            // No temporal scalability flag
+	w.Write("1")                            // No spatial scalability flag
+	w.Write("1")                            // No quality scalability flag
+	w.Write("0001")                         // Hierarchy type
+	w.Write("11")                           // Reserved
+	w.Write("000010")                       // Hierarchy layer index
+	w.Write("0")                            // TREF present flag
+	w.Write("1")                            // Reserved
+	w.Write("000001")                       // Hierarchy embedded layer index
+	w.Write("11")                           // Reserved
+	w.Write("000011")                       // Hierarchy channel
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 2)
+	assert.Equal(t, &DescriptorMPEG4Video{ProfileAndLevel: 0xf5}, ds[0].MPEG4Video)
+	assert.Equal(t, &DescriptorHierarchy{
+		Channel:              3,
+		EmbeddedLayerIndex:   1,
+		LayerIndex:           2,
+		NoQualityScalability: true,
+		NoSpatialScalability: true,
+		NoViewScalability:    true,
+		TrefPresent:          true,
+		Type:                 HierarchyTypeSpatialScalability,
+	}, ds[1].Hierarchy)
+
+	// Serialise
+	b := make([]byte, buf.Len())
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b[:n])
+}
+
+func TestParseDescriptorsApplicationSignalling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf008))                            // Reserved and length
+	w.Write(uint8(DescriptorTagApplicationSignalling)) // Tag
+	w.Write(uint8(6))                                  // Length
+	w.Write("1")                                       // Reserved
+	w.Write("000000000010000")                         // Application type
+	w.Write("111")                                     // Reserved
+	w.Write("00011")                                   // AIT version number
+	w.Write("1")                                       // Reserved
+	w.Write("000000000000001")                         // Application type
+	w.Write("111")                                     // Reserved
+	w.Write("11111")                                   // AIT version number
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, &DescriptorApplicationSignalling{Items: []*DescriptorApplicationSignallingItem{
+		{AITVersionNumber: 3, ApplicationType: 0x10},
+		{AITVersionNumber: 31, ApplicationType: 0x1},
+	}}, ds[0].ApplicationSignalling)
+
+	// Serialise
+	b := make([]byte, buf.Len())
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b[:n])
+}
+
+func TestParseDescriptorsCountryAvailability(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf009))                          // Reserved and length
+	w.Write(uint8(DescriptorTagCountryAvailability)) // Tag
+	w.Write(uint8(7))                                // Length
+	w.Write("1")                                     // Country availability flag
+	w.Write("1111111")                               // Reserved
+	w.Write([]byte("FRA"))                           // Country code
+	w.Write([]byte("GBR"))                           // Country code
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.Equal(t, &DescriptorCountryAvailability{
+		CountryAvailabilityFlag: true,
+		CountryCodes:            [][]byte{[]byte("FRA"), []byte("GBR")},
+	}, ds[0].CountryAvailability)
+
+	// Serialise
+	b := make([]byte, buf.Len())
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b[:n])
+
+	// Invalid country code
+	ds[0].CountryAvailability.CountryCodes = [][]byte{[]byte("FR")}
+	_, err = serialiseDescriptors(b, ds)
+	assert.Error(t, err)
+}
+
+func TestParseDescriptorsMetadata(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(0xf000))                                   // Reserved and length, overwritten afterwards
+	w.Write(uint8(DescriptorTagMetadataPointer))              // Tag
+	w.Write(uint8(17))                                        // Length
+	w.Write(uint16(0x100))                                    // Metadata application format
+	w.Write(uint8(MetadataFormatIdentifierField))             // Metadata format
+	w.Write(uint32(MetadataFormatIdentifierKLV))              // Metadata format identifier
+	w.Write(uint8(1))                                         // Metadata service ID
+	w.Write("1")                                              // Metadata locator record flag
+	w.Write("01")                                             // MPEG carriage flags
+	w.Write("11111")                                          // Reserved
+	w.Write(uint8(1))                                         // Metadata locator record length
+	w.Write([]byte("l"))                                      // Metadata locator record
+	w.Write(uint16(2))                                        // Program number
+	w.Write(uint16(3))                                        // Transport stream location
+	w.Write(uint16(4))                                        // Transport stream ID
+	w.Write(uint8(DescriptorTagMetadata))                     // Tag
+	w.Write(uint8(17))                                        // Length
+	w.Write(uint16(MetadataApplicationFormatIdentifierField)) // Metadata application format
+	w.Write(uint32(5))                                        // Metadata application format identifier
+	w.Write(uint8(6))                                         // Metadata format
+	w.Write(uint8(1))                                         // Metadata service ID
+	w.Write("001")                                            // Decoder config flags
+	w.Write("1")                                              // DSM-CC flag
+	w.Write("1111")                                           // Reserved
+	w.Write(uint8(2))                                         // Service identification length
+	w.Write([]byte("si"))                                     // Service identification record
+	w.Write(uint8(2))                                         // Decoder config length
+	w.Write([]byte("dc"))                                     // Decoder config
+	w.Write([]byte("pd"))                                     // Private data
+	w.Write(uint8(DescriptorTagMetadataSTD))                  // Tag
+	w.Write(uint8(9))                                         // Length
+	w.Write("11")                                             // Reserved
+	w.Write("0000000000000000000001")                         // Metadata input leak rate
+	w.Write("11")                                             // Reserved
+	w.Write("0000000000000000000010")                         // Metadata buffer size
+	w.Write("11")                                             // Reserved
+	w.Write("0000000000000000000011")                         // Metadata output leak rate
+	b := buf.Bytes()
+	b[1] = uint8(len(b) - 2)
+
+	// Assert
+	ds, err := parseDescriptors(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Len(t, ds, 3)
+	assert.Equal(t, &DescriptorMetadataPointer{
+		ApplicationFormat:       0x100,
+		Format:                  MetadataFormatIdentifierField,
+		FormatIdentifier:        MetadataFormatIdentifierKLV,
+		HasLocatorRecord:        true,
+		LocatorRecord:           []byte("l"),
+		MPEGCarriage:            MetadataMPEGCarriageOtherTransportStream,
+		ProgramNumber:           2,
+		ServiceID:               1,
+		TransportStreamID:       4,
+		TransportStreamLocation: 3,
+	}, ds[0].MetadataPointer)
+	assert.Equal(t, &DescriptorMetadata{
+		ApplicationFormat:           MetadataApplicationFormatIdentifierField,
+		ApplicationFormatIdentifier: 5,
+		DecoderConfig:               []byte("dc"),
+		DecoderConfigFlags:          MetadataDecoderConfigInDescriptor,
+		Format:                      6,
+		HasServiceIdentification:    true,
+		PrivateData:                 []byte("pd"),
+		ServiceID:                   1,
+		ServiceIdentification:       []byte("si"),
+	}, ds[1].Metadata)
+	assert.Equal(t, &DescriptorMetadataSTD{BufferSize: 2, InputLeakRate: 1, OutputLeakRate: 3}, ds[2].MetadataSTD)
+
+	// Serialise
+	v := make([]byte, len(b))
+	n, err := serialiseDescriptors(v, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, b, v[:n])
+}
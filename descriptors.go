@@ -0,0 +1,86 @@
+package astits
+
+import (
+	"fmt"
+)
+
+// Maximum length of a descriptors loop, its length being written on 12 bits
+const descriptorsMaxLoopLength = 0xfff
+
+// Descriptors represents a descriptors loop
+// Descriptor fields can be converted to it in order to use its helpers, as in Descriptors(pmt.ProgramDescriptors).
+type Descriptors []*Descriptor
+
+// Find returns the first descriptor with the tag, or nil if there is none
+func (ds Descriptors) Find(tag uint8) *Descriptor {
+	for _, d := range ds {
+		if d.Tag == tag {
+			return d
+		}
+	}
+	return nil
+}
+
+// FindAll returns all descriptors with the tag, in order
+func (ds Descriptors) FindAll(tag uint8) (o Descriptors) {
+	for _, d := range ds {
+		if d.Tag == tag {
+			o = append(o, d)
+		}
+	}
+	return
+}
+
+// Append returns a new loop with descriptors appended, the original loop being left untouched
+func (ds Descriptors) Append(vs ...*Descriptor) Descriptors {
+	return append(append(Descriptors{}, ds...), vs...)
+}
+
+// Replace returns a new loop where the descriptors with the same tag as d are replaced with d, at the position of the
+// first of them
+// If there is none, d is appended. The original loop is left untouched.
+func (ds Descriptors) Replace(d *Descriptor) (o Descriptors) {
+	var replaced bool
+	for _, v := range ds {
+		// Different tag
+		if v.Tag != d.Tag {
+			o = append(o, v)
+			continue
+		}
+
+		// Replace
+		if !replaced {
+			o = append(o, d)
+			replaced = true
+		}
+	}
+
+	// Append
+	if !replaced {
+		o = append(o, d)
+	}
+	return
+}
+
+// LoopLength returns the number of bytes the descriptors take once serialised, which is the value of the 12 bits
+// descriptors loop length fields
+// An error is returned if a descriptor can't be serialised or if the length doesn't fit on 12 bits.
+func (ds Descriptors) LoopLength() (l int, err error) {
+	// Loop through descriptors
+	b := make([]byte, 2+0xff)
+	for idx, d := range ds {
+		var n int
+		if n, err = d.serialise(b); err != nil {
+			err = fmt.Errorf("astits: serialising descriptor #%d failed: %w", idx, err)
+			return
+		}
+		l += n
+	}
+
+	// Check length
+	if l > descriptorsMaxLoopLength {
+		err = fmt.Errorf("astits: descriptors loop length %d doesn't fit on 12 bits", l)
+		return
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescriptors(t *testing.T) {
+	// Init
+	d1 := NewRegistrationDescriptor("CUEI")
+	d2 := NewISO639Descriptor("eng", AudioTypeCleanEffects)
+	d3 := NewRegistrationDescriptor("SCTE")
+	ds := Descriptors{d1, d2, d3}
+
+	// Find
+	assert.Equal(t, d1, ds.Find(DescriptorTagRegistration))
+	assert.Nil(t, ds.Find(DescriptorTagService))
+	assert.Equal(t, Descriptors{d1, d3}, ds.FindAll(DescriptorTagRegistration))
+	assert.Nil(t, ds.FindAll(DescriptorTagService))
+
+	// Append
+	d4 := NewStreamIdentifierDescriptor(1)
+	assert.Equal(t, Descriptors{d1, d2, d3, d4}, ds.Append(d4))
+	assert.Len(t, ds, 3)
+
+	// Replace
+	d5 := NewRegistrationDescriptor("GA94")
+	assert.Equal(t, Descriptors{d5, d2}, ds.Replace(d5))
+	assert.Equal(t, Descriptors{d1, d2, d3, d4}, ds.Replace(d4))
+	assert.Equal(t, d1, ds[0])
+
+	// Loop length
+	l, err := ds.LoopLength()
+	assert.NoError(t, err)
+	assert.Equal(t, 18, l)
+	b := make([]byte, 20)
+	n, err := serialiseDescriptors(b, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, 2+l, n)
+	var long Descriptors
+	for idx := 0; idx < 16; idx++ {
+		long = long.Append(NewNetworkNameDescriptor(string(bytes.Repeat([]byte("a"), 255))))
+	}
+	_, err = long.LoopLength()
+	assert.Error(t, err)
+}
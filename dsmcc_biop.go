@@ -0,0 +1,326 @@
+package astits
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// DSM-CC object kinds
+// Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101202/01.02.01_60/tr_101202v010201p.pdf
+const (
+	DSMCCObjectKindDirectory      = "dir"
+	DSMCCObjectKindFile           = "fil"
+	DSMCCObjectKindServiceGateway = "srg"
+	DSMCCObjectKindStream         = "str"
+	DSMCCObjectKindStreamEvent    = "ste"
+)
+
+// BIOP tags
+const (
+	dsmccTagBIOP           = 0x49534f06
+	dsmccTagObjectLocation = 0x49534f50
+	dsmccTagConnBinder     = 0x49534f40
+)
+
+// DSMCCIOR represents an Interoperable Object Reference, which points at an object of an object carousel
+type DSMCCIOR struct {
+	AssociationTag uint16               // Association tag of the first tap of the connection binder, which identifies the PID carrying the module
+	ObjectLocation *DSMCCObjectLocation // Only set for BIOP profiles
+	TypeID         string               // Kind of the object, see DSMCCObjectKind*
+}
+
+// DSMCCObjectLocation represents the location of an object in an object carousel
+type DSMCCObjectLocation struct {
+	CarouselID uint32
+	ModuleID   uint16
+	ObjectKey  []byte
+}
+
+// DSMCCBIOPMessage represents a BIOP message, which describes an object of an object carousel
+type DSMCCBIOPMessage struct {
+	Bindings   []*DSMCCBIOPBinding // Only set for directories and service gateways
+	Content    []byte              // Only set for files
+	ObjectInfo []byte
+	ObjectKey  []byte
+	ObjectKind string // See DSMCCObjectKind*
+}
+
+// DSMCCBIOPBinding represents an entry of a directory
+type DSMCCBIOPBinding struct {
+	IOR  *DSMCCIOR
+	Kind string // See DSMCCObjectKind*
+	Name string
+	Type uint8 // 0x1 for objects, 0x2 for contexts such as directories
+}
+
+// parseDSMCCString parses a string which may be terminated with a NUL byte
+func parseDSMCCString(b []byte) string {
+	return string(bytes.TrimRight(b, "\x00"))
+}
+
+// parseDSMCCIOR parses an IOR
+func parseDSMCCIOR(i *astikit.BytesIterator) (r *DSMCCIOR, err error) {
+	// Type ID
+	var bs []byte
+	if bs, err = parseDSMCCLengthBytes(i, 4); err != nil {
+		err = fmt.Errorf("astits: parsing type ID failed: %w", err)
+		return
+	}
+
+	// Create IOR
+	r = &DSMCCIOR{TypeID: parseDSMCCString(bs)}
+
+	// Type ID is aligned on 4 bytes
+	if len(bs)%4 > 0 {
+		i.Skip(4 - len(bs)%4)
+	}
+
+	// Get next bytes
+	if bs, err = i.NextBytes(4); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Loop through profiles
+	profilesNum := int(uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3]))
+	for idx := 0; idx < profilesNum; idx++ {
+		// Get next bytes
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		tag := uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])
+
+		// Profile data
+		var data []byte
+		if data, err = parseDSMCCLengthBytes(i, 4); err != nil {
+			err = fmt.Errorf("astits: parsing profile data failed: %w", err)
+			return
+		}
+
+		// Only BIOP profiles are parsed
+		if tag != dsmccTagBIOP {
+			continue
+		}
+		if err = r.parseBIOPProfile(astikit.NewBytesIterator(data)); err != nil {
+			err = fmt.Errorf("astits: parsing BIOP profile failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// parseBIOPProfile parses the lite components of a BIOP profile
+func (r *DSMCCIOR) parseBIOPProfile(i *astikit.BytesIterator) (err error) {
+	// Get next bytes
+	var bs []byte
+	if bs, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Loop through components
+	componentsNum := int(bs[1])
+	for idx := 0; idx < componentsNum; idx++ {
+		// Get next bytes
+		if bs, err = i.NextBytes(4); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+		tag := uint32(bs[0])<<24 | uint32(bs[1])<<16 | uint32(bs[2])<<8 | uint32(bs[3])
+
+		// Component data
+		var data []byte
+		if data, err = parseDSMCCLengthBytes(i, 1); err != nil {
+			err = fmt.Errorf("astits: parsing component data failed: %w", err)
+			return
+		}
+
+		// Switch on tag
+		switch tag {
+		case dsmccTagConnBinder:
+			// Taps are made of an ID, a use, an association tag and a selector
+			if len(data) >= 7 && data[0] > 0 {
+				r.AssociationTag = uint16(data[5])<<8 | uint16(data[6])
+			}
+		case dsmccTagObjectLocation:
+			if len(data) < 9 || len(data) < 9+int(data[8]) {
+				err = fmt.Errorf("astits: object location length %d is invalid", len(data))
+				return
+			}
+			r.ObjectLocation = &DSMCCObjectLocation{
+				CarouselID: uint32(data[0])<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3]),
+				ModuleID:   uint16(data[4])<<8 | uint16(data[5]),
+				ObjectKey:  data[9 : 9+int(data[8])],
+			}
+		}
+	}
+	return
+}
+
+// ParseDSMCCBIOPMessages parses the BIOP messages a module of an object carousel is made of
+func ParseDSMCCBIOPMessages(b []byte) (ms []*DSMCCBIOPMessage, err error) {
+	i := astikit.NewBytesIterator(b)
+	for i.HasBytesLeft() {
+		// Get next bytes
+		var bs []byte
+		if bs, err = i.NextBytes(12); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Check magic
+		if string(bs[:4]) != "BIOP" {
+			err = fmt.Errorf("astits: BIOP message #%d has an invalid magic", len(ms))
+			return
+		}
+
+		// Get message
+		l := int(uint32(bs[8])<<24 | uint32(bs[9])<<16 | uint32(bs[10])<<8 | uint32(bs[11]))
+		if bs, err = i.NextBytes(l); err != nil {
+			err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+			return
+		}
+
+		// Parse message
+		var m *DSMCCBIOPMessage
+		if m, err = parseDSMCCBIOPMessage(astikit.NewBytesIterator(bs)); err != nil {
+			err = fmt.Errorf("astits: parsing BIOP message #%d failed: %w", len(ms), err)
+			return
+		}
+		ms = append(ms, m)
+	}
+	return
+}
+
+// parseDSMCCBIOPMessage parses a BIOP message following its size
+func parseDSMCCBIOPMessage(i *astikit.BytesIterator) (m *DSMCCBIOPMessage, err error) {
+	// Create message
+	m = &DSMCCBIOPMessage{}
+
+	// Object key
+	if m.ObjectKey, err = parseDSMCCLengthBytes(i, 1); err != nil {
+		err = fmt.Errorf("astits: parsing object key failed: %w", err)
+		return
+	}
+
+	// Object kind
+	var bs []byte
+	if bs, err = parseDSMCCLengthBytes(i, 4); err != nil {
+		err = fmt.Errorf("astits: parsing object kind failed: %w", err)
+		return
+	}
+	m.ObjectKind = parseDSMCCString(bs)
+
+	// Object info
+	if m.ObjectInfo, err = parseDSMCCLengthBytes(i, 2); err != nil {
+		err = fmt.Errorf("astits: parsing object info failed: %w", err)
+		return
+	}
+
+	// Get next byte
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Skip service contexts
+	for idx := 0; idx < int(b); idx++ {
+		i.Skip(4)
+		if _, err = parseDSMCCLengthBytes(i, 2); err != nil {
+			err = fmt.Errorf("astits: parsing service context failed: %w", err)
+			return
+		}
+	}
+
+	// Message body
+	if bs, err = parseDSMCCLengthBytes(i, 4); err != nil {
+		err = fmt.Errorf("astits: parsing message body failed: %w", err)
+		return
+	}
+	bi := astikit.NewBytesIterator(bs)
+
+	// Switch on object kind
+	switch m.ObjectKind {
+	case DSMCCObjectKindFile:
+		if m.Content, err = parseDSMCCLengthBytes(bi, 4); err != nil {
+			err = fmt.Errorf("astits: parsing content failed: %w", err)
+			return
+		}
+	case DSMCCObjectKindDirectory, DSMCCObjectKindServiceGateway:
+		if m.Bindings, err = parseDSMCCBIOPBindings(bi); err != nil {
+			err = fmt.Errorf("astits: parsing bindings failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// parseDSMCCBIOPBindings parses the bindings of a directory
+func parseDSMCCBIOPBindings(i *astikit.BytesIterator) (bs []*DSMCCBIOPBinding, err error) {
+	// Get next bytes
+	var b []byte
+	if b, err = i.NextBytes(2); err != nil {
+		err = fmt.Errorf("astits: fetching next bytes failed: %w", err)
+		return
+	}
+
+	// Loop through bindings
+	bindingsNum := int(uint16(b[0])<<8 | uint16(b[1]))
+	for idx := 0; idx < bindingsNum; idx++ {
+		// Create binding
+		bd := &DSMCCBIOPBinding{}
+
+		// Get next byte
+		var c byte
+		if c, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// Loop through name components, which are joined with "/" in the unusual case where there are several
+		for componentIdx := 0; componentIdx < int(c); componentIdx++ {
+			// ID
+			if b, err = parseDSMCCLengthBytes(i, 1); err != nil {
+				err = fmt.Errorf("astits: parsing name component ID failed: %w", err)
+				return
+			}
+			if componentIdx > 0 {
+				bd.Name += "/"
+			}
+			bd.Name += parseDSMCCString(b)
+
+			// Kind
+			if b, err = parseDSMCCLengthBytes(i, 1); err != nil {
+				err = fmt.Errorf("astits: parsing name component kind failed: %w", err)
+				return
+			}
+			bd.Kind = parseDSMCCString(b)
+		}
+
+		// Binding type
+		if bd.Type, err = i.NextByte(); err != nil {
+			err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+			return
+		}
+
+		// IOR
+		if bd.IOR, err = parseDSMCCIOR(i); err != nil {
+			err = fmt.Errorf("astits: parsing IOR failed: %w", err)
+			return
+		}
+
+		// Object info
+		if _, err = parseDSMCCLengthBytes(i, 2); err != nil {
+			err = fmt.Errorf("astits: parsing object info failed: %w", err)
+			return
+		}
+
+		// Append binding
+		bs = append(bs, bd)
+	}
+	return
+}
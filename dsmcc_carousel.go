@@ -0,0 +1,495 @@
+package astits
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"sync"
+)
+
+// Errors
+var (
+	ErrDSMCCModuleTooBig           = errors.New("astits: decompressed DSM-CC module is bigger than its original size")
+	ErrDSMCCServiceGatewayNotFound = errors.New("astits: DSM-CC service gateway not found")
+)
+
+// Default max size of the modules reassembled by the carousel
+const dsmccCarouselDefaultMaxModuleSize = 16 << 20
+
+// DSM-CC descriptor tags, which are not in the same namespace as the descriptors of the PSI tables
+// Chapter: 9.2.2 | Link: https://www.etsi.org/deliver/etsi_en/301100_301199/301192/01.06.01_60/en_301192v010601p.pdf
+const (
+	dsmccDescriptorTagCompressedModule = 0x09
+)
+
+// DSMCCModule represents a module of a DSM-CC carousel, reassembled out of its DDBs
+type DSMCCModule struct {
+	Data       []byte // Decompressed if the module was compressed
+	DownloadID uint32 // Carousel ID for object carousels
+	ID         uint16
+	Version    uint8
+}
+
+// DSMCCFile represents a file of a DSM-CC object carousel
+type DSMCCFile struct {
+	Content []byte
+	Path    string // Relative to the service gateway, such as "images/logo.png"
+}
+
+type dsmccModuleKey struct {
+	downloadID uint32
+	id         uint16
+}
+
+// dsmccCarouselModule represents a module being reassembled
+type dsmccCarouselModule struct {
+	blockSize    uint16
+	blocks       map[uint16]bool
+	compressed   bool
+	data         []byte
+	messages     []*DSMCCBIOPMessage // Parsed lazily
+	module       *DSMCCModule        // Only set once the module is complete
+	originalSize uint32              // Max size of the decompressed data
+	version      uint8
+}
+
+// DSMCCCarousel reassembles the modules of DSM-CC data and object carousels out of their DIIs and DDBs, and the
+// files of object carousels out of their modules
+// Modules are reset whenever a DII announces a new version. Compressed modules are decompressed once complete, up to
+// the original size announced by their DII or, if it is missing, the max module size.
+type DSMCCCarousel struct {
+	dsi              *DSMCCDSI
+	files            map[string][]byte // Content of the files delivered to the file handler, indexed by path
+	m                *sync.Mutex
+	modules          map[dsmccModuleKey]*dsmccCarouselModule
+	optFileHandler   func(f *DSMCCFile)
+	optMaxModuleSize uint32
+	optModuleHandler func(m *DSMCCModule)
+}
+
+// NewDSMCCCarousel creates a new DSM-CC carousel
+func NewDSMCCCarousel(opts ...func(*DSMCCCarousel)) (c *DSMCCCarousel) {
+	// Create carousel
+	c = &DSMCCCarousel{
+		files:            make(map[string][]byte),
+		m:                &sync.Mutex{},
+		modules:          make(map[dsmccModuleKey]*dsmccCarouselModule),
+		optMaxModuleSize: dsmccCarouselDefaultMaxModuleSize,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(c)
+	}
+	return
+}
+
+// DSMCCCarouselOptFileHandler returns the option to set the handler called whenever a file of the object carousel
+// becomes available, either because the modules holding it and its directories are complete or because its content
+// has changed with a new version of its module
+// Files are walked from the service gateway announced by the last DSI, the handler is called once the carousel has
+// been updated.
+func DSMCCCarouselOptFileHandler(fn func(f *DSMCCFile)) func(*DSMCCCarousel) {
+	return func(c *DSMCCCarousel) {
+		c.optFileHandler = fn
+	}
+}
+
+// DSMCCCarouselOptMaxModuleSize returns the option to set the max size, in bytes, of the modules reassembled by the
+// carousel, which defaults to 16 MiB
+// Modules announced with a bigger size are skipped since their size is allocated upfront.
+func DSMCCCarouselOptMaxModuleSize(n uint32) func(*DSMCCCarousel) {
+	return func(c *DSMCCCarousel) {
+		c.optMaxModuleSize = n
+	}
+}
+
+// DSMCCCarouselOptModuleHandler returns the option to set the handler called with every module completed, such as
+// the modules of firmware data carousels
+// The handler is called once the carousel has been updated.
+func DSMCCCarouselOptModuleHandler(fn func(m *DSMCCModule)) func(*DSMCCCarousel) {
+	return func(c *DSMCCCarousel) {
+		c.optModuleHandler = fn
+	}
+}
+
+// AddData updates the carousel with a new data and returns the modules it has completed
+func (c *DSMCCCarousel) AddData(d *Data) (ms []*DSMCCModule, err error) {
+	// Update carousel
+	var fs []*DSMCCFile
+	ms, fs, err = c.addData(d)
+
+	// Handle modules
+	if c.optModuleHandler != nil {
+		for _, m := range ms {
+			c.optModuleHandler(m)
+		}
+	}
+
+	// Handle files
+	if c.optFileHandler != nil {
+		for _, f := range fs {
+			c.optFileHandler(f)
+		}
+	}
+	return
+}
+
+func (c *DSMCCCarousel) addData(d *Data) (ms []*DSMCCModule, fs []*DSMCCFile, err error) {
+	// Not a DSM-CC data
+	if d.DSMCC == nil {
+		return
+	}
+
+	// Lock
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// Get new files once the carousel has been updated
+	defer func() {
+		if err != nil || c.optFileHandler == nil || (d.DSMCC.DSI == nil && len(ms) == 0) {
+			return
+		}
+		if fs, err = c.newFiles(); err != nil {
+			err = fmt.Errorf("astits: getting new files failed: %w", err)
+			return
+		}
+	}()
+
+	// Switch on message
+	switch {
+	case d.DSMCC.DSI != nil:
+		c.dsi = d.DSMCC.DSI
+	case d.DSMCC.DII != nil:
+		for _, dm := range d.DSMCC.DII.Modules {
+			var m *DSMCCModule
+			if m, err = c.addDIIModule(d.DSMCC.DII, dm); err != nil {
+				err = fmt.Errorf("astits: adding DII module %d failed: %w", dm.ID, err)
+				return
+			} else if m != nil {
+				ms = append(ms, m)
+			}
+		}
+	case d.DSMCC.DDB != nil:
+		var m *DSMCCModule
+		if m, err = c.addDDB(d.DSMCC.DDB); err != nil {
+			err = fmt.Errorf("astits: adding DDB of module %d failed: %w", d.DSMCC.DDB.ModuleID, err)
+			return
+		} else if m != nil {
+			ms = append(ms, m)
+		}
+	}
+	return
+}
+
+func (c *DSMCCCarousel) addDIIModule(d *DSMCCDII, dm *DSMCCDIIModule) (m *DSMCCModule, err error) {
+	// Module is already known
+	k := dsmccModuleKey{downloadID: d.DownloadID, id: dm.ID}
+	if cm, ok := c.modules[k]; ok && cm.version == dm.Version && len(cm.data) == int(dm.Size) {
+		return
+	}
+
+	// Module is too big
+	if dm.Size > c.optMaxModuleSize {
+		return
+	}
+
+	// Create module
+	cm := &dsmccCarouselModule{
+		blockSize: d.BlockSize,
+		blocks:    make(map[uint16]bool),
+		data:      make([]byte, dm.Size),
+		version:   dm.Version,
+	}
+	if cm.compressed, cm.originalSize = dm.Compressed(); cm.originalSize == 0 || cm.originalSize > c.optMaxModuleSize {
+		cm.originalSize = c.optMaxModuleSize
+	}
+	c.modules[k] = cm
+
+	// Empty modules are complete right away
+	if dm.Size == 0 {
+		return cm.complete(k)
+	}
+	return
+}
+
+func (c *DSMCCCarousel) addDDB(d *DSMCCDDB) (m *DSMCCModule, err error) {
+	// Get module
+	k := dsmccModuleKey{downloadID: d.DownloadID, id: d.ModuleID}
+	cm, ok := c.modules[k]
+	if !ok || cm.module != nil || cm.version != d.ModuleVersion || cm.blockSize == 0 || cm.blocks[d.BlockNumber] {
+		return
+	}
+
+	// Block is out of bounds
+	offset := int(d.BlockNumber) * int(cm.blockSize)
+	if offset >= len(cm.data) {
+		return
+	}
+
+	// Copy block
+	copy(cm.data[offset:], d.BlockData)
+	cm.blocks[d.BlockNumber] = true
+
+	// Module is not complete yet
+	if len(cm.blocks) < (len(cm.data)+int(cm.blockSize)-1)/int(cm.blockSize) {
+		return
+	}
+	return cm.complete(k)
+}
+
+// complete creates the module out of its data once all its blocks have been received
+func (cm *dsmccCarouselModule) complete(k dsmccModuleKey) (m *DSMCCModule, err error) {
+	// Create module
+	m = &DSMCCModule{
+		Data:       cm.data,
+		DownloadID: k.downloadID,
+		ID:         k.id,
+		Version:    cm.version,
+	}
+
+	// Decompress
+	if cm.compressed {
+		var r io.ReadCloser
+		if r, err = zlib.NewReader(bytes.NewReader(cm.data)); err != nil {
+			err = fmt.Errorf("astits: creating zlib reader failed: %w", err)
+			return
+		}
+		defer r.Close()
+
+		// Read one byte more than the original size so that decompression bombs are detected
+		if m.Data, err = ioutil.ReadAll(io.LimitReader(r, int64(cm.originalSize)+1)); err != nil {
+			err = fmt.Errorf("astits: decompressing module failed: %w", err)
+			return
+		} else if len(m.Data) > int(cm.originalSize) {
+			err = ErrDSMCCModuleTooBig
+			return
+		}
+	}
+
+	// Store module
+	cm.module = m
+	return
+}
+
+// Module returns the module if it is complete, or nil
+func (c *DSMCCCarousel) Module(downloadID uint32, moduleID uint16) *DSMCCModule {
+	// Lock
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// Get module
+	if cm, ok := c.modules[dsmccModuleKey{downloadID: downloadID, id: moduleID}]; ok {
+		return cm.module
+	}
+	return nil
+}
+
+// Modules returns the complete modules sorted by download ID and module ID
+func (c *DSMCCCarousel) Modules() (ms []*DSMCCModule) {
+	// Lock
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// Loop through modules
+	for _, cm := range c.modules {
+		if cm.module != nil {
+			ms = append(ms, cm.module)
+		}
+	}
+
+	// Sort
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].DownloadID != ms[j].DownloadID {
+			return ms[i].DownloadID < ms[j].DownloadID
+		}
+		return ms[i].ID < ms[j].ID
+	})
+	return
+}
+
+// Files returns the files of the object carousel sorted by path, walking the directories from the service gateway
+// announced by the last DSI
+// Objects whose module is not complete yet are skipped.
+func (c *DSMCCCarousel) Files() (fs []*DSMCCFile, err error) {
+	// Lock
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// Get files
+	return c.walkFiles()
+}
+
+// newFiles returns the files that have not been delivered to the file handler yet, or whose content has changed
+// since
+func (c *DSMCCCarousel) newFiles() (fs []*DSMCCFile, err error) {
+	// Get files
+	var afs []*DSMCCFile
+	if afs, err = c.walkFiles(); err != nil {
+		// Service gateway is not available yet
+		if errors.Is(err, ErrDSMCCServiceGatewayNotFound) {
+			err = nil
+		}
+		return
+	}
+
+	// Loop through files
+	for _, f := range afs {
+		if content, ok := c.files[f.Path]; ok && bytes.Equal(content, f.Content) {
+			continue
+		}
+		c.files[f.Path] = f.Content
+		fs = append(fs, f)
+	}
+	return
+}
+
+func (c *DSMCCCarousel) walkFiles() (fs []*DSMCCFile, err error) {
+	// Get service gateway
+	if c.dsi == nil || c.dsi.ServiceGateway == nil || c.dsi.ServiceGateway.ObjectLocation == nil {
+		err = ErrDSMCCServiceGatewayNotFound
+		return
+	}
+	var m *DSMCCBIOPMessage
+	if m, err = c.object(c.dsi.ServiceGateway.ObjectLocation); err != nil {
+		err = fmt.Errorf("astits: getting service gateway failed: %w", err)
+		return
+	} else if m == nil {
+		err = ErrDSMCCServiceGatewayNotFound
+		return
+	}
+
+	// Walk directories
+	if fs, err = c.walk(m, "", map[string]bool{}); err != nil {
+		err = fmt.Errorf("astits: walking directories failed: %w", err)
+		return
+	}
+
+	// Sort
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Path < fs[j].Path })
+	return
+}
+
+func (c *DSMCCCarousel) walk(dir *DSMCCBIOPMessage, dirPath string, visited map[string]bool) (fs []*DSMCCFile, err error) {
+	// Loop through bindings
+	for _, b := range dir.Bindings {
+		// No location
+		if b.IOR == nil || b.IOR.ObjectLocation == nil {
+			continue
+		}
+
+		// Object has already been visited, which may happen with ill-formed carousels
+		l := b.IOR.ObjectLocation
+		k := fmt.Sprintf("%d/%d/%x", l.CarouselID, l.ModuleID, l.ObjectKey)
+		if visited[k] {
+			continue
+		}
+		visited[k] = true
+
+		// Get object
+		var m *DSMCCBIOPMessage
+		if m, err = c.object(l); err != nil {
+			err = fmt.Errorf("astits: getting object %s failed: %w", b.Name, err)
+			return
+		} else if m == nil {
+			continue
+		}
+
+		// Switch on object kind
+		p := path.Join(dirPath, b.Name)
+		switch m.ObjectKind {
+		case DSMCCObjectKindDirectory:
+			var dfs []*DSMCCFile
+			if dfs, err = c.walk(m, p, visited); err != nil {
+				err = fmt.Errorf("astits: walking directory %s failed: %w", p, err)
+				return
+			}
+			fs = append(fs, dfs...)
+		case DSMCCObjectKindFile:
+			fs = append(fs, &DSMCCFile{
+				Content: m.Content,
+				Path:    p,
+			})
+		}
+	}
+	return
+}
+
+// object returns the object at the location, or nil if its module is not complete yet
+func (c *DSMCCCarousel) object(l *DSMCCObjectLocation) (m *DSMCCBIOPMessage, err error) {
+	// Get module
+	cm, ok := c.modules[dsmccModuleKey{downloadID: l.CarouselID, id: l.ModuleID}]
+	if !ok || cm.module == nil {
+		return
+	}
+
+	// Parse messages
+	if cm.messages == nil {
+		if cm.messages, err = ParseDSMCCBIOPMessages(cm.module.Data); err != nil {
+			err = fmt.Errorf("astits: parsing BIOP messages of module %d failed: %w", l.ModuleID, err)
+			return
+		}
+	}
+
+	// Find object
+	for _, v := range cm.messages {
+		if bytes.Equal(v.ObjectKey, l.ObjectKey) {
+			m = v
+			return
+		}
+	}
+	return
+}
+
+// Compressed checks whether the module is compressed, in which case its original size is returned as well
+// For object carousels the compressed module descriptor is looked for in the user info of the BIOP module info,
+// whereas for data carousels the module info is made of descriptors.
+func (m *DSMCCDIIModule) Compressed() (compressed bool, originalSize uint32) {
+	// Loop through descriptors
+	ds := dsmccModuleInfoDescriptors(m.Info)
+	for len(ds) >= 2 {
+		// Descriptor is too long
+		l := int(ds[1])
+		if len(ds) < 2+l {
+			break
+		}
+
+		// Compressed module
+		if ds[0] == dsmccDescriptorTagCompressedModule && l >= 5 {
+			compressed = true
+			originalSize = uint32(ds[3])<<24 | uint32(ds[4])<<16 | uint32(ds[5])<<8 | uint32(ds[6])
+			return
+		}
+		ds = ds[2+l:]
+	}
+	return
+}
+
+// dsmccModuleInfoDescriptors returns the user info of the module info if it is a BIOP module info, or the module
+// info itself otherwise
+func dsmccModuleInfoDescriptors(b []byte) []byte {
+	// Time outs
+	if len(b) < 13 {
+		return b
+	}
+
+	// Loop through taps
+	offset := 13
+	for idx := 0; idx < int(b[12]); idx++ {
+		if len(b) < offset+7 {
+			return b
+		}
+		offset += 7 + int(b[offset+6])
+	}
+
+	// User info must end the module info
+	if len(b) < offset+1 || len(b) != offset+1+int(b[offset]) {
+		return b
+	}
+	return b[offset+1:]
+}
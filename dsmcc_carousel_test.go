@@ -0,0 +1,323 @@
+package astits
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+type dsmccTestBinding struct {
+	kind      string
+	moduleID  uint16
+	name      string
+	objectKey []byte
+}
+
+func dsmccBIOPMessageBytes(kind string, objectKey []byte, body []byte) []byte {
+	// Message
+	mbuf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: mbuf})
+	w.Write(uint8(len(objectKey))) // Object key length
+	w.Write(objectKey)             // Object key
+	w.Write(uint32(4))             // Object kind length
+	w.Write([]byte(kind + "\x00")) // Object kind
+	w.Write(uint16(0))             // Object info length
+	w.Write(uint8(1))              // Service context list count
+	w.Write(uint32(0x44564201))    // Context ID
+	w.Write(uint16(1))             // Context data length
+	w.Write(uint8(0))              // Context data
+	w.Write(uint32(len(body)))     // Message body length
+	w.Write(body)                  // Message body
+
+	// Header
+	buf := &bytes.Buffer{}
+	w = astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write([]byte("BIOP"))     // Magic
+	w.Write([]byte{0x1, 0x0})   // Version
+	w.Write(uint8(0))           // Byte order
+	w.Write(uint8(0))           // Message type
+	w.Write(uint32(mbuf.Len())) // Message size
+	w.Write(mbuf.Bytes())       // Message
+	return buf.Bytes()
+}
+
+func dsmccBIOPFileBytes(objectKey, content []byte) []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint32(len(content))) // Content length
+	w.Write(content)              // Content
+	return dsmccBIOPMessageBytes(DSMCCObjectKindFile, objectKey, buf.Bytes())
+}
+
+func dsmccBIOPDirectoryBytes(kind string, objectKey []byte, bs []dsmccTestBinding) []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint16(len(bs))) // Bindings count
+	for _, b := range bs {
+		w.Write(uint8(1))                // Name components count
+		w.Write(uint8(len(b.name) + 1))  // ID length
+		w.Write([]byte(b.name + "\x00")) // ID
+		w.Write(uint8(4))                // Kind length
+		w.Write([]byte(b.kind + "\x00")) // Kind
+		if b.kind == DSMCCObjectKindDirectory {
+			w.Write(uint8(2)) // Binding type
+		} else {
+			w.Write(uint8(1)) // Binding type
+		}
+		w.Write(dsmccIORBytes(b.kind, 1, b.moduleID, b.objectKey)) // IOR
+		w.Write(uint16(0))                                         // Object info length
+	}
+	return dsmccBIOPMessageBytes(kind, objectKey, buf.Bytes())
+}
+
+func dsmccModuleInfoBytes(compressedSize int) []byte {
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint32(0xffffffff)) // Module time out
+	w.Write(uint32(0xffffffff)) // Block time out
+	w.Write(uint32(0))          // Min block time
+	w.Write(uint8(1))           // Taps count
+	w.Write(uint16(0))          // ID
+	w.Write(uint16(0x17))       // Use
+	w.Write(uint16(0xb))        // Association tag
+	w.Write(uint8(0))           // Selector length
+	if compressedSize < 0 {
+		w.Write(uint8(0)) // User info length
+	} else {
+		w.Write(uint8(7))                                  // User info length
+		w.Write(uint8(dsmccDescriptorTagCompressedModule)) // Tag
+		w.Write(uint8(5))                                  // Length
+		w.Write(uint8(8))                                  // Compression method
+		w.Write(uint32(compressedSize))                    // Original size
+	}
+	return buf.Bytes()
+}
+
+func TestDSMCCCarousel(t *testing.T) {
+	// Module 1 holds the service gateway and a file
+	m1 := append(dsmccBIOPDirectoryBytes(DSMCCObjectKindServiceGateway, []byte{0x1}, []dsmccTestBinding{
+		{kind: DSMCCObjectKindFile, moduleID: 1, name: "index.html", objectKey: []byte{0x2}},
+		{kind: DSMCCObjectKindDirectory, moduleID: 2, name: "images", objectKey: []byte{0x1}},
+	}), dsmccBIOPFileBytes([]byte{0x2}, bytes.Repeat([]byte("<html>"), 20))...)
+
+	// Module 2 holds a directory and a file, and is compressed
+	m2 := append(dsmccBIOPDirectoryBytes(DSMCCObjectKindDirectory, []byte{0x1}, []dsmccTestBinding{
+		{kind: DSMCCObjectKindFile, moduleID: 2, name: "logo.png", objectKey: []byte{0x2}},
+		{kind: DSMCCObjectKindFile, moduleID: 3, name: "missing.png", objectKey: []byte{0x1}},
+	}), dsmccBIOPFileBytes([]byte{0x2}, []byte("png"))...)
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	zw.Write(m2)
+	zw.Close()
+	m2z := buf.Bytes()
+
+	// No service gateway
+	c := NewDSMCCCarousel()
+	_, err := c.Files()
+	assert.True(t, errors.Is(err, ErrDSMCCServiceGatewayNotFound))
+
+	// DSI
+	ms, err := c.AddData(&Data{DSMCC: &DSMCCData{DSI: &DSMCCDSI{ServiceGateway: &DSMCCIOR{
+		ObjectLocation: &DSMCCObjectLocation{CarouselID: 1, ModuleID: 1, ObjectKey: []byte{0x1}},
+		TypeID:         DSMCCObjectKindServiceGateway,
+	}}}})
+	assert.NoError(t, err)
+	assert.Len(t, ms, 0)
+
+	// DII
+	const blockSize = 64
+	ms, err = c.AddData(&Data{DSMCC: &DSMCCData{DII: &DSMCCDII{
+		BlockSize:  blockSize,
+		DownloadID: 1,
+		Modules: []*DSMCCDIIModule{
+			{ID: 1, Info: dsmccModuleInfoBytes(-1), Size: uint32(len(m1)), Version: 1},
+			{ID: 2, Info: dsmccModuleInfoBytes(len(m2)), Size: uint32(len(m2z)), Version: 1},
+			{ID: 3, Size: 0, Version: 1},
+		},
+	}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*DSMCCModule{{Data: []byte{}, DownloadID: 1, ID: 3, Version: 1}}, ms)
+	compressed, originalSize := (&DSMCCDIIModule{Info: dsmccModuleInfoBytes(len(m2))}).Compressed()
+	assert.True(t, compressed)
+	assert.Equal(t, uint32(len(m2)), originalSize)
+
+	// Service gateway module is not complete
+	_, err = c.Files()
+	assert.True(t, errors.Is(err, ErrDSMCCServiceGatewayNotFound))
+
+	// DDBs of module 1, in reverse order and with a repeated block, a block of a wrong version and a block of an unknown
+	// module
+	ddb := func(moduleID uint16, version uint8, b []byte, n int) *Data {
+		end := (n + 1) * blockSize
+		if end > len(b) {
+			end = len(b)
+		}
+		return &Data{DSMCC: &DSMCCData{DDB: &DSMCCDDB{
+			BlockData:     b[n*blockSize : end],
+			BlockNumber:   uint16(n),
+			DownloadID:    1,
+			ModuleID:      moduleID,
+			ModuleVersion: version,
+		}}}
+	}
+	blocksNum := (len(m1) + blockSize - 1) / blockSize
+	assert.True(t, blocksNum > 2)
+	for n := blocksNum - 1; n > 0; n-- {
+		ms, err = c.AddData(ddb(1, 1, m1, n))
+		assert.NoError(t, err)
+		assert.Len(t, ms, 0)
+	}
+	ms, err = c.AddData(ddb(1, 1, m1, 1))
+	assert.NoError(t, err)
+	assert.Len(t, ms, 0)
+	ms, err = c.AddData(ddb(1, 2, m1, 0))
+	assert.NoError(t, err)
+	assert.Len(t, ms, 0)
+	ms, err = c.AddData(ddb(4, 1, m1, 0))
+	assert.NoError(t, err)
+	assert.Len(t, ms, 0)
+	ms, err = c.AddData(ddb(1, 1, m1, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []*DSMCCModule{{Data: m1, DownloadID: 1, ID: 1, Version: 1}}, ms)
+
+	// Module 2 is not complete
+	fs, err := c.Files()
+	assert.NoError(t, err)
+	assert.Equal(t, []*DSMCCFile{{Content: bytes.Repeat([]byte("<html>"), 20), Path: "index.html"}}, fs)
+
+	// DDBs of module 2
+	for n := 0; n*blockSize < len(m2z); n++ {
+		ms, err = c.AddData(ddb(2, 1, m2z, n))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []*DSMCCModule{{Data: m2, DownloadID: 1, ID: 2, Version: 1}}, ms)
+	assert.Equal(t, m2, c.Module(1, 2).Data)
+	assert.Len(t, c.Modules(), 3)
+
+	// Files
+	fs, err = c.Files()
+	assert.NoError(t, err)
+	assert.Equal(t, []*DSMCCFile{
+		{Content: []byte("png"), Path: "images/logo.png"},
+		{Content: bytes.Repeat([]byte("<html>"), 20), Path: "index.html"},
+	}, fs)
+
+	// New version
+	ms, err = c.AddData(&Data{DSMCC: &DSMCCData{DII: &DSMCCDII{
+		BlockSize:  blockSize,
+		DownloadID: 1,
+		Modules:    []*DSMCCDIIModule{{ID: 1, Info: dsmccModuleInfoBytes(-1), Size: uint32(len(m1)), Version: 2}},
+	}}})
+	assert.NoError(t, err)
+	assert.Len(t, ms, 0)
+	assert.Nil(t, c.Module(1, 1))
+}
+
+func TestDSMCCCarouselHandlers(t *testing.T) {
+	// Create carousel
+	var fs []*DSMCCFile
+	var ms []*DSMCCModule
+	c := NewDSMCCCarousel(DSMCCCarouselOptFileHandler(func(f *DSMCCFile) {
+		fs = append(fs, f)
+	}), DSMCCCarouselOptModuleHandler(func(m *DSMCCModule) {
+		ms = append(ms, m)
+	}))
+
+	// Module holds the service gateway and a file
+	m := func(content string) []byte {
+		return append(dsmccBIOPDirectoryBytes(DSMCCObjectKindServiceGateway, []byte{0x1}, []dsmccTestBinding{
+			{kind: DSMCCObjectKindFile, moduleID: 1, name: "index.html", objectKey: []byte{0x2}},
+		}), dsmccBIOPFileBytes([]byte{0x2}, []byte(content))...)
+	}
+	m1 := m("v1")
+	add := func(d *DSMCCData) {
+		_, err := c.AddData(&Data{DSMCC: d})
+		assert.NoError(t, err)
+	}
+	dii := func(version uint8, b []byte) *DSMCCData {
+		return &DSMCCData{DII: &DSMCCDII{
+			BlockSize:  uint16(len(b)),
+			DownloadID: 1,
+			Modules:    []*DSMCCDIIModule{{ID: 1, Info: dsmccModuleInfoBytes(-1), Size: uint32(len(b)), Version: version}},
+		}}
+	}
+	ddb := func(version uint8, b []byte) *DSMCCData {
+		return &DSMCCData{DDB: &DSMCCDDB{BlockData: b, DownloadID: 1, ModuleID: 1, ModuleVersion: version}}
+	}
+
+	// Module is complete before the DSI
+	add(dii(1, m1))
+	add(ddb(1, m1))
+	assert.Equal(t, []*DSMCCModule{{Data: m1, DownloadID: 1, ID: 1, Version: 1}}, ms)
+	assert.Len(t, fs, 0)
+
+	// DSI
+	dsi := &DSMCCData{DSI: &DSMCCDSI{ServiceGateway: &DSMCCIOR{
+		ObjectLocation: &DSMCCObjectLocation{CarouselID: 1, ModuleID: 1, ObjectKey: []byte{0x1}},
+		TypeID:         DSMCCObjectKindServiceGateway,
+	}}}
+	add(dsi)
+	assert.Equal(t, []*DSMCCFile{{Content: []byte("v1"), Path: "index.html"}}, fs)
+
+	// Repeated DSI
+	add(dsi)
+	assert.Len(t, fs, 1)
+
+	// New version
+	m2 := m("v2")
+	add(dii(2, m2))
+	add(ddb(2, m2))
+	assert.Len(t, ms, 2)
+	assert.Equal(t, []*DSMCCFile{
+		{Content: []byte("v1"), Path: "index.html"},
+		{Content: []byte("v2"), Path: "index.html"},
+	}, fs)
+}
+
+func TestDSMCCCarouselMaxModuleSize(t *testing.T) {
+	c := NewDSMCCCarousel(DSMCCCarouselOptMaxModuleSize(4))
+	dii := &DSMCCData{DII: &DSMCCDII{
+		BlockSize:  4,
+		DownloadID: 1,
+		Modules: []*DSMCCDIIModule{
+			{ID: 1, Info: dsmccModuleInfoBytes(-1), Size: 0xffffffff},
+			{ID: 2, Info: dsmccModuleInfoBytes(-1), Size: 4},
+		},
+	}}
+	_, err := c.AddData(&Data{DSMCC: dii})
+	assert.NoError(t, err)
+
+	// Module above the max size is skipped
+	assert.Len(t, c.modules, 1)
+	_, ok := c.modules[dsmccModuleKey{downloadID: 1, id: 2}]
+	assert.True(t, ok)
+
+	// Default
+	assert.Equal(t, uint32(dsmccCarouselDefaultMaxModuleSize), NewDSMCCCarousel().optMaxModuleSize)
+}
+
+func TestDSMCCCarouselDecompressionBomb(t *testing.T) {
+	// Compress
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	_, err := zw.Write(make([]byte, 1000))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	b := buf.Bytes()
+
+	// Add module whose original size is smaller than its decompressed data
+	c := NewDSMCCCarousel()
+	_, err = c.AddData(&Data{DSMCC: &DSMCCData{DII: &DSMCCDII{
+		BlockSize:  uint16(len(b)),
+		DownloadID: 1,
+		Modules:    []*DSMCCDIIModule{{ID: 1, Info: dsmccModuleInfoBytes(10), Size: uint32(len(b))}},
+	}}})
+	assert.NoError(t, err)
+	_, err = c.AddData(&Data{DSMCC: &DSMCCData{DDB: &DSMCCDDB{BlockData: b, DownloadID: 1, ModuleID: 1}}})
+	assert.True(t, errors.Is(err, ErrDSMCCModuleTooBig))
+	assert.Nil(t, c.Module(1, 1))
+}
@@ -32,7 +32,7 @@ func parseDVBTime(i *astikit.BytesIterator) (t time.Time, err error) {
 	}
 	var y = yt + k
 	var m = mt - 1 - k*12
-	t, _ = time.Parse("06-01-02", fmt.Sprintf("%d-%d-%d", y, m, d))
+	t = time.Date(1900+y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
 
 	// Time
 	var s time.Duration
@@ -72,3 +72,49 @@ func parseDVBDurationSeconds(i *astikit.BytesIterator) (d time.Duration, err err
 func parseDVBDurationByte(i byte) time.Duration {
 	return time.Duration(uint8(i)>>4*10 + uint8(i)&0xf)
 }
+
+// writeDVBTime writes a DVB time, in UTC, with a precision of one second
+func writeDVBTime(b []byte, t time.Time) {
+	// Date
+	t = t.UTC()
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	mjd := uint16(d.Unix()/86400 + 40587) // 40587 is the MJD of the unix epoch
+	b[0], b[1] = U16toU8s(mjd)
+
+	// Time
+	writeDVBDurationSeconds(b[2:], t.Sub(d))
+}
+
+// writeDVBDurationMinutes writes a minutes duration
+func writeDVBDurationMinutes(b []byte, d time.Duration) {
+	b[0] = dvbDurationByte(int(d / time.Hour))
+	b[1] = dvbDurationByte(int(d % time.Hour / time.Minute))
+}
+
+// writeDVBDurationSeconds writes a seconds duration
+func writeDVBDurationSeconds(b []byte, d time.Duration) {
+	b[0] = dvbDurationByte(int(d / time.Hour))
+	b[1] = dvbDurationByte(int(d % time.Hour / time.Minute))
+	b[2] = dvbDurationByte(int(d % time.Minute / time.Second))
+}
+
+// dvbDurationByte returns the 2 digits BCD representation of a number
+func dvbDurationByte(n int) byte {
+	return byte(n/10%10)<<4 | byte(n%10)
+}
+
+// parseBCD parses a number coded as 4 bits BCD digits, most significant digit first
+func parseBCD(bs []byte) (o uint64) {
+	for _, b := range bs {
+		o = o*100 + uint64(b>>4)*10 + uint64(b&0xf)
+	}
+	return
+}
+
+// writeBCD writes a number as 4 bits BCD digits filling b, most significant digit first
+func writeBCD(b []byte, v uint64) {
+	for idx := len(b) - 1; idx >= 0; idx-- {
+		b[idx] = dvbDurationByte(int(v % 100))
+		v /= 100
+	}
+}
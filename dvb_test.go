@@ -34,3 +34,25 @@ func TestParseDVBDurationSeconds(t *testing.T) {
 	assert.Equal(t, dvbDurationSeconds, d)
 	assert.NoError(t, err)
 }
+
+func TestWriteDVBTime(t *testing.T) {
+	b := make([]byte, 5)
+	writeDVBTime(b, dvbTime)
+	assert.Equal(t, dvbTimeBytes, b)
+	writeDVBTime(b, time.Date(2020, 2, 29, 23, 59, 59, 999, time.UTC))
+	d, err := parseDVBTime(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 2, 29, 23, 59, 59, 0, time.UTC), d)
+}
+
+func TestWriteDVBDurationMinutes(t *testing.T) {
+	b := make([]byte, 2)
+	writeDVBDurationMinutes(b, dvbDurationMinutes)
+	assert.Equal(t, dvbDurationMinutesBytes, b)
+}
+
+func TestWriteDVBDurationSeconds(t *testing.T) {
+	b := make([]byte, 3)
+	writeDVBDurationSeconds(b, dvbDurationSeconds)
+	assert.Equal(t, dvbDurationSecondsBytes, b)
+}
@@ -0,0 +1,127 @@
+package astits
+
+import "fmt"
+
+// PayloadType represents the type of a payload, as classified by the demuxer before parsing it
+type PayloadType uint8
+
+// Payload types
+const (
+	PayloadTypeUnknown PayloadType = iota
+	PayloadTypePES
+	PayloadTypePSI
+)
+
+var payloadTypeNames = map[PayloadType]string{
+	PayloadTypePES:     "PES",
+	PayloadTypePSI:     "PSI",
+	PayloadTypeUnknown: "unknown",
+}
+
+// String implements the fmt.Stringer interface
+func (t PayloadType) String() string {
+	if n, ok := payloadTypeNames[t]; ok {
+		return n
+	}
+	return "unknown"
+}
+
+// interceptor represents a packets parser and the payloads it is registered for
+type interceptor struct {
+	hasPID      bool
+	hasType     bool
+	legacy      bool
+	p           PacketsParser
+	pid         uint16
+	payloadType PayloadType
+}
+
+// InterceptorRegistry represents an ordered set of packets parsers, each of them registered for every payload, for
+// the payloads of a PID or for the payloads of a type
+// Matching interceptors are executed in the order they have been added and their data are concatenated. As soon as
+// one of them returns skip = true, the remaining ones and the default process are skipped.
+type InterceptorRegistry struct {
+	is []interceptor
+}
+
+// NewInterceptorRegistry creates a new interceptor registry
+func NewInterceptorRegistry() *InterceptorRegistry {
+	return &InterceptorRegistry{}
+}
+
+// Add adds an interceptor executed on every payload
+func (r *InterceptorRegistry) Add(p PacketsParser) {
+	r.is = append(r.is, interceptor{p: p})
+}
+
+// addLegacy adds an interceptor executed on every payload whose data are replaced by the PSI data when it doesn't
+// skip the default process, which is how packets parsers set through OptPacketsParser or ParseData used to behave
+// CAT payloads weren't parsed back then, therefore their data are kept.
+func (r *InterceptorRegistry) addLegacy(p PacketsParser) {
+	r.is = append(r.is, interceptor{
+		legacy: true,
+		p:      p,
+	})
+}
+
+// AddPID adds an interceptor executed on the payloads of a PID
+func (r *InterceptorRegistry) AddPID(pid uint16, p PacketsParser) {
+	r.is = append(r.is, interceptor{
+		hasPID: true,
+		p:      p,
+		pid:    pid,
+	})
+}
+
+// AddPayloadType adds an interceptor executed on the payloads of a type
+func (r *InterceptorRegistry) AddPayloadType(t PayloadType, p PacketsParser) {
+	r.is = append(r.is, interceptor{
+		hasType:     true,
+		p:           p,
+		payloadType: t,
+	})
+}
+
+// Len returns the number of interceptors
+func (r *InterceptorRegistry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.is)
+}
+
+// Intercept executes the interceptors matching the PID of the packets and the payload type
+func (r *InterceptorRegistry) Intercept(ps []*Packet, t PayloadType) (ds []*Data, skip bool, err error) {
+	// Nothing to do
+	if r.Len() == 0 {
+		return
+	}
+
+	// Loop through interceptors
+	for idx, i := range r.is {
+		// Interceptor doesn't match
+		if i.hasPID && (len(ps) == 0 || ps[0].Header.PID != i.pid) || i.hasType && i.payloadType != t {
+			continue
+		}
+
+		// Execute interceptor
+		var ids []*Data
+		if ids, skip, err = i.p(ps); err != nil {
+			err = fmt.Errorf("astits: interceptor #%d failed: %w", idx, err)
+			return
+		}
+
+		// Skip
+		if skip {
+			ds = append(ds, ids...)
+			return
+		}
+
+		// Legacy interceptors' data are replaced by the PSI data
+		if i.legacy && t == PayloadTypePSI && ps[0].Header.PID != PIDCAT {
+			continue
+		}
+		ds = append(ds, ids...)
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package astits
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptorRegistry(t *testing.T) {
+	// Nil registry
+	var r *InterceptorRegistry
+	ds, skip, err := r.Intercept(nil, PayloadTypeUnknown)
+	assert.NoError(t, err)
+	assert.False(t, skip)
+	assert.Len(t, ds, 0)
+
+	// Interceptors
+	var calls []string
+	i := func(name string, skip bool) PacketsParser {
+		return func(ps []*Packet) ([]*Data, bool, error) {
+			calls = append(calls, name)
+			return []*Data{{PID: uint16(len(calls))}}, skip, nil
+		}
+	}
+	r = NewInterceptorRegistry()
+	r.Add(i("all", false))
+	r.AddPID(0x100, i("pid", false))
+	r.AddPayloadType(PayloadTypePES, i("pes", false))
+	r.AddPayloadType(PayloadTypePSI, i("psi", true))
+	r.Add(i("last", false))
+	assert.Equal(t, 5, r.Len())
+
+	// Order
+	ps := []*Packet{{Header: &PacketHeader{PID: 0x100}}}
+	ds, skip, err = r.Intercept(ps, PayloadTypePES)
+	assert.NoError(t, err)
+	assert.False(t, skip)
+	assert.Equal(t, []string{"all", "pid", "pes", "last"}, calls)
+	assert.Equal(t, []*Data{{PID: 1}, {PID: 2}, {PID: 3}, {PID: 4}}, ds)
+
+	// Skip
+	calls = []string{}
+	ps[0].Header.PID = 0x101
+	_, skip, err = r.Intercept(ps, PayloadTypePSI)
+	assert.NoError(t, err)
+	assert.True(t, skip)
+	assert.Equal(t, []string{"all", "psi"}, calls)
+
+	// Error
+	e := errors.New("test")
+	r.AddPID(0x101, func(ps []*Packet) ([]*Data, bool, error) { return nil, false, e })
+	_, _, err = r.Intercept(ps, PayloadTypeUnknown)
+	assert.True(t, errors.Is(err, e))
+}
+
+func TestParseDataInterceptors(t *testing.T) {
+	// Interceptors
+	var types []PayloadType
+	r := NewInterceptorRegistry()
+	r.AddPayloadType(PayloadTypePSI, func(ps []*Packet) ([]*Data, bool, error) {
+		types = append(types, PayloadTypePSI)
+		return nil, false, nil
+	})
+	r.AddPayloadType(PayloadTypePES, func(ps []*Packet) ([]*Data, bool, error) {
+		types = append(types, PayloadTypePES)
+		return []*Data{{PID: 1}}, true, nil
+	})
+
+	// PSI
+	ds, err := parseData([]*Packet{{Header: &PacketHeader{PID: PIDCAT}, Payload: catPSIBytes(t)}}, r, NewProgramMap(), ProfileAuto, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.NotNil(t, ds[0].CAT)
+
+	// PES
+	ds, err = parseData([]*Packet{{Header: &PacketHeader{PID: 0x100}, Payload: pesWithHeaderBytes()}}, r, NewProgramMap(), ProfileAuto, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Data{{PID: 1}}, ds)
+	assert.Equal(t, []PayloadType{PayloadTypePSI, PayloadTypePES}, types)
+}
+
+func TestParseDataPacketsParser(t *testing.T) {
+	// Packets parser
+	prs := func(ps []*Packet) ([]*Data, bool, error) { return []*Data{{PID: 1}}, false, nil }
+	psi := []*Packet{{Header: &PacketHeader{PID: PIDPAT}, Payload: psiBytes()}}
+
+	// PSI data replace the packets parser data
+	ds, err := ParseData(psi, prs, NewProgramMap())
+	assert.NoError(t, err)
+	assert.Len(t, ds, 6)
+	for _, d := range ds {
+		assert.Equal(t, uint16(PIDPAT), d.PID)
+	}
+
+	// Unless it's a CAT
+	ds, err = ParseData([]*Packet{{Header: &PacketHeader{PID: PIDCAT}, Payload: catPSIBytes(t)}}, prs, NewProgramMap())
+	assert.NoError(t, err)
+	assert.Len(t, ds, 2)
+	assert.Equal(t, &Data{PID: 1}, ds[0])
+
+	// Interceptors append their data
+	r := NewInterceptorRegistry()
+	r.Add(prs)
+	ds, err = parseData(psi, r, NewProgramMap(), ProfileAuto, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 7)
+	assert.Equal(t, &Data{PID: 1}, ds[0])
+}
@@ -0,0 +1,173 @@
+package astits
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+)
+
+// LossInjectorOptions represents loss injector options
+// Rates are probabilities between 0 and 1 applied to every packet.
+type LossInjectorOptions struct {
+	BitFlipRate   float64 // A random bit of the packet, sync byte excluded, is flipped
+	DropRate      float64
+	DuplicateRate float64
+	PacketSize    int     // Defaults to MpegTsPacketSize
+	ReorderRate   float64 // The packet is swapped with the next one
+	Seed          int64   // Same seed, same impairments
+}
+
+// LossInjectorStats represents the impairments a loss injector has applied so far
+type LossInjectorStats struct {
+	BitFlipped int
+	Dropped    int
+	Duplicated int
+	Reordered  int
+}
+
+// LossInjector simulates a lossy transport by dropping, duplicating, reordering or corrupting packets at configurable
+// rates, which comes in handy when testing the robustness of receivers built on top of the demuxer
+// It wraps the reader the demuxer reads from, or can be plugged in the demuxer as a packet middleware, in which case
+// packets can only be dropped or corrupted.
+type LossInjector struct {
+	buf  []byte
+	err  error
+	held []byte
+	m    *sync.Mutex
+	o    LossInjectorOptions
+	out  []byte
+	r    io.Reader
+	rand *rand.Rand
+	s    LossInjectorStats
+}
+
+// NewLossInjector creates a new loss injector reading packets from r
+// r can be nil if the loss injector is only used as a packet middleware.
+func NewLossInjector(r io.Reader, o LossInjectorOptions) *LossInjector {
+	if o.PacketSize <= 0 {
+		o.PacketSize = MpegTsPacketSize
+	}
+	return &LossInjector{
+		buf:  make([]byte, o.PacketSize),
+		m:    &sync.Mutex{},
+		o:    o,
+		r:    r,
+		rand: rand.New(rand.NewSource(o.Seed)),
+	}
+}
+
+// Stats returns the impairments applied so far
+func (li *LossInjector) Stats() LossInjectorStats {
+	// Lock
+	li.m.Lock()
+	defer li.m.Unlock()
+	return li.s
+}
+
+// Read implements the io.Reader interface
+func (li *LossInjector) Read(p []byte) (n int, err error) {
+	// Lock
+	li.m.Lock()
+	defer li.m.Unlock()
+
+	// Loop until there are bytes to return
+	for len(li.out) == 0 {
+		// Reader is exhausted
+		if li.err != nil {
+			err = li.err
+			return
+		}
+
+		// Read packet
+		var rn int
+		if rn, err = io.ReadFull(li.r, li.buf); err != nil {
+			// Flush truncated and held packets as is
+			li.out = append(li.out, li.buf[:rn]...)
+			li.out = append(li.out, li.held...)
+			li.held = nil
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			li.err = err
+			err = nil
+			continue
+		}
+
+		// Impair packet
+		li.impair(append([]byte(nil), li.buf...))
+	}
+
+	// Copy bytes
+	n = copy(p, li.out)
+	li.out = li.out[n:]
+	return
+}
+
+// impair applies impairments to a packet and appends the result to the bytes to return
+func (li *LossInjector) impair(b []byte) {
+	// Drop
+	if li.hit(li.o.DropRate) {
+		li.s.Dropped++
+		return
+	}
+
+	// Bit flip
+	li.bitFlip(b)
+
+	// Reorder
+	if li.held == nil && li.hit(li.o.ReorderRate) {
+		li.s.Reordered++
+		li.held = b
+		return
+	}
+
+	// Duplicate
+	li.out = append(li.out, b...)
+	if li.hit(li.o.DuplicateRate) {
+		li.s.Duplicated++
+		li.out = append(li.out, b...)
+	}
+
+	// Release held packet
+	if li.held != nil {
+		li.out = append(li.out, li.held...)
+		li.held = nil
+	}
+}
+
+func (li *LossInjector) bitFlip(b []byte) {
+	if len(b) < 2 || !li.hit(li.o.BitFlipRate) {
+		return
+	}
+	li.s.BitFlipped++
+	idx := 1 + li.rand.Intn(len(b)-1)
+	b[idx] ^= 1 << uint(li.rand.Intn(8))
+}
+
+func (li *LossInjector) hit(rate float64) bool {
+	return rate > 0 && li.rand.Float64() < rate
+}
+
+// PacketMiddleware returns a packet middleware dropping packets and flipping bits of their payload
+// Duplication and reordering are not applied since middlewares process packets one at a time.
+func (li *LossInjector) PacketMiddleware() PacketMiddleware {
+	return func(p *Packet) (drop bool, err error) {
+		// Lock
+		li.m.Lock()
+		defer li.m.Unlock()
+
+		// Drop
+		if li.hit(li.o.DropRate) {
+			li.s.Dropped++
+			drop = true
+			return
+		}
+
+		// Bit flip
+		if len(p.Payload) > 0 && li.hit(li.o.BitFlipRate) {
+			li.s.BitFlipped++
+			p.Payload[li.rand.Intn(len(p.Payload))] ^= 1 << uint(li.rand.Intn(8))
+		}
+		return
+	}
+}
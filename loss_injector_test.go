@@ -0,0 +1,76 @@
+package astits
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lossInjectorPackets(n int) (b []byte) {
+	for idx := 0; idx < n; idx++ {
+		p := make([]byte, 4)
+		p[0] = syncByte
+		p[1] = byte(idx)
+		b = append(b, p...)
+	}
+	return
+}
+
+func TestLossInjector(t *testing.T) {
+	// No impairments
+	b := lossInjectorPackets(3)
+	o, err := ioutil.ReadAll(NewLossInjector(bytes.NewReader(b), LossInjectorOptions{PacketSize: 4}))
+	assert.NoError(t, err)
+	assert.Equal(t, b, o)
+
+	// Drop
+	li := NewLossInjector(bytes.NewReader(b), LossInjectorOptions{DropRate: 1, PacketSize: 4})
+	o, err = ioutil.ReadAll(li)
+	assert.NoError(t, err)
+	assert.Len(t, o, 0)
+	assert.Equal(t, LossInjectorStats{Dropped: 3}, li.Stats())
+
+	// Duplicate
+	o, err = ioutil.ReadAll(NewLossInjector(bytes.NewReader(b), LossInjectorOptions{DuplicateRate: 1, PacketSize: 4}))
+	assert.NoError(t, err)
+	assert.Equal(t, append(append(append(append(append(append([]byte{}, b[:4]...), b[:4]...), b[4:8]...), b[4:8]...), b[8:]...), b[8:]...), o)
+
+	// Reorder
+	o, err = ioutil.ReadAll(NewLossInjector(bytes.NewReader(append(b, 0x47, 0x3)), LossInjectorOptions{PacketSize: 4, ReorderRate: 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, append(append(append(append([]byte{}, b[4:8]...), b[:4]...), 0x47, 0x3), b[8:]...), o)
+
+	// Bit flip
+	li = NewLossInjector(bytes.NewReader(b), LossInjectorOptions{BitFlipRate: 1, PacketSize: 4, Seed: 1})
+	o, err = ioutil.ReadAll(li)
+	assert.NoError(t, err)
+	assert.Len(t, o, len(b))
+	assert.Equal(t, 3, li.Stats().BitFlipped)
+	for idx := 0; idx < len(b); idx += 4 {
+		assert.Equal(t, byte(syncByte), o[idx])
+		var diff int
+		for i := idx; i < idx+4; i++ {
+			for x := b[i] ^ o[i]; x > 0; x &= x - 1 {
+				diff++
+			}
+		}
+		assert.Equal(t, 1, diff)
+	}
+}
+
+func TestLossInjectorPacketMiddleware(t *testing.T) {
+	li := NewLossInjector(nil, LossInjectorOptions{BitFlipRate: 1})
+	p := &Packet{Payload: []byte{0, 0}}
+	drop, err := li.PacketMiddleware()(p)
+	assert.NoError(t, err)
+	assert.False(t, drop)
+	assert.NotEqual(t, []byte{0, 0}, p.Payload)
+
+	li = NewLossInjector(nil, LossInjectorOptions{DropRate: 1})
+	drop, err = li.PacketMiddleware()(p)
+	assert.NoError(t, err)
+	assert.True(t, drop)
+	assert.Equal(t, 1, li.Stats().Dropped)
+}
@@ -0,0 +1,62 @@
+package astits
+
+// Offset, in a packet carrying a PCR, of the byte containing the last bit of the PCR base, which is the byte the PCR
+// value refers to
+const pcrByteOffsetInPacket = 10
+
+// muxCBRPCR computes PCR values from the output byte position and the mux rate so that, in CBR mode, PCR accuracy is
+// deterministic and doesn't depend on the wall clock
+// It also computes the null packets needed to hold the mux rate: the output is paced on the timestamps of the data, the
+// first one being the reference.
+type muxCBRPCR struct {
+	muxRate  int64 // In bits per second
+	start    int64 // In 27 MHz units
+	ts       *ClockReference
+	tsOffset int64
+}
+
+func newMuxCBRPCR(muxRate int64, start ClockReference) *muxCBRPCR {
+	return &muxCBRPCR{
+		muxRate: muxRate,
+		start:   clockReferenceTicks(start),
+	}
+}
+
+// pcr returns the PCR of the packet starting at byte offset o of the output
+func (c *muxCBRPCR) pcr(o int64) *ClockReference {
+	return c.at(o + pcrByteOffsetInPacket)
+}
+
+// at returns the clock reference of the byte at offset o of the output
+func (c *muxCBRPCR) at(o int64) *ClockReference {
+	return newClockReferenceFromTicks(c.start + c.ticks(o))
+}
+
+// ticks returns the duration, in 27 MHz units, of the first o bytes of the output
+func (c *muxCBRPCR) ticks(o int64) int64 {
+	// Split the division to avoid overflowing
+	bits := o * 8
+	return bits/c.muxRate*27000000 + bits%c.muxRate*27000000/c.muxRate
+}
+
+// nullPackets returns the number of null packets to write at byte offset o of the output so that the data timestamped
+// ts doesn't start before its time
+// Null packets can't make up for an input bitrate higher than the mux rate, in which case PCRs drift behind timestamps.
+func (c *muxCBRPCR) nullPackets(o int64, ts ClockReference) int {
+	// First timestamp is the reference
+	if c.ts == nil {
+		c.ts = &ts
+		c.tsOffset = o
+		return 0
+	}
+
+	// Output is not ahead of the timestamp
+	d := clockReferenceTicksDiff(ts, *c.ts) - (c.ticks(o) - c.ticks(c.tsOffset))
+	if d <= 0 {
+		return 0
+	}
+
+	// Split the multiplication to avoid overflowing, and round up
+	bits := d/27000000*c.muxRate + (d%27000000*c.muxRate+27000000-1)/27000000
+	return int((bits + MpegTsPacketSize*8 - 1) / (MpegTsPacketSize * 8))
+}
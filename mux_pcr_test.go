@@ -0,0 +1,41 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMuxCBRPCR(t *testing.T) {
+	// 1 packet every ms
+	c := newMuxCBRPCR(MpegTsPacketSize*8*1000, ClockReference{Base: 1000})
+	assert.Equal(t, &ClockReference{Base: 1004, Extension: 236}, c.pcr(0))
+	assert.Equal(t, &ClockReference{Base: 1094, Extension: 236}, c.pcr(MpegTsPacketSize))
+	assert.Equal(t, &ClockReference{Base: 91004, Extension: 236}, c.pcr(1000*MpegTsPacketSize))
+
+	// Wrap around
+	c = newMuxCBRPCR(MpegTsPacketSize*8*1000, ClockReference{Base: clockReferenceBaseWrap - 10})
+	assert.Equal(t, &ClockReference{Base: 84, Extension: 236}, c.pcr(MpegTsPacketSize))
+
+	// Big offsets don't overflow
+	c = newMuxCBRPCR(80e6, ClockReference{})
+	assert.Equal(t, &ClockReference{Base: 90000 * 3600 * 24, Extension: 27}, c.pcr(10e6*3600*24))
+}
+
+func TestMuxCBRPCRNullPackets(t *testing.T) {
+	// 1 packet every ms, first timestamp is the reference
+	c := newMuxCBRPCR(MpegTsPacketSize*8*1000, ClockReference{})
+	assert.Equal(t, 0, c.nullPackets(2*MpegTsPacketSize, ClockReference{Base: 900}))
+
+	// Output is behind
+	assert.Equal(t, 7, c.nullPackets(5*MpegTsPacketSize, ClockReference{Base: 900 + 90*10}))
+
+	// Output is ahead, partial packets are rounded up
+	assert.Equal(t, 0, c.nullPackets(20*MpegTsPacketSize, ClockReference{Base: 900 + 90*15}))
+	assert.Equal(t, 2, c.nullPackets(20*MpegTsPacketSize, ClockReference{Base: 900 + 90*19 + 45}))
+
+	// Wrap around
+	c = newMuxCBRPCR(MpegTsPacketSize*8*1000, ClockReference{})
+	assert.Equal(t, 0, c.nullPackets(0, ClockReference{Base: clockReferenceBaseWrap - 90}))
+	assert.Equal(t, 2, c.nullPackets(0, ClockReference{Base: 90}))
+}
@@ -0,0 +1,99 @@
+package astits
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Payload size of a packet without adaptation field
+const muxPacketMaxPayloadSize = MpegTsPacketSize - 4
+
+// MuxerData represents a PES to be muxed
+type MuxerData struct {
+	// When true, the data alignment indicator of the PES optional header is set, signaling that the PES payload starts
+	// with an access unit. Since packetization always starts a PES in a new packet, access units then start at PES and
+	// packet boundaries, which many decoders require.
+	AccessUnitAligned bool
+	AdaptationField   *PacketAdaptationField // Carried by the first packet, e.g. to signal a random access point
+	PES               *PESData
+	PID               uint16
+}
+
+// newMuxPESPackets splits the PES of a data into packets
+// The first packet has the payload unit start indicator set and carries the adaptation field of the data, if any.
+// The last packet is stuffed through its adaptation field so that the next PES starts in a new packet.
+// Continuity counters start at cc.
+func newMuxPESPackets(d *MuxerData, cc uint8) (ps []*Packet, err error) {
+	// No PES
+	if d.PES == nil || d.PES.Header == nil {
+		err = errors.New("astits: PES is missing")
+		return
+	}
+
+	// Data alignment, on copies of the headers so that the caller's PES is left untouched
+	pes := d.PES
+	if d.AccessUnitAligned && hasPESOptionalHeader(d.PES.Header.StreamID) {
+		h := *d.PES.Header
+		oh := PESOptionalHeader{}
+		if h.OptionalHeader != nil {
+			oh = *h.OptionalHeader
+		}
+		oh.DataAlignmentIndicator = true
+		h.OptionalHeader = &oh
+		v := *d.PES
+		v.Header = &h
+		pes = &v
+	}
+
+	// Serialise PES
+	b := make([]byte, 6+3+0xff+len(pes.Data))
+	var n int
+	if n, err = pes.Serialise(b); err != nil {
+		err = fmt.Errorf("astits: serialising PES failed: %w", err)
+		return
+	}
+	b = b[:n]
+
+	// Loop through payload
+	for idx := 0; idx < len(b); {
+		// Create packet
+		p := &Packet{Header: &PacketHeader{
+			ContinuityCounter:         cc,
+			HasPayload:                true,
+			PayloadUnitStartIndicator: idx == 0,
+			PID:                       d.PID,
+		}}
+		cc = (cc + 1) & 0xf
+
+		// Adaptation field
+		afLength := 0
+		if idx == 0 && d.AdaptationField != nil {
+			a := *d.AdaptationField
+			p.AdaptationField = &a
+			afLength = 1 + p.AdaptationField.length()
+			if afLength > muxPacketMaxPayloadSize {
+				err = fmt.Errorf("astits: adaptation field length %d is too big", afLength)
+				return
+			}
+		}
+
+		// Stuffing
+		if r := len(b) - idx; r < muxPacketMaxPayloadSize-afLength {
+			if p.AdaptationField == nil {
+				p.AdaptationField = &PacketAdaptationField{}
+			}
+			afLength = muxPacketMaxPayloadSize - r
+			p.AdaptationField.Length = afLength - 1
+		} else if p.AdaptationField != nil {
+			p.AdaptationField.Length = afLength - 1
+		}
+		p.Header.HasAdaptationField = p.AdaptationField != nil
+
+		// Payload
+		l := muxPacketMaxPayloadSize - afLength
+		p.Payload = b[idx : idx+l]
+		idx += l
+		ps = append(ps, p)
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMuxPESPackets(t *testing.T) {
+	// Multiple packets
+	d := &MuxerData{
+		AccessUnitAligned: true,
+		AdaptationField:   &PacketAdaptationField{HasPCR: true, PCR: pcr, RandomAccessIndicator: true},
+		PES: &PESData{
+			Data: bytes.Repeat([]byte{1}, 400),
+			Header: &PESHeader{
+				OptionalHeader: &PESOptionalHeader{PTS: ptsClockReference},
+				StreamID:       0xe0, // Video stream
+			},
+		},
+		PID: 256,
+	}
+	ps, err := newMuxPESPackets(d, 15)
+	assert.NoError(t, err)
+	assert.Len(t, ps, 3)
+	assert.False(t, d.PES.Header.OptionalHeader.DataAlignmentIndicator)
+
+	var buf bytes.Buffer
+	for idx, p := range ps {
+		assert.Equal(t, uint16(256), p.Header.PID)
+		assert.Equal(t, uint8(15+idx)&0xf, p.Header.ContinuityCounter)
+		assert.Equal(t, idx == 0, p.Header.PayloadUnitStartIndicator)
+		b := make([]byte, MpegTsPacketSize)
+		_, err = p.Serialise(b)
+		assert.NoError(t, err)
+		buf.Write(b)
+	}
+	assert.Equal(t, pcr, ps[0].AdaptationField.PCR)
+	assert.True(t, ps[0].AdaptationField.RandomAccessIndicator)
+	assert.Nil(t, ps[1].AdaptationField)
+	assert.Equal(t, 400+14-(184-8)-184, len(ps[2].Payload))
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	v, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, d.PES.Data, v.PES.Data)
+	assert.True(t, v.PES.Header.OptionalHeader.DataAlignmentIndicator)
+	assert.Equal(t, ptsClockReference, v.PES.Header.OptionalHeader.PTS)
+
+	// Last packet stuffing is a single byte
+	d = &MuxerData{
+		PES: &PESData{
+			Data:   bytes.Repeat([]byte{1}, 183-9),
+			Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: StreamIDPrivateStream1},
+		},
+		PID: 257,
+	}
+	ps, err = newMuxPESPackets(d, 0)
+	assert.NoError(t, err)
+	assert.Len(t, ps, 1)
+	assert.Equal(t, &PacketAdaptationField{}, ps[0].AdaptationField)
+	assert.False(t, d.PES.Header.OptionalHeader.DataAlignmentIndicator)
+
+	// Missing optional header is not allocated in the caller's PES
+	d = &MuxerData{
+		AccessUnitAligned: true,
+		PES:               &PESData{Data: []byte{1}, Header: &PESHeader{StreamID: 0xe0}},
+		PID:               258,
+	}
+	ps, err = newMuxPESPackets(d, 0)
+	assert.NoError(t, err)
+	assert.Len(t, ps, 1)
+	assert.Nil(t, d.PES.Header.OptionalHeader)
+	assert.Equal(t, uint8(0x84), ps[0].Payload[6])
+
+	// No PES
+	_, err = newMuxPESPackets(&MuxerData{}, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package astits
+
+import (
+	"fmt"
+)
+
+// First PMT PID assigned by default to programs
+const muxDefaultPMTPIDStart = 0x1000
+
+// MuxProgram represents a program description that the muxer converts into PAT and PMT
+type MuxProgram struct {
+	Descriptors []*Descriptor // Program info descriptors
+	Number      uint16
+	PCRPID      uint16 // Defaults to the PID of the first stream
+	PMTPID      uint16 // Defaults to 0x1000 + the index of the program
+	Streams     []MuxStream
+}
+
+// MuxStream represents an elementary stream description of a program
+type MuxStream struct {
+	Descriptors []*Descriptor
+	PID         uint16
+	StreamType  uint8
+}
+
+// pmtPID returns the PMT PID of the program, idx being its index in the programs list
+func (p MuxProgram) pmtPID(idx int) uint16 {
+	if p.PMTPID > 0 {
+		return p.PMTPID
+	}
+	return muxDefaultPMTPIDStart + uint16(idx)
+}
+
+// pcrPID returns the PCR PID of the program
+func (p MuxProgram) pcrPID() uint16 {
+	if p.PCRPID > 0 || len(p.Streams) == 0 {
+		return p.PCRPID
+	}
+	return p.Streams[0].PID
+}
+
+// validateMuxPrograms checks whether programs can be converted into a valid PAT and PMTs
+func validateMuxPrograms(ps []MuxProgram) error {
+	numbers := make(map[uint16]bool)
+	pmtPIDs := make(map[uint16]bool)
+	esPIDs := make(map[uint16]bool)
+	for idx, p := range ps {
+		// Program number 0 is reserved to NIT
+		if p.Number == 0 {
+			return fmt.Errorf("astits: program #%d has reserved number 0", idx)
+		}
+		if numbers[p.Number] {
+			return fmt.Errorf("astits: program number %d is duplicated", p.Number)
+		}
+		numbers[p.Number] = true
+
+		// PMT PID
+		pmtPID := p.pmtPID(idx)
+		if IsReservedPID(pmtPID) {
+			return fmt.Errorf("astits: PMT PID %d of program %d: %w", pmtPID, p.Number, ErrPIDReserved)
+		}
+		pmtPIDs[pmtPID] = true
+
+		// PCR PID, PIDNull meaning the program has no PCR
+		if pcrPID := p.pcrPID(); pcrPID != PIDNull && pcrPID > 0 && IsReservedPID(pcrPID) {
+			return fmt.Errorf("astits: PCR PID %d of program %d: %w", pcrPID, p.Number, ErrPIDReserved)
+		}
+
+		// Streams
+		pids := make(map[uint16]bool)
+		for _, s := range p.Streams {
+			if IsReservedPID(s.PID) {
+				return fmt.Errorf("astits: PID %d of program %d: %w", s.PID, p.Number, ErrPIDReserved)
+			}
+			if pids[s.PID] {
+				return fmt.Errorf("astits: PID %d of program %d is duplicated", s.PID, p.Number)
+			}
+			pids[s.PID] = true
+			esPIDs[s.PID] = true
+		}
+	}
+
+	// PMT PIDs can't be shared with elementary streams
+	for pid := range pmtPIDs {
+		if esPIDs[pid] {
+			return fmt.Errorf("astits: PMT PID %d is used by an elementary stream", pid)
+		}
+	}
+	return nil
+}
+
+// validateMuxSIPIDs checks whether the PIDs SI tables are emitted on, indexed by PID, collide neither with the PAT
+// and the null packets nor with the PIDs of programs
+func validateMuxSIPIDs(ps []MuxProgram, siPIDs map[uint16]string) error {
+	// Reserved PIDs
+	for pid, n := range siPIDs {
+		if pid == PIDPAT || pid == PIDNull {
+			return fmt.Errorf("astits: %s PID %d: %w", n, pid, ErrPIDReserved)
+		}
+	}
+
+	// Programs
+	for idx, p := range ps {
+		if n, ok := siPIDs[p.pmtPID(idx)]; ok {
+			return fmt.Errorf("astits: %s PID %d is used by the PMT of program %d", n, p.pmtPID(idx), p.Number)
+		}
+		for _, s := range p.Streams {
+			if n, ok := siPIDs[s.PID]; ok {
+				return fmt.Errorf("astits: %s PID %d is used by an elementary stream of program %d", n, s.PID, p.Number)
+			}
+		}
+	}
+	return nil
+}
+
+// newMuxPATSections creates the PAT sections describing programs
+// When nitPID is not 0, the program number 0 pointing to it is listed first.
+func newMuxPATSections(transportStreamID uint16, ps []MuxProgram, nitPID uint16) ([]*PSISection, error) {
+	d := &PATData{TransportStreamID: transportStreamID}
+	if nitPID > 0 {
+		d.Programs = append(d.Programs, &PATProgram{ProgramMapID: nitPID})
+	}
+	for idx, p := range ps {
+		d.Programs = append(d.Programs, &PATProgram{
+			ProgramMapID:  p.pmtPID(idx),
+			ProgramNumber: p.Number,
+		})
+	}
+	return NewPATSections(d)
+}
+
+// newMuxPMTSection creates the PMT section describing a program
+func newMuxPMTSection(p MuxProgram) *PSISection {
+	d := &PMTData{
+		PCRPID:             p.pcrPID(),
+		ProgramDescriptors: p.Descriptors,
+		ProgramNumber:      p.Number,
+	}
+	for _, s := range p.Streams {
+		d.ElementaryStreams = append(d.ElementaryStreams, &PMTElementaryStream{
+			ElementaryPID:               s.PID,
+			ElementaryStreamDescriptors: s.Descriptors,
+			StreamType:                  s.StreamType,
+		})
+	}
+	return &PSISection{
+		Header: &PSISectionHeader{
+			SectionSyntaxIndicator: true,
+			TableID:                2,
+			TableType:              PSITableTypePMT,
+			Type:                   TableTypePMT,
+		},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{PMT: d},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: p.Number},
+		},
+	}
+}
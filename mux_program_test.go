@@ -0,0 +1,83 @@
+package astits
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+var muxPrograms = []MuxProgram{
+	{
+		Number: 1,
+		Streams: []MuxStream{
+			{PID: 256, StreamType: StreamTypeH264Video},
+			{PID: 257, StreamType: StreamTypeAudioADTS},
+		},
+	},
+	{
+		Number: 2,
+		PCRPID: 258,
+		PMTPID: 200,
+		Streams: []MuxStream{
+			{PID: 257, StreamType: StreamTypeAudioADTS},
+			{PID: 258, StreamType: StreamTypeH264Video},
+		},
+	},
+}
+
+func muxProgramRoundTrip(t *testing.T, s *PSISection) *PSISectionSyntaxData {
+	b := make([]byte, psiSectionMaxSize)
+	_, err := (&PSIData{Sections: []*PSISection{s}}).Serialise(b)
+	assert.NoError(t, err)
+	d, err := parsePSIData(astikit.NewBytesIterator(b), ProfileAuto)
+	assert.NoError(t, err)
+	return d.Sections[0].Syntax.Data
+}
+
+func TestNewMuxPATSections(t *testing.T) {
+	ss, err := newMuxPATSections(3, muxPrograms, 0)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+	assert.Equal(t, &PATData{
+		Programs: []*PATProgram{
+			{ProgramMapID: 4096, ProgramNumber: 1},
+			{ProgramMapID: 200, ProgramNumber: 2},
+		},
+		TransportStreamID: 3,
+	}, muxProgramRoundTrip(t, ss[0]).PAT)
+
+	// NIT
+	ss, err = newMuxPATSections(3, muxPrograms[:1], PIDNIT)
+	assert.NoError(t, err)
+	assert.Equal(t, []*PATProgram{
+		{ProgramMapID: PIDNIT, ProgramNumber: 0},
+		{ProgramMapID: 4096, ProgramNumber: 1},
+	}, muxProgramRoundTrip(t, ss[0]).PAT.Programs)
+}
+
+func TestNewMuxPMTSection(t *testing.T) {
+	assert.Equal(t, &PMTData{
+		ElementaryStreams: []*PMTElementaryStream{
+			{ElementaryPID: 256, StreamType: StreamTypeH264Video},
+			{ElementaryPID: 257, StreamType: StreamTypeAudioADTS},
+		},
+		PCRPID:        256,
+		ProgramNumber: 1,
+	}, muxProgramRoundTrip(t, newMuxPMTSection(muxPrograms[0])).PMT)
+	assert.Equal(t, uint16(258), muxProgramRoundTrip(t, newMuxPMTSection(muxPrograms[1])).PMT.PCRPID)
+}
+
+func TestValidateMuxPrograms(t *testing.T) {
+	assert.NoError(t, validateMuxPrograms(muxPrograms))
+	assert.Error(t, validateMuxPrograms([]MuxProgram{{}}))
+	assert.Error(t, validateMuxPrograms([]MuxProgram{{Number: 1}, {Number: 1}}))
+	assert.Error(t, validateMuxPrograms([]MuxProgram{{Number: 1, PMTPID: PIDNull}}))
+	assert.True(t, errors.Is(validateMuxPrograms([]MuxProgram{{Number: 1, Streams: []MuxStream{{PID: PIDCAT}}}}), ErrPIDReserved))
+	assert.True(t, errors.Is(validateMuxPrograms([]MuxProgram{{Number: 1, Streams: []MuxStream{{PID: PIDSIT}}}}), ErrPIDReserved))
+	assert.True(t, errors.Is(validateMuxPrograms([]MuxProgram{{Number: 1, PCRPID: PIDEIT, Streams: []MuxStream{{PID: 256}}}}), ErrPIDReserved))
+	assert.NoError(t, validateMuxPrograms([]MuxProgram{{Number: 1, PCRPID: PIDNull, Streams: []MuxStream{{PID: 256}}}}))
+	assert.Error(t, validateMuxPrograms([]MuxProgram{{Number: 1, Streams: []MuxStream{{PID: 256}, {PID: 256}}}}))
+	assert.Error(t, validateMuxPrograms([]MuxProgram{{Number: 1, Streams: []MuxStream{{PID: 4097}}}, {Number: 2}}))
+}
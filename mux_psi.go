@@ -0,0 +1,51 @@
+package astits
+
+import (
+	"fmt"
+)
+
+// newMuxPSIPackets splits PSI sections into packets
+// Sections are written back to back after a zero pointer field, starting in a new packet which has the payload unit
+// start indicator set. The last packet is stuffed with stuffingByte, which should be 0xff unless a legacy receiver
+// expects otherwise.
+// Continuity counters start at cc.
+func newMuxPSIPackets(pid uint16, ss []*PSISection, cc, stuffingByte uint8) (ps []*Packet, err error) {
+	// Serialise sections
+	b := []byte{0x0} // Pointer field
+	for _, s := range ss {
+		sb := make([]byte, psiSectionMaxSize)
+		var n int
+		if n, err = s.serialise(sb); err != nil {
+			err = fmt.Errorf("astits: serialising PSI section failed: %w", err)
+			return
+		}
+		b = append(b, sb[:n]...)
+	}
+	return newMuxSectionPackets(pid, b, cc, stuffingByte), nil
+}
+
+// newMuxSectionPackets splits a payload made of a pointer field followed by sections into packets
+func newMuxSectionPackets(pid uint16, b []byte, cc, stuffingByte uint8) (ps []*Packet) {
+	// Loop through payload
+	for idx := 0; idx < len(b); idx += muxPacketMaxPayloadSize {
+		// Create payload
+		pl := make([]byte, muxPacketMaxPayloadSize)
+		n := copy(pl, b[idx:])
+		for ; n < len(pl); n++ {
+			pl[n] = stuffingByte
+		}
+
+		// Create packet
+		ps = append(ps, &Packet{
+			Header: &PacketHeader{
+				ContinuityCounter:         cc,
+				HasPayload:                true,
+				PayloadUnitStartIndicator: idx == 0,
+				PID:                       pid,
+			},
+			Payload: pl,
+		})
+		cc = (cc + 1) & 0xf
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMuxPSIPackets(t *testing.T) {
+	// Sections spanning several packets
+	var streams []MuxStream
+	for idx := 0; idx < 40; idx++ {
+		streams = append(streams, MuxStream{PID: uint16(256 + idx), StreamType: StreamTypeH264Video})
+	}
+	s := newMuxPMTSection(MuxProgram{Number: 1, Streams: streams})
+	ps, err := newMuxPSIPackets(0x1000, []*PSISection{s}, 15, StuffingByte)
+	assert.NoError(t, err)
+	assert.Len(t, ps, 2)
+
+	var buf bytes.Buffer
+	for idx, p := range ps {
+		assert.Equal(t, uint16(0x1000), p.Header.PID)
+		assert.Equal(t, uint8(15+idx)&0xf, p.Header.ContinuityCounter)
+		assert.Equal(t, idx == 0, p.Header.PayloadUnitStartIndicator)
+		b := make([]byte, MpegTsPacketSize)
+		_, err = p.Serialise(b)
+		assert.NoError(t, err)
+		buf.Write(b)
+	}
+	assert.Equal(t, byte(0xff), ps[1].Payload[183])
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptProgramMap(map[uint16]uint16{0x1000: 1}))
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Len(t, d.PMT.ElementaryStreams, 40)
+}
@@ -0,0 +1,128 @@
+package astits
+
+// MuxNIT represents the config the muxer generates the NIT from
+type MuxNIT struct {
+	NetworkID        uint16
+	NetworkName      string
+	PID              uint16                  // Defaults to PIDNIT
+	TransportStreams []MuxNITTransportStream // Defaults to the muxed transport stream
+}
+
+// MuxNITTransportStream represents a transport stream of the NIT transport stream loop
+type MuxNITTransportStream struct {
+	Descriptors       []*Descriptor
+	OriginalNetworkID uint16
+	TransportStreamID uint16
+}
+
+// MuxSDT represents the config the muxer generates the SDT from
+type MuxSDT struct {
+	OriginalNetworkID uint16
+	PID               uint16 // Defaults to PIDSDT
+	Services          []MuxService
+}
+
+// MuxService represents a service of the SDT
+type MuxService struct {
+	Descriptors   []*Descriptor // Additional descriptors, the service descriptor is generated
+	Name          string
+	ProviderName  string
+	RunningStatus uint8 // Defaults to running
+	ServiceID     uint16
+	Type          uint8 // Defaults to digital television service
+}
+
+// pid returns the PID the NIT is emitted on
+func (c MuxNIT) pid() uint16 {
+	if c.PID > 0 {
+		return c.PID
+	}
+	return PIDNIT
+}
+
+// pid returns the PID the SDT is emitted on
+func (c MuxSDT) pid() uint16 {
+	if c.PID > 0 {
+		return c.PID
+	}
+	return PIDSDT
+}
+
+// newMuxNITSection creates the NIT section describing the network
+func newMuxNITSection(transportStreamID uint16, c MuxNIT) *PSISection {
+	// Create data
+	d := &NITData{NetworkID: c.NetworkID}
+
+	// Network name
+	if c.NetworkName != "" {
+		d.NetworkDescriptors = append(d.NetworkDescriptors, NewNetworkNameDescriptor(c.NetworkName))
+	}
+
+	// Transport streams
+	tss := c.TransportStreams
+	if len(tss) == 0 {
+		tss = []MuxNITTransportStream{{OriginalNetworkID: c.NetworkID, TransportStreamID: transportStreamID}}
+	}
+	for _, ts := range tss {
+		d.TransportStreams = append(d.TransportStreams, &NITDataTransportStream{
+			OriginalNetworkID:    ts.OriginalNetworkID,
+			TransportDescriptors: ts.Descriptors,
+			TransportStreamID:    ts.TransportStreamID,
+		})
+	}
+	return &PSISection{
+		Header: &PSISectionHeader{
+			PrivateBit:             true,
+			SectionSyntaxIndicator: true,
+			TableID:                0x40,
+			TableType:              PSITableTypeNIT,
+			Type:                   TableTypeNIT,
+		},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{NIT: d},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: c.NetworkID},
+		},
+	}
+}
+
+// newMuxSDTSection creates the SDT section describing the services of the muxed transport stream
+func newMuxSDTSection(transportStreamID uint16, c MuxSDT) *PSISection {
+	// Create data
+	d := &SDTData{
+		OriginalNetworkID: c.OriginalNetworkID,
+		TransportStreamID: transportStreamID,
+	}
+
+	// Services
+	for _, s := range c.Services {
+		// Default values
+		rs := s.RunningStatus
+		if rs == RunningStatusUndefined {
+			rs = RunningStatusRunning
+		}
+		t := s.Type
+		if t == 0 {
+			t = ServiceTypeDigitalTelevisionService
+		}
+
+		// Append service
+		d.Services = append(d.Services, &SDTDataService{
+			Descriptors:   append([]*Descriptor{NewServiceDescriptor(t, s.ProviderName, s.Name)}, s.Descriptors...),
+			RunningStatus: rs,
+			ServiceID:     s.ServiceID,
+		})
+	}
+	return &PSISection{
+		Header: &PSISectionHeader{
+			PrivateBit:             true,
+			SectionSyntaxIndicator: true,
+			TableID:                0x42,
+			TableType:              PSITableTypeSDT,
+			Type:                   TableTypeSDT,
+		},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{SDT: d},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: transportStreamID},
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMuxNITSection(t *testing.T) {
+	d := muxProgramRoundTrip(t, newMuxNITSection(2, MuxNIT{NetworkID: 1, NetworkName: "network"}))
+	removeOriginalBytesFromPSIData(d)
+	assert.Equal(t, &NITData{
+		NetworkDescriptors: []*Descriptor{{Length: 7, NetworkName: &DescriptorNetworkName{Name: []byte("network")}, Tag: DescriptorTagNetworkName}},
+		NetworkID:          1,
+		TransportStreams:   []*NITDataTransportStream{{OriginalNetworkID: 1, TransportStreamID: 2}},
+	}, d.NIT)
+}
+
+func TestNewMuxSDTSection(t *testing.T) {
+	d := muxProgramRoundTrip(t, newMuxSDTSection(2, MuxSDT{
+		OriginalNetworkID: 1,
+		Services:          []MuxService{{Name: "name", ProviderName: "provider", ServiceID: 3}},
+	}))
+	removeOriginalBytesFromPSIData(d)
+	assert.Equal(t, &SDTData{
+		OriginalNetworkID: 1,
+		Services: []*SDTDataService{{
+			Descriptors:   []*Descriptor{{Length: 15, Service: &DescriptorService{Name: []byte("name"), Provider: []byte("provider"), Type: ServiceTypeDigitalTelevisionService}, Tag: DescriptorTagService}},
+			RunningStatus: RunningStatusRunning,
+			ServiceID:     3,
+		}},
+		TransportStreamID: 2,
+	}, d.SDT)
+}
@@ -0,0 +1,80 @@
+package astits
+
+// MuxerCounter represents a number of packets and bytes written by the muxer
+type MuxerCounter struct {
+	Bytes   int64
+	Packets int64
+}
+
+// MuxerStats represents the packets and bytes written by the muxer, which is needed for bitrate budgeting
+type MuxerStats struct {
+	PIDs   map[uint16]MuxerCounter
+	Tables map[TableType]MuxerCounter // Tables generated by the muxer, such as the PAT, the PMTs or the SDT
+	Total  MuxerCounter
+}
+
+// NullRatio returns the ratio of null packets among the packets written, 0 if no packet has been written
+// Null packets are only written in CBR mode, to hold the mux rate, and the ratio is the headroom left by the inputs.
+func (s MuxerStats) NullRatio() float64 {
+	if s.Total.Packets == 0 {
+		return 0
+	}
+	return float64(s.PIDs[PIDNull].Packets) / float64(s.Total.Packets)
+}
+
+// muxerStats accumulates the muxer stats, the muxer lock being held
+type muxerStats struct {
+	pids   map[uint16]*MuxerCounter
+	tables map[TableType]*MuxerCounter
+	total  MuxerCounter
+}
+
+func newMuxerStats() *muxerStats {
+	return &muxerStats{
+		pids:   make(map[uint16]*MuxerCounter),
+		tables: make(map[TableType]*MuxerCounter),
+	}
+}
+
+func (s *muxerStats) addPID(pid uint16, n int) {
+	c, ok := s.pids[pid]
+	if !ok {
+		c = &MuxerCounter{}
+		s.pids[pid] = c
+	}
+	c.Bytes += int64(n)
+	c.Packets++
+	s.total.Bytes += int64(n)
+	s.total.Packets++
+}
+
+func (s *muxerStats) addTable(t TableType, packets, n int) {
+	c, ok := s.tables[t]
+	if !ok {
+		c = &MuxerCounter{}
+		s.tables[t] = c
+	}
+	c.Bytes += int64(n)
+	c.Packets += int64(packets)
+}
+
+// Stats returns a snapshot of the packets and bytes written so far, per PID and per table
+func (m *Muxer) Stats() (s MuxerStats) {
+	// Lock
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	// Copy
+	s = MuxerStats{
+		PIDs:   make(map[uint16]MuxerCounter, len(m.stats.pids)),
+		Tables: make(map[TableType]MuxerCounter, len(m.stats.tables)),
+		Total:  m.stats.total,
+	}
+	for k, v := range m.stats.pids {
+		s.PIDs[k] = *v
+	}
+	for k, v := range m.stats.tables {
+		s.Tables[k] = *v
+	}
+	return
+}
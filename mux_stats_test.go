@@ -0,0 +1,74 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMuxerStats(t *testing.T) {
+	now := time.Date(2021, 3, 28, 0, 59, 30, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf,
+		MuxerOptSDT(MuxSDT{Services: []MuxService{{Name: "name", ServiceID: 1}}}),
+		MuxerOptTime(MuxTime{
+			LocalTimeOffsets: []*DescriptorLocalTimeOffsetItem{{CountryCode: []byte("GBR")}},
+			Now:              func() time.Time { return now },
+		}),
+	)
+	assert.Equal(t, 0.0, m.Stats().NullRatio())
+
+	// Write
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, PMTPID: 0x1000, Streams: []MuxStream{{PID: 0x100, StreamType: StreamTypeH264Video}}}))
+	_, err := m.WriteData(muxerTestData(0x100, 1))
+	assert.NoError(t, err)
+
+	// Stats
+	p := func(n int64) MuxerCounter { return MuxerCounter{Bytes: n * MpegTsPacketSize, Packets: n} }
+	s := m.Stats()
+	assert.Equal(t, p(int64(buf.Len()/MpegTsPacketSize)), s.Total)
+	assert.Equal(t, map[uint16]MuxerCounter{
+		PIDPAT: p(1),
+		PIDSDT: p(1),
+		PIDTDT: p(2),
+		0x100:  p(2),
+		0x1000: p(1),
+	}, s.PIDs)
+	assert.Equal(t, map[TableType]MuxerCounter{
+		TableTypePAT: p(1),
+		TableTypePMT: p(1),
+		TableTypeSDT: p(1),
+		TableTypeTDT: p(1),
+		TableTypeTOT: p(1),
+	}, s.Tables)
+	assert.Equal(t, 0.0, s.NullRatio())
+
+	// Stats are a copy
+	s.PIDs[PIDNull] = p(2)
+	_, ok := m.Stats().PIDs[PIDNull]
+	assert.False(t, ok)
+}
+
+func TestMuxerStatsNullRatio(t *testing.T) {
+	// 1 packet every ms
+	m := NewMuxer(context.Background(), &bytes.Buffer{},
+		MuxerOptCBR(MpegTsPacketSize*8*1000, ClockReference{}),
+		MuxerOptTablesRetransmitPeriod(1000),
+	)
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 0x100, StreamType: StreamTypeH264Video}}}))
+
+	// Data 10ms apart, each being 2 packets long
+	for _, pts := range []int64{0, 900, 1800} {
+		_, err := m.WriteData(muxerTestData(0x100, pts))
+		assert.NoError(t, err)
+	}
+
+	// Null ratio
+	s := m.Stats()
+	assert.Equal(t, int64(16), s.PIDs[PIDNull].Packets)
+	assert.Equal(t, int64(24), s.Total.Packets)
+	assert.Equal(t, 16.0/24.0, s.NullRatio())
+}
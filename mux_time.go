@@ -0,0 +1,131 @@
+package astits
+
+import (
+	"time"
+)
+
+// Default interval between 2 TDT/TOT emissions
+const muxTimeDefaultInterval = 5 * time.Second
+
+// MuxTime represents the config the muxer generates the TDT and TOT from
+type MuxTime struct {
+	Interval         time.Duration                    // Defaults to 5s
+	LocalTimeOffsets []*DescriptorLocalTimeOffsetItem // If not empty, a TOT is emitted along with the TDT
+	Now              func() time.Time                 // Defaults to the host clock
+}
+
+// muxTimeScheduler decides when the TDT and TOT are due
+type muxTimeScheduler struct {
+	c    MuxTime
+	last time.Time
+}
+
+func newMuxTimeScheduler(c MuxTime) *muxTimeScheduler {
+	if c.Interval <= 0 {
+		c.Interval = muxTimeDefaultInterval
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	return &muxTimeScheduler{c: c}
+}
+
+// sections returns the TDT and TOT sections to emit, if they are due
+func (s *muxTimeScheduler) sections() (ss []*PSISection) {
+	// Not due yet
+	now := s.c.Now()
+	if !s.last.IsZero() && now.Sub(s.last) < s.c.Interval {
+		return
+	}
+	s.last = now
+
+	// TDT
+	ss = append(ss, NewTDTSection(now))
+
+	// TOT
+	if len(s.c.LocalTimeOffsets) > 0 {
+		ss = append(ss, NewTOTSection(now, s.c.LocalTimeOffsets))
+	}
+	return
+}
+
+// NewTDTSection creates a TDT section stamping t, with a precision of one second, as its UTC time
+func NewTDTSection(t time.Time) *PSISection {
+	return &PSISection{
+		Header: &PSISectionHeader{
+			PrivateBit: true,
+			TableID:    0x70,
+			TableType:  PSITableTypeTDT,
+			Type:       TableTypeTDT,
+		},
+		Syntax: &PSISectionSyntax{Data: &PSISectionSyntaxData{TDT: &TDTData{UTCTime: t}}},
+	}
+}
+
+// NewTOTSection creates a TOT section stamping t, with a precision of one second, as its UTC time along with a local
+// time offset descriptor listing offsets
+func NewTOTSection(t time.Time, offsets []*DescriptorLocalTimeOffsetItem) *PSISection {
+	return &PSISection{
+		Header: &PSISectionHeader{
+			PrivateBit: true,
+			TableID:    0x73,
+			TableType:  PSITableTypeTOT,
+			Type:       TableTypeTOT,
+		},
+		Syntax: &PSISectionSyntax{Data: &PSISectionSyntaxData{TOT: &TOTData{
+			Descriptors: []*Descriptor{{
+				LocalTimeOffset: &DescriptorLocalTimeOffset{Items: offsets},
+				Tag:             DescriptorTagLocalTimeOffset,
+			}},
+			UTCTime: t,
+		}}},
+	}
+}
+
+// NewLocalTimeOffsetItem creates a local time offset item out of the location of t
+// The next offset change is looked for up to a year ahead, the next offset being the current one if there is none.
+func NewLocalTimeOffsetItem(countryCode []byte, countryRegionID uint8, t time.Time) *DescriptorLocalTimeOffsetItem {
+	// Current offset
+	t = t.Truncate(time.Second)
+	_, o := t.Zone()
+	i := &DescriptorLocalTimeOffsetItem{
+		CountryCode:             countryCode,
+		CountryRegionID:         countryRegionID,
+		LocalTimeOffset:         muxTimeAbsOffset(o),
+		LocalTimeOffsetPolarity: o < 0,
+		NextTimeOffset:          muxTimeAbsOffset(o),
+		TimeOfChange:            t,
+	}
+
+	// Loop through days
+	end := t.AddDate(1, 0, 0)
+	for from, to := t, t.Add(24*time.Hour); !from.After(end); from, to = to, to.Add(24*time.Hour) {
+		// Offset didn't change
+		_, no := to.Zone()
+		if no == o {
+			continue
+		}
+
+		// Narrow down the change to the second
+		for to.Sub(from) > time.Second {
+			mid := from.Add((to.Sub(from) / 2).Truncate(time.Second))
+			if _, mo := mid.Zone(); mo == o {
+				from = mid
+			} else {
+				to = mid
+			}
+		}
+		i.NextTimeOffset = muxTimeAbsOffset(no)
+		i.TimeOfChange = to
+		break
+	}
+	return i
+}
+
+// muxTimeAbsOffset converts a zone offset in seconds into its absolute duration
+func muxTimeAbsOffset(o int) time.Duration {
+	if o < 0 {
+		o = -o
+	}
+	return time.Duration(o) * time.Second
+}
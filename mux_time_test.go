@@ -0,0 +1,84 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMuxTimeScheduler(t *testing.T) {
+	// Init
+	now := time.Date(2021, 3, 28, 0, 59, 30, 0, time.UTC)
+	lto := &DescriptorLocalTimeOffsetItem{
+		CountryCode:     []byte("GBR"),
+		LocalTimeOffset: 0,
+		NextTimeOffset:  time.Hour,
+		TimeOfChange:    time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC),
+	}
+	s := newMuxTimeScheduler(MuxTime{
+		Interval:         10 * time.Second,
+		LocalTimeOffsets: []*DescriptorLocalTimeOffsetItem{lto},
+		Now:              func() time.Time { return now },
+	})
+
+	// First call
+	ss := s.sections()
+	assert.Len(t, ss, 2)
+	d := muxProgramRoundTrip(t, ss[0])
+	assert.Equal(t, &TDTData{UTCTime: now}, d.TDT)
+	d = muxProgramRoundTrip(t, ss[1])
+	removeOriginalBytesFromPSIData(d)
+	assert.Equal(t, &TOTData{
+		Descriptors: []*Descriptor{{
+			Length:          13,
+			LocalTimeOffset: &DescriptorLocalTimeOffset{Items: []*DescriptorLocalTimeOffsetItem{lto}},
+			Tag:             DescriptorTagLocalTimeOffset,
+		}},
+		UTCTime: now,
+	}, d.TOT)
+
+	// Not due yet
+	now = now.Add(5 * time.Second)
+	assert.Empty(t, s.sections())
+
+	// Due
+	now = now.Add(5 * time.Second)
+	assert.Len(t, s.sections(), 2)
+
+	// TDT only
+	s = newMuxTimeScheduler(MuxTime{Now: func() time.Time { return now }})
+	assert.Len(t, s.sections(), 1)
+	assert.Equal(t, muxTimeDefaultInterval, s.c.Interval)
+}
+
+func TestNewLocalTimeOffsetItem(t *testing.T) {
+	// Offset change
+	loc := time.FixedZone("", 0)
+	l, err := time.LoadLocation("Europe/London")
+	if err == nil {
+		loc = l
+	}
+	i := NewLocalTimeOffsetItem([]byte("GBR"), 0, time.Date(2021, 3, 1, 12, 0, 0, 0, loc))
+	assert.Equal(t, []byte("GBR"), i.CountryCode)
+	assert.Equal(t, time.Duration(0), i.LocalTimeOffset)
+	if err == nil {
+		assert.Equal(t, time.Hour, i.NextTimeOffset)
+		assert.True(t, i.TimeOfChange.Equal(time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC)))
+	}
+
+	// Negative fixed offset
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.FixedZone("", -5*3600))
+	i = NewLocalTimeOffsetItem([]byte("USA"), 1, now)
+	assert.Equal(t, 5*time.Hour, i.LocalTimeOffset)
+	assert.Equal(t, 5*time.Hour, i.NextTimeOffset)
+	assert.True(t, i.LocalTimeOffsetPolarity)
+	assert.Equal(t, now, i.TimeOfChange)
+
+	// Sections
+	d := muxProgramRoundTrip(t, NewTDTSection(now))
+	assert.True(t, d.TDT.UTCTime.Equal(now))
+	d = muxProgramRoundTrip(t, NewTOTSection(now, []*DescriptorLocalTimeOffsetItem{i}))
+	assert.True(t, d.TOT.UTCTime.Equal(now))
+	assert.Equal(t, 5*time.Hour, d.TOT.Descriptors[0].LocalTimeOffset.Items[0].LocalTimeOffset)
+}
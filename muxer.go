@@ -0,0 +1,579 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Default number of packets written between 2 PSI tables retransmissions
+const muxerDefaultTablesRetransmitPeriod = 40
+
+// Muxer errors
+var (
+	ErrPIDNotMuxed     = errors.New("astits: PID is not an elementary stream of any program")
+	ErrPIDReserved     = errors.New("astits: PID is reserved")
+	ErrProgramNotFound = errors.New("astits: program not found")
+)
+
+// Placeholder of the PCRs inserted by the muxer, replaced with the actual value when the packet is written
+var muxerPCRPlaceholder = &ClockReference{}
+
+// Muxer represents a muxer
+// It is the counterpart of Demuxer: it writes 188 bytes packets to a writer out of declared programs and PES data.
+// PAT and PMTs are generated out of the declared programs and retransmitted periodically along with, optionally, the
+// NIT and the SDT. Their version numbers are bumped whenever their content changes, the new version being announced
+// with the current next indicator unset right before being applied. TDT and TOT are emitted on their own schedule.
+type Muxer struct {
+	ccs                       map[uint16]uint8 // Next continuity counter, indexed by PID
+	ctx                       context.Context
+	m                         *sync.Mutex
+	n                         int64 // Number of bytes written
+	optCBR                    *muxCBRPCR
+	optClock                  Clock
+	optNIT                    *MuxNIT
+	optSDT                    *MuxSDT
+	optStuffingByte           uint8
+	optTablesRetransmitPeriod int
+	optTime                   *muxTimeScheduler
+	optTransportStreamID      uint16
+	packetsSinceTables        int
+	programs                  []MuxProgram
+	stats                     *muxerStats
+	tablesChanged             bool
+	versioner                 *psiVersioner
+	w                         io.Writer
+}
+
+// NewMuxer creates a new muxer writing to w
+func NewMuxer(ctx context.Context, w io.Writer, opts ...func(*Muxer)) (m *Muxer) {
+	// Init
+	m = &Muxer{
+		ccs:                       make(map[uint16]uint8),
+		ctx:                       ctx,
+		m:                         &sync.Mutex{},
+		optStuffingByte:           StuffingByte,
+		optTablesRetransmitPeriod: muxerDefaultTablesRetransmitPeriod,
+		stats:                     newMuxerStats(),
+		tablesChanged:             true,
+		versioner:                 newPSIVersioner(),
+		w:                         w,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(m)
+	}
+	return
+}
+
+// MuxerOptCBR returns the option to compute PCRs out of the output byte position and the mux rate, in bits per
+// second, so that they are deterministic when muxing files
+// Null packets are written whenever the output gets ahead of the PES timestamps, the first one being the reference, so
+// that PCRs don't drift from them. The mux rate must be higher than the input bitrate since null packets can only slow
+// the output down.
+func MuxerOptCBR(muxRate int64, start ClockReference) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optCBR = newMuxCBRPCR(muxRate, start)
+	}
+}
+
+// MuxerOptClock returns the option to compute PCRs out of a clock, for instance a RealTimeClock when muxing live
+// A ByteRateClock is advanced with every byte written.
+func MuxerOptClock(c Clock) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optClock = c
+	}
+}
+
+// MuxerOptNIT returns the option to emit a NIT generated from a config
+func MuxerOptNIT(c MuxNIT) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optNIT = &c
+	}
+}
+
+// MuxerOptSDT returns the option to emit an SDT generated from a config
+func MuxerOptSDT(c MuxSDT) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optSDT = &c
+	}
+}
+
+// MuxerOptStuffingByte returns the option to set the byte used to stuff adaptation fields and PSI payloads
+// It defaults to 0xff, as mandated by ISO/IEC 13818-1, and should only be changed for legacy receivers.
+func MuxerOptStuffingByte(b uint8) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optStuffingByte = b
+	}
+}
+
+// MuxerOptTablesRetransmitPeriod returns the option to set the number of packets written between 2 PSI tables
+// retransmissions
+func MuxerOptTablesRetransmitPeriod(n int) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optTablesRetransmitPeriod = n
+	}
+}
+
+// MuxerOptTime returns the option to emit the TDT and the TOT generated from a config
+func MuxerOptTime(c MuxTime) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optTime = newMuxTimeScheduler(c)
+	}
+}
+
+// MuxerOptTransportStreamID returns the option to set the transport stream ID
+func MuxerOptTransportStreamID(id uint16) func(*Muxer) {
+	return func(m *Muxer) {
+		m.optTransportStreamID = id
+	}
+}
+
+// AddProgram declares a new program
+// If the PMT PID is not set, the first free PID starting at 0x1000 is used. PIDs can't collide with the PIDs of the
+// other programs nor with the ones of the SI tables. Tables are written again before the next data.
+func (m *Muxer) AddProgram(p MuxProgram) (err error) {
+	// Lock
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	// PMT PID
+	if p.PMTPID == 0 {
+		p.PMTPID = m.freePMTPID(p)
+	}
+
+	// Validate
+	ps := append(append([]MuxProgram{}, m.programs...), p)
+	if err = validateMuxPrograms(ps); err != nil {
+		err = fmt.Errorf("astits: validating programs failed: %w", err)
+		return
+	}
+	var siPIDs map[uint16]string
+	if siPIDs, err = m.siPIDs(); err != nil {
+		err = fmt.Errorf("astits: getting SI PIDs failed: %w", err)
+		return
+	}
+	if err = validateMuxSIPIDs(ps, siPIDs); err != nil {
+		err = fmt.Errorf("astits: validating SI PIDs failed: %w", err)
+		return
+	}
+
+	// Update
+	m.programs = ps
+	m.tablesChanged = true
+	return
+}
+
+// siPIDs returns the names of the SI tables emitted by the muxer, indexed by PID
+// An error is returned if several tables share a PID.
+func (m *Muxer) siPIDs() (pids map[uint16]string, err error) {
+	pids = make(map[uint16]string)
+	add := func(pid uint16, name string) {
+		if n, ok := pids[pid]; ok && err == nil {
+			err = fmt.Errorf("astits: %s and %s share PID %d", n, name, pid)
+		}
+		pids[pid] = name
+	}
+	if m.optNIT != nil {
+		add(m.optNIT.pid(), PSITableTypeNIT)
+	}
+	if m.optSDT != nil {
+		add(m.optSDT.pid(), PSITableTypeSDT)
+	}
+	if m.optTime != nil {
+		add(PIDTDT, PSITableTypeTDT)
+	}
+	return
+}
+
+// freePMTPID returns the first PID, starting at 0x1000, that is used neither by the declared programs, by the streams
+// of the new program nor by SI tables
+// If there is none, validation of the programs fails.
+func (m *Muxer) freePMTPID(n MuxProgram) (pid uint16) {
+	// Get used PIDs
+	used := make(map[uint16]bool)
+	siPIDs, _ := m.siPIDs()
+	for pid := range siPIDs {
+		used[pid] = true
+	}
+	for idx, p := range m.programs {
+		used[p.pmtPID(idx)] = true
+		for _, s := range p.Streams {
+			used[s.PID] = true
+		}
+	}
+	for _, s := range n.Streams {
+		used[s.PID] = true
+	}
+
+	// Get first free PID
+	for pid = muxDefaultPMTPIDStart; pid < PIDNull && used[pid]; pid++ {
+	}
+	return
+}
+
+// RemoveProgram removes a program
+// Tables are written again before the next data.
+func (m *Muxer) RemoveProgram(number uint16) error {
+	// Lock
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	// Loop through programs
+	for idx, p := range m.programs {
+		if p.Number == number {
+			m.programs = append(m.programs[:idx:idx], m.programs[idx+1:]...)
+			m.tablesChanged = true
+			return nil
+		}
+	}
+	return ErrProgramNotFound
+}
+
+// WriteTables writes the PSI tables and returns the number of bytes written
+func (m *Muxer) WriteTables() (n int, err error) {
+	// Lock
+	m.m.Lock()
+	defer m.m.Unlock()
+	return m.writeTables()
+}
+
+func (m *Muxer) writeTables() (n int, err error) {
+	// PAT
+	var ss []*PSISection
+	var nitPID uint16
+	if m.optNIT != nil {
+		nitPID = m.optNIT.pid()
+	}
+	if ss, err = newMuxPATSections(m.optTransportStreamID, m.programs, nitPID); err != nil {
+		err = fmt.Errorf("astits: creating PAT sections failed: %w", err)
+		return
+	}
+	var o int
+	if o, err = m.writeSections(PIDPAT, TableTypePAT, ss); err != nil {
+		err = fmt.Errorf("astits: writing PAT failed: %w", err)
+		return
+	}
+	n += o
+
+	// PMTs
+	for idx, p := range m.programs {
+		if o, err = m.writeSections(p.pmtPID(idx), TableTypePMT, []*PSISection{newMuxPMTSection(p)}); err != nil {
+			err = fmt.Errorf("astits: writing PMT of program %d failed: %w", p.Number, err)
+			return
+		}
+		n += o
+	}
+
+	// NIT
+	if m.optNIT != nil {
+		if o, err = m.writeSections(m.optNIT.pid(), TableTypeNIT, []*PSISection{newMuxNITSection(m.optTransportStreamID, *m.optNIT)}); err != nil {
+			err = fmt.Errorf("astits: writing NIT failed: %w", err)
+			return
+		}
+		n += o
+	}
+
+	// SDT
+	if m.optSDT != nil {
+		if o, err = m.writeSections(m.optSDT.pid(), TableTypeSDT, []*PSISection{newMuxSDTSection(m.optTransportStreamID, *m.optSDT)}); err != nil {
+			err = fmt.Errorf("astits: writing SDT failed: %w", err)
+			return
+		}
+		n += o
+	}
+
+	// Update
+	m.packetsSinceTables = 0
+	m.tablesChanged = false
+	return
+}
+
+// WriteData packetizes a PES and writes it, preceded by the PSI tables when they have changed or are due, and
+// returns the number of bytes written
+// When PCRs are computed by the muxer and the PID is the PCR PID of a program, a PCR is inserted in the first packet.
+// When the PCR PID of the program doesn't carry any elementary stream, an adaptation field only packet carrying a PCR
+// is written on it first.
+func (m *Muxer) WriteData(d *MuxerData) (n int, err error) {
+	// Check ctx error
+	if err = m.ctx.Err(); err != nil {
+		return
+	}
+
+	// Lock
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	// Get program
+	isPCRPID, ok := m.isMuxedPID(d.PID)
+	if !ok {
+		err = ErrPIDNotMuxed
+		return
+	}
+
+	// Write tables
+	var o int
+	if m.tablesChanged || m.packetsSinceTables >= m.optTablesRetransmitPeriod {
+		if o, err = m.writeTables(); err != nil {
+			err = fmt.Errorf("astits: writing tables failed: %w", err)
+			return
+		}
+		n += o
+	}
+
+	// Write time tables, each in its own packets so that they can be accounted for separately
+	if m.optTime != nil {
+		for _, s := range m.optTime.sections() {
+			if o, err = m.writeSections(PIDTDT, s.Header.Type, []*PSISection{s}); err != nil {
+				err = fmt.Errorf("astits: writing %s failed: %w", s.Header.Type, err)
+				return
+			}
+			n += o
+		}
+	}
+
+	// Hold the mux rate
+	if m.optCBR != nil {
+		if ts := muxerDataTimestamp(d); ts != nil {
+			if o, err = m.writeNullPackets(m.optCBR.nullPackets(m.n, *ts)); err != nil {
+				err = fmt.Errorf("astits: writing null packets failed: %w", err)
+				return
+			}
+			n += o
+		}
+	}
+
+	// Write PCRs of the programs whose PCR PID doesn't carry any elementary stream
+	if m.optCBR != nil || m.optClock != nil {
+		for _, pid := range m.pcrOnlyPIDs(d.PID) {
+			if o, err = m.writePacket(&Packet{
+				AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: muxerPCRPlaceholder},
+				Header: &PacketHeader{
+					ContinuityCounter:  m.ccs[pid],
+					HasAdaptationField: true,
+					PID:                pid,
+				},
+			}); err != nil {
+				err = fmt.Errorf("astits: writing PCR packet failed: %w", err)
+				return
+			}
+			n += o
+		}
+	}
+
+	// Insert PCR placeholder, the actual value is computed when the packet is written
+	if isPCRPID && (m.optCBR != nil || m.optClock != nil) && (d.AdaptationField == nil || !d.AdaptationField.HasPCR) {
+		a := PacketAdaptationField{}
+		if d.AdaptationField != nil {
+			a = *d.AdaptationField
+		}
+		a.HasPCR = true
+		a.PCR = muxerPCRPlaceholder
+		v := *d
+		v.AdaptationField = &a
+		d = &v
+	}
+
+	// Create packets
+	var ps []*Packet
+	if ps, err = newMuxPESPackets(d, m.ccs[d.PID]); err != nil {
+		err = fmt.Errorf("astits: creating PES packets failed: %w", err)
+		return
+	}
+
+	// Write packets
+	for _, p := range ps {
+		if o, err = m.writePacket(p); err != nil {
+			err = fmt.Errorf("astits: writing packet failed: %w", err)
+			return
+		}
+		n += o
+	}
+	return
+}
+
+// WriteSCTE35 writes an SCTE-35 splice info section on the PID of an elementary stream, usually declared with
+// StreamTypeBluRaySCTE35OrDTS8ChannelAudio, so that cue messages can be injected in the output
+func (m *Muxer) WriteSCTE35(pid uint16, s *SCTE35SpliceInfoSection) (n int, err error) {
+	// Check ctx error
+	if err = m.ctx.Err(); err != nil {
+		return
+	}
+
+	// Lock
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	// Check PID
+	if _, ok := m.isMuxedPID(pid); !ok {
+		err = ErrPIDNotMuxed
+		return
+	}
+
+	// Serialise section
+	b := make([]byte, 1+scte35SectionMaxSize)
+	var o int
+	if o, err = s.Serialise(b[1:]); err != nil {
+		err = fmt.Errorf("astits: serialising SCTE-35 section failed: %w", err)
+		return
+	}
+
+	// Write packets
+	for _, p := range newMuxSectionPackets(pid, b[:1+o], m.ccs[pid], m.optStuffingByte) {
+		if o, err = m.writePacket(p); err != nil {
+			err = fmt.Errorf("astits: writing packet failed: %w", err)
+			return
+		}
+		n += o
+	}
+	return
+}
+
+// isMuxedPID checks whether a PID is an elementary stream of a program, and whether it is the PCR PID of a program
+func (m *Muxer) isMuxedPID(pid uint16) (isPCRPID, ok bool) {
+	for _, p := range m.programs {
+		if p.pcrPID() == pid {
+			isPCRPID = true
+		}
+		for _, s := range p.Streams {
+			if s.PID == pid {
+				ok = true
+			}
+		}
+	}
+	return
+}
+
+// pcrOnlyPIDs returns the PCR PIDs of the programs the PID is an elementary stream of, which don't carry any
+// elementary stream and therefore need adaptation field only packets to carry PCRs
+func (m *Muxer) pcrOnlyPIDs(pid uint16) (pids []uint16) {
+	for _, p := range m.programs {
+		// PIDNull means the program has no PCR
+		pcrPID := p.pcrPID()
+		if pcrPID == PIDNull || pcrPID == 0 {
+			continue
+		}
+
+		// PID is not an elementary stream of the program
+		var ok bool
+		for _, s := range p.Streams {
+			if s.PID == pid {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		// PCR PID carries an elementary stream
+		if _, isStream := m.isMuxedPID(pcrPID); !isStream {
+			pids = append(pids, pcrPID)
+		}
+	}
+	return
+}
+
+// writeSections versions PSI sections of a table and writes them
+func (m *Muxer) writeSections(pid uint16, t TableType, ss []*PSISection) (n int, err error) {
+	// Version, the new version of a table that has changed being announced first with the current next indicator unset
+	var next []*PSISection
+	if next, err = m.versioner.updateSectionsWithNext(ss); err != nil {
+		err = fmt.Errorf("astits: updating PSI version failed: %w", err)
+		return
+	}
+	ss = append(next, ss...)
+
+	// Create packets
+	var ps []*Packet
+	if ps, err = newMuxPSIPackets(pid, ss, m.ccs[pid], m.optStuffingByte); err != nil {
+		err = fmt.Errorf("astits: creating PSI packets failed: %w", err)
+		return
+	}
+
+	// Write packets
+	for _, p := range ps {
+		var o int
+		if o, err = m.writePacket(p); err != nil {
+			err = fmt.Errorf("astits: writing packet failed: %w", err)
+			return
+		}
+		n += o
+	}
+
+	// Update stats
+	m.stats.addTable(t, len(ps), n)
+	return
+}
+
+// muxerDataTimestamp returns the DTS of the data, or its PTS when it has no DTS
+func muxerDataTimestamp(d *MuxerData) *ClockReference {
+	if d.PES == nil || d.PES.Header == nil || d.PES.Header.OptionalHeader == nil {
+		return nil
+	}
+	if d.PES.Header.OptionalHeader.DTS != nil {
+		return d.PES.Header.OptionalHeader.DTS
+	}
+	return d.PES.Header.OptionalHeader.PTS
+}
+
+// writeNullPackets writes c null packets
+func (m *Muxer) writeNullPackets(c int) (n int, err error) {
+	for idx := 0; idx < c; idx++ {
+		var o int
+		if o, err = m.writePacket(&Packet{
+			Header: &PacketHeader{
+				ContinuityCounter: m.ccs[PIDNull],
+				HasPayload:        true,
+				PID:               PIDNull,
+			},
+			Payload: bytes.Repeat([]byte{0xff}, muxPacketMaxPayloadSize),
+		}); err != nil {
+			return
+		}
+		n += o
+	}
+	return
+}
+
+// writePacket serialises a packet and writes it
+func (m *Muxer) writePacket(p *Packet) (n int, err error) {
+	// PCR
+	if p.AdaptationField != nil && p.AdaptationField.PCR == muxerPCRPlaceholder {
+		if m.optCBR != nil {
+			p.AdaptationField.PCR = m.optCBR.pcr(m.n)
+		} else {
+			pcr := m.optClock.Now()
+			p.AdaptationField.PCR = &pcr
+		}
+	}
+
+	// Serialise
+	b := make([]byte, MpegTsPacketSize)
+	if _, err = p.serialise(b, m.optStuffingByte); err != nil {
+		err = fmt.Errorf("astits: serialising packet failed: %w", err)
+		return
+	}
+
+	// Write
+	if n, err = m.w.Write(b); err != nil {
+		err = fmt.Errorf("astits: writing failed: %w", err)
+		return
+	}
+
+	// Update
+	m.n += int64(n)
+	m.packetsSinceTables++
+	m.stats.addPID(p.Header.PID, n)
+	if p.Header.HasPayload {
+		m.ccs[p.Header.PID] = (p.Header.ContinuityCounter + 1) & 0xf
+	}
+	if c, ok := m.optClock.(*ByteRateClock); ok {
+		c.Add(n)
+	}
+	return
+}
@@ -0,0 +1,258 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func muxerTestData(pid uint16, pts int64) *MuxerData {
+	return &MuxerData{
+		PES: &PESData{
+			Data: bytes.Repeat([]byte{1}, 300),
+			Header: &PESHeader{
+				OptionalHeader: &PESOptionalHeader{
+					MarkerBits:      2,
+					PTS:             &ClockReference{Base: pts},
+					PTSDTSIndicator: PTSDTSIndicatorOnlyPTS,
+				},
+				StreamID: 0xe0,
+			},
+		},
+		PID: pid,
+	}
+}
+
+func TestMuxer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf,
+		MuxerOptCBR(1000000, ClockReference{Base: 10}),
+		MuxerOptNIT(MuxNIT{NetworkID: 4}),
+		MuxerOptSDT(MuxSDT{Services: []MuxService{{Name: "name", ServiceID: 1}}}),
+		MuxerOptTablesRetransmitPeriod(2),
+		MuxerOptTransportStreamID(2),
+	)
+
+	// Programs
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 0x1000, StreamType: StreamTypeH264Video}}}))
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 2, Streams: []MuxStream{{PID: 257, StreamType: StreamTypeH264Video}}}))
+	assert.Error(t, m.AddProgram(MuxProgram{Number: 1}))
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 3}))
+	assert.NoError(t, m.RemoveProgram(3))
+	assert.True(t, errors.Is(m.RemoveProgram(3), ErrProgramNotFound))
+
+	// Data
+	_, err := m.WriteData(muxerTestData(258, 1))
+	assert.True(t, errors.Is(err, ErrPIDNotMuxed))
+	for _, pts := range []int64{1, 2} {
+		n, err := m.WriteData(muxerTestData(0x1000, pts))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n%MpegTsPacketSize)
+	}
+	assert.Equal(t, 0, buf.Len()%MpegTsPacketSize)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	var pat *PATData
+	var pmts, pess []*Data
+	var nit, sdt bool
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrNoMorePackets))
+			break
+		}
+		switch {
+		case d.PAT != nil:
+			pat = d.PAT
+		case d.PMT != nil:
+			pmts = append(pmts, d)
+		case d.NIT != nil:
+			nit = true
+		case d.SDT != nil:
+			sdt = true
+		case d.PES != nil:
+			pess = append(pess, d)
+		}
+	}
+	assert.Equal(t, &PATData{
+		Programs: []*PATProgram{
+			{ProgramMapID: PIDNIT},
+			{ProgramMapID: 0x1001, ProgramNumber: 1},
+			{ProgramMapID: 0x1002, ProgramNumber: 2},
+		},
+		TransportStreamID: 2,
+	}, pat)
+	assert.True(t, len(pmts) >= 4)
+	assert.Equal(t, uint16(0x1001), pmts[0].PID)
+	assert.Equal(t, uint16(0x1000), pmts[0].PMT.PCRPID)
+	assert.True(t, nit)
+	assert.True(t, sdt)
+	assert.Len(t, pess, 2)
+	for idx, d := range pess {
+		assert.Equal(t, uint16(0x1000), d.PID)
+		assert.Equal(t, int64(idx+1), d.PES.Header.OptionalHeader.PTS.Base)
+		assert.True(t, d.FirstPacket.AdaptationField.HasPCR)
+		assert.Equal(t, uint8(idx*2), d.FirstPacket.Header.ContinuityCounter)
+	}
+}
+
+func TestMuxerCBRNullPackets(t *testing.T) {
+	// 1 packet every ms
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf,
+		MuxerOptCBR(MpegTsPacketSize*8*1000, ClockReference{}),
+		MuxerOptTablesRetransmitPeriod(1000),
+	)
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 0x100, StreamType: StreamTypeH264Video}}}))
+
+	// Data 100ms apart
+	for _, pts := range []int64{0, 9000} {
+		_, err := m.WriteData(muxerTestData(0x100, pts))
+		assert.NoError(t, err)
+	}
+
+	// Null packets fill the gap
+	assert.Equal(t, int64(98), m.Stats().PIDs[PIDNull].Packets)
+
+	// PCRs follow PTSs
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	var pcrs []*ClockReference
+	for {
+		p, err := dmx.NextPacket()
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrNoMorePackets))
+			break
+		}
+		if p.AdaptationField != nil && p.AdaptationField.HasPCR {
+			pcrs = append(pcrs, p.AdaptationField.PCR)
+		}
+	}
+	assert.Len(t, pcrs, 2)
+	assert.Equal(t, int64(9000*300), clockReferenceTicksDiff(*pcrs[1], *pcrs[0]))
+}
+
+func TestMuxerPCROnlyPID(t *testing.T) {
+	// PCR PID doesn't carry any elementary stream
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf, MuxerOptCBR(1000000, ClockReference{}))
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, PCRPID: 0x1ff, Streams: []MuxStream{
+		{PID: 0x100, StreamType: StreamTypeH264Video},
+		{PID: 0x101, StreamType: StreamTypeMPEG1Audio},
+	}}))
+	for _, pid := range []uint16{0x100, 0x101} {
+		_, err := m.WriteData(muxerTestData(pid, 1))
+		assert.NoError(t, err)
+	}
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	var pcrs int
+	for {
+		p, err := dmx.NextPacket()
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrNoMorePackets))
+			break
+		}
+		hasPCR := p.AdaptationField != nil && p.AdaptationField.HasPCR
+		if p.Header.PID == 0x1ff {
+			assert.True(t, hasPCR)
+			assert.False(t, p.Header.HasPayload)
+			pcrs++
+		} else {
+			assert.False(t, hasPCR)
+		}
+	}
+	assert.Equal(t, 2, pcrs)
+}
+
+func TestMuxerNextTables(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 0x100, StreamType: StreamTypeH264Video}}}))
+	_, err := m.WriteTables()
+	assert.NoError(t, err)
+
+	// Program added
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 2, Streams: []MuxStream{{PID: 0x101, StreamType: StreamTypeH264Video}}}))
+	buf.Reset()
+	_, err = m.WriteTables()
+	assert.NoError(t, err)
+
+	// PAT is announced before being applied
+	p, err := parsePacket(astikit.NewBytesIterator(buf.Bytes()[:MpegTsPacketSize]))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(PIDPAT), p.Header.PID)
+	d, err := parsePSIData(astikit.NewBytesIterator(p.Payload), ProfileAuto)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 2)
+	for idx, s := range d.Sections {
+		assert.Equal(t, idx == 1, s.Syntax.Header.CurrentNextIndicator)
+		assert.Equal(t, uint8(1), s.Syntax.Header.VersionNumber)
+		assert.Len(t, s.Syntax.Data.PAT.Programs, 2)
+	}
+}
+
+func TestMuxerSIPIDs(t *testing.T) {
+	// Collisions
+	m := NewMuxer(context.Background(), &bytes.Buffer{}, MuxerOptNIT(MuxNIT{PID: 0x20}), MuxerOptSDT(MuxSDT{PID: 0x20}))
+	assert.Error(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 256}}}))
+	m = NewMuxer(context.Background(), &bytes.Buffer{}, MuxerOptNIT(MuxNIT{PID: PIDNull}))
+	assert.True(t, errors.Is(m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 256}}}), ErrPIDReserved))
+	m = NewMuxer(context.Background(), &bytes.Buffer{}, MuxerOptSDT(MuxSDT{PID: 256}))
+	assert.Error(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 256}}}))
+	assert.Error(t, m.AddProgram(MuxProgram{Number: 1, PMTPID: 256, Streams: []MuxStream{{PID: 257}}}))
+
+	// Custom PIDs
+	buf := &bytes.Buffer{}
+	m = NewMuxer(context.Background(), buf, MuxerOptNIT(MuxNIT{PID: 0x20}), MuxerOptSDT(MuxSDT{PID: 0x1000}))
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{{PID: 256}}}))
+	_, err := m.WriteTables()
+	assert.NoError(t, err)
+	var pids []uint16
+	for idx := 0; idx < buf.Len(); idx += MpegTsPacketSize {
+		h, err := ParsePacketHeader(buf.Bytes()[idx : idx+MpegTsPacketSize])
+		assert.NoError(t, err)
+		pids = append(pids, h.PID)
+	}
+	assert.Equal(t, []uint16{PIDPAT, 0x1001, 0x20, 0x1000}, pids)
+	p, err := ParsePacket(buf.Bytes()[:MpegTsPacketSize])
+	assert.NoError(t, err)
+	d, err := ParsePSIPacket(p)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x20), d.Sections[0].Syntax.Data.PAT.Programs[0].ProgramMapID)
+}
+
+func TestMuxerWriteSCTE35(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	assert.NoError(t, m.AddProgram(MuxProgram{Number: 1, Streams: []MuxStream{
+		{PID: 256, StreamType: StreamTypeH264Video},
+		{PID: 257, StreamType: StreamTypeBluRaySCTE35OrDTS8ChannelAudio},
+	}}))
+	s := NewSCTE35SpliceInsert(1, true, &ClockReference{Base: 90000}, nil)
+	_, err := m.WriteSCTE35(258, s)
+	assert.True(t, errors.Is(err, ErrPIDNotMuxed))
+	for idx := 0; idx < 2; idx++ {
+		n, err := m.WriteSCTE35(257, s)
+		assert.NoError(t, err)
+		assert.Equal(t, MpegTsPacketSize, n)
+	}
+
+	// Parse
+	b := make([]byte, scte35SectionMaxSize)
+	sn, err := s.Serialise(b)
+	assert.NoError(t, err)
+	for idx := 0; idx < 2; idx++ {
+		p, err := ParsePacket(buf.Bytes()[idx*MpegTsPacketSize : (idx+1)*MpegTsPacketSize])
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(257), p.Header.PID)
+		assert.Equal(t, uint8(idx), p.Header.ContinuityCounter)
+		assert.True(t, p.Header.PayloadUnitStartIndicator)
+		assert.Equal(t, b[:sn], p.Payload[1:1+sn])
+	}
+}
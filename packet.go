@@ -7,6 +7,9 @@ import (
 	"github.com/asticode/go-astikit"
 )
 
+// Size of a packet, without any extra header such as the 4 bytes timecode of M2TS
+const MpegTsPacketSize = 188
+
 // Scrambling Controls
 const (
 	ScramblingControlNotScrambled         = 0
@@ -56,7 +59,9 @@ type PacketAdaptationField struct {
 
 // PacketAdaptationExtensionField represents a packet adaptation extension field
 type PacketAdaptationExtensionField struct {
+	AFDescriptors          []*AFDescriptor
 	DTSNextAccessUnit      *ClockReference // The PES DTS of the splice point. Split up as 3 bits, 1 marker bit (0x1), 15 bits, 1 marker bit, 15 bits, and 1 marker bit, for 33 data bits total.
+	HasAFDescriptors       bool // Set when the AF descriptor not present flag is unset
 	HasLegalTimeWindow     bool
 	HasPiecewiseRate       bool
 	HasSeamlessSplice      bool
@@ -74,9 +79,90 @@ func ParsePacket(b []byte) (p *Packet, err error) {
 	return parsePacket(astikit.NewBytesIterator(b))
 }
 
+// ErrPacketTooShort is returned when a raw packet is smaller than 188 bytes
+var ErrPacketTooShort = errors.New("astits: packet is too short")
+
+// ParsePacketHeader parses only the header of a raw packet, without building a Packet, which suits tools inspecting
+// packets at a high rate
+// As in ParsePacket, bytes in excess of 188 are expected to be right after the sync byte.
+func ParsePacketHeader(b []byte) (h PacketHeader, err error) {
+	// Get header bytes
+	var bs []byte
+	if bs, err = rawPacketBytes(b); err != nil {
+		return
+	}
+
+	// Create header
+	h = newPacketHeader(bs)
+	return
+}
+
+// ParseAdaptationField parses only the adaptation field of a raw packet, without building a Packet
+// a is nil if the packet has no adaptation field.
+func ParseAdaptationField(b []byte) (a *PacketAdaptationField, err error) {
+	// Get header bytes
+	var bs []byte
+	if bs, err = rawPacketBytes(b); err != nil {
+		return
+	}
+
+	// No adaptation field
+	if bs[2]&0x20 == 0 {
+		return
+	}
+
+	// Parse adaptation field
+	if a, err = parsePacketAdaptationField(astikit.NewBytesIterator(bs[3:])); err != nil {
+		err = fmt.Errorf("astits: parsing packet adaptation field failed: %w", err)
+		return
+	}
+	return
+}
+
+// rawPacketBytes returns the bytes of a raw packet following the sync byte
+func rawPacketBytes(b []byte) (bs []byte, err error) {
+	// Check length
+	if len(b) < MpegTsPacketSize {
+		err = ErrPacketTooShort
+		return
+	}
+
+	// Packet must start with a sync byte
+	if b[0] != syncByte {
+		err = ErrPacketMustStartWithASyncByte
+		return
+	}
+	return b[len(b)-MpegTsPacketSize+1:], nil
+}
+
 //ParsePSIPacket parses a known PSI packet
 func ParsePSIPacket(p *Packet) (*PSIData, error) {
-	return parsePSIData(astikit.NewBytesIterator(p.Payload))
+	return parsePSIData(astikit.NewBytesIterator(p.Payload), ProfileAuto)
+}
+
+// SerialisePSIPackets serialises the sections of a PSI data into as many 188 bytes packets as needed, which is the
+// counterpart of ParsePSIPacket
+// Sections are written back to back after a zero pointer field, the first packet has the payload unit start indicator
+// set and the last one is stuffed with 0xff. Continuity counters start at cc, so the next packet of the PID should use
+// (cc + len(bs)) & 0xf.
+func SerialisePSIPackets(psi *PSIData, pid uint16, cc uint8) (bs [][]byte, err error) {
+	// Create packets
+	var ps []*Packet
+	if ps, err = newMuxPSIPackets(pid, psi.Sections, cc&0xf, StuffingByte); err != nil {
+		err = fmt.Errorf("astits: creating PSI packets failed: %w", err)
+		return
+	}
+
+	// Serialise packets
+	for _, p := range ps {
+		b := make([]byte, MpegTsPacketSize)
+		if _, err = p.Serialise(b); err != nil {
+			err = fmt.Errorf("astits: serialising packet failed: %w", err)
+			return
+		}
+		bs = append(bs, b)
+	}
+	return
 }
 
 //ParsePESPacket parses a known PES packet
@@ -103,20 +189,49 @@ func ParsePESPacketHeader(p *Packet) (d *PESData, err error) {
 	return
 }
 
+// Serialise writes the packet in b and returns the payload offset
+// Since the parser considers that the payload ends the packet, the adaptation field is stuffed when the payload
+// doesn't fill the packet. An adaptation field of length 0 is therefore only written when the payload is 183 bytes
+// long, which is the single stuffing byte case.
 func (p *Packet) Serialise(b []byte) (int, error) {
+	return p.serialise(b, StuffingByte)
+}
+
+// serialise writes the packet in b, stuffing the adaptation field with stuffingByte
+func (p *Packet) serialise(b []byte, stuffingByte uint8) (int, error) {
 	if len(b) < 188 {
 		return 0, errors.New("b not large enough to hold a packet")
 	}
 	b[0] = syncByte
-	p.Header.Serialise(b)
+
+	// Packets without adaptation field whose payload doesn't fill them are stuffed through an adaptation field, since
+	// the parser considers that the payload ends the packet. Packets without payload are left as is so that callers
+	// can write the payload at the returned offset themselves.
+	h := *p.Header
+	af := p.AdaptationField
+	if !h.HasAdaptationField && len(p.Payload) > 0 && len(p.Payload) < 188-4 {
+		h.HasAdaptationField = true
+		af = &PacketAdaptationField{}
+	}
+	h.Serialise(b)
+
 	payloadStart := 4
-	if p.Header.HasAdaptationField {
-		return 4, errors.New("Serialising adaptation field unimplemented")
-		err := p.AdaptationField.Serialise(b)
+	if h.HasAdaptationField {
+		if af == nil {
+			return payloadStart, errors.New("astits: adaptation field is missing")
+		}
+		a := *af
+		if l := 188 - payloadStart - 1 - len(p.Payload); l > a.Length {
+			a.Length = l
+		}
+		n, err := a.serialise(b[payloadStart:188], stuffingByte)
 		if err != nil {
-			return payloadStart, err
+			return payloadStart, fmt.Errorf("astits: serialising adaptation field failed: %w", err)
 		}
-		payloadStart += p.AdaptationField.Length
+		payloadStart += n
+	}
+	if len(p.Payload) > 188-payloadStart {
+		return payloadStart, ErrNoRoomInBuffer
 	}
 	copy(b[payloadStart:], p.Payload)
 	return payloadStart, nil
@@ -145,14 +260,234 @@ func (h *PacketHeader) Serialise(b []byte) {
 		pBit = 0x10
 	}
 	ccBits = uint8(h.ContinuityCounter & 0xf)
-	tscBits = h.TransportScramblingControl << 6
+	tscBits = (h.TransportScramblingControl & 0x3) << 6
 	b[3] = afBit | pBit | ccBits | tscBits
 }
 
-func (p *PacketAdaptationField) Serialise(b []byte) error {
+// SetPacketTransportPriority updates the transport priority bit of a raw packet in place, which allows re-marking
+// priorities without parsing and serialising packets
+func SetPacketTransportPriority(b []byte, priority bool) (err error) {
+	// Get header bytes
+	var bs []byte
+	if bs, err = rawPacketBytes(b); err != nil {
+		return
+	}
+
+	// Update bit
+	if priority {
+		bs[0] |= 0x20
+	} else {
+		bs[0] &^= 0x20
+	}
+	return
+}
+
+// SetPacketScramblingControl updates the transport scrambling control bits of a raw packet in place, see
+// ScramblingControl*
+func SetPacketScramblingControl(b []byte, scramblingControl uint8) (err error) {
+	// Get header bytes
+	var bs []byte
+	if bs, err = rawPacketBytes(b); err != nil {
+		return
+	}
+
+	// Update bits
+	bs[2] = bs[2]&0x3f | (scramblingControl&0x3)<<6
+	return
+}
+
+// Serialise serialises the adaptation field, length byte included, and returns the number of bytes written
+// Length is used as is if it is big enough to hold the fields, in which case the remaining bytes are stuffed with 0xff
+func (a *PacketAdaptationField) Serialise(b []byte) (int, error) {
+	return a.serialise(b, StuffingByte)
+}
+
+// serialise serialises the adaptation field, the remaining bytes being stuffed with stuffingByte
+func (a *PacketAdaptationField) serialise(b []byte, stuffingByte uint8) (int, error) {
+	// Validate
+	if err := a.validate(); err != nil {
+		return 0, err
+	}
+
+	// Get length
+	l := a.length()
+	if a.Length > l {
+		l = a.Length
+	}
+	if l > 183 {
+		return 0, fmt.Errorf("astits: adaptation field length %d is too big", l)
+	}
+	if len(b) < l+1 {
+		return 0, ErrNoRoomInBuffer
+	}
+	b[0] = uint8(l)
+	if l == 0 {
+		return 1, nil
+	}
+
+	// Flags
+	b[1] = Btou8(a.DiscontinuityIndicator)<<7 | Btou8(a.RandomAccessIndicator)<<6 |
+		Btou8(a.ElementaryStreamPriorityIndicator)<<5 | Btou8(a.HasPCR)<<4 | Btou8(a.HasOPCR)<<3 |
+		Btou8(a.HasSplicingCountdown)<<2 | Btou8(a.HasTransportPrivateData)<<1 | Btou8(a.HasAdaptationExtensionField)
+	idx := 2
+
+	// PCR
+	if a.HasPCR {
+		writePCR(b[idx:], a.PCR)
+		idx += PCRLength
+	}
+
+	// OPCR
+	if a.HasOPCR {
+		writePCR(b[idx:], a.OPCR)
+		idx += PCRLength
+	}
+
+	// Splicing countdown
+	if a.HasSplicingCountdown {
+		b[idx] = uint8(a.SpliceCountdown)
+		idx++
+	}
+
+	// Transport private data
+	if a.HasTransportPrivateData {
+		b[idx] = uint8(len(a.TransportPrivateData))
+		idx++
+		idx += copy(b[idx:], a.TransportPrivateData)
+	}
+
+	// Adaptation extension
+	if a.HasAdaptationExtensionField {
+		e := a.AdaptationExtensionField
+		el := e.length()
+		if e.Length > el {
+			el = e.Length
+		}
+		b[idx] = uint8(el)
+		idx++
+		if el > 0 {
+			end := idx + el
+			b[idx] = Btou8(e.HasLegalTimeWindow)<<7 | Btou8(e.HasPiecewiseRate)<<6 | Btou8(e.HasSeamlessSplice)<<5 |
+				Btou8(!e.HasAFDescriptors)<<4 | 0xf
+			idx++
+
+			// Legal time window
+			if e.HasLegalTimeWindow {
+				b[idx] = Btou8(e.LegalTimeWindowIsValid)<<7 | uint8(e.LegalTimeWindowOffset>>8&0x7f)
+				b[idx+1] = uint8(e.LegalTimeWindowOffset)
+				idx += 2
+			}
+
+			// Piecewise rate
+			if e.HasPiecewiseRate {
+				b[idx] = 0xc0 | uint8(e.PiecewiseRate>>16&0x3f)
+				b[idx+1] = uint8(e.PiecewiseRate >> 8)
+				b[idx+2] = uint8(e.PiecewiseRate)
+				idx += 3
+			}
+
+			// Seamless splice
+			if e.HasSeamlessSplice {
+				writePTSOrDTS(b[idx:], e.SpliceType, e.DTSNextAccessUnit)
+				idx += PTSOrDTSLength
+			}
+
+			// AF descriptors
+			if e.HasAFDescriptors {
+				for _, d := range e.AFDescriptors {
+					b[idx] = d.Tag
+					b[idx+1] = uint8(len(d.Content))
+					idx += 2
+					idx += copy(b[idx:], d.Content)
+				}
+			}
+
+			// Reserved
+			for ; idx < end; idx++ {
+				b[idx] = 0xff
+			}
+		}
+	}
+
+	// Stuffing
+	for ; idx < l+1; idx++ {
+		b[idx] = stuffingByte
+	}
+	return idx, nil
+}
+
+// validate checks whether the fields announced by the flags are set
+func (a *PacketAdaptationField) validate() error {
+	if a.HasPCR && a.PCR == nil {
+		return errors.New("astits: PCR is missing")
+	}
+	if a.HasOPCR && a.OPCR == nil {
+		return errors.New("astits: OPCR is missing")
+	}
+	if a.HasAdaptationExtensionField {
+		if a.AdaptationExtensionField == nil {
+			return errors.New("astits: adaptation extension field is missing")
+		}
+		if a.AdaptationExtensionField.HasSeamlessSplice && a.AdaptationExtensionField.DTSNextAccessUnit == nil {
+			return errors.New("astits: DTS of the next access unit is missing")
+		}
+	}
 	return nil
 }
 
+// length returns the minimum length needed to hold the adaptation field, length byte excluded
+// A missing adaptation extension field counts as an empty one, see validate.
+func (a *PacketAdaptationField) length() (l int) {
+	if !a.DiscontinuityIndicator && !a.RandomAccessIndicator && !a.ElementaryStreamPriorityIndicator && !a.HasPCR &&
+		!a.HasOPCR && !a.HasSplicingCountdown && !a.HasTransportPrivateData && !a.HasAdaptationExtensionField {
+		return
+	}
+	l = 1
+	if a.HasPCR {
+		l += 6
+	}
+	if a.HasOPCR {
+		l += 6
+	}
+	if a.HasSplicingCountdown {
+		l++
+	}
+	if a.HasTransportPrivateData {
+		l += 1 + len(a.TransportPrivateData)
+	}
+	if a.HasAdaptationExtensionField {
+		el := 1
+		if a.AdaptationExtensionField != nil {
+			el = a.AdaptationExtensionField.length()
+			if a.AdaptationExtensionField.Length > el {
+				el = a.AdaptationExtensionField.Length
+			}
+		}
+		l += 1 + el
+	}
+	return
+}
+
+// length returns the minimum length needed to hold the adaptation extension field, length byte excluded
+func (e *PacketAdaptationExtensionField) length() (l int) {
+	l = 1
+	if e.HasLegalTimeWindow {
+		l += 2
+	}
+	if e.HasPiecewiseRate {
+		l += 3
+	}
+	if e.HasSeamlessSplice {
+		l += 5
+	}
+	if e.HasAFDescriptors {
+		for _, d := range e.AFDescriptors {
+			l += 2 + len(d.Content)
+		}
+	}
+	return
+}
+
 // parsePacket parses a packet
 func parsePacket(i *astikit.BytesIterator) (p *Packet, err error) {
 	// Get next byte
@@ -216,7 +551,14 @@ func parsePacketHeader(i *astikit.BytesIterator) (h *PacketHeader, err error) {
 	}
 
 	// Create header
-	h = &PacketHeader{
+	ph := newPacketHeader(bs)
+	h = &ph
+	return
+}
+
+// newPacketHeader creates a packet header from the 3 bytes following the sync byte
+func newPacketHeader(bs []byte) PacketHeader {
+	return PacketHeader{
 		ContinuityCounter:          uint8(bs[2] & 0xf),
 		HasAdaptationField:         bs[2]&0x20 > 0,
 		HasPayload:                 bs[2]&0x10 > 0,
@@ -226,7 +568,6 @@ func parsePacketHeader(i *astikit.BytesIterator) (h *PacketHeader, err error) {
 		TransportPriority:          bs[0]&0x20 > 0,
 		TransportScramblingControl: uint8(bs[2]) >> 6 & 0x3,
 	}
-	return
 }
 
 // parsePacketAdaptationField parses the packet adaptation field
@@ -319,6 +660,8 @@ func parsePacketAdaptationField(i *astikit.BytesIterator) (a *PacketAdaptationFi
 			// Length
 			a.AdaptationExtensionField.Length = int(b)
 			if a.AdaptationExtensionField.Length > 0 {
+				offsetEnd := i.Offset() + a.AdaptationExtensionField.Length
+
 				// Get next byte
 				if b, err = i.NextByte(); err != nil {
 					err = fmt.Errorf("astits: fetching next byte failed: %w", err)
@@ -329,6 +672,7 @@ func parsePacketAdaptationField(i *astikit.BytesIterator) (a *PacketAdaptationFi
 				a.AdaptationExtensionField.HasLegalTimeWindow = b&0x80 > 0
 				a.AdaptationExtensionField.HasPiecewiseRate = b&0x40 > 0
 				a.AdaptationExtensionField.HasSeamlessSplice = b&0x20 > 0
+				a.AdaptationExtensionField.HasAFDescriptors = b&0x10 == 0
 
 				// Legal time window
 				if a.AdaptationExtensionField.HasLegalTimeWindow {
@@ -371,6 +715,14 @@ func parsePacketAdaptationField(i *astikit.BytesIterator) (a *PacketAdaptationFi
 						return
 					}
 				}
+
+				// AF descriptors
+				if a.AdaptationExtensionField.HasAFDescriptors {
+					if a.AdaptationExtensionField.AFDescriptors, err = parseAFDescriptors(i, offsetEnd); err != nil {
+						err = fmt.Errorf("astits: parsing AF descriptors failed: %w", err)
+						return
+					}
+				}
 			}
 		}
 	}
@@ -9,6 +9,9 @@ import (
 
 // packetBuffer represents a packet buffer
 type packetBuffer struct {
+	lastBytes  []byte // Raw bytes of the last packet fetched
+	lastOffset int64  // Byte offset of the last packet fetched in the reader
+	offset     int64  // Byte offset of the next packet in the reader
 	packetSize int
 	r          io.Reader
 }
@@ -24,7 +27,7 @@ func newPacketBuffer(r io.Reader, packetSize int) (pb *packetBuffer, err error)
 	// Packet size is not set
 	if pb.packetSize == 0 {
 		// Auto detect packet size
-		if pb.packetSize, err = autoDetectPacketSize(r); err != nil {
+		if pb.packetSize, pb.offset, err = autoDetectPacketSize(r); err != nil {
 			err = fmt.Errorf("astits: auto detecting packet size failed: %w", err)
 			return
 		}
@@ -35,7 +38,8 @@ func newPacketBuffer(r io.Reader, packetSize int) (pb *packetBuffer, err error)
 // autoDetectPacketSize updates the packet size based on the first bytes
 // Minimum packet size is 188 and is bounded by 2 sync bytes
 // Assumption is made that the first byte of the reader is a sync byte
-func autoDetectPacketSize(r io.Reader) (packetSize int, err error) {
+// offset is the byte offset of the next packet once the reader has been rewound or synced
+func autoDetectPacketSize(r io.Reader) (packetSize int, offset int64, err error) {
 	// Read first bytes
 	const l = 193
 	var b = make([]byte, l)
@@ -67,6 +71,7 @@ func autoDetectPacketSize(r io.Reader) (packetSize int, err error) {
 					err = fmt.Errorf("astits: reading %d bytes to sync reader failed: %w", ls, err)
 					return
 				}
+				offset = int64(l + ls)
 			}
 			return
 		}
@@ -100,6 +105,9 @@ func (pb *packetBuffer) next() (p *Packet, err error) {
 		}
 		return
 	}
+	pb.lastBytes = b
+	pb.lastOffset = pb.offset
+	pb.offset += int64(pb.packetSize)
 
 	// Parse packet
 	if p, err = parsePacket(astikit.NewBytesIterator(b)); err != nil {
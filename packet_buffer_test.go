@@ -14,7 +14,7 @@ func TestAutoDetectPacketSize(t *testing.T) {
 	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
 	w.Write(uint8(2))
 	w.Write(byte(syncByte))
-	_, err := autoDetectPacketSize(bytes.NewReader(buf.Bytes()))
+	_, _, err := autoDetectPacketSize(bytes.NewReader(buf.Bytes()))
 	assert.EqualError(t, err, ErrPacketMustStartWithASyncByte.Error())
 
 	// Valid packet size
@@ -27,8 +27,9 @@ func TestAutoDetectPacketSize(t *testing.T) {
 	w.Write(make([]byte, 187))
 	w.Write([]byte("test"))
 	r := bytes.NewReader(buf.Bytes())
-	p, err := autoDetectPacketSize(r)
+	p, o, err := autoDetectPacketSize(r)
 	assert.NoError(t, err)
 	assert.Equal(t, 188, p)
+	assert.Equal(t, int64(0), o)
 	assert.Equal(t, 380, r.Len())
 }
@@ -7,8 +7,10 @@ import (
 
 // PacketPool represents a pool of packets
 type PacketPool struct {
-	b map[uint16][]*Packet // Indexed by PID
-	m *sync.Mutex
+	b       map[uint16][]*Packet // Indexed by PID
+	m       *sync.Mutex
+	maxSize int                            // Maximum number of packets buffered per PID, 0 means no limit
+	onDrop  func(pid uint16, ps []*Packet) // Called when packets are dropped because the limit is reached
 }
 
 // NewPacketPool creates a new packet pool
@@ -32,6 +34,14 @@ func (b *PacketPool) Add(p *Packet) (ps []*Packet) {
 		return
 	}
 
+	// Report dropped packets once unlocked
+	var dropped []*Packet
+	defer func() {
+		if len(dropped) > 0 && b.onDrop != nil {
+			b.onDrop(p.Header.PID, dropped)
+		}
+	}()
+
 	// Lock
 	b.m.Lock()
 	defer b.m.Unlock()
@@ -53,6 +63,13 @@ func (b *PacketPool) Add(p *Packet) (ps []*Packet) {
 		return
 	}
 
+	// Drop buffered packets if the limit is reached since the payload can't be completed without them
+	if b.maxSize > 0 && len(mps) >= b.maxSize && !p.Header.PayloadUnitStartIndicator {
+		dropped = mps
+		b.b[p.Header.PID] = []*Packet{}
+		return
+	}
+
 	// Add packet
 	if len(mps) > 0 || (len(mps) == 0 && p.Header.PayloadUnitStartIndicator) {
 		mps = append(mps, p)
@@ -47,3 +47,17 @@ func TestPacketPool(t *testing.T) {
 	ps = b.dump()
 	assert.Len(t, ps, 0)
 }
+
+func TestPacketPoolMaxSize(t *testing.T) {
+	b := NewPacketPool()
+	b.maxSize = 2
+	var dropped []*Packet
+	b.onDrop = func(pid uint16, ps []*Packet) { dropped = ps }
+	b.Add(&Packet{Header: &PacketHeader{ContinuityCounter: 0, HasPayload: true, PayloadUnitStartIndicator: true, PID: 1}})
+	b.Add(&Packet{Header: &PacketHeader{ContinuityCounter: 1, HasPayload: true, PID: 1}})
+	assert.Len(t, dropped, 0)
+	ps := b.Add(&Packet{Header: &PacketHeader{ContinuityCounter: 2, HasPayload: true, PID: 1}})
+	assert.Len(t, ps, 0)
+	assert.Len(t, dropped, 2)
+	assert.Len(t, b.b[1], 0)
+}
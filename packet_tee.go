@@ -0,0 +1,76 @@
+package astits
+
+import (
+	"sync"
+)
+
+// PacketTee fans packets read once by a demuxer out to several independent consumers, such as an analyzer and a
+// recorder, each of them receiving every packet through its own buffered channel
+// Packets are shared between consumers and must therefore not be modified. A consumer whose buffer is full blocks the
+// demuxer until it catches up.
+type PacketTee struct {
+	bufferSize int
+	cs         []chan *Packet
+	m          *sync.Mutex
+	closed     bool
+}
+
+// NewPacketTee creates a new packet tee whose consumers buffer up to bufferSize packets
+func NewPacketTee(bufferSize int) *PacketTee {
+	return &PacketTee{
+		bufferSize: bufferSize,
+		m:          &sync.Mutex{},
+	}
+}
+
+// Add adds a new consumer and returns the channel it receives packets from
+// The channel is closed when the tee is closed
+func (t *PacketTee) Add() <-chan *Packet {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Create channel
+	c := make(chan *Packet, t.bufferSize)
+	if t.closed {
+		close(c)
+		return c
+	}
+	t.cs = append(t.cs, c)
+	return c
+}
+
+// Send sends a packet to every consumer
+func (t *PacketTee) Send(p *Packet) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Tee is closed
+	if t.closed {
+		return
+	}
+
+	// Loop through consumers
+	for _, c := range t.cs {
+		c <- p
+	}
+}
+
+// Close closes the channel of every consumer
+func (t *PacketTee) Close() {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Tee is already closed
+	if t.closed {
+		return
+	}
+	t.closed = true
+
+	// Loop through consumers
+	for _, c := range t.cs {
+		close(c)
+	}
+}
@@ -0,0 +1,56 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacketTee(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	b1, _ := packet(*packetHeader, *packetAdaptationField, []byte("1"))
+	w.Write(b1)
+	b2, _ := packet(*packetHeader, *packetAdaptationField, []byte("2"))
+	w.Write(b2)
+	tee := NewPacketTee(1)
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptPacketTee(tee))
+
+	// Consumers
+	wg := &sync.WaitGroup{}
+	pss := make([][]*Packet, 2)
+	for idx := range pss {
+		c := tee.Add()
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			for p := range c {
+				pss[idx] = append(pss[idx], p)
+			}
+		}(idx)
+	}
+
+	// Demux
+	var ps []*Packet
+	for {
+		p, err := dmx.NextPacket()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		ps = append(ps, p)
+	}
+	wg.Wait()
+	assert.Len(t, ps, 2)
+	assert.Equal(t, ps, pss[0])
+	assert.Equal(t, ps, pss[1])
+
+	// Closed tee
+	_, ok := <-tee.Add()
+	assert.False(t, ok)
+}
@@ -40,6 +40,31 @@ func TestParsePacket(t *testing.T) {
 	assert.Equal(t, p, ep)
 }
 
+func TestParsePacketHeaderAndAdaptationField(t *testing.T) {
+	// Invalid
+	_, err := ParsePacketHeader(make([]byte, 10))
+	assert.EqualError(t, err, ErrPacketTooShort.Error())
+	_, err = ParseAdaptationField(make([]byte, MpegTsPacketSize))
+	assert.EqualError(t, err, ErrPacketMustStartWithASyncByte.Error())
+
+	// Valid
+	b, ep := packet(*packetHeader, *packetAdaptationField, []byte("payload"))
+	h, err := ParsePacketHeader(b)
+	assert.NoError(t, err)
+	assert.Equal(t, *ep.Header, h)
+	a, err := ParseAdaptationField(b)
+	assert.NoError(t, err)
+	assert.Equal(t, ep.AdaptationField, a)
+
+	// No adaptation field
+	b = make([]byte, MpegTsPacketSize)
+	b[0] = syncByte
+	b[3] = 0x10
+	a, err = ParseAdaptationField(b)
+	assert.NoError(t, err)
+	assert.Nil(t, a)
+}
+
 func TestPayloadOffset(t *testing.T) {
 	assert.Equal(t, 3, payloadOffset(0, &PacketHeader{}, nil))
 	assert.Equal(t, 7, payloadOffset(1, &PacketHeader{HasAdaptationField: true}, &PacketAdaptationField{Length: 2}))
@@ -159,3 +184,198 @@ func TestParsePCR(t *testing.T) {
 	assert.Equal(t, pcr, v)
 	assert.NoError(t, err)
 }
+
+func TestWritePCR(t *testing.T) {
+	// No room
+	assert.Equal(t, ErrNoRoomInBuffer, WritePCR(make([]byte, 5), pcr))
+
+	// Write
+	b := make([]byte, PCRLength)
+	assert.NoError(t, WritePCR(b, pcr))
+	assert.Equal(t, pcrBytes(), b)
+
+	// Base wraps around
+	assert.NoError(t, WritePCR(b, &ClockReference{Base: clockReferenceBaseWrap + 1, Extension: 1}))
+	v, err := parsePCR(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Equal(t, &ClockReference{Base: 1, Extension: 1}, v)
+}
+
+func TestPacketAdaptationFieldSerialise(t *testing.T) {
+	// Round trip
+	b := make([]byte, 37)
+	n, err := packetAdaptationField.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 37, n)
+	a, err := parsePacketAdaptationField(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Equal(t, packetAdaptationField, a)
+	assert.Equal(t, packetAdaptationFieldBytes(*packetAdaptationField)[:27], b[:27])
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff}, b[32:])
+
+	// Length is computed
+	n, err = (&PacketAdaptationField{HasPCR: true, PCR: pcr}).Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, n)
+	assert.Equal(t, append([]byte{7, 0x10}, pcrBytes()...), b[:n])
+
+	// Zero length
+	n, err = (&PacketAdaptationField{}).Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0}, b[:n])
+
+	// No room
+	_, err = packetAdaptationField.Serialise(b[:36])
+	assert.Equal(t, ErrNoRoomInBuffer, err)
+}
+
+func TestPacketSerialiseWithAdaptationField(t *testing.T) {
+	p := &Packet{
+		AdaptationField: &PacketAdaptationField{Length: 10, RandomAccessIndicator: true},
+		Header:          &PacketHeader{HasAdaptationField: true, HasPayload: true, PID: 256},
+		Payload:         bytes.Repeat([]byte{1}, 173),
+	}
+	b := make([]byte, MpegTsPacketSize)
+	n, err := p.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 15, n)
+	v, err := parsePacket(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Equal(t, p, v)
+
+	// Payload too big
+	p.Payload = append(p.Payload, 1)
+	_, err = p.Serialise(b)
+	assert.Equal(t, ErrNoRoomInBuffer, err)
+}
+
+func TestPacketSerialiseWithZeroLengthAdaptationField(t *testing.T) {
+	// Single stuffing byte
+	b := append([]byte{syncByte, 0x1, 0x0, 0x30, 0x0}, bytes.Repeat([]byte{1}, 183)...)
+	p, err := parsePacket(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.Equal(t, &PacketAdaptationField{}, p.AdaptationField)
+	assert.Len(t, p.Payload, 183)
+	v := make([]byte, MpegTsPacketSize)
+	n, err := p.Serialise(v)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, b, v)
+
+	// Adaptation field is stuffed when payload doesn't fill the packet
+	p.Payload = p.Payload[:180]
+	v = bytes.Repeat([]byte{0xaa}, MpegTsPacketSize)
+	n, err = p.Serialise(v)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, n)
+	assert.Equal(t, []byte{0x3, 0x0, 0xff, 0xff}, v[4:8])
+	assert.Equal(t, &PacketAdaptationField{}, p.AdaptationField)
+	p, err = parsePacket(astikit.NewBytesIterator(v))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, p.AdaptationField.Length)
+	assert.Len(t, p.Payload, 180)
+
+	// Adaptation field only
+	b = append([]byte{syncByte, 0x1, 0x0, 0x20, 183, 0x0}, bytes.Repeat([]byte{0xff}, 182)...)
+	p, err = parsePacket(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	v = make([]byte, MpegTsPacketSize)
+	_, err = (&Packet{AdaptationField: &PacketAdaptationField{}, Header: p.Header}).Serialise(v)
+	assert.NoError(t, err)
+	assert.Equal(t, b, v)
+}
+
+func TestPacketSerialiseWithShortPayload(t *testing.T) {
+	p := &Packet{
+		Header:  &PacketHeader{ContinuityCounter: 3, HasPayload: true, PID: 256},
+		Payload: []byte{1, 2, 3},
+	}
+	b := bytes.Repeat([]byte{0xaa}, MpegTsPacketSize)
+	n, err := p.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 185, n)
+	assert.False(t, p.Header.HasAdaptationField)
+	assert.Nil(t, p.AdaptationField)
+	v, err := parsePacket(astikit.NewBytesIterator(b))
+	assert.NoError(t, err)
+	assert.True(t, v.Header.HasAdaptationField)
+	assert.Equal(t, 180, v.AdaptationField.Length)
+	assert.Equal(t, uint8(3), v.Header.ContinuityCounter)
+	assert.Equal(t, p.Payload, v.Payload)
+}
+
+func TestPacketAdaptationFieldSerialiseMissingFields(t *testing.T) {
+	b := make([]byte, MpegTsPacketSize)
+	for name, a := range map[string]*PacketAdaptationField{
+		"pcr":       {HasPCR: true, Length: 7},
+		"opcr":      {HasOPCR: true, Length: 7},
+		"extension": {HasAdaptationExtensionField: true, Length: 3},
+	} {
+		_, err := a.Serialise(b)
+		assert.Error(t, err, name)
+		_, err = (&Packet{AdaptationField: a, Header: &PacketHeader{HasAdaptationField: true}}).Serialise(b)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestPacketHeaderPriorityAndScrambling(t *testing.T) {
+	// Round trip
+	p := &Packet{Header: &PacketHeader{
+		ContinuityCounter:          5,
+		HasPayload:                 true,
+		PID:                        256,
+		TransportPriority:          true,
+		TransportScramblingControl: ScramblingControlScrambledWithOddKey,
+	}}
+	b := make([]byte, MpegTsPacketSize)
+	_, err := p.Serialise(b)
+	assert.NoError(t, err)
+	h, err := ParsePacketHeader(b)
+	assert.NoError(t, err)
+	assert.Equal(t, *p.Header, h)
+
+	// Re-mark
+	assert.NoError(t, SetPacketTransportPriority(b, false))
+	assert.NoError(t, SetPacketScramblingControl(b, ScramblingControlScrambledWithEvenKey))
+	h, err = ParsePacketHeader(b)
+	assert.NoError(t, err)
+	assert.Equal(t, PacketHeader{
+		ContinuityCounter:          5,
+		HasPayload:                 true,
+		PID:                        256,
+		TransportScramblingControl: ScramblingControlScrambledWithEvenKey,
+	}, h)
+	assert.NoError(t, SetPacketTransportPriority(b, true))
+	assert.NoError(t, SetPacketScramblingControl(b, ScramblingControlNotScrambled))
+	assert.Equal(t, []byte{syncByte, 0x21, 0x0, 0x15}, b[:4])
+
+	// Invalid
+	assert.EqualError(t, SetPacketTransportPriority(b[:4], true), ErrPacketTooShort.Error())
+}
+
+func TestSerialisePSIPackets(t *testing.T) {
+	// Sections spanning several packets
+	var streams []MuxStream
+	for idx := 0; idx < 40; idx++ {
+		streams = append(streams, MuxStream{PID: uint16(256 + idx), StreamType: StreamTypeH264Video})
+	}
+	bs, err := SerialisePSIPackets(&PSIData{Sections: []*PSISection{newMuxPMTSection(MuxProgram{Number: 1, Streams: streams})}}, 0x1000, 15)
+	assert.NoError(t, err)
+	assert.Len(t, bs, 2)
+
+	// Round trip
+	var payload []byte
+	for idx, b := range bs {
+		assert.Len(t, b, MpegTsPacketSize)
+		p, err := ParsePacket(b)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(0x1000), p.Header.PID)
+		assert.Equal(t, uint8(15+idx)&0xf, p.Header.ContinuityCounter)
+		assert.Equal(t, idx == 0, p.Header.PayloadUnitStartIndicator)
+		payload = append(payload, p.Payload...)
+	}
+	d, err := ParsePSIPacket(&Packet{Payload: payload})
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 1)
+	assert.Len(t, d.Sections[0].Syntax.Data.PMT.ElementaryStreams, 40)
+}
@@ -0,0 +1,243 @@
+package astits
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Number of PES starts per PID for which the PCR is remembered while waiting for the PES data to be parsed
+const pcrLeadPendingStarts = 4
+
+// PCRLead represents a measurement of how far a PES PTS leads the PCR of its program, which is the time the
+// decoder has to wait before presenting the access unit
+type PCRLead struct {
+	ExceedsMax bool // Set when a max lead has been configured and the lead is bigger than it
+	Lead       time.Duration
+	Negative   bool // Set when the PTS is behind the PCR, which means the access unit arrived too late
+	PCR        ClockReference
+	PID        uint16
+	PTS        ClockReference
+}
+
+// PCRLeadStats represents the PTS vs PCR lead measurements aggregated for a PID
+type PCRLeadStats struct {
+	Count           int
+	ExceedsMaxCount int
+	Last            time.Duration
+	Max             time.Duration
+	Min             time.Duration
+	NegativeCount   int
+	PID             uint16
+}
+
+// PCRLeadTracker measures, for each PID carrying PES data with a PTS, how far the PTS leads the PCR
+// The PCR is the one at the first packet of each PES, interpolated out of the PCRs received on the program PCR PID
+// before and after it and the bitrate observed between them. When the PES data is complete before the next PCR is
+// received, the PCR is extrapolated out of the bitrate observed between the 2 previous PCRs instead. Positions are
+// counted in packets since they all have the same size.
+type PCRLeadTracker struct {
+	m       *sync.Mutex
+	max     time.Duration
+	n       int64                      // Number of packets received so far, which is the position of the next packet
+	pcrPIDs map[uint16]uint16          // Elementary PID --> PCR PID
+	pcrs    map[uint16][]pcrLeadSample // Last 2 PCRs, indexed by PCR PID
+	pending map[uint16][]*pcrLeadStart
+	stats   map[uint16]*PCRLeadStats
+}
+
+type pcrLeadSample struct {
+	n   int64 // Position of the packet carrying the PCR
+	pcr ClockReference
+}
+
+type pcrLeadStart struct {
+	discontinuity bool           // A discontinuity occurred before the next PCR
+	n             int64          // Position of the first packet of the PES
+	next          *pcrLeadSample // First PCR received after the first packet of the PES
+	p             *Packet
+	pcrPID        uint16
+	prev          []pcrLeadSample // Last 2 PCRs received up to the first packet of the PES
+}
+
+// pcr returns the PCR at the first packet of the PES
+func (s *pcrLeadStart) pcr() ClockReference {
+	// Get the 2 PCRs the bitrate is observed between
+	a := s.prev[len(s.prev)-1]
+	var b pcrLeadSample
+	if s.next != nil {
+		b = *s.next
+	} else if len(s.prev) > 1 {
+		a, b = s.prev[0], s.prev[1]
+	} else {
+		return a.pcr
+	}
+	if b.n == a.n {
+		return a.pcr
+	}
+
+	// Interpolate
+	return *newClockReferenceFromTicks(clockReferenceTicks(a.pcr) + clockReferenceTicksDiff(b.pcr, a.pcr)*(s.n-a.n)/(b.n-a.n))
+}
+
+// NewPCRLeadTracker creates a new PCR lead tracker
+// If max is > 0, leads bigger than max are flagged
+func NewPCRLeadTracker(max time.Duration) *PCRLeadTracker {
+	return &PCRLeadTracker{
+		m:       &sync.Mutex{},
+		max:     max,
+		pcrPIDs: make(map[uint16]uint16),
+		pcrs:    make(map[uint16][]pcrLeadSample),
+		pending: make(map[uint16][]*pcrLeadStart),
+		stats:   make(map[uint16]*PCRLeadStats),
+	}
+}
+
+// AddPacket updates the tracker with a new packet
+// It must be called for every packet, in the order they've been read
+func (t *PCRLeadTracker) AddPacket(p *Packet) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get position
+	n := t.n
+	t.n++
+
+	// Update PCR
+	if p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil {
+		s := pcrLeadSample{n: n, pcr: *p.AdaptationField.PCR}
+
+		// The bitrate can't be observed across a discontinuity
+		ss := t.pcrs[p.Header.PID]
+		if p.AdaptationField.DiscontinuityIndicator {
+			ss = nil
+		}
+		ss = append(ss, s)
+		if len(ss) > 2 {
+			ss = ss[len(ss)-2:]
+		}
+		t.pcrs[p.Header.PID] = ss
+
+		// Update starts waiting for the next PCR, which they stop waiting for after a discontinuity
+		for _, starts := range t.pending {
+			for _, start := range starts {
+				if start.pcrPID != p.Header.PID || start.next != nil || start.discontinuity {
+					continue
+				}
+				if p.AdaptationField.DiscontinuityIndicator {
+					start.discontinuity = true
+				} else {
+					start.next = &s
+				}
+			}
+		}
+	}
+
+	// Remember the PCRs at the start of the PES
+	if p.Header.PayloadUnitStartIndicator {
+		pcrPID, ok := t.pcrPIDs[p.Header.PID]
+		if !ok {
+			return
+		}
+		pcrs, ok := t.pcrs[pcrPID]
+		if !ok {
+			return
+		}
+		ss := append(t.pending[p.Header.PID], &pcrLeadStart{
+			n:      n,
+			p:      p,
+			pcrPID: pcrPID,
+			prev:   append([]pcrLeadSample(nil), pcrs...),
+		})
+		if len(ss) > pcrLeadPendingStarts {
+			ss = ss[len(ss)-pcrLeadPendingStarts:]
+		}
+		t.pending[p.Header.PID] = ss
+	}
+}
+
+// AddData updates the tracker with a new data and returns the lead measurement if the data allowed one
+func (t *PCRLeadTracker) AddData(d *Data) (l *PCRLead) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// PMT
+	if d.PMT != nil {
+		for _, es := range d.PMT.ElementaryStreams {
+			t.pcrPIDs[es.ElementaryPID] = d.PMT.PCRPID
+		}
+		return
+	}
+
+	// PES with a PTS
+	if d.PES == nil || d.PES.Header == nil || d.PES.Header.OptionalHeader == nil || d.PES.Header.OptionalHeader.PTS == nil {
+		return
+	}
+
+	// Get PCR at the start of the PES
+	var start *pcrLeadStart
+	ss := t.pending[d.PID]
+	for idx, s := range ss {
+		if s.p == d.FirstPacket {
+			start = s
+			t.pending[d.PID] = ss[idx+1:]
+			break
+		}
+	}
+	if start == nil {
+		return
+	}
+	pcr := start.pcr()
+
+	// Create measurement
+	l = &PCRLead{
+		Lead: clockReferenceBaseDuration(clockReferenceBaseDiff(d.PES.Header.OptionalHeader.PTS.Base, pcr.Base)),
+		PCR:  pcr,
+		PID:  d.PID,
+		PTS:  *d.PES.Header.OptionalHeader.PTS,
+	}
+	l.Negative = l.Lead < 0
+	l.ExceedsMax = t.max > 0 && l.Lead > t.max
+
+	// Update stats
+	s, ok := t.stats[d.PID]
+	if !ok {
+		s = &PCRLeadStats{
+			Max: l.Lead,
+			Min: l.Lead,
+			PID: d.PID,
+		}
+		t.stats[d.PID] = s
+	}
+	s.Count++
+	s.Last = l.Lead
+	if l.Lead > s.Max {
+		s.Max = l.Lead
+	}
+	if l.Lead < s.Min {
+		s.Min = l.Lead
+	}
+	if l.Negative {
+		s.NegativeCount++
+	}
+	if l.ExceedsMax {
+		s.ExceedsMaxCount++
+	}
+	return
+}
+
+// Stats returns the stats of every PID for which a lead has been measured, sorted by PID
+func (t *PCRLeadTracker) Stats() (ss []PCRLeadStats) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Loop through stats
+	for _, s := range t.stats {
+		ss = append(ss, *s)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].PID < ss[j].PID })
+	return
+}
@@ -0,0 +1,94 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pcrLeadPESData(pid uint16, p *Packet, pts int64) *Data {
+	return &Data{
+		FirstPacket: p,
+		PES:         &PESData{Header: &PESHeader{OptionalHeader: &PESOptionalHeader{PTS: &ClockReference{Base: pts}}}},
+		PID:         pid,
+	}
+}
+
+func pcrLeadPCRPacket(pid uint16, base int64, discontinuity bool) *Packet {
+	return &Packet{
+		AdaptationField: &PacketAdaptationField{DiscontinuityIndicator: discontinuity, HasPCR: true, PCR: &ClockReference{Base: base}},
+		Header:          &PacketHeader{HasAdaptationField: true, PID: pid},
+	}
+}
+
+func TestPCRLeadTracker(t *testing.T) {
+	tr := NewPCRLeadTracker(time.Second)
+
+	// PCR received before the PMT is ignored for the lead
+	p1 := &Packet{Header: &PacketHeader{PID: 256, PayloadUnitStartIndicator: true}}
+	tr.AddPacket(pcrLeadPCRPacket(100, 1000, false))
+	tr.AddPacket(p1)
+	assert.Nil(t, tr.AddData(pcrLeadPESData(256, p1, 2000)))
+
+	// PMT
+	assert.Nil(t, tr.AddData(&Data{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 256}}, PCRPID: 100}}))
+
+	// PCR is interpolated between the surrounding PCRs
+	p2 := &Packet{Header: &PacketHeader{PID: 256, PayloadUnitStartIndicator: true}}
+	tr.AddPacket(p2)
+	tr.AddPacket(&Packet{Header: &PacketHeader{PID: 256}})
+	tr.AddPacket(pcrLeadPCRPacket(100, 5000, false))
+	assert.Equal(t, &PCRLead{
+		Lead: clockReferenceBaseDuration(7000),
+		PCR:  ClockReference{Base: 3000},
+		PID:  256,
+		PTS:  ClockReference{Base: 10000},
+	}, tr.AddData(pcrLeadPESData(256, p2, 10000)))
+
+	// PCR is extrapolated when the next PCR has not been received yet
+	p3 := &Packet{Header: &PacketHeader{PID: 256, PayloadUnitStartIndicator: true}}
+	tr.AddPacket(p3)
+	l := tr.AddData(pcrLeadPESData(256, p3, 5500))
+	assert.Equal(t, ClockReference{Base: 6000}, l.PCR)
+	assert.True(t, l.Negative)
+	assert.False(t, l.ExceedsMax)
+
+	// Lead exceeding max
+	p4 := &Packet{Header: &PacketHeader{PID: 256, PayloadUnitStartIndicator: true}}
+	tr.AddPacket(p4)
+	l = tr.AddData(pcrLeadPESData(256, p4, 300000))
+	assert.Equal(t, ClockReference{Base: 7000}, l.PCR)
+	assert.False(t, l.Negative)
+	assert.True(t, l.ExceedsMax)
+
+	// PCR is not interpolated across a discontinuity
+	p5 := &Packet{Header: &PacketHeader{PID: 256, PayloadUnitStartIndicator: true}}
+	tr.AddPacket(p5)
+	tr.AddPacket(pcrLeadPCRPacket(100, 100, true))
+	tr.AddPacket(pcrLeadPCRPacket(100, 200, false))
+	l = tr.AddData(pcrLeadPESData(256, p5, 8000))
+	assert.Equal(t, ClockReference{Base: 8000}, l.PCR)
+
+	// PCR carried by the first packet of the PES is used as is
+	p6 := pcrLeadPCRPacket(100, 1000, false)
+	p6.Header.PayloadUnitStartIndicator = true
+	assert.Nil(t, tr.AddData(&Data{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 100}}, PCRPID: 100}}))
+	tr.AddPacket(p6)
+	tr.AddPacket(pcrLeadPCRPacket(100, 2000, false))
+	l = tr.AddData(pcrLeadPESData(100, p6, 1000))
+	assert.Equal(t, ClockReference{Base: 1000}, l.PCR)
+
+	// Stats
+	assert.Equal(t, []PCRLeadStats{
+		{Count: 1, PID: 100},
+		{
+			Count:           4,
+			ExceedsMaxCount: 1,
+			Max:             clockReferenceBaseDuration(293000),
+			Min:             clockReferenceBaseDuration(-500),
+			NegativeCount:   1,
+			PID:             256,
+		},
+	}, tr.Stats())
+}
@@ -0,0 +1,119 @@
+package astits
+
+import (
+	"sync"
+	"time"
+)
+
+// Default max gap between 2 consecutive PCRs of a PID above which a jump is considered as a discontinuity
+// The spec requires PCRs to be sent at least every 100ms
+const defaultPCRTimelineMaxGap = time.Second
+
+// PCRTimeline maps the PCRs of each PID onto a continuous media time axis starting at 0, usable for duration,
+// seeking or segment timing
+// When the discontinuity indicator is set, or when the PCR jumps backward or forward by more than the max gap, the
+// PCR is considered discontinuous and the media time is advanced by the last regular PCR interval instead. The 33 bits
+// wrap around is not considered as a discontinuity.
+type PCRTimeline struct {
+	m      *sync.Mutex
+	maxGap int64 // In 27 MHz units
+	pids   map[uint16]*pcrTimelinePID
+}
+
+type pcrTimelinePID struct {
+	discontinuities int
+	interval        int64 // In 27 MHz units
+	last            int64 // In 27 MHz units
+	position        int64 // In 27 MHz units
+}
+
+// NewPCRTimeline creates a new PCR timeline
+// If maxGap is <= 0, a default value of 1s is used
+func NewPCRTimeline(maxGap time.Duration) *PCRTimeline {
+	if maxGap <= 0 {
+		maxGap = defaultPCRTimelineMaxGap
+	}
+	return &PCRTimeline{
+		m:      &sync.Mutex{},
+		maxGap: int64(maxGap) * 27 / 1000,
+		pids:   make(map[uint16]*pcrTimelinePID),
+	}
+}
+
+// AddPacket updates the timeline with a new packet and returns the media time of its PCR
+// ok is false if the packet doesn't carry a PCR
+func (t *PCRTimeline) AddPacket(p *Packet) (d time.Duration, ok bool) {
+	// No PCR
+	if !p.Header.HasAdaptationField || p.AdaptationField == nil || !p.AdaptationField.HasPCR || p.AdaptationField.PCR == nil {
+		return
+	}
+	ok = true
+
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get PID
+	pcr := clockReferenceTicks(*p.AdaptationField.PCR)
+	v, exists := t.pids[p.Header.PID]
+	if !exists {
+		t.pids[p.Header.PID] = &pcrTimelinePID{last: pcr}
+		return
+	}
+
+	// Get delta, taking the 33 bits wrap around into account
+	const wrap = clockReferenceBaseWrap * 300
+	delta := (pcr - v.last) % wrap
+	if delta >= wrap/2 {
+		delta -= wrap
+	} else if delta < -wrap/2 {
+		delta += wrap
+	}
+
+	// Discontinuity
+	if p.AdaptationField.DiscontinuityIndicator || delta < 0 || delta > t.maxGap {
+		v.discontinuities++
+		delta = v.interval
+	} else {
+		v.interval = delta
+	}
+
+	// Update
+	v.last = pcr
+	v.position += delta
+	d = pcrTimelineDuration(v.position)
+	return
+}
+
+// Position returns the media time of the last PCR of a PID
+func (t *PCRTimeline) Position(pid uint16) time.Duration {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get PID
+	v, ok := t.pids[pid]
+	if !ok {
+		return 0
+	}
+	return pcrTimelineDuration(v.position)
+}
+
+// Discontinuities returns the number of discontinuities detected for a PID
+func (t *PCRTimeline) Discontinuities(pid uint16) int {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get PID
+	v, ok := t.pids[pid]
+	if !ok {
+		return 0
+	}
+	return v.discontinuities
+}
+
+// pcrTimelineDuration converts a value in 27 MHz units into a duration
+func pcrTimelineDuration(v int64) time.Duration {
+	return time.Duration(v / 27 * 1000)
+}
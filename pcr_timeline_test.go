@@ -0,0 +1,48 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pcrTimelinePacket(pid uint16, base int64, discontinuity bool) *Packet {
+	return &Packet{
+		AdaptationField: &PacketAdaptationField{DiscontinuityIndicator: discontinuity, HasPCR: true, PCR: &ClockReference{Base: base}},
+		Header:          &PacketHeader{HasAdaptationField: true, PID: pid},
+	}
+}
+
+func TestPCRTimeline(t *testing.T) {
+	tl := NewPCRTimeline(0)
+
+	// No PCR
+	_, ok := tl.AddPacket(&Packet{Header: &PacketHeader{PID: 256}})
+	assert.False(t, ok)
+
+	// Regular PCRs
+	for idx, v := range []struct {
+		base          int64
+		discontinuity bool
+		expected      time.Duration
+	}{
+		{base: clockReferenceBaseWrap - 9000},
+		{base: clockReferenceBaseWrap - 4500, expected: 50 * time.Millisecond},
+		{base: 4500, expected: 150 * time.Millisecond},                       // Wrap around
+		{base: 1000, expected: 250 * time.Millisecond},                       // Backward jump
+		{base: 1000000, expected: 350 * time.Millisecond},                    // Forward jump
+		{base: 10000, discontinuity: true, expected: 450 * time.Millisecond}, // Discontinuity indicator
+		{base: 19000, expected: 550 * time.Millisecond},
+	} {
+		d, ok := tl.AddPacket(pcrTimelinePacket(256, v.base, v.discontinuity))
+		assert.True(t, ok, "idx %d", idx)
+		assert.Equal(t, v.expected, d, "idx %d", idx)
+	}
+	assert.Equal(t, 550*time.Millisecond, tl.Position(256))
+	assert.Equal(t, 3, tl.Discontinuities(256))
+
+	// Other PID
+	assert.Equal(t, time.Duration(0), tl.Position(257))
+	assert.Equal(t, 0, tl.Discontinuities(257))
+}
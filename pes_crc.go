@@ -0,0 +1,104 @@
+package astits
+
+import (
+	"sync"
+)
+
+// PESCRCMismatch represents a mismatch between the previous PES packet CRC signaled in a PES header and the CRC
+// computed out of the data of the previous PES of the same PID
+type PESCRCMismatch struct {
+	Computed uint16
+	Expected uint16
+	PID      uint16
+}
+
+// PESCRCValidator verifies the previous PES packet CRC of the PES headers carrying one
+// The CRC covers the data bytes of the previous PES of the same PID, therefore PESs following a lost PES can't be
+// verified and are skipped.
+type PESCRCValidator struct {
+	crcs       map[uint16]uint16 // Indexed by PID
+	fn         func(m PESCRCMismatch)
+	m          *sync.Mutex
+	mismatches map[uint16]int // Indexed by PID
+}
+
+// NewPESCRCValidator creates a new PES CRC validator
+// If fn is not nil, it is called for every mismatch
+func NewPESCRCValidator(fn func(m PESCRCMismatch)) *PESCRCValidator {
+	return &PESCRCValidator{
+		crcs:       make(map[uint16]uint16),
+		fn:         fn,
+		m:          &sync.Mutex{},
+		mismatches: make(map[uint16]int),
+	}
+}
+
+// AddData updates the validator with a new data and returns the mismatch it triggered, if any
+func (v *PESCRCValidator) AddData(d *Data) (m *PESCRCMismatch) {
+	// Not a PES
+	if d.PES == nil || d.PES.Header == nil {
+		return
+	}
+
+	// Lock
+	v.m.Lock()
+
+	// Check CRC
+	if h := d.PES.Header.OptionalHeader; h != nil && h.HasCRC {
+		if c, ok := v.crcs[d.PID]; ok && c != h.CRC {
+			m = &PESCRCMismatch{
+				Computed: c,
+				Expected: h.CRC,
+				PID:      d.PID,
+			}
+			v.mismatches[d.PID]++
+		}
+	}
+
+	// Store CRC
+	v.crcs[d.PID] = computeCRC16(d.PES.Data)
+
+	// Unlock
+	v.m.Unlock()
+
+	// Callback
+	if m != nil && v.fn != nil {
+		v.fn(*m)
+	}
+	return
+}
+
+// Reset forgets the previous PES of a PID, for instance after packets have been lost
+func (v *PESCRCValidator) Reset(pid uint16) {
+	// Lock
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	// Reset
+	delete(v.crcs, pid)
+}
+
+// Mismatches returns the number of mismatches detected for a PID
+func (v *PESCRCValidator) Mismatches(pid uint16) int {
+	// Lock
+	v.m.Lock()
+	defer v.m.Unlock()
+	return v.mismatches[pid]
+}
+
+// computeCRC16 computes the CRC16 used by the previous PES packet CRC
+// Chapter: 2.4.3.7 and Annex A | Link: ISO/IEC 13818-1
+func computeCRC16(bs []byte) (o uint16) {
+	o = uint16(0xffff)
+	for _, b := range bs {
+		for i := 0; i < 8; i++ {
+			if (o >= uint16(0x8000)) != (b >= uint8(0x80)) {
+				o = (o << 1) ^ 0x1021
+			} else {
+				o = o << 1
+			}
+			b <<= 1
+		}
+	}
+	return
+}
@@ -0,0 +1,38 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeCRC16(t *testing.T) {
+	assert.Equal(t, uint16(0x29b1), computeCRC16([]byte("123456789")))
+}
+
+func TestPESCRCValidator(t *testing.T) {
+	var ms []PESCRCMismatch
+	v := NewPESCRCValidator(func(m PESCRCMismatch) { ms = append(ms, m) })
+	pes := func(data string, crc uint16) *Data {
+		return &Data{PES: &PESData{Data: []byte(data), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{CRC: crc, HasCRC: true}}}, PID: 256}
+	}
+
+	// First PES can't be verified
+	assert.Nil(t, v.AddData(pes("123456789", 0)))
+
+	// Valid
+	assert.Nil(t, v.AddData(pes("test", 0x29b1)))
+
+	// Mismatch
+	m := v.AddData(pes("test", 0x1234))
+	assert.Equal(t, &PESCRCMismatch{Computed: computeCRC16([]byte("test")), Expected: 0x1234, PID: 256}, m)
+	assert.Equal(t, []PESCRCMismatch{*m}, ms)
+	assert.Equal(t, 1, v.Mismatches(256))
+
+	// Reset
+	v.Reset(256)
+	assert.Nil(t, v.AddData(pes("test", 0x1234)))
+
+	// Not a PES
+	assert.Nil(t, v.AddData(&Data{PID: 256}))
+}
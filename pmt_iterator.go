@@ -0,0 +1,102 @@
+package astits
+
+import "sort"
+
+// PMTElementaryStreamIterator iterates over the elementary streams of a program, whatever the number of PMT sections
+// they are spread over
+// Only the sections of the version of the most recent data are used, sections being ordered by section number and
+// retransmitted sections replacing the previous ones. An elementary PID is yielded only once.
+type PMTElementaryStreamIterator struct {
+	esIdx   int
+	pids    map[uint16]bool
+	pmts    []*PMTData
+	pmtsIdx int
+}
+
+// NewPMTElementaryStreamIterator creates a new elementary stream iterator out of data in reception order, such as the
+// data of a SubtableUpdate, data that are not PMTs of the program being ignored
+func NewPMTElementaryStreamIterator(programNumber uint16, ds []*Data) *PMTElementaryStreamIterator {
+	// Get PMT data of the program
+	var pds []*Data
+	for _, d := range ds {
+		if d.PMT != nil && d.PMT.ProgramNumber == programNumber {
+			pds = append(pds, d)
+		}
+	}
+
+	// Index the sections of the most recent version
+	sections := make(map[uint8]*PMTData)
+	if len(pds) > 0 {
+		version, hasVersion := pmtVersionNumber(pds[len(pds)-1])
+		for _, d := range pds {
+			v, ok := pmtVersionNumber(d)
+			if hasVersion && (!ok || v != version) {
+				continue
+			}
+			var n uint8
+			if ok {
+				n = d.Section.Syntax.Header.SectionNumber
+			}
+			sections[n] = d.PMT
+		}
+	}
+
+	// Sort sections
+	var ns []int
+	for n := range sections {
+		ns = append(ns, int(n))
+	}
+	sort.Ints(ns)
+
+	// Create iterator
+	it := &PMTElementaryStreamIterator{pids: make(map[uint16]bool)}
+	for _, n := range ns {
+		it.pmts = append(it.pmts, sections[uint8(n)])
+	}
+	return it
+}
+
+// pmtVersionNumber returns the version number of the section of a PMT data, ok being false if it is unknown
+func pmtVersionNumber(d *Data) (v uint8, ok bool) {
+	if d.Section == nil || d.Section.Syntax == nil || d.Section.Syntax.Header == nil {
+		return
+	}
+	return d.Section.Syntax.Header.VersionNumber, true
+}
+
+// Next returns the next elementary stream, ok being false once there are no more elementary streams
+func (it *PMTElementaryStreamIterator) Next() (es *PMTElementaryStream, ok bool) {
+	for it.pmtsIdx < len(it.pmts) {
+		// Section is over
+		p := it.pmts[it.pmtsIdx]
+		if it.esIdx >= len(p.ElementaryStreams) {
+			it.esIdx = 0
+			it.pmtsIdx++
+			continue
+		}
+
+		// Get elementary stream
+		es = p.ElementaryStreams[it.esIdx]
+		it.esIdx++
+
+		// Elementary PID has already been yielded
+		if it.pids[es.ElementaryPID] {
+			continue
+		}
+		it.pids[es.ElementaryPID] = true
+		return es, true
+	}
+	return nil, false
+}
+
+// PMTElementaryStreams returns all the elementary streams of a program, see PMTElementaryStreamIterator
+func PMTElementaryStreams(programNumber uint16, ds []*Data) (ess []*PMTElementaryStream) {
+	it := NewPMTElementaryStreamIterator(programNumber, ds)
+	for {
+		es, ok := it.Next()
+		if !ok {
+			return
+		}
+		ess = append(ess, es)
+	}
+}
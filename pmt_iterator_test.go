@@ -0,0 +1,58 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPMTElementaryStreamIterator(t *testing.T) {
+	pmt := func(version, section uint8, programNumber uint16, pids ...uint16) *Data {
+		d := &PMTData{ProgramNumber: programNumber}
+		for _, pid := range pids {
+			d.ElementaryStreams = append(d.ElementaryStreams, &PMTElementaryStream{ElementaryPID: pid})
+		}
+		return &Data{
+			PMT: d,
+			Section: &PSISection{Syntax: &PSISectionSyntax{Header: &PSISectionSyntaxHeader{
+				LastSectionNumber: 1,
+				SectionNumber:     section,
+				VersionNumber:     version,
+			}}},
+		}
+	}
+	pids := func(ess []*PMTElementaryStream) (o []uint16) {
+		for _, es := range ess {
+			o = append(o, es.ElementaryPID)
+		}
+		return
+	}
+
+	// Sections
+	ds := []*Data{
+		pmt(1, 1, 1, 0x102, 0x103),
+		pmt(1, 0, 2, 0x200),
+		{PAT: &PATData{}},
+		pmt(1, 0, 1, 0x100, 0x101, 0x102),
+	}
+	assert.Equal(t, []uint16{0x100, 0x101, 0x102, 0x103}, pids(PMTElementaryStreams(1, ds)))
+
+	// Versions
+	ds = append(ds, pmt(2, 1, 1, 0x104))
+	assert.Equal(t, []uint16{0x104}, pids(PMTElementaryStreams(1, ds)))
+
+	// Retransmission
+	ds = append(ds, pmt(2, 0, 1, 0x100), pmt(2, 0, 1, 0x101))
+	it := NewPMTElementaryStreamIterator(1, ds)
+	es, ok := it.Next()
+	assert.True(t, ok)
+	assert.Equal(t, uint16(0x101), es.ElementaryPID)
+	es, ok = it.Next()
+	assert.True(t, ok)
+	assert.Equal(t, uint16(0x104), es.ElementaryPID)
+	_, ok = it.Next()
+	assert.False(t, ok)
+
+	// No PMT
+	assert.Len(t, PMTElementaryStreams(3, ds), 0)
+}
@@ -0,0 +1,112 @@
+package astits
+
+// Profile represents the broadcast standard a stream complies with
+// Standards assign different meanings to the same PIDs and table IDs, the profile determines which of them are used
+// so that, for instance, ATSC elementary streams on PIDs DVB reserves for SI are not parsed as SI.
+// ProfileAuto makes the demuxer start with DVB and switch to ATSC as soon as it spots PSIP tables on the PSIP base PID
+// or a PMT carrying a 'GA94' registration descriptor. ISDB can't be told apart from DVB and must be set explicitly.
+// Outside of the demuxer, ProfileAuto is the same as ProfileDVB.
+type Profile uint8
+
+// Profiles
+const (
+	ProfileAuto Profile = iota // Detected by the demuxer, DVB being used until ATSC is detected
+	ProfileATSC
+	ProfileDVB
+	ProfileISDB
+)
+
+var profileNames = map[Profile]string{
+	ProfileATSC: "ATSC",
+	ProfileAuto: "auto",
+	ProfileDVB:  "DVB",
+	ProfileISDB: "ISDB",
+}
+
+// String implements the fmt.Stringer interface
+func (p Profile) String() string {
+	if n, ok := profileNames[p]; ok {
+		return n
+	}
+	return "unknown"
+}
+
+// ATSC registration descriptor format identifier
+// Chapter: 6.7.1 | Link: https://www.atsc.org/wp-content/uploads/2015/03/A53-Part-3-2013.pdf
+const atscFormatIdentifier = 0x47413934 // 'GA94'
+
+// detectPacketProfile returns the profile revealed by a packet, ProfileAuto if it doesn't reveal any
+func detectPacketProfile(p *Packet) Profile {
+	// PSIP tables start on the PSIP base PID
+	if p.Header.PID != PIDATSCPSIP || !p.Header.PayloadUnitStartIndicator || len(p.Payload) == 0 {
+		return ProfileAuto
+	}
+
+	// Table ID follows the pointer field
+	if o := 1 + int(p.Payload[0]); o < len(p.Payload) && p.Payload[o] >= 0xc7 && p.Payload[o] <= 0xcd {
+		return ProfileATSC
+	}
+	return ProfileAuto
+}
+
+// detectPMTProfile returns the profile revealed by a PMT, ProfileAuto if it doesn't reveal any
+func detectPMTProfile(d *PMTData) Profile {
+	for _, v := range d.ProgramDescriptors {
+		if v.Registration != nil && v.Registration.FormatIdentifier == atscFormatIdentifier {
+			return ProfileATSC
+		}
+	}
+	return ProfileAuto
+}
+
+// TableType returns the table type of a table ID in the namespace of the profile
+func (p Profile) TableType(tableID int) TableType {
+	if p == ProfileATSC {
+		return atscTableType(tableID)
+	}
+	return TableTypeFromID(tableID)
+}
+
+// atscTableType returns the table type of a table ID in the ATSC namespace
+// ATSC doesn't use DVB SI table IDs and defines PSIP ones in the range DVB leaves to user private tables.
+// Chapter: 4.2 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+func atscTableType(tableID int) TableType {
+	switch {
+	case tableID == 0xc7:
+		return TableTypeMGT
+	case tableID == 0xc8:
+		return TableTypeTVCT
+	case tableID == 0xc9:
+		return TableTypeCVCT
+	case tableID == 0xca:
+		return TableTypeRRT
+	case tableID == 0xcb:
+		return TableTypeATSCEIT
+	case tableID == 0xcc:
+		return TableTypeETT
+	case tableID == 0xcd:
+		return TableTypeSTT
+	case tableID >= 0x40 && tableID <= 0x7f:
+		return TableTypeUnknown
+	default:
+		return TableTypeFromID(tableID)
+	}
+}
+
+// isSIPID checks whether the PID is reserved for SI tables by the profile
+func (p Profile) isSIPID(pid uint16) bool {
+	switch p {
+	case ProfileATSC:
+		return pid == PIDATSCPSIP
+	case ProfileISDB:
+		// ISDB adds SDTT, BIT, NBIT, L-EIT and CDT PIDs to the DVB ones
+		return isDVBSIPID(pid) || (pid >= 0x23 && pid <= 0x29)
+	default:
+		return isDVBSIPID(pid)
+	}
+}
+
+// isDVBSIPID checks whether the PID is reserved for SI tables by DVB
+func isDVBSIPID(pid uint16) bool {
+	return (pid >= 0x10 && pid <= 0x14) || (pid >= 0x1e && pid <= 0x1f)
+}
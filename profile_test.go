@@ -0,0 +1,105 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astikit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	assert.Equal(t, "ATSC", ProfileATSC.String())
+	assert.Equal(t, "unknown", Profile(0xff).String())
+
+	// Table types
+	assert.Equal(t, TableTypeSDT, ProfileAuto.TableType(0x42))
+	assert.Equal(t, TableTypeSDT, ProfileDVB.TableType(0x42))
+	assert.Equal(t, TableTypeUnknown, ProfileATSC.TableType(0x42))
+	assert.Equal(t, TableTypePMT, ProfileATSC.TableType(0x2))
+	assert.Equal(t, TableTypeMGT, ProfileATSC.TableType(0xc7))
+	assert.Equal(t, TableTypeTVCT, ProfileATSC.TableType(0xc8))
+	assert.Equal(t, TableTypeCVCT, ProfileATSC.TableType(0xc9))
+	assert.Equal(t, TableTypeATSCEIT, ProfileATSC.TableType(0xcb))
+	assert.Equal(t, TableTypeETT, ProfileATSC.TableType(0xcc))
+	assert.Equal(t, TableTypeSTT, ProfileATSC.TableType(0xcd))
+	assert.Equal(t, TableTypeRRT, ProfileATSC.TableType(0xca))
+	assert.Equal(t, TableTypeUnknown, ProfileDVB.TableType(0xc7))
+	assert.Equal(t, "MGT", TableTypeMGT.String())
+
+	// SI PIDs
+	assert.True(t, ProfileAuto.isSIPID(PIDSDT))
+	assert.False(t, ProfileAuto.isSIPID(PIDATSCPSIP))
+	assert.False(t, ProfileATSC.isSIPID(PIDSDT))
+	assert.True(t, ProfileATSC.isSIPID(PIDATSCPSIP))
+	assert.True(t, ProfileISDB.isSIPID(0x29))
+	assert.False(t, ProfileDVB.isSIPID(0x29))
+}
+
+func TestParseDataProfile(t *testing.T) {
+	// SDT on a PID DVB reserves for SI
+	s := &PSISection{
+		Header: &PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x42},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{SDT: &SDTData{}},
+			Header: &PSISectionSyntaxHeader{CurrentNextIndicator: true},
+		},
+	}
+	b := make([]byte, 184)
+	n, err := s.Serialise(b[1:])
+	assert.NoError(t, err)
+	ps := []*Packet{{Header: &PacketHeader{PID: PIDSDT, PayloadUnitStartIndicator: true}, Payload: b[:n+1]}}
+
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileDVB, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	ds, err = parseData(ps, nil, NewProgramMap(), ProfileATSC, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ds, 0)
+}
+
+func TestDemuxerProfileAuto(t *testing.T) {
+	// Create MGT packet
+	b := mgtBytes()
+	buf := &bytes.Buffer{}
+	w := astikit.NewBitsWriter(astikit.BitsWriterOptions{Writer: buf})
+	w.Write(uint8(0))                      // Pointer field
+	w.Write(uint8(0xc7))                   // Table ID
+	w.Write(uint16(0xf000 | (len(b) + 9))) // Syntax indicator, private indicator, reserved and section length
+	w.Write(uint16(0))                     // Table ID extension
+	w.Write("11000011")                    // Reserved, version number and current/next indicator
+	w.Write(uint8(0))                      // Section number
+	w.Write(uint8(0))                      // Last section number
+	w.Write(b)
+	crc32, _ := computeCRC32(buf.Bytes()[1:])
+	w.Write(crc32)
+	w.Write(bytes.Repeat([]byte{0xff}, MpegTsPacketSize-4-buf.Len()))
+	pb := make([]byte, MpegTsPacketSize)
+	_, err := (&Packet{Header: &PacketHeader{HasPayload: true, PID: PIDATSCPSIP, PayloadUnitStartIndicator: true}, Payload: buf.Bytes()}).serialise(pb, StuffingByte)
+	assert.NoError(t, err)
+
+	// PSIP tables reveal ATSC
+	dmx := New(context.Background(), bytes.NewReader(pb), OptPacketSize(MpegTsPacketSize))
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.MGT)
+	assert.Equal(t, ProfileATSC, dmx.profile)
+
+	// Explicit profile is not overridden
+	dmx = New(context.Background(), bytes.NewReader(pb), OptPacketSize(MpegTsPacketSize), OptProfile(ProfileDVB))
+	_, err = dmx.NextData()
+	assert.Equal(t, ErrNoMorePackets, err)
+	assert.Equal(t, ProfileDVB, dmx.profile)
+
+	// 'GA94' registration descriptor reveals ATSC
+	dmx = New(context.Background(), nil)
+	dmx.updateData([]*Data{{PID: 0x1000, PMT: &PMTData{}}})
+	assert.Equal(t, ProfileAuto, dmx.profile)
+	dmx.updateData([]*Data{{PID: 0x1000, PMT: &PMTData{ProgramDescriptors: []*Descriptor{{
+		Registration: &DescriptorRegistration{FormatIdentifier: atscFormatIdentifier},
+		Tag:          DescriptorTagRegistration,
+	}}}}})
+	assert.Equal(t, ProfileATSC, dmx.profile)
+}
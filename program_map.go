@@ -30,3 +30,14 @@ func (m ProgramMap) Set(pid, number uint16) {
 	defer m.m.Unlock()
 	m.p[pid] = number
 }
+
+// Map returns a copy of the program map, indexed by program map PID
+func (m ProgramMap) Map() (o map[uint16]uint16) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	o = make(map[uint16]uint16, len(m.p))
+	for pid, number := range m.p {
+		o[pid] = number
+	}
+	return
+}
@@ -11,4 +11,8 @@ func TestProgramMap(t *testing.T) {
 	assert.False(t, pm.Exists(1))
 	pm.Set(1, 1)
 	assert.True(t, pm.Exists(1))
+	m := pm.Map()
+	assert.Equal(t, map[uint16]uint16{1: 1}, m)
+	m[2] = 2
+	assert.False(t, pm.Exists(2))
 }
@@ -0,0 +1,196 @@
+package astits
+
+import "bytes"
+
+// PATChanges represents the changes between two versions of a PAT
+type PATChanges struct {
+	AddedPrograms            []*PATProgram
+	ChangedPrograms          []*PATProgramChange // Programs whose PMT PID has changed
+	RemovedPrograms          []*PATProgram
+	TransportStreamIDChanged bool
+}
+
+// PATProgramChange represents a program whose PMT PID has changed
+type PATProgramChange struct {
+	Next     *PATProgram
+	Previous *PATProgram
+}
+
+// IsEmpty checks whether there are no changes
+func (c *PATChanges) IsEmpty() bool {
+	return len(c.AddedPrograms) == 0 && len(c.ChangedPrograms) == 0 && len(c.RemovedPrograms) == 0 &&
+		!c.TransportStreamIDChanged
+}
+
+// DiffPAT returns the changes between two versions of a PAT, programs being matched by program number and being
+// returned in the order of the PAT they belong to
+// prev can be nil, in which case all programs of next are added.
+func DiffPAT(prev, next *PATData) (c *PATChanges) {
+	// Create changes
+	c = &PATChanges{}
+	if next == nil {
+		next = &PATData{}
+	}
+	if prev == nil {
+		prev = &PATData{TransportStreamID: next.TransportStreamID}
+	}
+	c.TransportStreamIDChanged = prev.TransportStreamID != next.TransportStreamID
+
+	// Index previous programs
+	ps := make(map[uint16]*PATProgram)
+	for _, p := range prev.Programs {
+		ps[p.ProgramNumber] = p
+	}
+
+	// Loop through next programs
+	ns := make(map[uint16]bool)
+	for _, p := range next.Programs {
+		ns[p.ProgramNumber] = true
+		if pp, ok := ps[p.ProgramNumber]; !ok {
+			c.AddedPrograms = append(c.AddedPrograms, p)
+		} else if pp.ProgramMapID != p.ProgramMapID {
+			c.ChangedPrograms = append(c.ChangedPrograms, &PATProgramChange{
+				Next:     p,
+				Previous: pp,
+			})
+		}
+	}
+
+	// Loop through previous programs
+	for _, p := range prev.Programs {
+		if !ns[p.ProgramNumber] {
+			c.RemovedPrograms = append(c.RemovedPrograms, p)
+		}
+	}
+	return
+}
+
+// DescriptorChanges represents the changes between two versions of a descriptors loop
+// Descriptors are compared on their serialised bytes, therefore a modified descriptor shows up as removed and added.
+type DescriptorChanges struct {
+	Added   []*Descriptor
+	Removed []*Descriptor
+}
+
+// IsEmpty checks whether there are no changes
+func (c *DescriptorChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0
+}
+
+// DiffDescriptors returns the changes between two versions of a descriptors loop, the order of descriptors being
+// ignored
+func DiffDescriptors(prev, next []*Descriptor) (c DescriptorChanges) {
+	c.Removed = descriptorsNotIn(prev, next)
+	c.Added = descriptorsNotIn(next, prev)
+	return
+}
+
+// descriptorsNotIn returns the descriptors of a that are not in b, duplicates being taken into account
+func descriptorsNotIn(a, b []*Descriptor) (o []*Descriptor) {
+	// Serialise b
+	bbs := make([][]byte, 0, len(b))
+	for _, d := range b {
+		bbs = append(bbs, descriptorBytes(d))
+	}
+
+	// Loop through a
+	for _, d := range a {
+		// Look for a descriptor with the same bytes, which can only be used once
+		abs := descriptorBytes(d)
+		found := false
+		for idx, bs := range bbs {
+			if bs != nil && bytes.Equal(abs, bs) {
+				bbs[idx] = nil
+				found = true
+				break
+			}
+		}
+		if !found {
+			o = append(o, d)
+		}
+	}
+	return
+}
+
+// descriptorBytes returns the serialised descriptor, or only its tag if it can't be serialised
+func descriptorBytes(d *Descriptor) []byte {
+	b := make([]byte, 2+0xff)
+	n, err := d.serialise(b)
+	if err != nil {
+		return []byte{d.Tag}
+	}
+	return b[:n]
+}
+
+// PMTChanges represents the changes between two versions of a PMT
+type PMTChanges struct {
+	AddedElementaryStreams   []*PMTElementaryStream
+	ChangedElementaryStreams []*PMTElementaryStreamChange
+	PCRPIDChanged            bool
+	ProgramDescriptors       DescriptorChanges
+	RemovedElementaryStreams []*PMTElementaryStream
+}
+
+// PMTElementaryStreamChange represents an elementary stream whose stream type or descriptors have changed
+type PMTElementaryStreamChange struct {
+	Descriptors       DescriptorChanges
+	Next              *PMTElementaryStream
+	Previous          *PMTElementaryStream
+	StreamTypeChanged bool
+}
+
+// IsEmpty checks whether there are no changes
+func (c *PMTChanges) IsEmpty() bool {
+	return len(c.AddedElementaryStreams) == 0 && len(c.ChangedElementaryStreams) == 0 && !c.PCRPIDChanged &&
+		c.ProgramDescriptors.IsEmpty() && len(c.RemovedElementaryStreams) == 0
+}
+
+// DiffPMT returns the changes between two versions of the PMT of a program, elementary streams being matched by PID
+// and being returned in the order of the PMT they belong to
+// prev can be nil, in which case all elementary streams and program descriptors of next are added.
+func DiffPMT(prev, next *PMTData) (c *PMTChanges) {
+	// Create changes
+	c = &PMTChanges{}
+	if next == nil {
+		next = &PMTData{}
+	}
+	if prev == nil {
+		prev = &PMTData{PCRPID: next.PCRPID}
+	}
+	c.PCRPIDChanged = prev.PCRPID != next.PCRPID
+	c.ProgramDescriptors = DiffDescriptors(prev.ProgramDescriptors, next.ProgramDescriptors)
+
+	// Index previous elementary streams
+	ps := make(map[uint16]*PMTElementaryStream)
+	for _, es := range prev.ElementaryStreams {
+		ps[es.ElementaryPID] = es
+	}
+
+	// Loop through next elementary streams
+	ns := make(map[uint16]bool)
+	for _, es := range next.ElementaryStreams {
+		ns[es.ElementaryPID] = true
+		pes, ok := ps[es.ElementaryPID]
+		if !ok {
+			c.AddedElementaryStreams = append(c.AddedElementaryStreams, es)
+			continue
+		}
+		esc := &PMTElementaryStreamChange{
+			Descriptors:       DiffDescriptors(pes.ElementaryStreamDescriptors, es.ElementaryStreamDescriptors),
+			Next:              es,
+			Previous:          pes,
+			StreamTypeChanged: pes.StreamType != es.StreamType,
+		}
+		if esc.StreamTypeChanged || !esc.Descriptors.IsEmpty() {
+			c.ChangedElementaryStreams = append(c.ChangedElementaryStreams, esc)
+		}
+	}
+
+	// Loop through previous elementary streams
+	for _, es := range prev.ElementaryStreams {
+		if !ns[es.ElementaryPID] {
+			c.RemovedElementaryStreams = append(c.RemovedElementaryStreams, es)
+		}
+	}
+	return
+}
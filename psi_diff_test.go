@@ -0,0 +1,126 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffPAT(t *testing.T) {
+	// No previous PAT
+	next := &PATData{
+		Programs: []*PATProgram{
+			{ProgramMapID: 0x100, ProgramNumber: 1},
+			{ProgramMapID: 0x201, ProgramNumber: 2},
+			{ProgramMapID: 0x300, ProgramNumber: 3},
+		},
+		TransportStreamID: 1,
+	}
+	c := DiffPAT(nil, next)
+	assert.Equal(t, &PATChanges{AddedPrograms: next.Programs}, c)
+
+	// Same PAT
+	assert.True(t, DiffPAT(next, next).IsEmpty())
+
+	// Changes
+	prev := &PATData{
+		Programs: []*PATProgram{
+			{ProgramMapID: 0x100, ProgramNumber: 1},
+			{ProgramMapID: 0x200, ProgramNumber: 2},
+			{ProgramMapID: 0x400, ProgramNumber: 4},
+		},
+		TransportStreamID: 2,
+	}
+	c = DiffPAT(prev, next)
+	assert.False(t, c.IsEmpty())
+	assert.Equal(t, &PATChanges{
+		AddedPrograms:            []*PATProgram{next.Programs[2]},
+		ChangedPrograms:          []*PATProgramChange{{Next: next.Programs[1], Previous: prev.Programs[1]}},
+		RemovedPrograms:          []*PATProgram{prev.Programs[2]},
+		TransportStreamIDChanged: true,
+	}, c)
+}
+
+func TestDiffPMT(t *testing.T) {
+	// No previous PMT
+	next := &PMTData{
+		ElementaryStreams: []*PMTElementaryStream{
+			{ElementaryPID: 0x100, StreamType: StreamTypeH264Video},
+			{
+				ElementaryPID: 0x101,
+				ElementaryStreamDescriptors: []*Descriptor{
+					NewISO639Descriptor("eng", AudioTypeCleanEffects),
+					NewISO639Descriptor("fra", AudioTypeCleanEffects),
+				},
+				StreamType: StreamTypeMPEG1Audio,
+			},
+			{ElementaryPID: 0x102, StreamType: StreamTypeH265Video},
+			{ElementaryPID: 0x104, StreamType: StreamTypeMPEG1Audio},
+		},
+		PCRPID:             0x100,
+		ProgramDescriptors: []*Descriptor{NewMaximumBitrateDescriptor(1000)},
+	}
+	c := DiffPMT(nil, next)
+	assert.Equal(t, &PMTChanges{
+		AddedElementaryStreams: next.ElementaryStreams,
+		ProgramDescriptors:     DescriptorChanges{Added: next.ProgramDescriptors},
+	}, c)
+
+	// Same PMT
+	assert.True(t, DiffPMT(next, next).IsEmpty())
+
+	// Changes
+	prev := &PMTData{
+		ElementaryStreams: []*PMTElementaryStream{
+			{ElementaryPID: 0x100, StreamType: StreamTypeH264Video},
+			{
+				ElementaryPID: 0x101,
+				ElementaryStreamDescriptors: []*Descriptor{
+					NewISO639Descriptor("fra", AudioTypeCleanEffects),
+					NewISO639Descriptor("deu", AudioTypeCleanEffects),
+				},
+				StreamType: StreamTypeMPEG1Audio,
+			},
+			{ElementaryPID: 0x102, StreamType: StreamTypeH264Video},
+			{ElementaryPID: 0x103, StreamType: StreamTypeMPEG1Audio},
+		},
+		PCRPID:             0x101,
+		ProgramDescriptors: []*Descriptor{NewMaximumBitrateDescriptor(1000)},
+	}
+	c = DiffPMT(prev, next)
+	assert.False(t, c.IsEmpty())
+	assert.Equal(t, &PMTChanges{
+		AddedElementaryStreams: []*PMTElementaryStream{next.ElementaryStreams[3]},
+		ChangedElementaryStreams: []*PMTElementaryStreamChange{
+			{
+				Descriptors: DescriptorChanges{
+					Added:   []*Descriptor{next.ElementaryStreams[1].ElementaryStreamDescriptors[0]},
+					Removed: []*Descriptor{prev.ElementaryStreams[1].ElementaryStreamDescriptors[1]},
+				},
+				Next:     next.ElementaryStreams[1],
+				Previous: prev.ElementaryStreams[1],
+			},
+			{
+				Next:              next.ElementaryStreams[2],
+				Previous:          prev.ElementaryStreams[2],
+				StreamTypeChanged: true,
+			},
+		},
+		PCRPIDChanged:            true,
+		RemovedElementaryStreams: []*PMTElementaryStream{prev.ElementaryStreams[3]},
+	}, c)
+}
+
+func TestDiffDescriptors(t *testing.T) {
+	// Duplicates
+	d := NewStreamIdentifierDescriptor(1)
+	c := DiffDescriptors([]*Descriptor{d, d}, []*Descriptor{d})
+	assert.Equal(t, DescriptorChanges{Removed: []*Descriptor{d}}, c)
+
+	// Parsed descriptors are compared on their original bytes
+	ds, err := parseDescriptors(astikit.NewBytesIterator(append([]byte{0x0, 0x3}, descriptorBytes(d)...)))
+	assert.NoError(t, err)
+	c = DiffDescriptors(ds, []*Descriptor{d})
+	assert.True(t, c.IsEmpty())
+}
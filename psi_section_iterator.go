@@ -0,0 +1,117 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Table ID of the stuffing bytes following the last section of a PSI payload
+const psiStuffingTableID = 0xff
+
+// PSISectionIterator iterates over the sections of a PSI payload, whatever their table type
+// Sections are delimited by their section length only, so that several sections of any table type, including unknown
+// ones, can be read out of the same payload. Iteration stops at the first stuffing byte or once the remaining bytes
+// can't hold a complete section.
+type PSISectionIterator struct {
+	i              *astikit.BytesIterator
+	len            int
+	stuffingByte   uint8
+	stuffingBytes  []uint8 // Recognised non standard stuffing bytes
+	stuffingLength int     // Number of non standard stuffing bytes found after the last section
+}
+
+// NewPSISectionIterator creates a new section iterator out of a PSI payload starting with its pointer field
+func NewPSISectionIterator(payload []byte) (it *PSISectionIterator, err error) {
+	// Get pointer field
+	i := astikit.NewBytesIterator(payload)
+	var b byte
+	if b, err = i.NextByte(); err != nil {
+		err = fmt.Errorf("astits: fetching next byte failed: %w", err)
+		return
+	}
+
+	// Skip pointer filler bytes
+	i.Skip(int(b))
+	return newPSISectionIterator(i), nil
+}
+
+// newPSISectionIterator creates a new section iterator out of a bytes iterator positioned at the start of a section
+func newPSISectionIterator(i *astikit.BytesIterator) *PSISectionIterator {
+	return &PSISectionIterator{
+		i:   i,
+		len: i.Len(),
+	}
+}
+
+// Next returns the bytes of the next section, from its table ID to its end, ok being false once there are no more
+// sections
+func (it *PSISectionIterator) Next() (b []byte, ok bool) {
+	// Get next section offsets
+	var offsetStart, offsetEnd int
+	if offsetStart, offsetEnd, ok = it.next(); !ok {
+		return
+	}
+
+	// Get section bytes
+	var err error
+	if b, err = it.i.NextBytes(offsetEnd - offsetStart); err != nil {
+		return nil, false
+	}
+	return
+}
+
+// next returns the offsets of the next section and leaves the bytes iterator at its start
+func (it *PSISectionIterator) next() (offsetStart, offsetEnd int, ok bool) {
+	// Non standard stuffing
+	offsetStart = it.i.Offset()
+	if it.nonStandardStuffing(offsetStart) {
+		return
+	}
+
+	// Not enough bytes for a section header or stuffing
+	if offsetStart+3 > it.len {
+		return
+	}
+
+	// Get header bytes
+	bs, err := it.i.NextBytes(3)
+	if err != nil || bs[0] == psiStuffingTableID {
+		return
+	}
+	it.i.Seek(offsetStart)
+
+	// Section is truncated
+	offsetEnd = offsetStart + 3 + int(uint16(bs[1]&0xf)<<8|uint16(bs[2]))
+	if offsetEnd > it.len {
+		return
+	}
+	ok = true
+	return
+}
+
+// nonStandardStuffing checks whether the remaining bytes are made of one of the recognised non standard stuffing
+// bytes, and leaves the bytes iterator at offsetStart
+func (it *PSISectionIterator) nonStandardStuffing(offsetStart int) bool {
+	// Nothing to check
+	if len(it.stuffingBytes) == 0 || offsetStart >= it.len {
+		return false
+	}
+
+	// Get remaining bytes
+	bs, err := it.i.NextBytes(it.len - offsetStart)
+	it.i.Seek(offsetStart)
+	if err != nil {
+		return false
+	}
+
+	// Loop through recognised bytes
+	for _, b := range it.stuffingBytes {
+		if b != StuffingByte && isStuffing(bs, b) {
+			it.stuffingByte = b
+			it.stuffingLength = len(bs)
+			return true
+		}
+	}
+	return false
+}
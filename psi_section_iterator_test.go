@@ -0,0 +1,34 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPSISectionIterator(t *testing.T) {
+	_, err := NewPSISectionIterator(nil)
+	assert.Error(t, err)
+
+	it, err := NewPSISectionIterator([]byte{
+		2, 0xa, 0xb, // Pointer field
+		0xfc, 0x30, 0x01, 0x1, // Unknown table
+		0x00, 0xb0, 0x02, 0x2, 0x3, // PAT
+		0xff, 0x00, 0x00, // Stuffing
+	})
+	assert.NoError(t, err)
+	b, ok := it.Next()
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0xfc, 0x30, 0x01, 0x1}, b)
+	b, ok = it.Next()
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x00, 0xb0, 0x02, 0x2, 0x3}, b)
+	_, ok = it.Next()
+	assert.False(t, ok)
+
+	// Truncated section
+	it, err = NewPSISectionIterator([]byte{0, 0x00, 0xb0, 0x02, 0x2})
+	assert.NoError(t, err)
+	_, ok = it.Next()
+	assert.False(t, ok)
+}
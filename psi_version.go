@@ -0,0 +1,117 @@
+package astits
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Max size of a PSI section
+const psiSectionMaxSize = 1024
+
+// psiVersioner keeps track of the version number of the PSI tables emitted by the muxer
+// The version number of a table is bumped, modulo 32, every time its content changes
+type psiVersioner struct {
+	tables map[psiVersionKey]*psiVersion
+}
+
+type psiVersionKey struct {
+	tableID          int
+	tableIDExtension uint16
+}
+
+// newPSIVersionKey returns the key of the table sections belong to, which is identified by its first section
+func newPSIVersionKey(ss []*PSISection) psiVersionKey {
+	return psiVersionKey{
+		tableID:          ss[0].Header.TableID,
+		tableIDExtension: ss[0].Syntax.Header.TableIDExtension,
+	}
+}
+
+type psiVersion struct {
+	content []byte
+	version uint8
+}
+
+func newPSIVersioner() *psiVersioner {
+	return &psiVersioner{tables: make(map[psiVersionKey]*psiVersion)}
+}
+
+// update sets the version number and current next indicator of a section based on its content and returns whether
+// the content has changed since the last update
+func (v *psiVersioner) update(s *PSISection) (changed bool, err error) {
+	return v.updateSections([]*PSISection{s})
+}
+
+// updateSections is the same as update for a table spanning several sections, which share the same version number
+// The table is identified by its first section.
+func (v *psiVersioner) updateSections(ss []*PSISection) (changed bool, err error) {
+	// Nothing to version
+	for _, s := range ss {
+		if s.Header == nil || s.Syntax == nil || s.Syntax.Header == nil || s.Syntax.Data == nil {
+			return
+		}
+	}
+	if len(ss) == 0 {
+		return
+	}
+
+	// Serialise content
+	var c []byte
+	for _, s := range ss {
+		var b []byte
+		if b, err = SerialiseGrowable(s.Syntax.Data.serialise); err != nil {
+			err = fmt.Errorf("astits: serialising PSI section syntax data failed: %w", err)
+			return
+		}
+		c = append(c, b...)
+	}
+
+	// Get version
+	pv, ok := v.tables[newPSIVersionKey(ss)]
+	if !ok {
+		pv = &psiVersion{content: c}
+		v.tables[newPSIVersionKey(ss)] = pv
+		changed = true
+	} else if !bytes.Equal(pv.content, c) {
+		pv.content = c
+		pv.version = (pv.version + 1) % 32
+		changed = true
+	}
+
+	// Update headers
+	// Sections are always applicable, see updateSectionsWithNext to announce them beforehand
+	for _, s := range ss {
+		s.Syntax.Header.CurrentNextIndicator = true
+		s.Syntax.Header.VersionNumber = pv.version
+	}
+	return
+}
+
+// updateSectionsWithNext is the same as updateSections but, when the content of a table that has already been
+// versioned changes, it also returns copies of the sections with the current next indicator unset, which announce the
+// new version and are to be emitted right before the sections themselves
+func (v *psiVersioner) updateSectionsWithNext(ss []*PSISection) (next []*PSISection, err error) {
+	// Check whether the table has already been versioned
+	var ok bool
+	if len(ss) > 0 && ss[0].Header != nil && ss[0].Syntax != nil && ss[0].Syntax.Header != nil {
+		_, ok = v.tables[newPSIVersionKey(ss)]
+	}
+
+	// Update
+	var changed bool
+	if changed, err = v.updateSections(ss); err != nil || !changed || !ok {
+		return
+	}
+
+	// Copy sections
+	for _, s := range ss {
+		h := *s.Syntax.Header
+		h.CurrentNextIndicator = false
+		sx := *s.Syntax
+		sx.Header = &h
+		c := *s
+		c.Syntax = &sx
+		next = append(next, &c)
+	}
+	return
+}
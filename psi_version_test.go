@@ -0,0 +1,104 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func psiVersionPATSection(programs ...*PATProgram) *PSISection {
+	return &PSISection{
+		Header: &PSISectionHeader{TableID: 0},
+		Syntax: &PSISectionSyntax{
+			Data:   &PSISectionSyntaxData{PAT: &PATData{Programs: programs, TransportStreamID: 1}},
+			Header: &PSISectionSyntaxHeader{TableIDExtension: 1},
+		},
+	}
+}
+
+func TestPSIVersioner(t *testing.T) {
+	v := newPSIVersioner()
+
+	// First emission
+	s := psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1})
+	c, err := v.update(s)
+	assert.NoError(t, err)
+	assert.True(t, c)
+	assert.Equal(t, uint8(0), s.Syntax.Header.VersionNumber)
+	assert.True(t, s.Syntax.Header.CurrentNextIndicator)
+
+	// Same content
+	s = psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1})
+	c, err = v.update(s)
+	assert.NoError(t, err)
+	assert.False(t, c)
+	assert.Equal(t, uint8(0), s.Syntax.Header.VersionNumber)
+
+	// Program added
+	s = psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1}, &PATProgram{ProgramMapID: 4097, ProgramNumber: 2})
+	c, err = v.update(s)
+	assert.NoError(t, err)
+	assert.True(t, c)
+	assert.Equal(t, uint8(1), s.Syntax.Header.VersionNumber)
+
+	// Other table is versioned independently
+	s = psiVersionPATSection()
+	s.Syntax.Header.TableIDExtension = 2
+	c, err = v.update(s)
+	assert.NoError(t, err)
+	assert.True(t, c)
+	assert.Equal(t, uint8(0), s.Syntax.Header.VersionNumber)
+
+	// Wrap around
+	for i := 0; i < 31; i++ {
+		s = psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: uint16(i + 3)})
+		_, err = v.update(s)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, uint8(0), s.Syntax.Header.VersionNumber)
+}
+
+func TestPSIVersionerSections(t *testing.T) {
+	v := newPSIVersioner()
+	ss := []*PSISection{psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1}), psiVersionPATSection()}
+	c, err := v.updateSections(ss)
+	assert.NoError(t, err)
+	assert.True(t, c)
+
+	// Change in the last section bumps the version of all sections
+	ss = []*PSISection{psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1}), psiVersionPATSection(&PATProgram{ProgramMapID: 4097, ProgramNumber: 2})}
+	c, err = v.updateSections(ss)
+	assert.NoError(t, err)
+	assert.True(t, c)
+	for _, s := range ss {
+		assert.Equal(t, uint8(1), s.Syntax.Header.VersionNumber)
+		assert.True(t, s.Syntax.Header.CurrentNextIndicator)
+	}
+}
+
+func TestPSIVersionerNext(t *testing.T) {
+	v := newPSIVersioner()
+
+	// First emission is not announced
+	s := psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1})
+	next, err := v.updateSectionsWithNext([]*PSISection{s})
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+
+	// Same content is not announced
+	s = psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1})
+	next, err = v.updateSectionsWithNext([]*PSISection{s})
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+
+	// New version is announced
+	s = psiVersionPATSection(&PATProgram{ProgramMapID: 4096, ProgramNumber: 1}, &PATProgram{ProgramMapID: 4097, ProgramNumber: 2})
+	next, err = v.updateSectionsWithNext([]*PSISection{s})
+	assert.NoError(t, err)
+	assert.Len(t, next, 1)
+	assert.False(t, next[0].Syntax.Header.CurrentNextIndicator)
+	assert.Equal(t, uint8(1), next[0].Syntax.Header.VersionNumber)
+	assert.Equal(t, s.Syntax.Data, next[0].Syntax.Data)
+	assert.True(t, s.Syntax.Header.CurrentNextIndicator)
+	assert.Equal(t, uint8(1), s.Syntax.Header.VersionNumber)
+}
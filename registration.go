@@ -0,0 +1,60 @@
+package astits
+
+// Format identifiers of registration descriptors
+// https://smpte-ra.org/registered-mpeg-ts-ids
+const (
+	RegistrationFormatIdentifierAC3  = 0x41432d33 // "AC-3"
+	RegistrationFormatIdentifierCUEI = 0x43554549 // "CUEI", SCTE-35 splice information
+	RegistrationFormatIdentifierEAC3 = 0x45414333 // "EAC3"
+	RegistrationFormatIdentifierGA94 = 0x47413934 // "GA94", ATSC
+	RegistrationFormatIdentifierHDMV = 0x48444d56 // "HDMV", Blu-ray
+	RegistrationFormatIdentifierID3  = 0x49443320 // "ID3 ", timed metadata
+	RegistrationFormatIdentifierKLVA = 0x4b4c5641 // "KLVA", SMPTE KLV metadata
+	RegistrationFormatIdentifierSCTE = 0x53435445 // "SCTE"
+)
+
+// TransportStreamDescription describes the ecosystem a transport stream belongs to, as detected from its program
+// level registration descriptors, to guide downstream parsing choices
+type TransportStreamDescription struct {
+	ATSC              bool     // "GA94" has been found
+	BluRay            bool     // "HDMV" has been found
+	FormatIdentifiers []uint32 // Format identifiers found, in order of appearance and without duplicates
+	SCTE              bool     // "SCTE" has been found
+	SCTE35            bool     // "CUEI" has been found
+}
+
+// DescribeTransportStream inspects the program level registration descriptors of PMTs and reports the detected
+// ecosystem
+func DescribeTransportStream(pmts ...*PMTData) (d TransportStreamDescription) {
+	// Loop through descriptors
+	fs := make(map[uint32]bool)
+	for _, pmt := range pmts {
+		for _, dsc := range pmt.ProgramDescriptors {
+			// Not a registration descriptor
+			if dsc.Tag != DescriptorTagRegistration || dsc.Registration == nil {
+				continue
+			}
+
+			// Already found
+			f := dsc.Registration.FormatIdentifier
+			if fs[f] {
+				continue
+			}
+			fs[f] = true
+			d.FormatIdentifiers = append(d.FormatIdentifiers, f)
+
+			// Ecosystem
+			switch f {
+			case RegistrationFormatIdentifierCUEI:
+				d.SCTE35 = true
+			case RegistrationFormatIdentifierGA94:
+				d.ATSC = true
+			case RegistrationFormatIdentifierHDMV:
+				d.BluRay = true
+			case RegistrationFormatIdentifierSCTE:
+				d.SCTE = true
+			}
+		}
+	}
+	return
+}
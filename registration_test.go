@@ -0,0 +1,24 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func registrationDescriptor(f uint32) *Descriptor {
+	return &Descriptor{Registration: &DescriptorRegistration{FormatIdentifier: f}, Tag: DescriptorTagRegistration}
+}
+
+func TestDescribeTransportStream(t *testing.T) {
+	assert.Equal(t, TransportStreamDescription{}, DescribeTransportStream())
+	assert.Equal(t, TransportStreamDescription{
+		ATSC:              true,
+		FormatIdentifiers: []uint32{RegistrationFormatIdentifierGA94, RegistrationFormatIdentifierCUEI},
+		SCTE35:            true,
+	}, DescribeTransportStream(
+		&PMTData{ProgramDescriptors: []*Descriptor{registrationDescriptor(RegistrationFormatIdentifierGA94), {Tag: DescriptorTagMaximumBitrate}}},
+		&PMTData{ProgramDescriptors: []*Descriptor{registrationDescriptor(RegistrationFormatIdentifierCUEI), registrationDescriptor(RegistrationFormatIdentifierGA94)}},
+	))
+	assert.True(t, DescribeTransportStream(&PMTData{ProgramDescriptors: []*Descriptor{registrationDescriptor(RegistrationFormatIdentifierHDMV)}}).BluRay)
+}
@@ -0,0 +1,102 @@
+package astits
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ScramblingStatus represents the scrambling status of a PID
+type ScramblingStatus struct {
+	LastParityChange time.Time // Zero if the parity has never changed
+	Parity           uint8     // Transport scrambling control of the last packet, see ScramblingControl* constants
+	ParityChanges    int       // Number of switches between the even and odd keys
+	PID              uint16
+	Scrambled        bool
+}
+
+// ScramblingTracker tracks the scrambling status of each PID out of the transport scrambling control of its packets
+// Packets without payload are ignored since their transport scrambling control is always 0.
+type ScramblingTracker struct {
+	m    *sync.Mutex
+	now  func() time.Time
+	pids map[uint16]*ScramblingStatus
+}
+
+// NewScramblingTracker creates a new scrambling tracker
+func NewScramblingTracker() *ScramblingTracker {
+	return newScramblingTracker(time.Now)
+}
+
+func newScramblingTracker(now func() time.Time) *ScramblingTracker {
+	return &ScramblingTracker{
+		m:    &sync.Mutex{},
+		now:  now,
+		pids: make(map[uint16]*ScramblingStatus),
+	}
+}
+
+// AddPacket updates the tracker with a new packet and returns whether the parity of its PID has changed
+func (t *ScramblingTracker) AddPacket(p *Packet) (parityChanged bool) {
+	// No payload
+	if !p.Header.HasPayload {
+		return
+	}
+
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get status
+	tsc := p.Header.TransportScramblingControl
+	s, ok := t.pids[p.Header.PID]
+	if !ok {
+		s = &ScramblingStatus{PID: p.Header.PID}
+		t.pids[p.Header.PID] = s
+	}
+
+	// Parity change
+	if isScramblingControlParity(s.Parity) && isScramblingControlParity(tsc) && s.Parity != tsc {
+		parityChanged = true
+		s.LastParityChange = t.now()
+		s.ParityChanges++
+	}
+
+	// Update
+	s.Parity = tsc
+	s.Scrambled = tsc != ScramblingControlNotScrambled
+	return
+}
+
+// isScramblingControlParity checks whether a transport scrambling control indicates a key parity
+func isScramblingControlParity(tsc uint8) bool {
+	return tsc == ScramblingControlScrambledWithEvenKey || tsc == ScramblingControlScrambledWithOddKey
+}
+
+// Status returns the scrambling status of a PID
+func (t *ScramblingTracker) Status(pid uint16) (s ScramblingStatus, ok bool) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get status
+	var v *ScramblingStatus
+	if v, ok = t.pids[pid]; ok {
+		s = *v
+	}
+	return
+}
+
+// Statuses returns the scrambling status of every PID, sorted by PID
+func (t *ScramblingTracker) Statuses() (ss []ScramblingStatus) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Loop through PIDs
+	for _, s := range t.pids {
+		ss = append(ss, *s)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].PID < ss[j].PID })
+	return
+}
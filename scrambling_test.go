@@ -0,0 +1,40 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScramblingTracker(t *testing.T) {
+	n := time.Unix(10, 0)
+	tr := newScramblingTracker(func() time.Time { return n })
+	p := func(pid uint16, tsc uint8) *Packet {
+		return &Packet{Header: &PacketHeader{HasPayload: true, PID: pid, TransportScramblingControl: tsc}}
+	}
+
+	// Clear
+	assert.False(t, tr.AddPacket(p(256, ScramblingControlNotScrambled)))
+	s, ok := tr.Status(256)
+	assert.True(t, ok)
+	assert.Equal(t, ScramblingStatus{PID: 256}, s)
+
+	// Scrambled
+	assert.False(t, tr.AddPacket(p(256, ScramblingControlScrambledWithEvenKey)))
+	assert.False(t, tr.AddPacket(p(257, ScramblingControlScrambledWithOddKey)))
+	assert.False(t, tr.AddPacket(&Packet{Header: &PacketHeader{PID: 256}}))
+	s, _ = tr.Status(256)
+	assert.Equal(t, ScramblingStatus{Parity: ScramblingControlScrambledWithEvenKey, PID: 256, Scrambled: true}, s)
+
+	// Parity change
+	assert.True(t, tr.AddPacket(p(256, ScramblingControlScrambledWithOddKey)))
+	assert.Equal(t, []ScramblingStatus{
+		{LastParityChange: n, Parity: ScramblingControlScrambledWithOddKey, ParityChanges: 1, PID: 256, Scrambled: true},
+		{Parity: ScramblingControlScrambledWithOddKey, PID: 257, Scrambled: true},
+	}, tr.Statuses())
+
+	// Unknown PID
+	_, ok = tr.Status(258)
+	assert.False(t, ok)
+}
@@ -0,0 +1,495 @@
+package astits
+
+import (
+	"fmt"
+)
+
+// SCTE-35 table ID
+const SCTE35TableID = 0xfc
+
+// Max size of a splice info section, whose section length is coded on 12 bits
+const scte35SectionMaxSize = 3 + 0xfff
+
+// SCTE-35 SAP types
+const (
+	SCTE35SAPType1           = 0x0
+	SCTE35SAPType2           = 0x1
+	SCTE35SAPType3           = 0x2
+	SCTE35SAPTypeUnspecified = 0x3
+)
+
+// SCTE-35 splice command types
+// Chapter: 9.6 | Link: https://www.scte.org/standards/library/catalog/scte-35-digital-program-insertion-cueing-message/
+const (
+	SCTE35SpliceCommandTypeSpliceInsert = 0x05
+	SCTE35SpliceCommandTypeSpliceNull   = 0x00
+	SCTE35SpliceCommandTypeTimeSignal   = 0x06
+)
+
+// SCTE-35 splice descriptor tags
+const (
+	SCTE35SpliceDescriptorTagAudio        = 0x04
+	SCTE35SpliceDescriptorTagAvail        = 0x00
+	SCTE35SpliceDescriptorTagDTMF         = 0x01
+	SCTE35SpliceDescriptorTagSegmentation = 0x02
+	SCTE35SpliceDescriptorTagTime         = 0x03
+)
+
+// SCTE-35 segmentation type IDs
+// Chapter: 10.3.3.1 | Link: https://www.scte.org/standards/library/catalog/scte-35-digital-program-insertion-cueing-message/
+const (
+	SCTE35SegmentationTypeBreakEnd                             = 0x23
+	SCTE35SegmentationTypeBreakStart                           = 0x22
+	SCTE35SegmentationTypeDistributorAdvertisementEnd          = 0x33
+	SCTE35SegmentationTypeDistributorAdvertisementStart        = 0x32
+	SCTE35SegmentationTypeDistributorPlacementOpportunityEnd   = 0x37
+	SCTE35SegmentationTypeDistributorPlacementOpportunityStart = 0x36
+	SCTE35SegmentationTypeProgramEnd                           = 0x11
+	SCTE35SegmentationTypeProgramStart                         = 0x10
+	SCTE35SegmentationTypeProviderAdvertisementEnd             = 0x31
+	SCTE35SegmentationTypeProviderAdvertisementStart           = 0x30
+	SCTE35SegmentationTypeProviderPlacementOpportunityEnd      = 0x35
+	SCTE35SegmentationTypeProviderPlacementOpportunityStart    = 0x34
+)
+
+// SCTE-35 segmentation UPID types
+const (
+	SCTE35SegmentationUPIDTypeADI      = 0x09
+	SCTE35SegmentationUPIDTypeISCI     = 0x01
+	SCTE35SegmentationUPIDTypeNotUsed  = 0x00
+	SCTE35SegmentationUPIDTypeTI       = 0x08
+	SCTE35SegmentationUPIDTypeURI      = 0x0f
+	SCTE35SegmentationUPIDTypeUserDefd = 0x0c // Deprecated but still in use
+)
+
+// SCTE35SpliceInfoSection represents an SCTE-35 splice info section, also known as a cue message
+// Encrypted sections are not supported.
+// Chapter: 9.6 | Link: https://www.scte.org/standards/library/catalog/scte-35-digital-program-insertion-cueing-message/
+type SCTE35SpliceInfoSection struct {
+	CWIndex         uint8
+	Descriptors     []*SCTE35SpliceDescriptor
+	PTSAdjustment   *ClockReference // Added to every PTS of the section, nil means 0
+	ProtocolVersion uint8
+	SAPType         uint8
+	SpliceInsert    *SCTE35SpliceInsert // Set when the splice command is a splice insert
+	Tier            uint16              // 12 bits, 0xfff means all tiers
+	TimeSignal      *SCTE35TimeSignal   // Set when the splice command is a time signal
+}
+
+// SCTE35SpliceInsert represents an SCTE-35 splice insert command
+type SCTE35SpliceInsert struct {
+	AutoReturn      bool
+	AvailNum        uint8
+	AvailsExpected  uint8
+	BreakDuration   *ClockReference                // nil means there's no break duration
+	Components      []*SCTE35SpliceInsertComponent // Component splice mode if not empty, program splice mode otherwise
+	EventID         uint32
+	IsCancel        bool
+	IsImmediate     bool
+	IsOutOfNetwork  bool
+	SpliceTime      *ClockReference // PTS of the splice point in program splice mode, nil meaning the time is not specified
+	UniqueProgramID uint16
+}
+
+// SCTE35SpliceInsertComponent represents a component of an SCTE-35 splice insert command in component splice mode
+type SCTE35SpliceInsertComponent struct {
+	SpliceTime *ClockReference // nil means the time is not specified
+	Tag        uint8
+}
+
+// SCTE35TimeSignal represents an SCTE-35 time signal command
+type SCTE35TimeSignal struct {
+	SpliceTime *ClockReference // nil means the time is not specified
+}
+
+// SCTE35SpliceDescriptor represents an SCTE-35 splice descriptor
+type SCTE35SpliceDescriptor struct {
+	Data         []byte // Bytes following the identifier, when the descriptor is not a segmentation descriptor
+	Identifier   uint32 // Usually RegistrationFormatIdentifierCUEI
+	Segmentation *SCTE35SegmentationDescriptor
+	Tag          uint8
+}
+
+// SCTE35SegmentationDescriptor represents an SCTE-35 segmentation descriptor
+// Chapter: 10.3.3 | Link: https://www.scte.org/standards/library/catalog/scte-35-digital-program-insertion-cueing-message/
+type SCTE35SegmentationDescriptor struct {
+	ArchiveAllowed        bool
+	Components            []*SCTE35SegmentationComponent // Component segmentation if not empty, program segmentation otherwise
+	DeliveryNotRestricted bool                           // When true, web delivery, regional blackout, archive and device restrictions are ignored
+	DeviceRestrictions    uint8
+	Duration              *ClockReference // nil means there's no segmentation duration
+	EventID               uint32
+	HasSubSegments        bool // Sub segment fields are written, which is expected for placement opportunity starts
+	IsCancel              bool
+	NoRegionalBlackout    bool
+	SegmentNum            uint8
+	SegmentsExpected      uint8
+	SubSegmentNum         uint8
+	SubSegmentsExpected   uint8
+	TypeID                uint8
+	UPID                  []byte
+	UPIDType              uint8
+	WebDeliveryAllowed    bool
+}
+
+// SCTE35SegmentationComponent represents a component of an SCTE-35 segmentation descriptor
+type SCTE35SegmentationComponent struct {
+	PTSOffset *ClockReference
+	Tag       uint8
+}
+
+// NewSCTE35TimeSignal creates a time signal section for the provided PTS, usually carrying segmentation descriptors
+func NewSCTE35TimeSignal(pts *ClockReference, ds ...*SCTE35SpliceDescriptor) *SCTE35SpliceInfoSection {
+	return &SCTE35SpliceInfoSection{
+		CWIndex:     0xff,
+		Descriptors: ds,
+		SAPType:     SCTE35SAPTypeUnspecified,
+		Tier:        0xfff,
+		TimeSignal:  &SCTE35TimeSignal{SpliceTime: pts},
+	}
+}
+
+// NewSCTE35SpliceInsert creates a program splice insert section
+// A nil PTS makes the splice immediate. A break duration can only be provided when splicing out of the network, in
+// which case the splice back in is automatic.
+func NewSCTE35SpliceInsert(eventID uint32, isOutOfNetwork bool, pts, breakDuration *ClockReference, ds ...*SCTE35SpliceDescriptor) *SCTE35SpliceInfoSection {
+	i := &SCTE35SpliceInsert{
+		EventID:        eventID,
+		IsImmediate:    pts == nil,
+		IsOutOfNetwork: isOutOfNetwork,
+		SpliceTime:     pts,
+	}
+	if isOutOfNetwork && breakDuration != nil {
+		i.AutoReturn = true
+		i.BreakDuration = breakDuration
+	}
+	return &SCTE35SpliceInfoSection{
+		CWIndex:      0xff,
+		Descriptors:  ds,
+		SAPType:      SCTE35SAPTypeUnspecified,
+		SpliceInsert: i,
+		Tier:         0xfff,
+	}
+}
+
+// NewSCTE35SegmentationDescriptor creates a program segmentation descriptor whose delivery is not restricted
+// A nil duration means there's no segmentation duration.
+func NewSCTE35SegmentationDescriptor(eventID uint32, typeID uint8, duration *ClockReference, upidType uint8, upid []byte) *SCTE35SpliceDescriptor {
+	return &SCTE35SpliceDescriptor{
+		Identifier: RegistrationFormatIdentifierCUEI,
+		Segmentation: &SCTE35SegmentationDescriptor{
+			DeliveryNotRestricted: true,
+			Duration:              duration,
+			EventID:               eventID,
+			HasSubSegments:        scte35SegmentationTypeHasSubSegments(typeID),
+			SegmentNum:            1,
+			SegmentsExpected:      1,
+			TypeID:                typeID,
+			UPID:                  upid,
+			UPIDType:              upidType,
+		},
+		Tag: SCTE35SpliceDescriptorTagSegmentation,
+	}
+}
+
+// scte35SegmentationTypeHasSubSegments checks whether segmentation descriptors of this type carry sub segment fields
+func scte35SegmentationTypeHasSubSegments(typeID uint8) bool {
+	return typeID == SCTE35SegmentationTypeProviderPlacementOpportunityStart ||
+		typeID == SCTE35SegmentationTypeDistributorPlacementOpportunityStart ||
+		typeID == 0x38 || // Provider promo start
+		typeID == 0x3a // Distributor promo start
+}
+
+// SpliceCommandType returns the type of the splice command of the section
+func (s *SCTE35SpliceInfoSection) SpliceCommandType() uint8 {
+	switch {
+	case s.SpliceInsert != nil:
+		return SCTE35SpliceCommandTypeSpliceInsert
+	case s.TimeSignal != nil:
+		return SCTE35SpliceCommandTypeTimeSignal
+	default:
+		return SCTE35SpliceCommandTypeSpliceNull
+	}
+}
+
+// Serialise serialises the splice info section, CRC32 included
+func (s *SCTE35SpliceInfoSection) Serialise(b []byte) (int, error) {
+	// Reserve table ID and section length
+	w := newCheckedWriter(b)
+	hb, err := w.next("section header", 3)
+	if err != nil {
+		return w.offset, err
+	}
+
+	// Protocol version
+	if err = w.writeUint8("protocol version", s.ProtocolVersion); err != nil {
+		return w.offset, err
+	}
+
+	// PTS adjustment, encryption is not supported
+	var ptsAdjustment int64
+	if s.PTSAdjustment != nil {
+		ptsAdjustment = s.PTSAdjustment.Base
+	}
+	if err = writeSCTE35Timestamp(w, "PTS adjustment", 0x0, ptsAdjustment); err != nil {
+		return w.offset, err
+	}
+
+	// CW index
+	if err = w.writeUint8("CW index", s.CWIndex); err != nil {
+		return w.offset, err
+	}
+
+	// Reserve tier and splice command length
+	cb, err := w.next("tier", 3)
+	if err != nil {
+		return w.offset, err
+	}
+
+	// Splice command
+	if err = w.writeUint8("splice command type", s.SpliceCommandType()); err != nil {
+		return w.offset, err
+	}
+	start := w.offset
+	switch {
+	case s.SpliceInsert != nil:
+		err = w.write("splice insert", s.SpliceInsert.serialise)
+	case s.TimeSignal != nil:
+		err = writeSCTE35SpliceTime(w, "time signal", s.TimeSignal.SpliceTime)
+	}
+	if err != nil {
+		return w.offset, err
+	}
+	l := w.offset - start
+	if l > 0xfff {
+		return w.offset, fmt.Errorf("astits: splice command length %d doesn't fit in 12 bits", l)
+	}
+	cb[0] = uint8(s.Tier >> 4)
+	cb[1] = uint8(s.Tier&0xf)<<4 | uint8(l>>8)
+	cb[2] = uint8(l)
+
+	// Descriptors
+	if err = w.writeLength("descriptor loop length", 16, 0, func() error {
+		for idx, d := range s.Descriptors {
+			if err := w.write(fmt.Sprintf("descriptor #%d", idx), d.Serialise); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return w.offset, err
+	}
+
+	// Section header
+	sl := w.offset - 3 + 4 // CRC32 included
+	if sl > 0xfff {
+		return w.offset, fmt.Errorf("astits: section length %d doesn't fit in 12 bits", sl)
+	}
+	hb[0] = SCTE35TableID
+	hb[1] = (s.SAPType&0x3)<<4 | uint8(sl>>8)
+	hb[2] = uint8(sl)
+
+	// CRC32
+	crc32, err := computeCRC32(b[:w.offset])
+	if err != nil {
+		return w.offset, fmt.Errorf("astits: computing CRC32 failed: %w", err)
+	}
+	if err = w.writeUint32("CRC32", crc32); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
+func (i *SCTE35SpliceInsert) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint32("splice event ID", i.EventID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("splice event cancel indicator", Btou8(i.IsCancel)<<7|0x7f); err != nil {
+		return w.offset, err
+	}
+	if i.IsCancel {
+		return w.offset, nil
+	}
+
+	// Flags
+	if err := w.writeUint8("flags", Btou8(i.IsOutOfNetwork)<<7|Btou8(len(i.Components) == 0)<<6|
+		Btou8(i.BreakDuration != nil)<<5|Btou8(i.IsImmediate)<<4|0xf); err != nil {
+		return w.offset, err
+	}
+
+	// Splice times
+	if len(i.Components) == 0 {
+		if !i.IsImmediate {
+			if err := writeSCTE35SpliceTime(w, "splice time", i.SpliceTime); err != nil {
+				return w.offset, err
+			}
+		}
+	} else {
+		if err := w.writeUint8("component count", uint8(len(i.Components))); err != nil {
+			return w.offset, err
+		}
+		for idx, c := range i.Components {
+			if err := w.writeUint8(fmt.Sprintf("component #%d tag", idx), c.Tag); err != nil {
+				return w.offset, err
+			}
+			if !i.IsImmediate {
+				if err := writeSCTE35SpliceTime(w, fmt.Sprintf("component #%d splice time", idx), c.SpliceTime); err != nil {
+					return w.offset, err
+				}
+			}
+		}
+	}
+
+	// Break duration
+	if i.BreakDuration != nil {
+		if err := writeSCTE35Timestamp(w, "break duration", Btou8(i.AutoReturn)<<7|0x7e, i.BreakDuration.Base); err != nil {
+			return w.offset, err
+		}
+	}
+
+	// Program
+	if err := w.writeUint16("unique program ID", i.UniqueProgramID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("avail num", i.AvailNum); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("avails expected", i.AvailsExpected); err != nil {
+		return w.offset, err
+	}
+	return w.offset, nil
+}
+
+// writeSCTE35SpliceTime writes a splice time, nil meaning the time is not specified
+func writeSCTE35SpliceTime(w *checkedWriter, field string, t *ClockReference) error {
+	if t == nil {
+		return w.writeUint8(field, 0x7f)
+	}
+	return writeSCTE35Timestamp(w, field, 0xfe, t.Base)
+}
+
+// writeSCTE35Timestamp writes a 33 bits timestamp preceded by 7 bits
+func writeSCTE35Timestamp(w *checkedWriter, field string, prefix uint8, v int64) error {
+	bs, err := w.next(field, 5)
+	if err != nil {
+		return err
+	}
+	bs[0] = prefix&0xfe | uint8(v>>32)&0x1
+	bs[1], bs[2] = U16toU8s(uint16(v >> 16))
+	bs[3], bs[4] = U16toU8s(uint16(v))
+	return nil
+}
+
+// Serialise serialises the splice descriptor
+func (d *SCTE35SpliceDescriptor) Serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint8("splice descriptor tag", d.Tag); err != nil {
+		return w.offset, err
+	}
+	lb, err := w.next("descriptor length", 1)
+	if err != nil {
+		return w.offset, err
+	}
+	start := w.offset
+	if err = w.writeUint32("identifier", d.Identifier); err != nil {
+		return w.offset, err
+	}
+	if d.Segmentation != nil {
+		err = w.write("segmentation descriptor", d.Segmentation.serialise)
+	} else {
+		err = w.writeBytes("descriptor content", d.Data)
+	}
+	if err != nil {
+		return w.offset, err
+	}
+	l := w.offset - start
+	if l > 0xff {
+		return w.offset, fmt.Errorf("astits: splice descriptor with tag %#x is too long", d.Tag)
+	}
+	lb[0] = uint8(l)
+	return w.offset, nil
+}
+
+func (d *SCTE35SegmentationDescriptor) serialise(b []byte) (int, error) {
+	w := newCheckedWriter(b)
+	if err := w.writeUint32("segmentation event ID", d.EventID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("segmentation event cancel indicator", Btou8(d.IsCancel)<<7|0x7f); err != nil {
+		return w.offset, err
+	}
+	if d.IsCancel {
+		return w.offset, nil
+	}
+
+	// Flags
+	f := Btou8(len(d.Components) == 0)<<7 | Btou8(d.Duration != nil)<<6 | Btou8(d.DeliveryNotRestricted)<<5
+	if d.DeliveryNotRestricted {
+		f |= 0x1f
+	} else {
+		f |= Btou8(d.WebDeliveryAllowed)<<4 | Btou8(d.NoRegionalBlackout)<<3 | Btou8(d.ArchiveAllowed)<<2 | d.DeviceRestrictions&0x3
+	}
+	if err := w.writeUint8("flags", f); err != nil {
+		return w.offset, err
+	}
+
+	// Components
+	if len(d.Components) > 0 {
+		if err := w.writeUint8("component count", uint8(len(d.Components))); err != nil {
+			return w.offset, err
+		}
+		for idx, c := range d.Components {
+			if err := w.writeUint8(fmt.Sprintf("component #%d tag", idx), c.Tag); err != nil {
+				return w.offset, err
+			}
+			var o int64
+			if c.PTSOffset != nil {
+				o = c.PTSOffset.Base
+			}
+			if err := writeSCTE35Timestamp(w, fmt.Sprintf("component #%d PTS offset", idx), 0xfe, o); err != nil {
+				return w.offset, err
+			}
+		}
+	}
+
+	// Duration
+	if d.Duration != nil {
+		bs, err := w.next("segmentation duration", 5)
+		if err != nil {
+			return w.offset, err
+		}
+		v := d.Duration.Base
+		bs[0] = uint8(v >> 32)
+		bs[1], bs[2] = U16toU8s(uint16(v >> 16))
+		bs[3], bs[4] = U16toU8s(uint16(v))
+	}
+
+	// UPID
+	if err := w.writeUint8("segmentation UPID type", d.UPIDType); err != nil {
+		return w.offset, err
+	}
+	if err := writeDescriptorLengthBytes(w, "segmentation UPID", d.UPID); err != nil {
+		return w.offset, err
+	}
+
+	// Segments
+	if err := w.writeUint8("segmentation type ID", d.TypeID); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("segment num", d.SegmentNum); err != nil {
+		return w.offset, err
+	}
+	if err := w.writeUint8("segments expected", d.SegmentsExpected); err != nil {
+		return w.offset, err
+	}
+	if d.HasSubSegments {
+		if err := w.writeUint8("sub segment num", d.SubSegmentNum); err != nil {
+			return w.offset, err
+		}
+		if err := w.writeUint8("sub segments expected", d.SubSegmentsExpected); err != nil {
+			return w.offset, err
+		}
+	}
+	return w.offset, nil
+}
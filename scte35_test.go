@@ -0,0 +1,63 @@
+package astits
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSCTE35SpliceInfoSectionSerialise(t *testing.T) {
+	// Time signal with a segmentation descriptor, from the SCTE-35 examples
+	s := NewSCTE35TimeSignal(&ClockReference{Base: 0x072bd0050}, &SCTE35SpliceDescriptor{
+		Identifier: RegistrationFormatIdentifierCUEI,
+		Segmentation: &SCTE35SegmentationDescriptor{
+			ArchiveAllowed:     true,
+			DeviceRestrictions: 3,
+			Duration:           &ClockReference{Base: 0x0001a599b0},
+			EventID:            0x4800008e,
+			NoRegionalBlackout: true,
+			SegmentNum:         2,
+			TypeID:             SCTE35SegmentationTypeProviderPlacementOpportunityStart,
+			UPID:               []byte{0x0, 0x0, 0x0, 0x0, 0x2c, 0xa0, 0xa1, 0x8a},
+			UPIDType:           SCTE35SegmentationUPIDTypeTI,
+		},
+		Tag: SCTE35SpliceDescriptorTagSegmentation,
+	})
+	assert.Equal(t, uint8(SCTE35SpliceCommandTypeTimeSignal), s.SpliceCommandType())
+	b := make([]byte, scte35SectionMaxSize)
+	n, err := s.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg==", base64.StdEncoding.EncodeToString(b[:n]))
+
+	// Splice insert with an avail descriptor, from the SCTE-35 examples
+	s = NewSCTE35SpliceInsert(0x4800008f, true, &ClockReference{Base: 0x07369c02e}, &ClockReference{Base: 0x00052ccf5}, &SCTE35SpliceDescriptor{
+		Data:       []byte{0x0, 0x0, 0x1, 0x35},
+		Identifier: RegistrationFormatIdentifierCUEI,
+		Tag:        SCTE35SpliceDescriptorTagAvail,
+	})
+	n, err = s.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=", base64.StdEncoding.EncodeToString(b[:n]))
+
+	// Cancel and CRC
+	s = NewSCTE35SpliceInsert(1, false, nil, nil)
+	s.SpliceInsert.IsCancel = true
+	n, err = s.Serialise(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 3+1+5+1+3+1+5+2+4, n)
+	crc32, err := computeCRC32(b[:n])
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), crc32)
+
+	// Builders
+	d := NewSCTE35SegmentationDescriptor(1, SCTE35SegmentationTypeProviderPlacementOpportunityStart, nil, SCTE35SegmentationUPIDTypeNotUsed, nil)
+	assert.True(t, d.Segmentation.HasSubSegments)
+	s = NewSCTE35SpliceInsert(1, false, nil, &ClockReference{Base: 1})
+	assert.True(t, s.SpliceInsert.IsImmediate)
+	assert.Nil(t, s.SpliceInsert.BreakDuration)
+
+	// No room
+	_, err = s.Serialise(make([]byte, 10))
+	assert.Error(t, err)
+}
@@ -0,0 +1,423 @@
+package astits
+
+import (
+	"sort"
+	"sync"
+)
+
+// ServiceKey represents the key identifying a service across networks and transport streams
+type ServiceKey struct {
+	OriginalNetworkID uint16
+	ServiceID         uint16
+	TransportStreamID uint16
+}
+
+// TransportStreamKey represents the key identifying a transport stream across networks
+type TransportStreamKey struct {
+	OriginalNetworkID uint16
+	TransportStreamID uint16
+}
+
+// ServiceDBNetwork represents a network of a service database, as described by a NIT
+type ServiceDBNetwork struct {
+	Descriptors []*Descriptor
+	Name        []byte // Out of the network name descriptor, if any
+	NetworkID   uint16
+}
+
+// ServiceDBTransportStream represents a transport stream of a service database, as described by a NIT
+type ServiceDBTransportStream struct {
+	Descriptors []*Descriptor // Delivery system descriptors, service lists, etc.
+	Key         TransportStreamKey
+	NetworkID   uint16
+}
+
+// ServiceDBService represents a service of a service database, as described by an SDT and the EITs
+type ServiceDBService struct {
+	Descriptors            []*Descriptor
+	Events                 []*EITDataEvent // Sorted by start time
+	HasEITPresentFollowing bool
+	HasEITSchedule         bool
+	HasFreeCSAMode         bool
+	Key                    ServiceKey
+	Name                   []byte // Out of the service descriptor, if any
+	ProviderName           []byte // Out of the service descriptor, if any
+	RunningStatus          uint8
+	Type                   uint8 // Out of the service descriptor, if any
+}
+
+// ServiceStatusChange represents a change of the running status or of the free CA mode of a service between two
+// versions of the SDT describing it
+type ServiceStatusChange struct {
+	HasFreeCSAMode         bool
+	Key                    ServiceKey
+	PreviousHasFreeCSAMode bool
+	PreviousRunningStatus  uint8
+	RunningStatus          uint8
+}
+
+// FreeCSAModeChanged checks whether the free CA mode has changed
+func (c ServiceStatusChange) FreeCSAModeChanged() bool {
+	return c.HasFreeCSAMode != c.PreviousHasFreeCSAMode
+}
+
+// RunningStatusChanged checks whether the running status has changed
+func (c ServiceStatusChange) RunningStatusChanged() bool {
+	return c.RunningStatus != c.PreviousRunningStatus
+}
+
+// EventUpdate represents an event that an EIT has described again with different values, such as a new start time
+// or a new text
+type EventUpdate struct {
+	Descriptors DescriptorChanges // Text changes show up as short and extended event descriptors changes
+	Key         ServiceKey
+	Next        *EITDataEvent
+	Previous    *EITDataEvent
+}
+
+// TimesChanged checks whether the start time or the duration has changed
+func (u EventUpdate) TimesChanged() bool {
+	return !u.Next.StartTime.Equal(u.Previous.StartTime) || u.Next.Duration != u.Previous.Duration
+}
+
+// newEventUpdate returns the update between two versions of an event, or nil if nothing has changed
+func newEventUpdate(k ServiceKey, prev, next *EITDataEvent) *EventUpdate {
+	u := &EventUpdate{
+		Descriptors: DiffDescriptors(prev.Descriptors, next.Descriptors),
+		Key:         k,
+		Next:        next,
+		Previous:    prev,
+	}
+	if u.Descriptors.IsEmpty() && !u.TimesChanged() && prev.RunningStatus == next.RunningStatus &&
+		prev.HasFreeCSAMode == next.HasFreeCSAMode {
+		return nil
+	}
+	return u
+}
+
+// ServiceDB aggregates the NITs, SDTs and EITs, both actual and other, into a database of the networks, transport
+// streams and services they describe, which is the data model needed for channel scans
+// Entries are created or replaced as tables are received and are never removed. RSTs update the running status of
+// the events.
+type ServiceDB struct {
+	actualTransportStreamID *uint16
+	events                  map[ServiceKey]map[uint16]*EITDataEvent // Indexed by service key and event ID
+	m                       *sync.Mutex
+	networks                map[uint16]*ServiceDBNetwork
+	optEventUpdateHandler   func(u EventUpdate)
+	optServiceStatusHandler func(c ServiceStatusChange)
+	services                map[ServiceKey]*ServiceDBService
+	transportStreams        map[TransportStreamKey]*ServiceDBTransportStream
+}
+
+// NewServiceDB creates a new service database
+func NewServiceDB(opts ...func(*ServiceDB)) (db *ServiceDB) {
+	// Create database
+	db = &ServiceDB{
+		events:           make(map[ServiceKey]map[uint16]*EITDataEvent),
+		m:                &sync.Mutex{},
+		networks:         make(map[uint16]*ServiceDBNetwork),
+		services:         make(map[ServiceKey]*ServiceDBService),
+		transportStreams: make(map[TransportStreamKey]*ServiceDBTransportStream),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(db)
+	}
+	return
+}
+
+// ServiceDBOptEventUpdateHandler returns the option to set the handler called whenever an EIT describes an event
+// already known, as identified by its service and its event ID, with different values
+// Events are deduplicated on their event ID either way, the handler is called once the database has been updated.
+func ServiceDBOptEventUpdateHandler(fn func(u EventUpdate)) func(*ServiceDB) {
+	return func(db *ServiceDB) {
+		db.optEventUpdateHandler = fn
+	}
+}
+
+// ServiceDBOptServiceStatusHandler returns the option to set the handler called whenever an SDT changes the running
+// status or the free CA mode of a service already known
+// The handler is called once the database has been updated and is not called for new services.
+func ServiceDBOptServiceStatusHandler(fn func(c ServiceStatusChange)) func(*ServiceDB) {
+	return func(db *ServiceDB) {
+		db.optServiceStatusHandler = fn
+	}
+}
+
+// AddData updates the database with a new data
+func (db *ServiceDB) AddData(d *Data) {
+	// Update database
+	cs, us := db.addData(d)
+
+	// Handle service status changes
+	if db.optServiceStatusHandler != nil {
+		for _, c := range cs {
+			db.optServiceStatusHandler(c)
+		}
+	}
+
+	// Handle event updates
+	if db.optEventUpdateHandler != nil {
+		for _, u := range us {
+			db.optEventUpdateHandler(u)
+		}
+	}
+}
+
+func (db *ServiceDB) addData(d *Data) (cs []ServiceStatusChange, us []EventUpdate) {
+	// Lock
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	// Switch on data
+	switch {
+	case d.EIT != nil:
+		us = db.addEIT(d.EIT)
+	case d.NIT != nil:
+		db.addNIT(d.NIT)
+	case d.PAT != nil:
+		id := d.PAT.TransportStreamID
+		db.actualTransportStreamID = &id
+	case d.RST != nil:
+		db.addRST(d.RST)
+	case d.SDT != nil:
+		cs = db.addSDT(d.SDT)
+	}
+	return
+}
+
+func (db *ServiceDB) addEIT(d *EITData) (us []EventUpdate) {
+	// Get events
+	k := ServiceKey{OriginalNetworkID: d.OriginalNetworkID, ServiceID: d.ServiceID, TransportStreamID: d.TransportStreamID}
+	es, ok := db.events[k]
+	if !ok {
+		es = make(map[uint16]*EITDataEvent)
+		db.events[k] = es
+	}
+
+	// Update events
+	for _, e := range d.Events {
+		if p, ok := es[e.EventID]; ok {
+			if u := newEventUpdate(k, p, e); u != nil {
+				us = append(us, *u)
+			}
+		}
+		es[e.EventID] = e
+	}
+
+	// Update service
+	if s, ok := db.services[k]; ok {
+		s.Events = sortedServiceDBEvents(es)
+	}
+	return
+}
+
+func (db *ServiceDB) addNIT(d *NITData) {
+	// Create network
+	n := &ServiceDBNetwork{
+		Descriptors: d.NetworkDescriptors,
+		NetworkID:   d.NetworkID,
+	}
+	for _, v := range d.NetworkDescriptors {
+		if v.NetworkName != nil {
+			n.Name = v.NetworkName.Name
+		}
+	}
+	db.networks[d.NetworkID] = n
+
+	// Loop through transport streams
+	for _, ts := range d.TransportStreams {
+		k := TransportStreamKey{OriginalNetworkID: ts.OriginalNetworkID, TransportStreamID: ts.TransportStreamID}
+		db.transportStreams[k] = &ServiceDBTransportStream{
+			Descriptors: ts.TransportDescriptors,
+			Key:         k,
+			NetworkID:   d.NetworkID,
+		}
+	}
+}
+
+// addRST updates the running status of the events already known, RSTs being sent as soon as a running status changes
+func (db *ServiceDB) addRST(d *RSTData) {
+	for _, v := range d.Events {
+		// Get event
+		k := ServiceKey{OriginalNetworkID: v.OriginalNetworkID, ServiceID: v.ServiceID, TransportStreamID: v.TransportStreamID}
+		e, ok := db.events[k][v.EventID]
+		if !ok || e.RunningStatus == v.RunningStatus {
+			continue
+		}
+
+		// Update event
+		// Events are copied since they belong to the EIT data that has been received
+		ne := *e
+		ne.RunningStatus = v.RunningStatus
+		db.events[k][v.EventID] = &ne
+
+		// Update service
+		if s, ok := db.services[k]; ok {
+			s.Events = sortedServiceDBEvents(db.events[k])
+		}
+	}
+}
+
+func (db *ServiceDB) addSDT(d *SDTData) (cs []ServiceStatusChange) {
+	for _, v := range d.Services {
+		// Create service
+		k := ServiceKey{OriginalNetworkID: d.OriginalNetworkID, ServiceID: v.ServiceID, TransportStreamID: d.TransportStreamID}
+		s := &ServiceDBService{
+			Descriptors:            v.Descriptors,
+			HasEITPresentFollowing: v.HasEITPresentFollowing,
+			HasEITSchedule:         v.HasEITSchedule,
+			HasFreeCSAMode:         v.HasFreeCSAMode,
+			Key:                    k,
+			RunningStatus:          v.RunningStatus,
+		}
+		for _, dsc := range v.Descriptors {
+			if dsc.Service != nil {
+				s.Name = dsc.Service.Name
+				s.ProviderName = dsc.Service.Provider
+				s.Type = dsc.Service.Type
+			}
+		}
+
+		// Status has changed
+		if p, ok := db.services[k]; ok && (p.RunningStatus != s.RunningStatus || p.HasFreeCSAMode != s.HasFreeCSAMode) {
+			cs = append(cs, ServiceStatusChange{
+				HasFreeCSAMode:         s.HasFreeCSAMode,
+				Key:                    k,
+				PreviousHasFreeCSAMode: p.HasFreeCSAMode,
+				PreviousRunningStatus:  p.RunningStatus,
+				RunningStatus:          s.RunningStatus,
+			})
+		}
+
+		// Events may have been received before the service
+		if es, ok := db.events[k]; ok {
+			s.Events = sortedServiceDBEvents(es)
+		}
+		db.services[k] = s
+	}
+	return
+}
+
+func sortedServiceDBEvents(m map[uint16]*EITDataEvent) (es []*EITDataEvent) {
+	for _, e := range m {
+		es = append(es, e)
+	}
+	sort.Slice(es, func(i, j int) bool {
+		if !es[i].StartTime.Equal(es[j].StartTime) {
+			return es[i].StartTime.Before(es[j].StartTime)
+		}
+		return es[i].EventID < es[j].EventID
+	})
+	return
+}
+
+// ActualTransportStreamID returns the ID of the transport stream being received, out of the last PAT
+func (db *ServiceDB) ActualTransportStreamID() (id uint16, ok bool) {
+	// Lock
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	// No PAT
+	if db.actualTransportStreamID == nil {
+		return
+	}
+	return *db.actualTransportStreamID, true
+}
+
+// Network returns a network
+func (db *ServiceDB) Network(networkID uint16) (n ServiceDBNetwork, ok bool) {
+	// Lock
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	// Get network
+	var v *ServiceDBNetwork
+	if v, ok = db.networks[networkID]; ok {
+		n = *v
+	}
+	return
+}
+
+// Networks returns all networks sorted by network ID
+func (db *ServiceDB) Networks() (ns []ServiceDBNetwork) {
+	// Lock
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	// Loop through networks
+	for _, n := range db.networks {
+		ns = append(ns, *n)
+	}
+	sort.Slice(ns, func(i, j int) bool { return ns[i].NetworkID < ns[j].NetworkID })
+	return
+}
+
+// Service returns a service
+func (db *ServiceDB) Service(k ServiceKey) (s ServiceDBService, ok bool) {
+	// Lock
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	// Get service
+	var v *ServiceDBService
+	if v, ok = db.services[k]; ok {
+		s = *v
+	}
+	return
+}
+
+// Services returns all services sorted by original network ID, transport stream ID and service ID
+func (db *ServiceDB) Services() (ss []ServiceDBService) {
+	// Lock
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	// Loop through services
+	for _, s := range db.services {
+		ss = append(ss, *s)
+	}
+	sort.Slice(ss, func(i, j int) bool { return serviceKeyLess(ss[i].Key, ss[j].Key) })
+	return
+}
+
+// TransportStreamServices returns the services of a transport stream sorted by service ID
+func (db *ServiceDB) TransportStreamServices(k TransportStreamKey) (ss []ServiceDBService) {
+	for _, s := range db.Services() {
+		if s.Key.OriginalNetworkID == k.OriginalNetworkID && s.Key.TransportStreamID == k.TransportStreamID {
+			ss = append(ss, s)
+		}
+	}
+	return
+}
+
+// TransportStreams returns all transport streams sorted by original network ID and transport stream ID
+func (db *ServiceDB) TransportStreams() (ts []ServiceDBTransportStream) {
+	// Lock
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	// Loop through transport streams
+	for _, t := range db.transportStreams {
+		ts = append(ts, *t)
+	}
+	sort.Slice(ts, func(i, j int) bool {
+		if ts[i].Key.OriginalNetworkID != ts[j].Key.OriginalNetworkID {
+			return ts[i].Key.OriginalNetworkID < ts[j].Key.OriginalNetworkID
+		}
+		return ts[i].Key.TransportStreamID < ts[j].Key.TransportStreamID
+	})
+	return
+}
+
+func serviceKeyLess(a, b ServiceKey) bool {
+	if a.OriginalNetworkID != b.OriginalNetworkID {
+		return a.OriginalNetworkID < b.OriginalNetworkID
+	}
+	if a.TransportStreamID != b.TransportStreamID {
+		return a.TransportStreamID < b.TransportStreamID
+	}
+	return a.ServiceID < b.ServiceID
+}
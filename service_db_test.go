@@ -0,0 +1,160 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceDB(t *testing.T) {
+	db := NewServiceDB()
+	_, ok := db.ActualTransportStreamID()
+	assert.False(t, ok)
+
+	// PAT
+	db.AddData(&Data{PAT: &PATData{TransportStreamID: 2}})
+	id, ok := db.ActualTransportStreamID()
+	assert.True(t, ok)
+	assert.Equal(t, uint16(2), id)
+
+	// NIT
+	db.AddData(&Data{NIT: &NITData{
+		NetworkDescriptors: []*Descriptor{{NetworkName: &DescriptorNetworkName{Name: []byte("network")}}},
+		NetworkID:          1,
+		TransportStreams: []*NITDataTransportStream{
+			{OriginalNetworkID: 3, TransportStreamID: 4},
+			{OriginalNetworkID: 3, TransportStreamID: 2},
+		},
+	}})
+	n, ok := db.Network(1)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("network"), n.Name)
+	assert.Equal(t, []ServiceDBTransportStream{
+		{Key: TransportStreamKey{OriginalNetworkID: 3, TransportStreamID: 2}, NetworkID: 1},
+		{Key: TransportStreamKey{OriginalNetworkID: 3, TransportStreamID: 4}, NetworkID: 1},
+	}, db.TransportStreams())
+
+	// EIT received before the SDT
+	e1 := &EITDataEvent{EventID: 1, StartTime: time.Unix(20, 0)}
+	e2 := &EITDataEvent{EventID: 2, StartTime: time.Unix(10, 0)}
+	db.AddData(&Data{EIT: &EITData{Events: []*EITDataEvent{e1}, OriginalNetworkID: 3, ServiceID: 5, TransportStreamID: 4}})
+
+	// SDT actual and other
+	db.AddData(&Data{SDT: &SDTData{
+		OriginalNetworkID: 3,
+		Services: []*SDTDataService{{
+			Descriptors:   []*Descriptor{{Service: &DescriptorService{Name: []byte("name"), Provider: []byte("provider"), Type: ServiceTypeDigitalTelevisionService}}},
+			RunningStatus: RunningStatusRunning,
+			ServiceID:     5,
+		}},
+		TransportStreamID: 4,
+	}})
+	db.AddData(&Data{SDT: &SDTData{OriginalNetworkID: 3, Services: []*SDTDataService{{ServiceID: 6}}, TransportStreamID: 2}})
+	db.AddData(&Data{EIT: &EITData{Events: []*EITDataEvent{e2}, OriginalNetworkID: 3, ServiceID: 5, TransportStreamID: 4}})
+	ss := db.Services()
+	assert.Len(t, ss, 2)
+	assert.Equal(t, ServiceKey{OriginalNetworkID: 3, ServiceID: 6, TransportStreamID: 2}, ss[0].Key)
+	s, ok := db.Service(ServiceKey{OriginalNetworkID: 3, ServiceID: 5, TransportStreamID: 4})
+	assert.True(t, ok)
+	assert.Equal(t, []byte("name"), s.Name)
+	assert.Equal(t, []byte("provider"), s.ProviderName)
+	assert.Equal(t, uint8(ServiceTypeDigitalTelevisionService), s.Type)
+	assert.Equal(t, uint8(RunningStatusRunning), s.RunningStatus)
+	assert.Equal(t, []*EITDataEvent{e2, e1}, s.Events)
+	assert.Equal(t, []ServiceDBService{s}, db.TransportStreamServices(TransportStreamKey{OriginalNetworkID: 3, TransportStreamID: 4}))
+
+	// RST
+	db.AddData(&Data{RST: &RSTData{Events: []*RSTDataEvent{
+		{EventID: 1, OriginalNetworkID: 3, RunningStatus: RunningStatusRunning, ServiceID: 5, TransportStreamID: 4},
+		{EventID: 3, OriginalNetworkID: 3, RunningStatus: RunningStatusRunning, ServiceID: 5, TransportStreamID: 4},
+	}}})
+	s, _ = db.Service(ServiceKey{OriginalNetworkID: 3, ServiceID: 5, TransportStreamID: 4})
+	assert.Len(t, s.Events, 2)
+	assert.Equal(t, uint8(RunningStatusRunning), s.Events[1].RunningStatus)
+	assert.Equal(t, uint8(RunningStatusUndefined), e1.RunningStatus)
+}
+
+func TestServiceDBServiceStatusChanges(t *testing.T) {
+	// Create database
+	var cs []ServiceStatusChange
+	db := NewServiceDB(ServiceDBOptServiceStatusHandler(func(c ServiceStatusChange) { cs = append(cs, c) }))
+	sdt := func(runningStatus uint8, freeCSAMode bool) *Data {
+		return &Data{SDT: &SDTData{
+			OriginalNetworkID: 1,
+			Services: []*SDTDataService{
+				{HasFreeCSAMode: freeCSAMode, RunningStatus: runningStatus, ServiceID: 3},
+				{RunningStatus: RunningStatusRunning, ServiceID: 4},
+			},
+			TransportStreamID: 2,
+		}}
+	}
+	k := ServiceKey{OriginalNetworkID: 1, ServiceID: 3, TransportStreamID: 2}
+
+	// New services
+	db.AddData(sdt(RunningStatusNotRunning, false))
+	assert.Len(t, cs, 0)
+
+	// Same version
+	db.AddData(sdt(RunningStatusNotRunning, false))
+	assert.Len(t, cs, 0)
+
+	// Running status
+	db.AddData(sdt(RunningStatusRunning, false))
+	assert.Equal(t, []ServiceStatusChange{{
+		Key:                   k,
+		PreviousRunningStatus: RunningStatusNotRunning,
+		RunningStatus:         RunningStatusRunning,
+	}}, cs)
+	assert.True(t, cs[0].RunningStatusChanged())
+	assert.False(t, cs[0].FreeCSAModeChanged())
+
+	// Free CA mode
+	cs = nil
+	db.AddData(sdt(RunningStatusRunning, true))
+	assert.Equal(t, []ServiceStatusChange{{
+		HasFreeCSAMode:        true,
+		Key:                   k,
+		PreviousRunningStatus: RunningStatusRunning,
+		RunningStatus:         RunningStatusRunning,
+	}}, cs)
+	assert.False(t, cs[0].RunningStatusChanged())
+	assert.True(t, cs[0].FreeCSAModeChanged())
+	s, ok := db.Service(k)
+	assert.True(t, ok)
+	assert.True(t, s.HasFreeCSAMode)
+}
+
+func TestServiceDBEventUpdates(t *testing.T) {
+	// Create database
+	var us []EventUpdate
+	db := NewServiceDB(ServiceDBOptEventUpdateHandler(func(u EventUpdate) { us = append(us, u) }))
+	eit := func(es ...*EITDataEvent) *Data {
+		return &Data{EIT: &EITData{Events: es, OriginalNetworkID: 1, ServiceID: 3, TransportStreamID: 2}}
+	}
+	k := ServiceKey{OriginalNetworkID: 1, ServiceID: 3, TransportStreamID: 2}
+	e1 := &EITDataEvent{Duration: time.Minute, EventID: 1, StartTime: time.Unix(10, 0)}
+	e2 := &EITDataEvent{Duration: time.Minute, EventID: 2, StartTime: time.Unix(70, 0)}
+
+	// New events
+	db.AddData(eit(e1, e2))
+	assert.Len(t, us, 0)
+
+	// Same events
+	db.AddData(eit(&EITDataEvent{Duration: time.Minute, EventID: 1, StartTime: time.Unix(10, 0)}, e2))
+	assert.Len(t, us, 0)
+
+	// Times
+	ne1 := &EITDataEvent{Duration: 2 * time.Minute, EventID: 1, StartTime: time.Unix(10, 0)}
+	db.AddData(eit(ne1))
+	assert.Equal(t, []EventUpdate{{Key: k, Next: ne1, Previous: e1}}, us)
+	assert.True(t, us[0].TimesChanged())
+
+	// Text
+	us = nil
+	d := NewShortEventDescriptor("eng", "name", "text")
+	ne2 := &EITDataEvent{Descriptors: []*Descriptor{d}, Duration: time.Minute, EventID: 2, StartTime: time.Unix(70, 0)}
+	db.AddData(eit(ne2))
+	assert.Equal(t, []EventUpdate{{Descriptors: DescriptorChanges{Added: []*Descriptor{d}}, Key: k, Next: ne2, Previous: e2}}, us)
+	assert.False(t, us[0].TimesChanged())
+}
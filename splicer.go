@@ -0,0 +1,426 @@
+package astits
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Default output program of the splicer
+const (
+	splicerDefaultPMTPID        = muxDefaultPMTPIDStart
+	splicerDefaultProgramNumber = 1
+)
+
+// SplicerOptions represents splicer options
+type SplicerOptions struct {
+	// When true, the discontinuity indicator is set on the first packet of each PID following a splice, signaling the
+	// time base change to decoders. When that packet has no room for an adaptation field, the end of its payload is
+	// moved into an additional packet.
+	DiscontinuityIndicator bool
+	PMTPID                 uint16 // PMT PID of the output program, defaults to 0x1000
+	ProgramNumber          uint16 // Number of the output program, defaults to 1
+	TransportStreamID      uint16
+}
+
+// SplicePoint represents the point at which an input becomes the output of the splicer
+// When PCR is set, the splice happens on the first packet of the input whose PCR is at or after it. When PTS is set,
+// the splice happens on the first packet of the PCR PID of the input starting a PES whose PTS is at or after it. When
+// neither is set, the splice happens on the next elementary stream packet of the input.
+type SplicePoint struct {
+	PCR *ClockReference
+	PTS *ClockReference
+}
+
+// Splicer switches its output from one input program to another at transport level
+// Output PAT and PMT are regenerated out of the active input's PMT, with version numbers bumped whenever their
+// content changes, and are emitted whenever the active input sends its own as well as right after a splice.
+// Elementary stream PIDs are left untouched but continuity counters are rewritten so that they are continuous across
+// splices. Once the splice point is reached, each PID of the input is output starting with its first packet starting a
+// payload unit, so that no PES is output truncated.
+type Splicer struct {
+	ccs           map[uint16]uint8 // Next output continuity counter per PID
+	current       *SplicerInput
+	discontinuity map[uint16]bool // PIDs waiting for the discontinuity indicator
+	m             *sync.Mutex
+	next          *SplicerInput
+	nextPoint     SplicePoint
+	o             SplicerOptions
+	pending       map[uint16]bool // PIDs waiting for their first payload unit start
+	versioner     *psiVersioner
+}
+
+// SplicerInput represents an input program of a splicer
+type SplicerInput struct {
+	pmt           *PMTData
+	pmtPID        uint16
+	programNumber uint16
+	psi           map[uint16][]byte // PSI payloads being gathered, indexed by PID
+	s             *Splicer
+}
+
+// NewSplicer creates a new splicer
+func NewSplicer(o SplicerOptions) *Splicer {
+	if o.PMTPID == 0 {
+		o.PMTPID = splicerDefaultPMTPID
+	}
+	if o.ProgramNumber == 0 {
+		o.ProgramNumber = splicerDefaultProgramNumber
+	}
+	return &Splicer{
+		ccs:           make(map[uint16]uint8),
+		discontinuity: make(map[uint16]bool),
+		m:             &sync.Mutex{},
+		o:             o,
+		pending:       make(map[uint16]bool),
+		versioner:     newPSIVersioner(),
+	}
+}
+
+// NewInput creates a new input reading the program with the provided number
+// If programNumber is 0, the first program of the input's PAT is read.
+func (s *Splicer) NewInput(programNumber uint16) *SplicerInput {
+	return &SplicerInput{
+		programNumber: programNumber,
+		psi:           make(map[uint16][]byte),
+		s:             s,
+	}
+}
+
+// Splice schedules the switch of the output to an input
+// It replaces any splice that hasn't happened yet.
+func (s *Splicer) Splice(in *SplicerInput, p SplicePoint) {
+	// Lock
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// Schedule
+	s.next = in
+	s.nextPoint = p
+}
+
+// Current returns the input currently being output, if any
+func (s *Splicer) Current() *SplicerInput {
+	// Lock
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.current
+}
+
+// AddPacket adds a packet read from the input and returns the packets to output, if any
+func (in *SplicerInput) AddPacket(p *Packet) (ps []*Packet, err error) {
+	// Lock
+	in.s.m.Lock()
+	defer in.s.m.Unlock()
+
+	// PAT
+	if p.Header.PID == PIDPAT {
+		var d *PSIData
+		if d, err = in.psiData(p); err != nil {
+			err = fmt.Errorf("astits: parsing PAT failed: %w", err)
+			return
+		} else if d == nil {
+			return
+		}
+		for _, sc := range d.Sections {
+			if sc.Syntax == nil || sc.Syntax.Data == nil || sc.Syntax.Data.PAT == nil {
+				continue
+			}
+			for _, pg := range sc.Syntax.Data.PAT.Programs {
+				if pg.ProgramNumber > 0 && (in.programNumber == 0 || in.programNumber == pg.ProgramNumber) {
+					in.pmtPID = pg.ProgramMapID
+					break
+				}
+			}
+		}
+
+		// Replace PAT
+		if in.s.current == in && in.pmt != nil {
+			ps, err = in.s.patPackets()
+		}
+		return
+	}
+
+	// PMT
+	if in.pmtPID > 0 && p.Header.PID == in.pmtPID {
+		var d *PSIData
+		if d, err = in.psiData(p); err != nil {
+			err = fmt.Errorf("astits: parsing PMT failed: %w", err)
+			return
+		} else if d == nil {
+			return
+		}
+		for _, sc := range d.Sections {
+			if sc.Syntax == nil || sc.Syntax.Data == nil || sc.Syntax.Data.PMT == nil {
+				continue
+			}
+			if in.programNumber == 0 || in.programNumber == sc.Syntax.Data.PMT.ProgramNumber {
+				in.pmt = sc.Syntax.Data.PMT
+			}
+		}
+
+		// Replace PMT
+		if in.s.current == in && in.pmt != nil {
+			ps, err = in.s.pmtPackets()
+		}
+		return
+	}
+
+	// Not an elementary stream of the program
+	if !in.isProgramPID(p.Header.PID) {
+		return
+	}
+
+	// Splice
+	if in.s.next == in && in.reached(p) {
+		// Discontinuity indicator
+		if in.s.o.DiscontinuityIndicator && in.s.current != nil {
+			for _, pid := range in.pids() {
+				in.s.discontinuity[pid] = true
+			}
+		}
+
+		// Each PID waits for its first payload unit start
+		in.s.pending = make(map[uint16]bool)
+		for _, pid := range in.pids() {
+			in.s.pending[pid] = true
+		}
+
+		// Switch
+		in.s.current = in
+		in.s.next = nil
+		in.s.nextPoint = SplicePoint{}
+
+		// Emit PAT and PMT
+		var pat, pmt []*Packet
+		if pat, err = in.s.patPackets(); err != nil {
+			err = fmt.Errorf("astits: creating PAT packets failed: %w", err)
+			return
+		}
+		if pmt, err = in.s.pmtPackets(); err != nil {
+			err = fmt.Errorf("astits: creating PMT packets failed: %w", err)
+			return
+		}
+		ps = append(pat, pmt...)
+	}
+
+	// Not the current input
+	if in.s.current != in {
+		return
+	}
+
+	// PID is waiting for its first payload unit start, packets without payload can't truncate anything though
+	if in.s.pending[p.Header.PID] && p.Header.HasPayload {
+		if !p.Header.PayloadUnitStartIndicator {
+			return
+		}
+		delete(in.s.pending, p.Header.PID)
+	}
+
+	// Output packet
+	ps = append(ps, in.s.output(p)...)
+	return
+}
+
+// psiData gathers the PSI payloads of a PID and returns the PSI data once its first section is complete
+func (in *SplicerInput) psiData(p *Packet) (d *PSIData, err error) {
+	// Gather payload
+	b, ok := in.psi[p.Header.PID]
+	if p.Header.PayloadUnitStartIndicator {
+		b = append([]byte{}, p.Payload...)
+	} else if ok {
+		b = append(b, p.Payload...)
+	} else {
+		return
+	}
+	in.psi[p.Header.PID] = b
+
+	// First section is not complete
+	if len(b) == 0 {
+		return
+	}
+	o := 1 + int(b[0])
+	if len(b) < o+3 || len(b) < o+3+int(uint16(b[o+1]&0xf)<<8|uint16(b[o+2])) {
+		return
+	}
+
+	// Parse
+	delete(in.psi, p.Header.PID)
+	return parsePSIData(astikit.NewBytesIterator(b), ProfileAuto)
+}
+
+// isProgramPID checks whether a PID is an elementary stream or the PCR PID of the input's program
+func (in *SplicerInput) isProgramPID(pid uint16) bool {
+	if in.pmt == nil {
+		return false
+	}
+	for _, v := range in.pids() {
+		if v == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// pids returns the elementary stream and PCR PIDs of the input's program
+func (in *SplicerInput) pids() (pids []uint16) {
+	if in.pmt == nil {
+		return
+	}
+	pids = append(pids, in.pmt.PCRPID)
+	for _, es := range in.pmt.ElementaryStreams {
+		if es.ElementaryPID != in.pmt.PCRPID {
+			pids = append(pids, es.ElementaryPID)
+		}
+	}
+	return
+}
+
+// reached checks whether a packet reaches the scheduled splice point
+func (in *SplicerInput) reached(p *Packet) bool {
+	sp := in.s.nextPoint
+	switch {
+	case sp.PCR != nil:
+		return p.AdaptationField != nil && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil &&
+			clockReferenceTicksDiff(*p.AdaptationField.PCR, *sp.PCR) >= 0
+	case sp.PTS != nil:
+		if p.Header.PID != in.pmt.PCRPID {
+			return false
+		}
+		pts := temiPacketPTS(p)
+		return pts != nil && clockReferenceBaseDiff(pts.Base, sp.PTS.Base) >= 0
+	}
+	return true
+}
+
+// clockReferenceTicksDiff returns a - b in 27 MHz units, taking the 33 bits wrap around into account
+func clockReferenceTicksDiff(a, b ClockReference) int64 {
+	return clockReferenceBaseDiff(a.Base, b.Base)*300 + a.Extension - b.Extension
+}
+
+// output rewrites the continuity counter and discontinuity indicator of a packet and returns the packets to output
+func (s *Splicer) output(p *Packet) (ps []*Packet) {
+	// Copy packet
+	o := *p
+	h := *p.Header
+	o.Header = &h
+	if p.AdaptationField != nil {
+		a := *p.AdaptationField
+		o.AdaptationField = &a
+	}
+	ps = []*Packet{&o}
+
+	// Discontinuity indicator, scrambled payloads can't be split and wait for a packet with room for it
+	if s.discontinuity[h.PID] {
+		if o.Header.HasAdaptationField && o.AdaptationField != nil && o.AdaptationField.Length > 0 {
+			o.AdaptationField.DiscontinuityIndicator = true
+			delete(s.discontinuity, h.PID)
+		} else if len(o.Payload) <= muxPacketMaxPayloadSize-2 || h.TransportScramblingControl == 0 {
+			// Move the end of the payload into an additional packet when there's no room for the adaptation field
+			if len(o.Payload) > muxPacketMaxPayloadSize-2 {
+				n := &Packet{
+					AdaptationField: &PacketAdaptationField{},
+					Header: &PacketHeader{
+						HasAdaptationField: true,
+						HasPayload:         true,
+						PID:                h.PID,
+						TransportPriority:  h.TransportPriority,
+					},
+					Payload: o.Payload[muxPacketMaxPayloadSize-2:],
+				}
+				n.AdaptationField.Length = muxPacketMaxPayloadSize - len(n.Payload) - 1
+				o.Payload = o.Payload[:muxPacketMaxPayloadSize-2]
+				ps = append(ps, n)
+			}
+			o.AdaptationField = &PacketAdaptationField{
+				DiscontinuityIndicator: true,
+				Length:                 muxPacketMaxPayloadSize - len(o.Payload) - 1,
+			}
+			o.Header.HasAdaptationField = true
+			delete(s.discontinuity, h.PID)
+		}
+	}
+
+	// Continuity counters
+	for _, v := range ps {
+		cc := s.ccs[h.PID]
+		v.Header.ContinuityCounter = cc
+		if v.Header.HasPayload {
+			s.ccs[h.PID] = (cc + 1) & 0xf
+		}
+	}
+	return
+}
+
+// program returns the output program
+func (s *Splicer) program() (p MuxProgram, err error) {
+	// No PMT
+	if s.current == nil || s.current.pmt == nil {
+		err = errors.New("astits: no PMT")
+		return
+	}
+
+	// Create program
+	p = MuxProgram{
+		Descriptors: s.current.pmt.ProgramDescriptors,
+		Number:      s.o.ProgramNumber,
+		PCRPID:      s.current.pmt.PCRPID,
+		PMTPID:      s.o.PMTPID,
+	}
+	for _, es := range s.current.pmt.ElementaryStreams {
+		p.Streams = append(p.Streams, MuxStream{
+			Descriptors: es.ElementaryStreamDescriptors,
+			PID:         es.ElementaryPID,
+			StreamType:  es.StreamType,
+		})
+	}
+	return
+}
+
+// patPackets returns the packets of the output PAT
+func (s *Splicer) patPackets() (ps []*Packet, err error) {
+	// Get program
+	var p MuxProgram
+	if p, err = s.program(); err != nil {
+		err = fmt.Errorf("astits: getting program failed: %w", err)
+		return
+	}
+
+	// Create sections
+	var ss []*PSISection
+	if ss, err = newMuxPATSections(s.o.TransportStreamID, []MuxProgram{p}, 0); err != nil {
+		err = fmt.Errorf("astits: creating PAT sections failed: %w", err)
+		return
+	}
+	return s.psiPackets(PIDPAT, ss)
+}
+
+// pmtPackets returns the packets of the output PMT
+func (s *Splicer) pmtPackets() (ps []*Packet, err error) {
+	// Get program
+	var p MuxProgram
+	if p, err = s.program(); err != nil {
+		err = fmt.Errorf("astits: getting program failed: %w", err)
+		return
+	}
+	return s.psiPackets(s.o.PMTPID, []*PSISection{newMuxPMTSection(p)})
+}
+
+// psiPackets versions PSI sections and splits them into packets
+func (s *Splicer) psiPackets(pid uint16, ss []*PSISection) (ps []*Packet, err error) {
+	// Version
+	if _, err = s.versioner.updateSections(ss); err != nil {
+		err = fmt.Errorf("astits: updating PSI version failed: %w", err)
+		return
+	}
+
+	// Create packets
+	if ps, err = newMuxPSIPackets(pid, ss, s.ccs[pid], StuffingByte); err != nil {
+		err = fmt.Errorf("astits: creating PSI packets failed: %w", err)
+		return
+	}
+	s.ccs[pid] = (s.ccs[pid] + uint8(len(ps))) & 0xf
+	return
+}
@@ -0,0 +1,130 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astikit"
+	"github.com/stretchr/testify/assert"
+)
+
+func splicerInputPSIPackets(t *testing.T, pmtPID uint16, p MuxProgram) []*Packet {
+	p.PMTPID = pmtPID
+	ss, err := newMuxPATSections(1, []MuxProgram{p}, 0)
+	assert.NoError(t, err)
+	ss = append(ss, newMuxPMTSection(p))
+	pat, err := newMuxPSIPackets(PIDPAT, ss[:1], 0, StuffingByte)
+	assert.NoError(t, err)
+	pmt, err := newMuxPSIPackets(pmtPID, ss[1:], 0, StuffingByte)
+	assert.NoError(t, err)
+	return append(pat, pmt...)
+}
+
+func splicerPCRPacket(pid uint16, base int64) *Packet {
+	return &Packet{
+		AdaptationField: &PacketAdaptationField{HasPCR: true, Length: 7, PCR: &ClockReference{Base: base}},
+		Header:          &PacketHeader{ContinuityCounter: 7, HasAdaptationField: true, HasPayload: true, PayloadUnitStartIndicator: true, PID: pid},
+		Payload:         make([]byte, 176),
+	}
+}
+
+func splicerPSIVersion(t *testing.T, p *Packet) uint8 {
+	d, err := parsePSIData(astikit.NewBytesIterator(p.Payload), ProfileAuto)
+	assert.NoError(t, err)
+	return d.Sections[0].Syntax.Header.VersionNumber
+}
+
+func TestSplicer(t *testing.T) {
+	s := NewSplicer(SplicerOptions{DiscontinuityIndicator: true, TransportStreamID: 2})
+	a := s.NewInput(0)
+	b := s.NewInput(3)
+
+	// Input A
+	s.Splice(a, SplicePoint{})
+	var ps []*Packet
+	for _, p := range splicerInputPSIPackets(t, 0x20, MuxProgram{Number: 5, Streams: []MuxStream{{PID: 256, StreamType: StreamTypeH264Video}}}) {
+		o, err := a.AddPacket(p)
+		assert.NoError(t, err)
+		ps = append(ps, o...)
+	}
+	assert.Empty(t, ps)
+	ps, err := a.AddPacket(splicerPCRPacket(256, 100))
+	assert.NoError(t, err)
+	assert.Len(t, ps, 3)
+	assert.Equal(t, a, s.Current())
+	assert.Equal(t, uint16(PIDPAT), ps[0].Header.PID)
+	assert.Equal(t, uint16(0x1000), ps[1].Header.PID)
+	assert.Equal(t, uint8(0), splicerPSIVersion(t, ps[1]))
+	assert.Equal(t, uint8(0), ps[2].Header.ContinuityCounter)
+	assert.False(t, ps[2].AdaptationField.DiscontinuityIndicator)
+
+	// Input A PSI is replaced
+	ps, err = a.AddPacket(splicerInputPSIPackets(t, 0x20, MuxProgram{Number: 5, Streams: []MuxStream{{PID: 256, StreamType: StreamTypeH264Video}}})[0])
+	assert.NoError(t, err)
+	assert.Len(t, ps, 1)
+	assert.Equal(t, uint8(1), ps[0].Header.ContinuityCounter)
+
+	// Input B
+	for _, p := range splicerInputPSIPackets(t, 0x30, MuxProgram{Number: 3, Streams: []MuxStream{{PID: 256, StreamType: StreamTypeH265Video}, {PID: 257, StreamType: StreamTypeAudioADTS}}}) {
+		ps, err = b.AddPacket(p)
+		assert.NoError(t, err)
+		assert.Empty(t, ps)
+	}
+	s.Splice(b, SplicePoint{PCR: &ClockReference{Base: 1000}})
+	ps, err = b.AddPacket(splicerPCRPacket(256, 900))
+	assert.NoError(t, err)
+	assert.Empty(t, ps)
+	ps, err = a.AddPacket(splicerPCRPacket(256, 200))
+	assert.NoError(t, err)
+	assert.Len(t, ps, 1)
+	assert.Equal(t, uint8(1), ps[0].Header.ContinuityCounter)
+
+	// Splice
+	ps, err = b.AddPacket(splicerPCRPacket(256, 1000))
+	assert.NoError(t, err)
+	assert.Len(t, ps, 3)
+	assert.Equal(t, b, s.Current())
+	assert.Equal(t, uint8(2), ps[0].Header.ContinuityCounter)
+	assert.Equal(t, uint8(0), splicerPSIVersion(t, ps[0]))
+	assert.Equal(t, uint8(1), ps[1].Header.ContinuityCounter)
+	assert.Equal(t, uint8(1), splicerPSIVersion(t, ps[1]))
+	assert.Equal(t, uint8(2), ps[2].Header.ContinuityCounter)
+	assert.True(t, ps[2].AdaptationField.DiscontinuityIndicator)
+
+	// Input A is dropped
+	ps, err = a.AddPacket(splicerPCRPacket(256, 300))
+	assert.NoError(t, err)
+	assert.Empty(t, ps)
+
+	// PID waits for its first payload unit start
+	ps, err = b.AddPacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: 257}, Payload: make([]byte, 100)})
+	assert.NoError(t, err)
+	assert.Empty(t, ps)
+
+	// Discontinuity indicator is added
+	ps, err = b.AddPacket(&Packet{Header: &PacketHeader{HasPayload: true, PayloadUnitStartIndicator: true, PID: 257}, Payload: make([]byte, 100)})
+	assert.NoError(t, err)
+	assert.Len(t, ps, 1)
+	assert.True(t, ps[0].AdaptationField.DiscontinuityIndicator)
+	buf := make([]byte, MpegTsPacketSize)
+	_, err = ps[0].Serialise(buf)
+	assert.NoError(t, err)
+
+	// Payload is moved into an additional packet to make room for the discontinuity indicator
+	s.Splice(a, SplicePoint{})
+	pl := make([]byte, muxPacketMaxPayloadSize)
+	pl[muxPacketMaxPayloadSize-1] = 1
+	ps, err = a.AddPacket(&Packet{Header: &PacketHeader{HasPayload: true, PayloadUnitStartIndicator: true, PID: 256}, Payload: pl})
+	assert.NoError(t, err)
+	assert.Len(t, ps, 4)
+	assert.Equal(t, a, s.Current())
+	assert.True(t, ps[2].Header.PayloadUnitStartIndicator)
+	assert.True(t, ps[2].AdaptationField.DiscontinuityIndicator)
+	assert.Equal(t, uint8(3), ps[2].Header.ContinuityCounter)
+	assert.False(t, ps[3].Header.PayloadUnitStartIndicator)
+	assert.Equal(t, uint8(4), ps[3].Header.ContinuityCounter)
+	assert.Equal(t, []byte{0, 1}, ps[3].Payload)
+	for _, p := range ps[2:] {
+		_, err = p.Serialise(buf)
+		assert.NoError(t, err)
+	}
+}
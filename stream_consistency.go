@@ -0,0 +1,236 @@
+package astits
+
+import (
+	"sync"
+)
+
+// Stream inconsistency kinds
+const (
+	StreamInconsistencyKindPayload      = "payload"      // The PES payload is in a format the stream type doesn't carry
+	StreamInconsistencyKindRegistration = "registration" // A registration descriptor contradicts the stream type
+	StreamInconsistencyKindStreamID     = "stream id"    // The PES stream ID doesn't match the stream type
+)
+
+// PES payload formats detected out of the first bytes of the PES data
+const (
+	PESPayloadFormatAC3       = "AC-3"
+	PESPayloadFormatADTS      = "AAC ADTS"
+	PESPayloadFormatMPEGAudio = "MPEG audio"
+	PESPayloadFormatUnknown   = ""
+	PESPayloadFormatVideo     = "video start code"
+	pesPayloadFormatMinLength = 4
+)
+
+// PES stream ID ranges
+const (
+	pesStreamIDAudioFirst      = 0xc0
+	pesStreamIDAudioLast       = 0xdf
+	pesStreamIDVideoFirst      = 0xe0
+	pesStreamIDVideoLast       = 0xef
+	pesStreamIDExtendedStreams = 0xfd
+)
+
+// Stream types registration format identifiers are compatible with
+var streamConsistencyRegistrations = map[uint32][]uint8{
+	RegistrationFormatIdentifierAC3:  {StreamTypeMPEG2PacketizedData, StreamTypeBluRayAndATSCDolbyDigitalAC3Max6ChannelAudio},
+	RegistrationFormatIdentifierCUEI: {StreamTypeBluRaySCTE35OrDTS8ChannelAudio},
+	RegistrationFormatIdentifierEAC3: {StreamTypeMPEG2PacketizedData, StreamTypeATSCDoblyDigitalPlusAC3Max16ChannelAudio, StreamTypeBluRayDoblyDigitalPlusAC3Max16ChannelAudio},
+	RegistrationFormatIdentifierID3:  {StreamTypeMPEG2PacketizedData, StreamTypePacketisedMetadata},
+	RegistrationFormatIdentifierKLVA: {StreamTypeMPEG2PacketizedData, StreamTypePacketisedMetadata},
+}
+
+// StreamInconsistency represents a mismatch between the way an elementary stream is declared in the PMT and what it
+// actually carries, which usually denotes an encoder misconfiguration
+type StreamInconsistency struct {
+	Detected   string // Payload format or registration format identifier, depending on the kind
+	Kind       string
+	PID        uint16
+	StreamID   uint8 // Only set for payload and stream ID inconsistencies
+	StreamType uint8
+}
+
+// StreamConsistencyChecker checks that PMT stream types, registration descriptors and the PES actually received are
+// consistent, for instance that a stream declared as H.264 doesn't carry AAC ADTS
+// Each inconsistency is reported once per elementary stream declaration so that it doesn't flood the callback.
+type StreamConsistencyChecker struct {
+	fn              func(i StreamInconsistency)
+	inconsistencies map[uint16]int // Indexed by PID
+	m               *sync.Mutex
+	reported        map[uint16]map[string]bool      // Indexed by PID and kind
+	streams         map[uint16]*PMTElementaryStream // Indexed by PID
+}
+
+// NewStreamConsistencyChecker creates a new stream consistency checker
+// If fn is not nil, it is called for every inconsistency
+func NewStreamConsistencyChecker(fn func(i StreamInconsistency)) *StreamConsistencyChecker {
+	return &StreamConsistencyChecker{
+		fn:              fn,
+		inconsistencies: make(map[uint16]int),
+		m:               &sync.Mutex{},
+		reported:        make(map[uint16]map[string]bool),
+		streams:         make(map[uint16]*PMTElementaryStream),
+	}
+}
+
+// AddData updates the checker with a new data and returns the inconsistencies it triggered
+func (c *StreamConsistencyChecker) AddData(d *Data) (is []StreamInconsistency) {
+	// Lock
+	c.m.Lock()
+
+	// Switch on data
+	switch {
+	case d.PMT != nil:
+		for _, es := range d.PMT.ElementaryStreams {
+			// Declaration has changed
+			if s, ok := c.streams[es.ElementaryPID]; !ok || s.StreamType != es.StreamType {
+				delete(c.reported, es.ElementaryPID)
+			}
+			c.streams[es.ElementaryPID] = es
+
+			// Check registration
+			if r := es.Registration(); r != nil {
+				if ts, ok := streamConsistencyRegistrations[r.FormatIdentifier]; ok && !streamConsistencyContains(ts, es.StreamType) {
+					is = c.report(is, StreamInconsistency{
+						Detected:   string([]byte{byte(r.FormatIdentifier >> 24), byte(r.FormatIdentifier >> 16), byte(r.FormatIdentifier >> 8), byte(r.FormatIdentifier)}),
+						Kind:       StreamInconsistencyKindRegistration,
+						PID:        es.ElementaryPID,
+						StreamType: es.StreamType,
+					})
+				}
+			}
+		}
+	case d.PES != nil && d.PES.Header != nil:
+		// Stream is not declared
+		es, ok := c.streams[d.PID]
+		if !ok {
+			break
+		}
+
+		// Check stream ID
+		if !streamConsistencyStreamIDMatches(es, d.PES.Header.StreamID) {
+			is = c.report(is, StreamInconsistency{
+				Kind:       StreamInconsistencyKindStreamID,
+				PID:        d.PID,
+				StreamID:   d.PES.Header.StreamID,
+				StreamType: es.StreamType,
+			})
+		}
+
+		// Check payload
+		if e := expectedPESPayloadFormat(es); e != PESPayloadFormatUnknown {
+			if f := DetectPESPayloadFormat(d.PES.Data); f != PESPayloadFormatUnknown && f != e {
+				is = c.report(is, StreamInconsistency{
+					Detected:   f,
+					Kind:       StreamInconsistencyKindPayload,
+					PID:        d.PID,
+					StreamID:   d.PES.Header.StreamID,
+					StreamType: es.StreamType,
+				})
+			}
+		}
+	}
+
+	// Unlock
+	c.m.Unlock()
+
+	// Callback
+	if c.fn != nil {
+		for _, i := range is {
+			c.fn(i)
+		}
+	}
+	return
+}
+
+// report appends the inconsistency if it hasn't been reported yet for the PID
+func (c *StreamConsistencyChecker) report(is []StreamInconsistency, i StreamInconsistency) []StreamInconsistency {
+	if _, ok := c.reported[i.PID]; !ok {
+		c.reported[i.PID] = make(map[string]bool)
+	}
+	if c.reported[i.PID][i.Kind] {
+		return is
+	}
+	c.reported[i.PID][i.Kind] = true
+	c.inconsistencies[i.PID]++
+	return append(is, i)
+}
+
+// Inconsistencies returns the number of inconsistencies reported for a PID
+func (c *StreamConsistencyChecker) Inconsistencies(pid uint16) int {
+	// Lock
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.inconsistencies[pid]
+}
+
+// DetectPESPayloadFormat detects the format of a PES payload out of its first bytes
+// Detection is only reliable when the PES is aligned on access units, therefore an unknown format shouldn't be
+// considered as an error.
+func DetectPESPayloadFormat(b []byte) string {
+	// Not enough bytes
+	if len(b) < pesPayloadFormatMinLength {
+		return PESPayloadFormatUnknown
+	}
+
+	// Switch on sync words
+	switch {
+	case b[0] == 0x0 && b[1] == 0x0 && (b[2] == 0x1 || (b[2] == 0x0 && b[3] == 0x1)):
+		return PESPayloadFormatVideo
+	case b[0] == 0x0b && b[1] == 0x77:
+		return PESPayloadFormatAC3
+	case b[0] == 0xff && b[1]&0xf6 == 0xf0:
+		// Layer is 0
+		return PESPayloadFormatADTS
+	case b[0] == 0xff && b[1]&0xe0 == 0xe0 && b[1]&0x6 != 0x0:
+		return PESPayloadFormatMPEGAudio
+	}
+	return PESPayloadFormatUnknown
+}
+
+// expectedPESPayloadFormat returns the payload format the stream type carries, if it can be detected
+func expectedPESPayloadFormat(es *PMTElementaryStream) string {
+	switch es.StreamType {
+	case StreamTypeMPEG1Video,
+		StreamTypeMPEG2HighRateInterlacedVideo,
+		StreamTypeH264Video,
+		StreamTypeH265Video:
+		return PESPayloadFormatVideo
+	case StreamTypeMPEG1Audio,
+		StreamTypeMPEG2HalvedSampleRateAudio:
+		return PESPayloadFormatMPEGAudio
+	case StreamTypeAudioADTS:
+		return PESPayloadFormatADTS
+	case StreamTypeBluRayAndATSCDolbyDigitalAC3Max6ChannelAudio,
+		StreamTypeATSCDoblyDigitalPlusAC3Max16ChannelAudio:
+		return PESPayloadFormatAC3
+	case StreamTypeMPEG2PacketizedData:
+		if d := es.FindDescriptor(DescriptorTagAC3); d != nil {
+			return PESPayloadFormatAC3
+		}
+		if d := es.FindDescriptor(DescriptorTagEnhancedAC3); d != nil {
+			return PESPayloadFormatAC3
+		}
+	}
+	return PESPayloadFormatUnknown
+}
+
+// streamConsistencyStreamIDMatches checks whether the PES stream ID is one the stream type is carried with
+// Only MPEG video and audio stream types are checked, private stream types being carried with various stream IDs.
+func streamConsistencyStreamIDMatches(es *PMTElementaryStream, streamID uint8) bool {
+	switch expectedPESPayloadFormat(es) {
+	case PESPayloadFormatVideo:
+		return (streamID >= pesStreamIDVideoFirst && streamID <= pesStreamIDVideoLast) || streamID == pesStreamIDExtendedStreams
+	case PESPayloadFormatADTS, PESPayloadFormatMPEGAudio:
+		return (streamID >= pesStreamIDAudioFirst && streamID <= pesStreamIDAudioLast) || streamID == pesStreamIDExtendedStreams
+	}
+	return true
+}
+
+func streamConsistencyContains(ts []uint8, t uint8) bool {
+	for _, v := range ts {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamConsistencyChecker(t *testing.T) {
+	var cis []StreamInconsistency
+	c := NewStreamConsistencyChecker(func(i StreamInconsistency) { cis = append(cis, i) })
+
+	// PES of an undeclared stream
+	assert.Empty(t, c.AddData(&Data{PES: &PESData{Data: []byte{0xff, 0xf1, 0x50, 0x80}, Header: &PESHeader{StreamID: 0xc0}}, PID: 256}))
+
+	// PMT
+	is := c.AddData(&Data{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{
+		{ElementaryPID: 256, StreamType: StreamTypeH264Video},
+		{ElementaryPID: 257, StreamType: StreamTypeAudioADTS},
+		{
+			ElementaryPID:               258,
+			ElementaryStreamDescriptors: []*Descriptor{{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierCUEI}, Tag: DescriptorTagRegistration}},
+			StreamType:                  StreamTypeH264Video,
+		},
+	}}, PID: 4096})
+	assert.Equal(t, []StreamInconsistency{{Detected: "CUEI", Kind: StreamInconsistencyKindRegistration, PID: 258, StreamType: StreamTypeH264Video}}, is)
+
+	// Consistent PES
+	assert.Empty(t, c.AddData(&Data{PES: &PESData{Data: []byte{0x0, 0x0, 0x0, 0x1, 0x9}, Header: &PESHeader{StreamID: 0xe0}}, PID: 256}))
+	assert.Empty(t, c.AddData(&Data{PES: &PESData{Data: []byte{0xff, 0xf1, 0x50, 0x80}, Header: &PESHeader{StreamID: 0xc0}}, PID: 257}))
+
+	// ADTS payload with an audio stream ID on a video stream
+	is = c.AddData(&Data{PES: &PESData{Data: []byte{0xff, 0xf1, 0x50, 0x80}, Header: &PESHeader{StreamID: 0xc0}}, PID: 256})
+	assert.Equal(t, []StreamInconsistency{
+		{Kind: StreamInconsistencyKindStreamID, PID: 256, StreamID: 0xc0, StreamType: StreamTypeH264Video},
+		{Detected: PESPayloadFormatADTS, Kind: StreamInconsistencyKindPayload, PID: 256, StreamID: 0xc0, StreamType: StreamTypeH264Video},
+	}, is)
+
+	// Inconsistencies are only reported once
+	assert.Empty(t, c.AddData(&Data{PES: &PESData{Data: []byte{0xff, 0xf1, 0x50, 0x80}, Header: &PESHeader{StreamID: 0xc0}}, PID: 256}))
+	assert.Empty(t, c.AddData(&Data{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{
+		{
+			ElementaryPID:               258,
+			ElementaryStreamDescriptors: []*Descriptor{{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierCUEI}, Tag: DescriptorTagRegistration}},
+			StreamType:                  StreamTypeH264Video,
+		},
+	}}, PID: 4096}))
+	assert.Equal(t, 2, c.Inconsistencies(256))
+	assert.Equal(t, 0, c.Inconsistencies(257))
+	assert.Equal(t, 1, c.Inconsistencies(258))
+	assert.Len(t, cis, 3)
+
+	// Declaration change
+	is = c.AddData(&Data{PMT: &PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 256, StreamType: StreamTypeMPEG1Video}}}, PID: 4096})
+	assert.Empty(t, is)
+	is = c.AddData(&Data{PES: &PESData{Data: []byte{0x0b, 0x77, 0x0, 0x0}, Header: &PESHeader{StreamID: 0xe0}}, PID: 256})
+	assert.Equal(t, []StreamInconsistency{{Detected: PESPayloadFormatAC3, Kind: StreamInconsistencyKindPayload, PID: 256, StreamID: 0xe0, StreamType: StreamTypeMPEG1Video}}, is)
+	assert.Equal(t, 3, c.Inconsistencies(256))
+}
+
+func TestDetectPESPayloadFormat(t *testing.T) {
+	for _, v := range []struct {
+		b []byte
+		f string
+	}{
+		{b: []byte{0x0, 0x0, 0x1}, f: PESPayloadFormatUnknown},
+		{b: []byte{0x0, 0x0, 0x1, 0xb3}, f: PESPayloadFormatVideo},
+		{b: []byte{0x0, 0x0, 0x0, 0x1}, f: PESPayloadFormatVideo},
+		{b: []byte{0x0b, 0x77, 0x1, 0x2}, f: PESPayloadFormatAC3},
+		{b: []byte{0xff, 0xf1, 0x50, 0x80}, f: PESPayloadFormatADTS},
+		{b: []byte{0xff, 0xf9, 0x50, 0x80}, f: PESPayloadFormatADTS},
+		{b: []byte{0xff, 0xfb, 0x90, 0x64}, f: PESPayloadFormatMPEGAudio},
+		{b: []byte{0x1, 0x2, 0x3, 0x4}, f: PESPayloadFormatUnknown},
+	} {
+		assert.Equal(t, v.f, DetectPESPayloadFormat(v.b))
+	}
+}
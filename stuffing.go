@@ -0,0 +1,60 @@
+package astits
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StuffingByte is the stuffing byte mandated by ISO/IEC 13818-1 for adaptation fields and PSI payloads
+const StuffingByte = 0xff
+
+// Errors
+var (
+	ErrNonStandardStuffing = errors.New("astits: non standard stuffing")
+)
+
+// StuffingWarning represents a PSI payload whose last section is followed by non standard stuffing bytes
+type StuffingWarning struct {
+	Byte   uint8
+	Length int // Number of stuffing bytes
+	PID    uint16
+}
+
+// StuffingPolicy defines the non standard stuffing bytes recognised when parsing PSI payloads
+// Some legacy muxes stuff PSI payloads with 0x00 instead of 0xff, which would otherwise be read as the header of a
+// PAT section. Bytes following the last section are only considered as stuffing when they are all equal to one of
+// the recognised bytes.
+type StuffingPolicy struct {
+	Bytes          []uint8 // Recognised stuffing bytes, 0xff always being recognised
+	Strict         bool    // When true, parsing fails with ErrNonStandardStuffing instead of calling the warning handler
+	WarningHandler func(w StuffingWarning)
+}
+
+// bytes returns the recognised non standard stuffing bytes
+func (p *StuffingPolicy) bytes() []uint8 {
+	if p == nil {
+		return nil
+	}
+	return p.Bytes
+}
+
+// handle reports non standard stuffing according to the policy
+func (p *StuffingPolicy) handle(w StuffingWarning) error {
+	if p.Strict {
+		return fmt.Errorf("%w: %d bytes of 0x%x on PID %d", ErrNonStandardStuffing, w.Length, w.Byte, w.PID)
+	}
+	if p.WarningHandler != nil {
+		p.WarningHandler(w)
+	}
+	return nil
+}
+
+// isStuffing checks whether bs are all equal to b
+func isStuffing(bs []byte, b uint8) bool {
+	for _, v := range bs {
+		if v != b {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package astits
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDataStuffingPolicy(t *testing.T) {
+	// Legacy stuffing
+	b := catPSIBytes(t)
+	b = append(b, make([]byte, 184-len(b))...)
+	ps := []*Packet{{Header: &PacketHeader{PID: PIDCAT}, Payload: b}}
+
+	// Warning
+	var ws []StuffingWarning
+	ds, err := parseData(ps, nil, NewProgramMap(), ProfileAuto, nil, nil, &StuffingPolicy{
+		Bytes:          []uint8{0x0},
+		WarningHandler: func(w StuffingWarning) { ws = append(ws, w) },
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ds, 1)
+	assert.NotNil(t, ds[0].CAT)
+	assert.Equal(t, []StuffingWarning{{Byte: 0x0, Length: 184 - len(catPSIBytes(t)), PID: PIDCAT}}, ws)
+
+	// Standard stuffing
+	ws = nil
+	for idx := len(catPSIBytes(t)); idx < len(b); idx++ {
+		b[idx] = StuffingByte
+	}
+	_, err = parseData(ps, nil, NewProgramMap(), ProfileAuto, nil, nil, &StuffingPolicy{
+		Bytes:          []uint8{0x0},
+		WarningHandler: func(w StuffingWarning) { ws = append(ws, w) },
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ws, 0)
+
+	// Strict
+	ps[0].Payload = append(catPSIBytes(t), 0x0, 0x0, 0x0)
+	_, err = parseData(ps, nil, NewProgramMap(), ProfileAuto, nil, nil, &StuffingPolicy{Bytes: []uint8{0x0}, Strict: true})
+	assert.True(t, errors.Is(err, ErrNonStandardStuffing))
+}
+
+func TestMuxStuffingByte(t *testing.T) {
+	// PSI
+	ps := newMuxSectionPackets(0x100, []byte{0x0, 0x1}, 0, 0x0)
+	assert.Len(t, ps, 1)
+	assert.Equal(t, make([]byte, muxPacketMaxPayloadSize-2), ps[0].Payload[2:])
+
+	// Adaptation field
+	b := make([]byte, MpegTsPacketSize)
+	n, err := (&Packet{
+		AdaptationField: &PacketAdaptationField{},
+		Header:          &PacketHeader{HasAdaptationField: true, HasPayload: true},
+		Payload:         []byte{0x1},
+	}).serialise(b, 0x0)
+	assert.NoError(t, err)
+	assert.Equal(t, MpegTsPacketSize-1, n)
+	assert.Equal(t, make([]byte, MpegTsPacketSize-7), b[6:n])
+}
@@ -0,0 +1,216 @@
+package astits
+
+import (
+	"sort"
+	"sync"
+)
+
+// SubtableKey represents the key identifying a PSI sub-table
+// Sections sharing a table ID, a table ID extension and, for EITs and SDTs, the IDs carried in their body, belong to
+// the same sub-table, which is why several PMTs sharing a PID or the EITs of several services are distinct sub-tables.
+// Page: 16 | Chapter: 3.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type SubtableKey struct {
+	OriginalNetworkID uint16 // EIT and SDT only
+	PID               uint16
+	TableID           int
+	TableIDExtension  uint16
+	TransportStreamID uint16 // EIT only, the table ID extension being the transport stream ID in SDTs
+}
+
+// SubtableUpdate represents the state of a sub-table after one of its sections has been received
+type SubtableUpdate struct {
+	Complete      bool    // Every section of the version has been received
+	Completed     bool    // The section has completed the sub-table
+	Data          []*Data // Data received for the version, sorted by section number
+	Key           SubtableKey
+	NewVersion    bool // The section is the first one received for the version
+	VersionNumber uint8
+}
+
+// SubtableTracker keeps track of the version and the sections received of every PSI sub-table, so that sub-tables
+// sharing a PID are handled independently and consumers know when a version has been fully received
+// Sections that are not applicable yet, as signaled by their current/next indicator, are ignored.
+type SubtableTracker struct {
+	m         *sync.Mutex
+	subtables map[SubtableKey]*subtable
+}
+
+type subtable struct {
+	data              map[uint8]*Data // Indexed by section number
+	lastSectionNumber uint8
+	version           uint8
+}
+
+// NewSubtableTracker creates a new sub-table tracker
+func NewSubtableTracker() *SubtableTracker {
+	return &SubtableTracker{
+		m:         &sync.Mutex{},
+		subtables: make(map[SubtableKey]*subtable),
+	}
+}
+
+// AddData updates the tracker with a new data
+// ok is false if the data doesn't belong to a sub-table, which is the case of PES and of sections without syntax
+// header.
+func (t *SubtableTracker) AddData(d *Data) (u SubtableUpdate, ok bool) {
+	// Get key
+	var k SubtableKey
+	if k, ok = subtableKey(d); !ok {
+		return
+	}
+
+	// Section is not applicable yet
+	h := d.Section.Syntax.Header
+	if !h.CurrentNextIndicator {
+		ok = false
+		return
+	}
+
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get sub-table
+	s, exists := t.subtables[k]
+	if !exists || s.version != h.VersionNumber {
+		s = &subtable{
+			data:    make(map[uint8]*Data),
+			version: h.VersionNumber,
+		}
+		t.subtables[k] = s
+		u.NewVersion = true
+	}
+
+	// Add section
+	wasComplete := s.complete()
+	s.data[h.SectionNumber] = d
+	s.lastSectionNumber = h.LastSectionNumber
+
+	// Create update
+	u.Complete = s.complete()
+	u.Completed = u.Complete && !wasComplete
+	u.Data = s.sortedData()
+	u.Key = k
+	u.VersionNumber = s.version
+	return
+}
+
+// Subtable returns the state of a sub-table
+func (t *SubtableTracker) Subtable(k SubtableKey) (u SubtableUpdate, ok bool) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get sub-table
+	var s *subtable
+	if s, ok = t.subtables[k]; !ok {
+		return
+	}
+	u = SubtableUpdate{
+		Complete:      s.complete(),
+		Data:          s.sortedData(),
+		Key:           k,
+		VersionNumber: s.version,
+	}
+	return
+}
+
+// Keys returns the keys of the sub-tables received on a PID, sorted by table ID and table ID extension
+func (t *SubtableTracker) Keys(pid uint16) (ks []SubtableKey) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Loop through sub-tables
+	for k := range t.subtables {
+		if k.PID == pid {
+			ks = append(ks, k)
+		}
+	}
+
+	// Sort
+	sort.Slice(ks, func(i, j int) bool {
+		if ks[i].TableID != ks[j].TableID {
+			return ks[i].TableID < ks[j].TableID
+		}
+		if ks[i].TableIDExtension != ks[j].TableIDExtension {
+			return ks[i].TableIDExtension < ks[j].TableIDExtension
+		}
+		if ks[i].OriginalNetworkID != ks[j].OriginalNetworkID {
+			return ks[i].OriginalNetworkID < ks[j].OriginalNetworkID
+		}
+		return ks[i].TransportStreamID < ks[j].TransportStreamID
+	})
+	return
+}
+
+// subtableKey returns the key of the sub-table the data belongs to
+func subtableKey(d *Data) (k SubtableKey, ok bool) {
+	// Section has no syntax header
+	if d.Section == nil || d.Section.Header == nil || d.Section.Syntax == nil || d.Section.Syntax.Header == nil {
+		return
+	}
+
+	// Create key
+	k = SubtableKey{
+		PID:              d.PID,
+		TableID:          d.Section.Header.TableID,
+		TableIDExtension: d.Section.Syntax.Header.TableIDExtension,
+	}
+
+	// Add IDs carried in the body
+	switch {
+	case d.EIT != nil:
+		k.OriginalNetworkID = d.EIT.OriginalNetworkID
+		k.TransportStreamID = d.EIT.TransportStreamID
+	case d.SDT != nil:
+		k.OriginalNetworkID = d.SDT.OriginalNetworkID
+	}
+	ok = true
+	return
+}
+
+// complete checks whether every section of the sub-table has been received
+// EIT sections are grouped in segments of 8 sections which may not be full, the sections after the last section of a
+// segment are therefore not expected.
+func (s *subtable) complete() bool {
+	for n := 0; n <= int(s.lastSectionNumber); n++ {
+		// Section has been received
+		if _, ok := s.data[uint8(n)]; ok {
+			continue
+		}
+
+		// Section is beyond the last section of its segment
+		if !s.isBeyondEITSegment(uint8(n)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *subtable) isBeyondEITSegment(n uint8) bool {
+	// Only the sections of the segment know where it ends
+	first := n - n%eitSegmentSize
+	for idx := int(first); idx < int(first)+eitSegmentSize; idx++ {
+		d, ok := s.data[uint8(idx)]
+		if !ok || d.EIT == nil {
+			continue
+		}
+		if _, last := d.EIT.SegmentSectionNumbers(uint8(idx)); n > last {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subtable) sortedData() (ds []*Data) {
+	var ns []int
+	for n := range s.data {
+		ns = append(ns, int(n))
+	}
+	sort.Ints(ns)
+	for _, n := range ns {
+		ds = append(ds, s.data[uint8(n)])
+	}
+	return
+}
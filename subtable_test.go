@@ -0,0 +1,94 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func subtableData(pid uint16, tableID int, tableIDExtension uint16, version, section, lastSection uint8) *Data {
+	return &Data{
+		PID: pid,
+		Section: &PSISection{
+			Header: &PSISectionHeader{TableID: tableID},
+			Syntax: &PSISectionSyntax{Header: &PSISectionSyntaxHeader{
+				CurrentNextIndicator: true,
+				LastSectionNumber:    lastSection,
+				SectionNumber:        section,
+				TableIDExtension:     tableIDExtension,
+				VersionNumber:        version,
+			}},
+		},
+	}
+}
+
+func TestSubtableTracker(t *testing.T) {
+	tr := NewSubtableTracker()
+
+	// No section
+	_, ok := tr.AddData(&Data{PES: &PESData{}, PID: 256})
+	assert.False(t, ok)
+	_, ok = tr.AddData(&Data{PID: PIDTDT, Section: &PSISection{Header: &PSISectionHeader{TableID: 0x70}}, TDT: &TDTData{}})
+	assert.False(t, ok)
+
+	// Not applicable yet
+	d := subtableData(4096, 2, 1, 0, 0, 0)
+	d.Section.Syntax.Header.CurrentNextIndicator = false
+	_, ok = tr.AddData(d)
+	assert.False(t, ok)
+
+	// Two PMTs sharing a PID
+	d1 := subtableData(4096, 2, 1, 0, 0, 0)
+	u, ok := tr.AddData(d1)
+	assert.True(t, ok)
+	assert.Equal(t, SubtableUpdate{Complete: true, Completed: true, Data: []*Data{d1}, Key: SubtableKey{PID: 4096, TableID: 2, TableIDExtension: 1}, NewVersion: true}, u)
+	d2 := subtableData(4096, 2, 2, 5, 0, 0)
+	u, ok = tr.AddData(d2)
+	assert.True(t, ok)
+	assert.True(t, u.Completed)
+	assert.True(t, u.NewVersion)
+	assert.Equal(t, uint8(5), u.VersionNumber)
+	assert.Equal(t, []SubtableKey{{PID: 4096, TableID: 2, TableIDExtension: 1}, {PID: 4096, TableID: 2, TableIDExtension: 2}}, tr.Keys(4096))
+
+	// Repetition
+	u, _ = tr.AddData(subtableData(4096, 2, 1, 0, 0, 0))
+	assert.True(t, u.Complete)
+	assert.False(t, u.Completed)
+	assert.False(t, u.NewVersion)
+
+	// New version spanning several sections
+	u, _ = tr.AddData(subtableData(4096, 2, 1, 1, 1, 1))
+	assert.False(t, u.Complete)
+	assert.True(t, u.NewVersion)
+	u, _ = tr.AddData(subtableData(4096, 2, 1, 1, 0, 1))
+	assert.True(t, u.Completed)
+	assert.Len(t, u.Data, 2)
+	assert.Equal(t, uint8(0), u.Data[0].Section.Syntax.Header.SectionNumber)
+	u, ok = tr.Subtable(SubtableKey{PID: 4096, TableID: 2, TableIDExtension: 1})
+	assert.True(t, ok)
+	assert.True(t, u.Complete)
+	assert.Equal(t, uint8(1), u.VersionNumber)
+	_, ok = tr.Subtable(SubtableKey{PID: 4096, TableID: 2, TableIDExtension: 3})
+	assert.False(t, ok)
+
+	// EITs of services sharing a service ID across transport streams
+	e1 := subtableData(0x12, 0x50, 1, 0, 0, 9)
+	e1.EIT = &EITData{SegmentLastSectionNumber: 0, TransportStreamID: 1}
+	u, _ = tr.AddData(e1)
+	assert.False(t, u.Complete)
+	e2 := subtableData(0x12, 0x50, 1, 0, 0, 0)
+	e2.EIT = &EITData{TransportStreamID: 2}
+	u, _ = tr.AddData(e2)
+	assert.True(t, u.Complete)
+	assert.Len(t, tr.Keys(0x12), 2)
+
+	// EIT segments that are not full
+	e3 := subtableData(0x12, 0x50, 1, 0, 8, 9)
+	e3.EIT = &EITData{SegmentLastSectionNumber: 9, TransportStreamID: 1}
+	u, _ = tr.AddData(e3)
+	assert.False(t, u.Complete)
+	e4 := subtableData(0x12, 0x50, 1, 0, 9, 9)
+	e4.EIT = &EITData{SegmentLastSectionNumber: 9, TransportStreamID: 1}
+	u, _ = tr.AddData(e4)
+	assert.True(t, u.Completed)
+}
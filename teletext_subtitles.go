@@ -0,0 +1,170 @@
+package astits
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TeletextSubtitlePage represents a decoded teletext subtitle page
+// Teletext decoding is out of the scope of this package, pages must be decoded beforehand, for instance out of the
+// data of the PES carrying them.
+type TeletextSubtitlePage struct {
+	Lines    []string // An empty page clears the previous one
+	Magazine uint8
+	Page     uint8 // Same representation as in the teletext descriptor, e.g. 88 for page 888 of magazine 8
+	PTS      *ClockReference
+}
+
+// TeletextCue represents a timed text cue built out of teletext subtitle pages
+// Start and End are relative to the PTS of the first page added to the builder
+type TeletextCue struct {
+	End      time.Duration
+	Language string
+	Lines    []string
+	Magazine uint8
+	Page     uint8
+	Start    time.Duration
+}
+
+// TeletextCueBuilder builds timed text cues out of decoded teletext subtitle pages
+// Only subtitle pages listed in the teletext descriptor items it has been created with are taken into account. A page
+// is displayed until the next page with the same magazine and page number is added.
+type TeletextCueBuilder struct {
+	cues     []*TeletextCue
+	current  map[uint16]*TeletextCue // Indexed by magazine and page
+	firstPTS *ClockReference
+	items    map[uint16]*DescriptorTeletextItem // Indexed by magazine and page
+}
+
+// NewTeletextCueBuilder creates a new teletext cue builder
+func NewTeletextCueBuilder(items []*DescriptorTeletextItem) *TeletextCueBuilder {
+	b := &TeletextCueBuilder{
+		current: make(map[uint16]*TeletextCue),
+		items:   make(map[uint16]*DescriptorTeletextItem),
+	}
+	for _, itm := range items {
+		if itm.Type != TeletextTypeTeletextSubtitlePage && itm.Type != TeletextTypeTeletextSubtitlePageForHearingImpairedPeople {
+			continue
+		}
+		b.items[teletextPageKey(itm.Magazine, itm.Page)] = itm
+	}
+	return b
+}
+
+func teletextPageKey(magazine, page uint8) uint16 {
+	return uint16(magazine&0x7)<<8 | uint16(page)
+}
+
+// AddPage adds a decoded teletext subtitle page
+func (b *TeletextCueBuilder) AddPage(p *TeletextSubtitlePage) {
+	// Page is not a subtitle page
+	k := teletextPageKey(p.Magazine, p.Page)
+	itm, ok := b.items[k]
+	if !ok || p.PTS == nil {
+		return
+	}
+
+	// Get position
+	if b.firstPTS == nil {
+		b.firstPTS = p.PTS
+	}
+	d := clockReferenceBaseDuration(clockReferenceBaseDiff(p.PTS.Base, b.firstPTS.Base))
+
+	// End current cue
+	b.end(k, d)
+
+	// Page is empty
+	var ls []string
+	for _, l := range p.Lines {
+		if l = strings.TrimSpace(l); l != "" {
+			ls = append(ls, l)
+		}
+	}
+	if len(ls) == 0 {
+		return
+	}
+
+	// Start new cue
+	b.current[k] = &TeletextCue{
+		Language: string(itm.Language),
+		Lines:    ls,
+		Magazine: p.Magazine,
+		Page:     p.Page,
+		Start:    d,
+	}
+}
+
+func (b *TeletextCueBuilder) end(k uint16, d time.Duration) {
+	c, ok := b.current[k]
+	if !ok {
+		return
+	}
+	delete(b.current, k)
+	if d <= c.Start {
+		return
+	}
+	c.End = d
+	b.cues = append(b.cues, c)
+}
+
+// Close ends the cues still displayed at pts
+func (b *TeletextCueBuilder) Close(pts *ClockReference) {
+	if b.firstPTS == nil {
+		return
+	}
+	d := clockReferenceBaseDuration(clockReferenceBaseDiff(pts.Base, b.firstPTS.Base))
+	for k := range b.current {
+		b.end(k, d)
+	}
+}
+
+// Cues returns the cues that have ended for the provided magazine and page, sorted by start time
+func (b *TeletextCueBuilder) Cues(magazine, page uint8) (cs []*TeletextCue) {
+	k := teletextPageKey(magazine, page)
+	for _, c := range b.cues {
+		if teletextPageKey(c.Magazine, c.Page) == k {
+			cs = append(cs, c)
+		}
+	}
+	return
+}
+
+// WriteSRT writes cues in the SRT format
+func WriteSRT(w io.Writer, cs []*TeletextCue) (err error) {
+	for idx, c := range cs {
+		if _, err = fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", idx+1, formatTimedTextDuration(c.Start, ","), formatTimedTextDuration(c.End, ","), strings.Join(c.Lines, "\n")); err != nil {
+			err = fmt.Errorf("astits: writing SRT cue failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// WriteWebVTT writes cues in the WebVTT format
+func WriteWebVTT(w io.Writer, cs []*TeletextCue) (err error) {
+	// Header
+	if _, err = io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		err = fmt.Errorf("astits: writing WebVTT header failed: %w", err)
+		return
+	}
+
+	// Loop through cues
+	for _, c := range cs {
+		if _, err = fmt.Fprintf(w, "%s --> %s\n%s\n\n", formatTimedTextDuration(c.Start, "."), formatTimedTextDuration(c.End, "."), strings.Join(c.Lines, "\n")); err != nil {
+			err = fmt.Errorf("astits: writing WebVTT cue failed: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// formatTimedTextDuration formats a duration as hh:mm:ss followed by the milliseconds separator and milliseconds
+func formatTimedTextDuration(d time.Duration, sep string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
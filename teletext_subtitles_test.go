@@ -0,0 +1,39 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeletextCueBuilder(t *testing.T) {
+	b := NewTeletextCueBuilder([]*DescriptorTeletextItem{
+		{Language: []byte("eng"), Magazine: 0, Page: 88, Type: TeletextTypeTeletextSubtitlePage},
+		{Language: []byte("fra"), Magazine: 1, Page: 0, Type: TeletextTypeInitialTeletextPage},
+	})
+	b.AddPage(&TeletextSubtitlePage{Lines: []string{" Hello ", ""}, Magazine: 0, Page: 88, PTS: &ClockReference{Base: 90000}})
+	b.AddPage(&TeletextSubtitlePage{Lines: []string{"Ignored"}, Magazine: 1, Page: 0, PTS: &ClockReference{Base: 90000}})
+	b.AddPage(&TeletextSubtitlePage{Lines: []string{"World", "!"}, Magazine: 0, Page: 88, PTS: &ClockReference{Base: 270000}})
+	b.AddPage(&TeletextSubtitlePage{Magazine: 0, Page: 88, PTS: &ClockReference{Base: 360045}})
+	b.AddPage(&TeletextSubtitlePage{Lines: []string{"Bye"}, Magazine: 0, Page: 88, PTS: &ClockReference{Base: 324090000}})
+	b.Close(&ClockReference{Base: 324180000})
+	cs := b.Cues(0, 88)
+	assert.Equal(t, []*TeletextCue{
+		{End: 2 * time.Second, Language: "eng", Lines: []string{"Hello"}, Page: 88},
+		{End: 3*time.Second + 500*time.Microsecond, Language: "eng", Lines: []string{"World", "!"}, Page: 88, Start: 2 * time.Second},
+		{End: time.Hour + time.Second, Language: "eng", Lines: []string{"Bye"}, Page: 88, Start: time.Hour},
+	}, cs)
+	assert.Empty(t, b.Cues(1, 0))
+
+	// SRT
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteSRT(buf, cs))
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:02,000\nHello\n\n2\n00:00:02,000 --> 00:00:03,000\nWorld\n!\n\n3\n01:00:00,000 --> 01:00:01,000\nBye\n\n", buf.String())
+
+	// WebVTT
+	buf.Reset()
+	assert.NoError(t, WriteWebVTT(buf, cs[:1]))
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:02.000\nHello\n\n", buf.String())
+}
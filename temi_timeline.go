@@ -0,0 +1,160 @@
+package astits
+
+import (
+	"sync"
+	"time"
+
+	"github.com/asticode/go-astikit"
+)
+
+// Offset between the NTP epoch (1900) and the unix epoch, in seconds
+const ntpEpochOffset = 2208988800
+
+// TEMIPoint represents a TEMI timeline descriptor occurrence, anchored to the PTS of the PES starting in the same
+// packet
+type TEMIPoint struct {
+	Discontinuity  bool
+	MediaTime      time.Duration // Media timestamp converted with the timescale, 0 if there is no media timestamp
+	MediaTimestamp uint64
+	NTPTime        time.Time // Zero if there is no NTP timestamp
+	Paused         bool
+	PID            uint16
+	PTPTime        time.Time       // Zero if there is no PTP timestamp. PTP counts TAI seconds, no leap second is applied.
+	PTS            *ClockReference // Nil if no PES header with a PTS starts in the packet
+	Timescale      uint32
+	TimelineID     uint8
+}
+
+// TEMITimeline gathers the TEMI timeline descriptors carried in the adaptation fields of each PID
+// It allows mapping presentation timestamps onto external media timelines, e.g. for companion screen synchronisation.
+type TEMITimeline struct {
+	m    *sync.Mutex
+	last map[uint16]map[uint8]TEMIPoint // Indexed by PID and timeline ID
+}
+
+// NewTEMITimeline creates a new TEMI timeline
+func NewTEMITimeline() *TEMITimeline {
+	return &TEMITimeline{
+		m:    &sync.Mutex{},
+		last: make(map[uint16]map[uint8]TEMIPoint),
+	}
+}
+
+// AddPacket updates the timeline with a new packet and returns the TEMI points it carries
+func (t *TEMITimeline) AddPacket(p *Packet) (ps []TEMIPoint) {
+	// No AF descriptors
+	if !p.Header.HasAdaptationField || p.AdaptationField == nil || !p.AdaptationField.HasAdaptationExtensionField ||
+		p.AdaptationField.AdaptationExtensionField == nil || !p.AdaptationField.AdaptationExtensionField.HasAFDescriptors {
+		return
+	}
+
+	// Get PTS
+	pts := temiPacketPTS(p)
+
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Loop through descriptors
+	for _, d := range p.AdaptationField.AdaptationExtensionField.AFDescriptors {
+		// Not a timeline descriptor
+		if d.Timeline == nil {
+			continue
+		}
+
+		// Create point
+		pt := newTEMIPoint(p.Header.PID, d.Timeline, pts)
+
+		// Store point
+		if _, ok := t.last[pt.PID]; !ok {
+			t.last[pt.PID] = make(map[uint8]TEMIPoint)
+		}
+		t.last[pt.PID][pt.TimelineID] = pt
+		ps = append(ps, pt)
+	}
+	return
+}
+
+func newTEMIPoint(pid uint16, d *AFDescriptorTimeline, pts *ClockReference) (pt TEMIPoint) {
+	pt = TEMIPoint{
+		Discontinuity: d.Discontinuity,
+		Paused:        d.Paused,
+		PID:           pid,
+		PTS:           pts,
+		TimelineID:    d.TimelineID,
+	}
+
+	// Media timestamp
+	if d.HasTimestamp > 0 {
+		pt.MediaTimestamp = d.MediaTimestamp
+		pt.Timescale = d.Timescale
+		if d.Timescale > 0 {
+			pt.MediaTime = time.Duration(d.MediaTimestamp/uint64(d.Timescale))*time.Second +
+				time.Duration(d.MediaTimestamp%uint64(d.Timescale)*1e9/uint64(d.Timescale))
+		}
+	}
+
+	// NTP: 32 bits seconds since 1900 followed by a 32 bits fraction
+	if d.HasNTP {
+		pt.NTPTime = time.Unix(int64(d.NTPTimestamp>>32)-ntpEpochOffset, int64((d.NTPTimestamp&0xffffffff)*1e9>>32)).UTC()
+	}
+
+	// PTP: 48 bits seconds since 1970 followed by 32 bits nanoseconds
+	if d.HasPTP && len(d.PTPTimestamp) == 10 {
+		pt.PTPTime = time.Unix(int64(bytesToUint64(d.PTPTimestamp[:6])), int64(bytesToUint64(d.PTPTimestamp[6:]))).UTC()
+	}
+	return
+}
+
+// temiPacketPTS returns the PTS of the PES starting in the packet, if any
+func temiPacketPTS(p *Packet) *ClockReference {
+	// No PES start
+	if !p.Header.PayloadUnitStartIndicator || len(p.Payload) < 3 || p.Payload[0] != 0 || p.Payload[1] != 0 || p.Payload[2] != 1 {
+		return nil
+	}
+
+	// Parse header
+	i := astikit.NewBytesIterator(p.Payload)
+	i.Skip(3)
+	h, _, _, err := parsePESHeader(i)
+	if err != nil || h.OptionalHeader == nil {
+		return nil
+	}
+	return h.OptionalHeader.PTS
+}
+
+// Last returns the last point of a timeline of a PID
+func (t *TEMITimeline) Last(pid uint16, timelineID uint8) (pt TEMIPoint, ok bool) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get point
+	if _, ok = t.last[pid]; !ok {
+		return
+	}
+	pt, ok = t.last[pid][timelineID]
+	return
+}
+
+// MediaTimeAt returns the media time of a timeline of a PID at the provided PTS
+// It is extrapolated from the last point of the timeline, unless the timeline is paused. ok is false if there is no
+// such point or if it is not anchored to a PTS or doesn't carry a media timestamp.
+func (t *TEMITimeline) MediaTimeAt(pid uint16, timelineID uint8, pts ClockReference) (d time.Duration, ok bool) {
+	// Get last point
+	var pt TEMIPoint
+	if pt, ok = t.Last(pid, timelineID); !ok || pt.PTS == nil || pt.Timescale == 0 {
+		ok = false
+		return
+	}
+
+	// Paused
+	d = pt.MediaTime
+	if pt.Paused {
+		return
+	}
+
+	// Extrapolate
+	d += clockReferenceBaseDuration(clockReferenceBaseDiff(pts.Base, pt.PTS.Base))
+	return
+}
@@ -0,0 +1,84 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTEMITimeline(t *testing.T) {
+	// Build packet
+	pes := &PESData{
+		Data: []byte{1, 2, 3},
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{MarkerBits: 2, PTS: &ClockReference{Base: 90000}, PTSDTSIndicator: PTSDTSIndicatorOnlyPTS},
+			StreamID:       0xe0,
+		},
+	}
+	b := make([]byte, 184)
+	n, err := pes.Serialise(b)
+	assert.NoError(t, err)
+	ptp := []byte{0, 0, 0x5f, 0x5e, 0x10, 0, 0x1d, 0xcd, 0x65, 0}
+	p := &Packet{
+		AdaptationField: &PacketAdaptationField{
+			AdaptationExtensionField: &PacketAdaptationExtensionField{
+				AFDescriptors: []*AFDescriptor{
+					{Tag: AFDescriptorTagLocation, Location: &AFDescriptorLocation{}},
+					{Tag: AFDescriptorTagTimeline, Timeline: &AFDescriptorTimeline{
+						HasNTP:         true,
+						HasPTP:         true,
+						HasTimestamp:   1,
+						MediaTimestamp: 2500,
+						NTPTimestamp:   (ntpEpochOffset+1)<<32 | 1<<31,
+						PTPTimestamp:   ptp,
+						TimelineID:     1,
+						Timescale:      1000,
+					}},
+				},
+				HasAFDescriptors: true,
+			},
+			HasAdaptationExtensionField: true,
+		},
+		Header:  &PacketHeader{HasAdaptationField: true, HasPayload: true, PayloadUnitStartIndicator: true, PID: 256},
+		Payload: b[:n],
+	}
+
+	// No point yet
+	tl := NewTEMITimeline()
+	_, ok := tl.MediaTimeAt(256, 1, ClockReference{Base: 90000})
+	assert.False(t, ok)
+
+	// Add packet
+	ps := tl.AddPacket(p)
+	assert.Equal(t, []TEMIPoint{{
+		MediaTime:      2500 * time.Millisecond,
+		MediaTimestamp: 2500,
+		NTPTime:        time.Unix(1, 5e8).UTC(),
+		PID:            256,
+		PTPTime:        time.Unix(1600000000, 500000000).UTC(),
+		PTS:            &ClockReference{Base: 90000},
+		Timescale:      1000,
+		TimelineID:     1,
+	}}, ps)
+	pt, ok := tl.Last(256, 1)
+	assert.True(t, ok)
+	assert.Equal(t, ps[0], pt)
+	_, ok = tl.Last(256, 2)
+	assert.False(t, ok)
+
+	// Media time
+	d, ok := tl.MediaTimeAt(256, 1, ClockReference{Base: 135000})
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	// Paused
+	p.AdaptationField.AdaptationExtensionField.AFDescriptors[1].Timeline.Paused = true
+	tl.AddPacket(p)
+	d, ok = tl.MediaTimeAt(256, 1, ClockReference{Base: 135000})
+	assert.True(t, ok)
+	assert.Equal(t, 2500*time.Millisecond, d)
+
+	// No AF descriptors
+	assert.Empty(t, tl.AddPacket(&Packet{Header: &PacketHeader{PID: 256}}))
+}